@@ -61,6 +61,7 @@ type HandlerT struct {
 	// For the pprof http server
 	handlerInited bool
 	pprofServer   *http.Server
+	metricsLite   bool // serves /debug/metrics-lite on the pprof server
 
 	logDir    string   // log directory path
 	vmLogFile *os.File // a file descriptor of the vmlog output file
@@ -144,6 +145,9 @@ func (h *HandlerT) StartPProf(ptrAddr *string, ptrPort *int) error {
 		// from the registry into expvar, and execute regular expvar handler.
 		exp.Exp(metrics.DefaultRegistry)
 		http.Handle("/memsize/", http.StripPrefix("/memsize", &Memsize))
+		if h.metricsLite {
+			http.Handle("/debug/metrics-lite", MetricsLiteHandler(metrics.DefaultRegistry, metricsLitePrefixes))
+		}
 		h.handlerInited = true
 	}
 
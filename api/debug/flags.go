@@ -147,6 +147,13 @@ var (
 		EnvVars:  []string{"KLAYTN_PPROFADDR"},
 		Category: "LOGGING AND DEBUGGING",
 	}
+	metricsLiteFlag = &cli.BoolFlag{
+		Name:     "metrics-lite",
+		Usage:    "Serve the storage and consensus metrics at /debug/metrics-lite of the pprof HTTP server (requires --pprof)",
+		Aliases:  []string{"debug-profile.metrics-lite.enable"},
+		EnvVars:  []string{"KLAYTN_METRICS_LITE"},
+		Category: "LOGGING AND DEBUGGING",
+	}
 	memprofileFlag = &cli.StringFlag{
 		Name:     "memprofile",
 		Usage:    "Write memory profile to the given file",
@@ -201,6 +208,7 @@ var Flags = []cli.Flag{
 	altsrc.NewBoolFlag(pprofFlag),
 	altsrc.NewStringFlag(pprofAddrFlag),
 	altsrc.NewIntFlag(pprofPortFlag),
+	altsrc.NewBoolFlag(metricsLiteFlag),
 	altsrc.NewStringFlag(memprofileFlag),
 	altsrc.NewIntFlag(memprofilerateFlag),
 	altsrc.NewIntFlag(blockprofilerateFlag),
@@ -325,7 +333,11 @@ func Setup(ctx *cli.Context) error {
 	}
 	Handler.memFile = ctx.String(memprofileFlag.Name)
 
-	// pprof server
+	// pprof server, which also serves the metrics-lite
+	Handler.metricsLite = ctx.Bool(metricsLiteFlag.Name)
+	if Handler.metricsLite && !ctx.Bool(pprofFlag.Name) {
+		return fmt.Errorf("--%s requires --%s, since the metrics are served by the pprof server", metricsLiteFlag.Name, pprofFlag.Name)
+	}
 	if ctx.Bool(pprofFlag.Name) {
 		addr := ctx.String(pprofAddrFlag.Name)
 		port := ctx.Int(pprofPortFlag.Name)
@@ -0,0 +1,117 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// metricsLitePrefixes are the prefixes of the metrics rendered by the
+// metrics-lite handler, which are the storage and the consensus metrics.
+var metricsLitePrefixes = []string{"klay/db/", "consensus/istanbul/"}
+
+// MetricsLiteHandler returns a handler rendering the current values of the
+// metrics of the registry whose names start with one of the prefixes. The
+// metrics are rendered as plain text, one metric per line, or as JSON if the
+// "format" query parameter is "json". Unlike the metrics export, it does not
+// require any configuration, which helps a quick look at a running node.
+func MetricsLiteHandler(r metrics.Registry, prefixes []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		values := collectMetricsLite(r, prefixes)
+
+		if req.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			if err := json.NewEncoder(w).Encode(values); err != nil {
+				logger.Error("Failed to render the metrics", "err", err)
+			}
+			return
+		}
+
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, name := range names {
+			fields := values[name]
+			keys := make([]string, 0, len(fields))
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			line := make([]string, 0, len(keys)+1)
+			line = append(line, name)
+			for _, key := range keys {
+				line = append(line, fmt.Sprintf("%s=%v", key, fields[key]))
+			}
+			fmt.Fprintln(w, strings.Join(line, " "))
+		}
+	})
+}
+
+// collectMetricsLite returns the current values of the metrics whose names
+// start with one of the prefixes, keyed by the metric names.
+func collectMetricsLite(r metrics.Registry, prefixes []string) map[string]map[string]interface{} {
+	values := make(map[string]map[string]interface{})
+	r.Each(func(name string, i interface{}) {
+		if !hasAnyPrefix(name, prefixes) {
+			return
+		}
+		switch metric := i.(type) {
+		case metrics.Counter:
+			values[name] = map[string]interface{}{"count": metric.Count()}
+		case metrics.Gauge:
+			values[name] = map[string]interface{}{"value": metric.Value()}
+		case metrics.GaugeFloat64:
+			values[name] = map[string]interface{}{"value": metric.Value()}
+		case metrics.Meter:
+			m := metric.Snapshot()
+			values[name] = map[string]interface{}{
+				"count": m.Count(), "rate1": m.Rate1(), "rate5": m.Rate5(), "mean": m.RateMean(),
+			}
+		case metrics.Timer:
+			t := metric.Snapshot()
+			values[name] = map[string]interface{}{
+				"count": t.Count(), "mean": t.Mean(), "max": t.Max(), "p99": t.Percentile(0.99), "rate1": t.Rate1(),
+			}
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			values[name] = map[string]interface{}{
+				"count": h.Count(), "mean": h.Mean(), "max": h.Max(), "p99": h.Percentile(0.99),
+			}
+		}
+	})
+	return values
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsLiteHandler(t *testing.T) {
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredMeter("klay/db/chaindata/batchwrite/time", r).Mark(42)
+	metrics.NewRegisteredGauge("consensus/istanbul/core/currentRound", r).Update(3)
+	metrics.NewRegisteredCounter("consensus/istanbul/core/roundChange", r).Inc(2)
+	metrics.NewRegisteredGauge("p2p/peers", r).Update(7)
+
+	handler := MetricsLiteHandler(r, metricsLitePrefixes)
+
+	// plain text, one metric per line in the order of the names
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics-lite", nil))
+	assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "consensus/istanbul/core/currentRound value=3", lines[0])
+		assert.Equal(t, "consensus/istanbul/core/roundChange count=2", lines[1])
+		assert.True(t, strings.HasPrefix(lines[2], "klay/db/chaindata/batchwrite/time count=42 "), lines[2])
+	}
+
+	// JSON
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics-lite?format=json", nil))
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	var values map[string]map[string]float64
+	if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &values)) {
+		assert.Len(t, values, 3)
+		assert.Equal(t, float64(42), values["klay/db/chaindata/batchwrite/time"]["count"])
+		assert.Equal(t, float64(3), values["consensus/istanbul/core/currentRound"]["value"])
+		assert.NotContains(t, values, "p2p/peers")
+	}
+}
@@ -66,6 +66,12 @@ func init() {
 		// See utils/nodecmd/db_migration.go:
 		nodecmd.MigrationCommand,
 
+		// See utils/nodecmd/dbresetcmd.go:
+		nodecmd.DBResetCommand,
+
+		// See utils/nodecmd/dbbenchcmd.go:
+		nodecmd.DBBenchCommand,
+
 		// See utils/nodecmd/util.go:
 		nodecmd.UtilCommand,
 
@@ -68,6 +68,15 @@ func init() {
 
 		// See utils/nodecmd/snapshot.go:
 		nodecmd.SnapshotCommand,
+
+		// See utils/nodecmd/dbresetcmd.go:
+		nodecmd.DBResetCommand,
+
+		// See utils/nodecmd/dbbenchcmd.go:
+		nodecmd.DBBenchCommand,
+
+		// See utils/nodecmd/verifyconsensuscmd.go:
+		nodecmd.VerifyConsensusCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -70,9 +70,12 @@ const (
 	ClientIdentifier = "klay" // Client identifier to advertise over the network
 	SCNNetworkType   = "scn"  // Service Chain Network
 	MNNetworkType    = "mn"   // Mainnet Network
-	gitCommit        = ""
 )
 
+// GitCommit returns the git commit of the build, which is reported by the node.
+// It is replaced by nodecmd to return the commit set by linker flags.
+var GitCommit = func() string { return "" }
+
 // These settings ensure that TOML keys use the same names as Go struct fields.
 var TomlSettings = toml.Config{
 	NormFieldName: func(rt reflect.Type, key string) string {
@@ -116,7 +119,8 @@ func LoadConfig(file string, cfg *KlayConfig) error {
 func DefaultNodeConfig() node.Config {
 	cfg := node.DefaultConfig
 	cfg.Name = ClientIdentifier
-	cfg.Version = params.VersionWithCommit(gitCommit)
+	cfg.GitCommit = GitCommit()
+	cfg.Version = params.VersionWithCommit(cfg.GitCommit)
 	cfg.HTTPModules = append(cfg.HTTPModules, "klay", "shh", "eth")
 	cfg.WSModules = append(cfg.WSModules, "klay", "shh", "eth")
 	cfg.IPCPath = "klay.ipc"
@@ -367,6 +371,10 @@ func (kCfg *KlayConfig) SetNodeConfig(ctx *cli.Context) {
 	cfg.DisableUnsafeDebug = ctx.Bool(UnsafeDebugDisableFlag.Name)
 
 	SetP2PConfig(ctx, &cfg.P2P)
+	cfg.NodeType = NodeTypeFlag.Value
+	if ctx.IsSet(NodeTypeFlag.Name) {
+		cfg.NodeType = ctx.String(NodeTypeFlag.Name)
+	}
 	setIPC(ctx, cfg)
 
 	// httptype is http
@@ -386,10 +394,10 @@ func (kCfg *KlayConfig) SetNodeConfig(ctx *cli.Context) {
 	setAPIConfig(ctx)
 	setNodeUserIdent(ctx, cfg)
 
-	if dbtype := database.DBType(ctx.String(DbTypeFlag.Name)).ToValid(); len(dbtype) != 0 {
+	if dbtype, err := database.ParseDBType(ctx.String(DbTypeFlag.Name)); err == nil {
 		cfg.DBType = dbtype
 	} else {
-		logger.Crit("invalid dbtype", "dbtype", ctx.String(DbTypeFlag.Name))
+		logger.Crit("invalid dbtype", "err", err)
 	}
 	cfg.DataDir = ctx.String(DataDirFlag.Name)
 	cfg.ChainDataDir = ctx.String(ChainDataDirFlag.Name)
@@ -551,10 +559,10 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 
 	cfg.NetworkId, cfg.IsPrivate = getNetworkId(ctx)
 
-	if dbtype := database.DBType(ctx.String(DbTypeFlag.Name)).ToValid(); len(dbtype) != 0 {
+	if dbtype, err := database.ParseDBType(ctx.String(DbTypeFlag.Name)); err == nil {
 		cfg.DBType = dbtype
 	} else {
-		logger.Crit("invalid dbtype", "dbtype", ctx.String(DbTypeFlag.Name))
+		logger.Crit("invalid dbtype", "err", err)
 	}
 	cfg.SingleDB = ctx.Bool(SingleDBFlag.Name)
 	cfg.NumStateTrieShards = ctx.Uint(NumStateTrieShardsFlag.Name)
@@ -567,6 +575,7 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 
 	cfg.LevelDBCompression = database.LevelDBCompressionType(ctx.Int(LevelDBCompressionTypeFlag.Name))
 	cfg.LevelDBBufferPool = !ctx.Bool(LevelDBNoBufferPoolFlag.Name)
+	cfg.MinFreeDiskSpace = ctx.Int(MinFreeDiskSpaceFlag.Name)
 	cfg.EnableDBPerfMetrics = !ctx.Bool(DBNoPerformanceMetricsFlag.Name)
 	cfg.LevelDBCacheSize = ctx.Int(LevelDBCacheSizeFlag.Name)
 
@@ -593,6 +602,20 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	cfg.DynamoDBConfig.ReadCapacityUnits = ctx.Int64(DynamoDBReadCapacityFlag.Name)
 	cfg.DynamoDBConfig.WriteCapacityUnits = ctx.Int64(DynamoDBWriteCapacityFlag.Name)
 	cfg.DynamoDBConfig.ReadOnly = ctx.Bool(DynamoDBReadOnlyFlag.Name)
+	cfg.DynamoDBConfig.SkipWriteCheck = ctx.Bool(DynamoDBSkipWriteCheckFlag.Name)
+	cfg.DynamoDBConfig.LogAWSRequests = ctx.Bool(DynamoDBLogRequestsFlag.Name)
+	cfg.DynamoDBConfig.AWSLogLevel = ctx.String(DynamoDBAWSLogLevelFlag.Name)
+	cfg.DynamoDBConfig.Endpoint = ctx.String(DynamoDBEndpointFlag.Name)
+	cfg.DynamoDBConfig.S3Endpoint = ctx.String(DynamoDBS3EndpointFlag.Name)
+	cfg.DynamoDBConfig.S3Bucket = ctx.String(DynamoDBS3BucketFlag.Name)
+	cfg.DynamoDBConfig.S3KeyPrefix = ctx.String(DynamoDBS3KeyPrefixFlag.Name)
+	cfg.DynamoDBConfig.S3CompressionThreshold = ctx.Int(DynamoDBS3CompressionThresholdFlag.Name)
+	cfg.DynamoDBConfig.S3MultipartThreshold = ctx.Int(DynamoDBS3MultipartThresholdFlag.Name)
+	cfg.DynamoDBConfig.S3ReadMaxRetries = ctx.Int(DynamoDBS3ReadMaxRetriesFlag.Name)
+	cfg.DynamoDBConfig.S3WriteMaxRetries = ctx.Int(DynamoDBS3WriteMaxRetriesFlag.Name)
+	cfg.DynamoDBConfig.SlowOpThreshold = ctx.Duration(DynamoDBSlowOpThresholdFlag.Name)
+	cfg.DynamoDBConfig.CoalesceGets = ctx.Bool(DynamoDBCoalesceGetsFlag.Name)
+	cfg.DynamoDBConfig.EventuallyConsistentReads = ctx.Bool(DynamoDBEventuallyConsistentReadsFlag.Name)
 
 	if gcmode := ctx.String(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		log.Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -688,6 +711,18 @@ func (kCfg *KlayConfig) SetKlayConfig(ctx *cli.Context, stack *node.Node) {
 	if ctx.IsSet(BlockGenerationTimeLimitFlag.Name) {
 		params.BlockGenerationTimeLimit = ctx.Duration(BlockGenerationTimeLimitFlag.Name)
 	}
+	if ctx.IsSet(VerifyCommitRLPFlag.Name) {
+		cfg.Istanbul.VerifyCommitRLP = ctx.Bool(VerifyCommitRLPFlag.Name)
+	}
+	if ctx.IsSet(RoundChangeHistorySizeFlag.Name) {
+		cfg.Istanbul.RoundChangeHistorySize = ctx.Uint64(RoundChangeHistorySizeFlag.Name)
+	}
+	if ctx.IsSet(PrioritizePreprepareFlag.Name) {
+		cfg.Istanbul.PrioritizePreprepare = ctx.Bool(PrioritizePreprepareFlag.Name)
+	}
+	if ctx.IsSet(QuarantineThresholdFlag.Name) {
+		cfg.Istanbul.QuarantineThreshold = ctx.Uint64(QuarantineThresholdFlag.Name)
+	}
 
 	params.OpcodeComputationCostLimit = ctx.Uint64(OpcodeComputationCostLimitFlag.Name)
 
@@ -52,6 +52,10 @@ var FlagGroups = []FlagGroup{
 			StartBlockNumberFlag,
 			BlockGenerationIntervalFlag,
 			BlockGenerationTimeLimitFlag,
+			VerifyCommitRLPFlag,
+			RoundChangeHistorySizeFlag,
+			PrioritizePreprepareFlag,
+			QuarantineThresholdFlag,
 			OpcodeComputationCostLimitFlag,
 		},
 	},
@@ -93,6 +97,7 @@ var FlagGroups = []FlagGroup{
 			NumStateTrieShardsFlag,
 			LevelDBCompressionTypeFlag,
 			LevelDBNoBufferPoolFlag,
+			MinFreeDiskSpaceFlag,
 			RocksDBSecondaryFlag,
 			RocksDBCacheSizeFlag,
 			RocksDBDumpMallocStatFlag,
@@ -108,6 +113,20 @@ var FlagGroups = []FlagGroup{
 			DynamoDBReadCapacityFlag,
 			DynamoDBWriteCapacityFlag,
 			DynamoDBReadOnlyFlag,
+			DynamoDBSkipWriteCheckFlag,
+			DynamoDBLogRequestsFlag,
+			DynamoDBAWSLogLevelFlag,
+			DynamoDBEndpointFlag,
+			DynamoDBS3EndpointFlag,
+			DynamoDBS3BucketFlag,
+			DynamoDBS3KeyPrefixFlag,
+			DynamoDBS3CompressionThresholdFlag,
+			DynamoDBS3MultipartThresholdFlag,
+			DynamoDBS3ReadMaxRetriesFlag,
+			DynamoDBS3WriteMaxRetriesFlag,
+			DynamoDBSlowOpThresholdFlag,
+			DynamoDBCoalesceGetsFlag,
+			DynamoDBEventuallyConsistentReadsFlag,
 			NoParallelDBWriteFlag,
 			SenderTxHashIndexingFlag,
 			DBNoPerformanceMetricsFlag,
@@ -180,6 +199,7 @@ var FlagGroups = []FlagGroup{
 			DstDynamoDBIsProvisionedFlag,
 			DstDynamoDBReadCapacityFlag,
 			DstDynamoDBWriteCapacityFlag,
+			DstDynamoDBIdempotentImportFlag,
 			DstRocksDBSecondaryFlag,
 			DstRocksDBCacheSizeFlag,
 			DstRocksDBDumpMallocStatFlag,
@@ -254,6 +274,7 @@ var FlagGroups = []FlagGroup{
 			MetricsEnabledFlag,
 			PrometheusExporterFlag,
 			PrometheusExporterPortFlag,
+			PrometheusBridgeFlag,
 		},
 	},
 	{
@@ -29,6 +29,7 @@ import (
 
 	"github.com/klaytn/klaytn/blockchain"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher"
 	"github.com/klaytn/klaytn/datasync/chaindatafetcher/kafka"
 	"github.com/klaytn/klaytn/datasync/dbsyncer"
@@ -350,6 +351,14 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_LEVELDB_NO_BUFFER_POOL"},
 		Category: "DATABASE",
 	}
+	MinFreeDiskSpaceFlag = &cli.IntFlag{
+		Name:     "db.min-free-disk-space",
+		Usage:    "Minimum free disk space (MiB) of the data directory to open a database on the local disk (0 = unchecked)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_MIN_FREE_DISK_SPACE"},
+		Category: "DATABASE",
+	}
 	RocksDBSecondaryFlag = &cli.BoolFlag{
 		Name:     "db.rocksdb.secondary",
 		Usage:    "Enable rocksdb secondary mode (read-only and catch-up with primary node dynamically)",
@@ -463,6 +472,110 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_DYNAMO_READ_ONLY"},
 		Category: "DATABASE",
 	}
+	DynamoDBSkipWriteCheckFlag = &cli.BoolFlag{
+		Name:     "db.dynamo.skip-write-check",
+		Usage:    "Skips checking if DynamoDB and S3 are writable on startup.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_SKIP_WRITE_CHECK"},
+		Category: "DATABASE",
+	}
+	DynamoDBLogRequestsFlag = &cli.BoolFlag{
+		Name:     "db.dynamo.log-requests",
+		Usage:    "Logs the AWS request IDs of all DynamoDB and S3 calls at debug level. The request IDs of failed calls are always logged.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_LOG_REQUESTS"},
+		Category: "DATABASE",
+	}
+	DynamoDBAWSLogLevelFlag = &cli.StringFlag{
+		Name:     "db.dynamo.aws-log-level",
+		Usage:    "Log level of the AWS SDK itself for DynamoDB and S3 calls (off, debug, debug-with-signing, debug-with-http-body, debug-with-request-retries, debug-with-request-errors, debug-with-event-stream-body)",
+		Value:    "off",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_AWS_LOG_LEVEL"},
+		Category: "DATABASE",
+	}
+	DynamoDBEndpointFlag = &cli.StringFlag{
+		Name:     "db.dynamo.endpoint",
+		Usage:    "Endpoint of DynamoDB, e.g. of a localstack. The default endpoint of the region is used if it is empty.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_ENDPOINT"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3EndpointFlag = &cli.StringFlag{
+		Name:     "db.dynamo.s3-endpoint",
+		Usage:    "Endpoint of S3, e.g. of a localstack. The default endpoint of the region is used if it is empty.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_ENDPOINT"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3BucketFlag = &cli.StringFlag{
+		Name:     "db.dynamo.s3-bucket",
+		Usage:    "S3 bucket storing the oversized values. The table name is used if it is empty.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_BUCKET"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3KeyPrefixFlag = &cli.StringFlag{
+		Name:     "db.dynamo.s3-key-prefix",
+		Usage:    "Prefix of the S3 object keys, which allows several tables to share a bucket",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_KEY_PREFIX"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3CompressionThresholdFlag = &cli.IntFlag{
+		Name:     "db.dynamo.s3-compression-threshold",
+		Usage:    "Size in bytes above which the values stored in S3 are gzip-compressed (0 = disabled)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_COMPRESSION_THRESHOLD"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3MultipartThresholdFlag = &cli.IntFlag{
+		Name:     "db.dynamo.s3-multipart-threshold",
+		Usage:    "Size in bytes above which the values are written to S3 by multipart upload (0 = disabled)",
+		Value:    database.GetDefaultDynamoDBConfig().S3MultipartThreshold,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_MULTIPART_THRESHOLD"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3ReadMaxRetriesFlag = &cli.IntFlag{
+		Name:     "db.dynamo.s3-read-max-retries",
+		Usage:    "Maximum number of retries of reading an S3 object (0 = default)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_READ_MAX_RETRIES"},
+		Category: "DATABASE",
+	}
+	DynamoDBS3WriteMaxRetriesFlag = &cli.IntFlag{
+		Name:     "db.dynamo.s3-write-max-retries",
+		Usage:    "Maximum number of retries of writing an S3 object (0 = default)",
+		Value:    0,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_S3_WRITE_MAX_RETRIES"},
+		Category: "DATABASE",
+	}
+	DynamoDBSlowOpThresholdFlag = &cli.DurationFlag{
+		Name:     "db.dynamo.slow-op-threshold",
+		Usage:    "Duration above which a DynamoDB get, put or batch write is logged as a slow operation (0 = disabled)",
+		Value:    database.GetDefaultDynamoDBConfig().SlowOpThreshold,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_SLOW_OP_THRESHOLD"},
+		Category: "DATABASE",
+	}
+	DynamoDBCoalesceGetsFlag = &cli.BoolFlag{
+		Name:     "db.dynamo.coalesce-gets",
+		Usage:    "Let concurrent DynamoDB reads of the same key share one request",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_COALESCE_GETS"},
+		Category: "DATABASE",
+	}
+	DynamoDBEventuallyConsistentReadsFlag = &cli.BoolFlag{
+		Name:     "db.dynamo.eventually-consistent-reads",
+		Usage:    "Read DynamoDB items with eventually consistent reads, which consume a half of read capacity but may return stale values",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_DB_DYNAMO_EVENTUALLY_CONSISTENT_READS"},
+		Category: "DATABASE",
+	}
 	NoParallelDBWriteFlag = &cli.BoolFlag{
 		Name:     "db.no-parallel-write",
 		Usage:    "Disables parallel writes of block data to persistent database",
@@ -779,6 +892,13 @@ var (
 		EnvVars:  []string{"KLAYTN_METRICUTILS_PROMETHEUSEXPORTERPORTFLAG"},
 		Category: "METRIC",
 	}
+	PrometheusBridgeFlag = &cli.BoolFlag{
+		Name:     metricutils.PrometheusBridgeFlag,
+		Usage:    "Export the metrics as Prometheus counters, gauges and summaries labeled with the storage backend and region, instead of gauges",
+		Aliases:  []string{"metrics-collection-reporting.prometheus-bridge"},
+		EnvVars:  []string{"KLAYTN_METRICUTILS_PROMETHEUSBRIDGEFLAG"},
+		Category: "METRIC",
+	}
 
 	// RPC settings
 	RPCEnabledFlag = &cli.BoolFlag{
@@ -1797,6 +1917,70 @@ var (
 		EnvVars:  []string{"KLAYTN_DST_DATADIR"},
 		Category: "DATABASE MIGRATION",
 	}
+	DBResetConfirmFlag = &cli.StringFlag{
+		Name:     "confirm",
+		Usage:    "Name of the DynamoDB table to be reset, which confirms that all of its data will be deleted",
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchDurationFlag = &cli.DurationFlag{
+		Name:     "bench.duration",
+		Usage:    "How long the database benchmark performs the operations",
+		Value:    time.Minute,
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchConcurrencyFlag = &cli.IntFlag{
+		Name:     "bench.concurrency",
+		Usage:    "Number of concurrent workers of the database benchmark",
+		Value:    16,
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchReadRatioFlag = &cli.Float64Flag{
+		Name:     "bench.read-ratio",
+		Usage:    "Ratio of reads among the operations of the database benchmark (0 to 1)",
+		Value:    0.5,
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchValueSizeFlag = &cli.IntFlag{
+		Name:     "bench.value-size",
+		Usage:    "Size in bytes of the values written by the database benchmark",
+		Value:    512,
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchOversizedSizeFlag = &cli.IntFlag{
+		Name:     "bench.oversized-size",
+		Usage:    "Size in bytes of the oversized values written by the database benchmark, which are stored in S3",
+		Value:    500 * 1024,
+		Category: "DATABASE MIGRATION",
+	}
+	DBBenchOversizedRateFlag = &cli.Float64Flag{
+		Name:     "bench.oversized-rate",
+		Usage:    "Ratio of oversized values among the values written by the database benchmark (0 to 1)",
+		Value:    0.01,
+		Category: "DATABASE MIGRATION",
+	}
+	VerifyConsensusFromFlag = &cli.Uint64Flag{
+		Name:     "from",
+		Usage:    "First block number whose consensus is verified",
+		Value:    1,
+		Category: "CONSENSUS",
+	}
+	VerifyConsensusToFlag = &cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Last block number whose consensus is verified (default = the head block)",
+		Category: "CONSENSUS",
+	}
+	DBMigrationDumpFileFlag = &cli.PathFlag{
+		Name:     "db.dump",
+		Usage:    "RLP dump file to be imported into the destination DB",
+		EnvVars:  []string{"KLAYTN_DB_DUMP"},
+		Category: "DATABASE MIGRATION",
+	}
+	DBMigrationBulkFlag = &cli.BoolFlag{
+		Name:     "bulk",
+		Usage:    "Migrate by the bulk load of the destination DB, which stages the items in S3 for DynamoDB",
+		EnvVars:  []string{"KLAYTN_DB_MIGRATION_BULK"},
+		Category: "DATABASE MIGRATION",
+	}
 	DstSingleDBFlag = &cli.BoolFlag{
 		Name:     "db.dst.single",
 		Usage:    "Create a single persistent storage. MiscDB, headerDB and etc are stored in one DB.",
@@ -1866,6 +2050,13 @@ var (
 		EnvVars:  []string{"KLAYTN_DB_DST_DYNAMO_WRITE_CAPACITY"},
 		Category: "DATABASE MIGRATION",
 	}
+	DstDynamoDBIdempotentImportFlag = &cli.BoolFlag{
+		Name:     "db.dst.dynamo.idempotent-import",
+		Usage:    "Skip the items which already exist in the dynamoDB table, which makes an interrupted migration resumable",
+		Aliases:  []string{"migration.dst.db.dynamo.idempotent-import"},
+		EnvVars:  []string{"KLAYTN_DB_DST_DYNAMO_IDEMPOTENT_IMPORT"},
+		Category: "DATABASE MIGRATION",
+	}
 	DstRocksDBSecondaryFlag = &cli.BoolFlag{
 		Name:     "db.dst.rocksdb.secondary",
 		Usage:    "Enable rocksdb secondary mode (read-only and catch-up with primary node dynamically)",
@@ -1962,6 +2153,40 @@ var (
 		EnvVars:  []string{"KLAYTN_BLOCK_GENERATION_TIME_LIMIT"},
 		Category: "KLAY",
 	}
+	VerifyCommitRLPFlag = &cli.BoolFlag{
+		Name: "consensus.verify-commit-rlp",
+		Usage: "Verify that a committed block is decoded back from its RLP encoding before it is persisted. " +
+			"This flag is only applicable to CN.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_CONSENSUS_VERIFY_COMMIT_RLP"},
+		Category: "KLAY",
+	}
+	RoundChangeHistorySizeFlag = &cli.Uint64Flag{
+		Name: "consensus.roundchange-history-size",
+		Usage: "The number of the recent round changes kept for istanbul_roundChangeHistory (0 = disabled). " +
+			"This flag is only applicable to CN.",
+		Value:    istanbul.DefaultConfig.RoundChangeHistorySize,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_CONSENSUS_ROUNDCHANGE_HISTORY_SIZE"},
+		Category: "KLAY",
+	}
+	PrioritizePreprepareFlag = &cli.BoolFlag{
+		Name: "consensus.prioritize-preprepare",
+		Usage: "Send the preprepare of the local proposer before the other consensus messages queued for each peer. " +
+			"This flag is only applicable to CN.",
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_CONSENSUS_PRIORITIZE_PREPREPARE"},
+		Category: "KLAY",
+	}
+	QuarantineThresholdFlag = &cli.Uint64Flag{
+		Name: "consensus.quarantine-threshold",
+		Usage: "The number of malformed consensus messages of a peer within a minute, which quarantines the peer for 10 minutes. " +
+			"0 disables the quarantine, which is the default. This flag is only applicable to CN.",
+		Value:    istanbul.DefaultConfig.QuarantineThreshold,
+		Aliases:  []string{},
+		EnvVars:  []string{"KLAYTN_CONSENSUS_QUARANTINE_THRESHOLD"},
+		Category: "KLAY",
+	}
 	OpcodeComputationCostLimitFlag = &cli.Uint64Flag{
 		Name: "opcode-computation-cost-limit",
 		Usage: "(experimental option) Set the computation cost limit for a tx. " +
@@ -1990,6 +2215,17 @@ func MakeDataDir(ctx *cli.Context) string {
 	return ""
 }
 
+// MakeMetricLabels returns the labels of the metrics bridged to Prometheus,
+// which are the storage backend type and the region of DynamoDB.
+func MakeMetricLabels(ctx *cli.Context) map[string]string {
+	backend := ctx.String(DbTypeFlag.Name)
+	labels := map[string]string{"backend": backend, "region": ""}
+	if dbtype, err := database.ParseDBType(backend); err == nil && dbtype == database.DynamoDB {
+		labels["region"] = ctx.String(DynamoDBRegionFlag.Name)
+	}
+	return labels
+}
+
 // splitAndTrim splits input separated by a comma
 // and trims excessive white space from the substrings.
 func SplitAndTrim(input string) []string {
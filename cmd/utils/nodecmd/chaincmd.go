@@ -55,6 +55,16 @@ var (
 			utils.DynamoDBReadCapacityFlag,
 			utils.DynamoDBWriteCapacityFlag,
 			utils.DynamoDBReadOnlyFlag,
+			utils.DynamoDBSkipWriteCheckFlag,
+			utils.DynamoDBLogRequestsFlag,
+			utils.DynamoDBAWSLogLevelFlag,
+			utils.DynamoDBS3CompressionThresholdFlag,
+			utils.DynamoDBS3MultipartThresholdFlag,
+			utils.DynamoDBS3ReadMaxRetriesFlag,
+			utils.DynamoDBS3WriteMaxRetriesFlag,
+			utils.DynamoDBSlowOpThresholdFlag,
+			utils.DynamoDBCoalesceGetsFlag,
+			utils.DynamoDBEventuallyConsistentReadsFlag,
 			utils.LevelDBCompressionTypeFlag,
 			utils.DataDirFlag,
 			utils.ChainDataDirFlag,
@@ -145,9 +155,9 @@ func initGenesis(ctx *cli.Context) error {
 	overwriteGenesis := ctx.Bool(utils.OverwriteGenesisFlag.Name)
 	livePruning := ctx.Bool(utils.LivePruningFlag.Name)
 
-	dbtype := database.DBType(ctx.String(utils.DbTypeFlag.Name)).ToValid()
-	if len(dbtype) == 0 {
-		logger.Crit("invalid dbtype", "dbtype", ctx.String(utils.DbTypeFlag.Name))
+	dbtype, err := database.ParseDBType(ctx.String(utils.DbTypeFlag.Name))
+	if err != nil {
+		logger.Crit("invalid dbtype", "err", err)
 	}
 
 	var dynamoDBConfig *database.DynamoDBConfig
@@ -159,6 +169,17 @@ func initGenesis(ctx *cli.Context) error {
 			ReadCapacityUnits:  ctx.Int64(utils.DynamoDBReadCapacityFlag.Name),
 			WriteCapacityUnits: ctx.Int64(utils.DynamoDBWriteCapacityFlag.Name),
 			ReadOnly:           ctx.Bool(utils.DynamoDBReadOnlyFlag.Name),
+			SkipWriteCheck:     ctx.Bool(utils.DynamoDBSkipWriteCheckFlag.Name),
+			LogAWSRequests:     ctx.Bool(utils.DynamoDBLogRequestsFlag.Name),
+			AWSLogLevel:        ctx.String(utils.DynamoDBAWSLogLevelFlag.Name),
+
+			S3CompressionThreshold:    ctx.Int(utils.DynamoDBS3CompressionThresholdFlag.Name),
+			S3MultipartThreshold:      ctx.Int(utils.DynamoDBS3MultipartThresholdFlag.Name),
+			S3ReadMaxRetries:          ctx.Int(utils.DynamoDBS3ReadMaxRetriesFlag.Name),
+			S3WriteMaxRetries:         ctx.Int(utils.DynamoDBS3WriteMaxRetriesFlag.Name),
+			SlowOpThreshold:           ctx.Duration(utils.DynamoDBSlowOpThresholdFlag.Name),
+			CoalesceGets:              ctx.Bool(utils.DynamoDBCoalesceGetsFlag.Name),
+			EventuallyConsistentReads: ctx.Bool(utils.DynamoDBEventuallyConsistentReadsFlag.Name),
 		}
 	}
 	rocksDBConfig := database.GetDefaultRocksDBConfig()
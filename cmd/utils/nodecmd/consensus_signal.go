@@ -0,0 +1,49 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package nodecmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/klaytn/klaytn/consensus"
+)
+
+// consensusSnapshotLogger is a consensus engine which can log a snapshot of its state.
+type consensusSnapshotLogger interface {
+	LogConsensusSnapshot()
+}
+
+// handleConsensusSnapshotSignal makes the engine log a snapshot of the consensus
+// state whenever SIGUSR1 is received, e.g., by `kill -USR1 <pid>`.
+func handleConsensusSnapshotSignal(engine consensus.Engine) {
+	snapshotLogger, ok := engine.(consensusSnapshotLogger)
+	if !ok {
+		return
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGUSR1)
+	go func() {
+		for range sigc {
+			snapshotLogger.LogConsensusSnapshot()
+		}
+	}()
+}
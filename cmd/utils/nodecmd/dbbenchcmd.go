@@ -0,0 +1,107 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var DBBenchCommand = &cli.Command{
+	Name:     "db-bench",
+	Usage:    "Measure the read/write latency distribution of a DynamoDB table and its S3 bucket",
+	Category: "DB MIGRATION COMMANDS",
+	Flags: []cli.Flag{
+		utils.DynamoDBTableNameFlag,
+		utils.DynamoDBRegionFlag,
+		utils.DynamoDBIsProvisionedFlag,
+		utils.DynamoDBReadCapacityFlag,
+		utils.DynamoDBWriteCapacityFlag,
+		utils.DynamoDBSkipWriteCheckFlag,
+		utils.DynamoDBLogRequestsFlag,
+		utils.DynamoDBAWSLogLevelFlag,
+		utils.DynamoDBS3CompressionThresholdFlag,
+		utils.DynamoDBS3MultipartThresholdFlag,
+		utils.DynamoDBS3ReadMaxRetriesFlag,
+		utils.DynamoDBS3WriteMaxRetriesFlag,
+		utils.DynamoDBSlowOpThresholdFlag,
+		utils.DynamoDBCoalesceGetsFlag,
+		utils.DynamoDBEventuallyConsistentReadsFlag,
+		utils.DBBenchDurationFlag,
+		utils.DBBenchConcurrencyFlag,
+		utils.DBBenchReadRatioFlag,
+		utils.DBBenchValueSizeFlag,
+		utils.DBBenchOversizedSizeFlag,
+		utils.DBBenchOversizedRateFlag,
+	},
+	Action: benchDynamoDB,
+	Description: `
+The db-bench command performs a mix of reads and writes on the DynamoDB table
+given by db.dynamo.tablename for bench.duration, and prints the latency
+distribution (min, mean, p50, p95, p99 and max in nanoseconds), the throughput
+(operations per second) and the error rate of each operation as a JSON object.
+Values larger than the item size limit of DynamoDB are stored in S3, so
+the oversized writes show the latency of S3.
+
+The table is accessed by the same code as a node, and it is created if it doesn't exist.
+The written items are not deleted, so use a table which is not in service.
+(e.g. db-bench --db.dynamo.tablename klaytn-bench --bench.read-ratio 0.8 > result.json)`,
+}
+
+func benchDynamoDB(ctx *cli.Context) error {
+	config := &database.DynamoDBConfig{
+		TableName:          ctx.String(utils.DynamoDBTableNameFlag.Name),
+		Region:             ctx.String(utils.DynamoDBRegionFlag.Name),
+		IsProvisioned:      ctx.Bool(utils.DynamoDBIsProvisionedFlag.Name),
+		ReadCapacityUnits:  ctx.Int64(utils.DynamoDBReadCapacityFlag.Name),
+		WriteCapacityUnits: ctx.Int64(utils.DynamoDBWriteCapacityFlag.Name),
+		SkipWriteCheck:     ctx.Bool(utils.DynamoDBSkipWriteCheckFlag.Name),
+		LogAWSRequests:     ctx.Bool(utils.DynamoDBLogRequestsFlag.Name),
+		AWSLogLevel:        ctx.String(utils.DynamoDBAWSLogLevelFlag.Name),
+		PerfCheck:          true,
+
+		S3CompressionThreshold:    ctx.Int(utils.DynamoDBS3CompressionThresholdFlag.Name),
+		S3MultipartThreshold:      ctx.Int(utils.DynamoDBS3MultipartThresholdFlag.Name),
+		S3ReadMaxRetries:          ctx.Int(utils.DynamoDBS3ReadMaxRetriesFlag.Name),
+		S3WriteMaxRetries:         ctx.Int(utils.DynamoDBS3WriteMaxRetriesFlag.Name),
+		SlowOpThreshold:           ctx.Duration(utils.DynamoDBSlowOpThresholdFlag.Name),
+		CoalesceGets:              ctx.Bool(utils.DynamoDBCoalesceGetsFlag.Name),
+		EventuallyConsistentReads: ctx.Bool(utils.DynamoDBEventuallyConsistentReadsFlag.Name),
+	}
+	db, err := database.NewDynamoDB(config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := database.RunDBBench(db, database.DBBenchConfig{
+		Duration:      ctx.Duration(utils.DBBenchDurationFlag.Name),
+		Concurrency:   ctx.Int(utils.DBBenchConcurrencyFlag.Name),
+		ReadRatio:     ctx.Float64(utils.DBBenchReadRatioFlag.Name),
+		ValueSize:     ctx.Int(utils.DBBenchValueSizeFlag.Name),
+		OversizedSize: ctx.Int(utils.DBBenchOversizedSizeFlag.Name),
+		OversizedRate: ctx.Float64(utils.DBBenchOversizedRateFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(result)
+}
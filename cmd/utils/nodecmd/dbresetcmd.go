@@ -0,0 +1,72 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var DBResetCommand = &cli.Command{
+	Name:     "db-reset",
+	Usage:    "Delete and recreate a DynamoDB table and its S3 bucket",
+	Category: "DB MIGRATION COMMANDS",
+	Flags: []cli.Flag{
+		utils.DynamoDBTableNameFlag,
+		utils.DynamoDBRegionFlag,
+		utils.DynamoDBIsProvisionedFlag,
+		utils.DynamoDBReadCapacityFlag,
+		utils.DynamoDBWriteCapacityFlag,
+		utils.DynamoDBEndpointFlag,
+		utils.DynamoDBS3EndpointFlag,
+		utils.DynamoDBS3BucketFlag,
+		utils.DynamoDBS3KeyPrefixFlag,
+		utils.DBResetConfirmFlag,
+	},
+	Action: resetDynamoDB,
+	Description: `
+The db-reset command deletes the DynamoDB table given by db.dynamo.tablename
+and its S3 bucket with ALL OF THEIR DATA, and recreates them with the current schema.
+If --db.dynamo.s3-key-prefix is given, only the S3 objects under the prefix are deleted
+and the bucket is kept. It is required if --db.dynamo.s3-bucket is not the table name.
+A node sharing a bucket stores the objects of each table under the prefix followed by
+the directory of the table, so reset such a table with that prefix.
+(e.g. --db.dynamo.tablename klaytn-header --db.dynamo.s3-key-prefix klaytn/header/)
+It is used to recover a table which became unusable, e.g., by a manual schema change.
+
+To prevent an accidental data loss, the command refuses to run
+unless --confirm is exactly the same as the table name.
+(e.g. db-reset --db.dynamo.tablename klaytn-misc --confirm klaytn-misc)
+
+Note: Do not reset a table while a node is using it.`,
+}
+
+func resetDynamoDB(ctx *cli.Context) error {
+	config := &database.DynamoDBConfig{
+		TableName:          ctx.String(utils.DynamoDBTableNameFlag.Name),
+		Region:             ctx.String(utils.DynamoDBRegionFlag.Name),
+		IsProvisioned:      ctx.Bool(utils.DynamoDBIsProvisionedFlag.Name),
+		ReadCapacityUnits:  ctx.Int64(utils.DynamoDBReadCapacityFlag.Name),
+		WriteCapacityUnits: ctx.Int64(utils.DynamoDBWriteCapacityFlag.Name),
+		Endpoint:           ctx.String(utils.DynamoDBEndpointFlag.Name),
+		S3Endpoint:         ctx.String(utils.DynamoDBS3EndpointFlag.Name),
+		S3Bucket:           ctx.String(utils.DynamoDBS3BucketFlag.Name),
+		S3KeyPrefix:        ctx.String(utils.DynamoDBS3KeyPrefixFlag.Name),
+	}
+	return database.ResetDynamoDB(config, ctx.String(utils.DBResetConfirmFlag.Name))
+}
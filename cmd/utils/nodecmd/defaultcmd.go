@@ -153,6 +153,7 @@ func startKlaytnAuxiliaryService(ctx *cli.Context, stack *node.Node) {
 	if err := stack.Service(&cn); err != nil {
 		log.Fatalf("Klaytn service not running: %v", err)
 	}
+	handleConsensusSnapshotSignal(cn.Engine())
 
 	// TODO-Klaytn-NodeCmd disable accept tx before finishing sync.
 	if err := cn.StartMining(false); err != nil {
@@ -211,7 +212,7 @@ func BeforeRunNode(ctx *cli.Context) error {
 	if err := debug.Setup(ctx); err != nil {
 		return err
 	}
-	metricutils.StartMetricCollectionAndExport(ctx)
+	metricutils.StartMetricCollectionAndExport(ctx, utils.MakeMetricLabels(ctx))
 	setupNetwork(ctx)
 	return nil
 }
@@ -229,7 +230,7 @@ func BeforeRunBootnode(ctx *cli.Context) error {
 	if err := debug.Setup(ctx); err != nil {
 		return err
 	}
-	metricutils.StartMetricCollectionAndExport(ctx)
+	metricutils.StartMetricCollectionAndExport(ctx, utils.MakeMetricLabels(ctx))
 	return nil
 }
 
@@ -24,6 +24,8 @@ Each file contains following contents
  - chaincmd.go		: Provides functions to `init` a block chain,
  - consolecmd.go		: Provides console functions `attach` and `console`
  - migrationcmd.go		: Provides functions of DB migration
+ - dbresetcmd.go		: Provides functions to reset a DynamoDB table
+ - dbbenchcmd.go		: Provides functions to measure the latency of a DynamoDB table
  - defaultcmd.go		: Provides functions to start a node
  - dumpconfigcmd.go		: Provides functions to dump and print current config to stdout
  - nodeflags.go		: Defines various flags that configure the node
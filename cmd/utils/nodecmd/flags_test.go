@@ -371,6 +371,10 @@ var flagsWithValues = []struct {
 		wrongValues: commonThreeErrors,
 		errors:      []int{ErrorInvalidValue, NonError, ErrorInvalidValue},
 	},
+	{
+		flag:     "--prometheusbridge",
+		flagType: FlagTypeBoolean,
+	},
 	{
 		flag:     "--rpc",
 		flagType: FlagTypeBoolean,
@@ -19,6 +19,8 @@ package nodecmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
 
 	"github.com/klaytn/klaytn/cmd/utils"
 	"github.com/klaytn/klaytn/storage/database"
@@ -45,7 +47,7 @@ Note: Do not use db migration while a node is executing.
 			{
 				Name:   "start",
 				Usage:  "Start db migration",
-				Flags:  dbMigrationFlags,
+				Flags:  append([]cli.Flag{utils.DBMigrationBulkFlag}, dbMigrationFlags...),
 				Action: startMigration,
 				Description: `
 This command starts DB migration.
@@ -55,9 +57,47 @@ Even if db dir names are changed in srcDB, the original db dir names are used in
 If dst db is singleDB, you should set dst.datadir or db.dst.dynamo.tablename
 to the original db dir name.
 (e.g. Data dir : 'chaindata/klay/statetrie', Dynamo table name : 'klaytn-statetrie')
+The last key of each written batch is stored in dstDB, so an interrupted migration
+resumes from the next key when it is started again. The stored key is deleted
+when the migration is finished.
+If db.dst.dynamo.idempotent-import is set, the items already in the dynamoDB table
+are not overwritten, so an interrupted migration can be started again.
+
+If --bulk is set, the items are written by the bulk load of dstDB, which is much
+faster for a large initial migration into an empty DB. For DynamoDB, the items are
+staged in the S3 bucket of the table, and loaded into the table in parallel.
+The bulk load is not resumed from the cursor, so an interrupted bulk migration
+starts again from the first key, which skips the written items with
+db.dst.dynamo.idempotent-import. The DBs without bulk load are migrated by batches.
 
 Note: This feature is only provided when srcDB is single LevelDB.`,
 			},
+			{
+				Name:   "import",
+				Usage:  "Import an RLP dump into a DB",
+				Flags:  append([]cli.Flag{utils.DBMigrationDumpFileFlag}, utils.DBMigrationDstFlags...),
+				Action: importRLPDump,
+				Description: `
+This command imports an RLP dump given by db.dump into dstDB.
+
+The checksum of the whole dump is verified before importing,
+so nothing is imported from a truncated or corrupted dump.
+
+Note: This feature is only provided when dstDB is single DB.`,
+			},
+			{
+				Name:   "diff",
+				Usage:  "Compare two DBs",
+				Flags:  dbMigrationFlags,
+				Action: diffDB,
+				Description: `
+This command compares srcDB and dstDB to check a migration.
+
+The keys in only one of the DBs and the keys with different values are
+printed as they are found. Both DBs are iterated in the key order, so
+the items are not loaded into memory at once.
+Note: Both DBs should be single DB or not, and support iteration.`,
+			},
 		},
 	}
 )
@@ -70,9 +110,68 @@ func startMigration(ctx *cli.Context) error {
 	defer srcDBManager.Close()
 	defer dstDBManager.Close()
 
+	if ctx.Bool(utils.DBMigrationBulkFlag.Name) {
+		return srcDBManager.StartDBBulkMigration(dstDBManager)
+	}
 	return srcDBManager.StartDBMigration(dstDBManager)
 }
 
+func importRLPDump(ctx *cli.Context) error {
+	dumpFile := ctx.String(utils.DBMigrationDumpFileFlag.Name)
+	if dumpFile == "" {
+		return errors.New("dump file is not specified")
+	}
+	f, err := os.Open(dumpFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dstDBConfig, err := createDstDBConfigForMigration(ctx)
+	if err != nil {
+		return err
+	}
+	dstDBManager := database.NewDBManager(dstDBConfig)
+	defer dstDBManager.Close()
+
+	return dstDBManager.ImportRLPDump(f)
+}
+
+func diffDB(ctx *cli.Context) error {
+	srcDBManager, dstDBManager, err := createDBManagerForMigration(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcDBManager.Close()
+	defer dstDBManager.Close()
+
+	diffs := 0
+	err = srcDBManager.DiffDB(dstDBManager, func(db string, diff database.DBDiff) error {
+		diffs++
+		switch {
+		case diff.BValue == nil:
+			fmt.Printf("%s: %x is only in srcDB\n", db, diff.Key)
+		case diff.AValue == nil:
+			fmt.Printf("%s: %x is only in dstDB\n", db, diff.Key)
+		default:
+			fmt.Printf("%s: %x has different values (src: %d bytes, dst: %d bytes)\n",
+				db, diff.Key, len(diff.AValue), len(diff.BValue))
+		}
+		return nil
+	})
+	if errors.Is(err, database.ErrDiffInterrupted) {
+		return fmt.Errorf("the comparison is incomplete, since it is interrupted: %d different keys are found until then", diffs)
+	}
+	if err != nil {
+		return err
+	}
+	if diffs > 0 {
+		return fmt.Errorf("%d different keys are found", diffs)
+	}
+	fmt.Println("No difference is found")
+	return nil
+}
+
 func createDBManagerForMigration(ctx *cli.Context) (database.DBManager, database.DBManager, error) {
 	// create db config from ctx
 	srcDBConfig, dstDBConfig, dbManagerCreationErr := createDBConfigForMigration(ctx)
@@ -130,7 +229,15 @@ func createDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, *database
 		return nil, nil, errors.New("srcDB is not specified or invalid : " + ctx.String(utils.DbTypeFlag.Name))
 	}
 
-	// dstDB
+	dstDBC, err := createDstDBConfigForMigration(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return srcDBC, dstDBC, nil
+}
+
+func createDstDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, error) {
 	dstDBC := &database.DBConfig{
 		Dir:                ctx.String(utils.DstDataDirFlag.Name),
 		DBType:             database.DBType(ctx.String(utils.DstDbTypeFlag.Name)).ToValid(),
@@ -149,6 +256,7 @@ func createDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, *database
 			ReadCapacityUnits:  ctx.Int64(utils.DstDynamoDBReadCapacityFlag.Name),
 			WriteCapacityUnits: ctx.Int64(utils.DstDynamoDBWriteCapacityFlag.Name),
 			PerfCheck:          !ctx.Bool(utils.DBNoPerformanceMetricsFlag.Name),
+			IdempotentImport:   ctx.Bool(utils.DstDynamoDBIdempotentImportFlag.Name),
 		},
 
 		RocksDBConfig: &database.RocksDBConfig{
@@ -164,10 +272,10 @@ func createDBConfigForMigration(ctx *cli.Context) (*database.DBConfig, *database
 		},
 	}
 	if len(dstDBC.DBType) == 0 { // changed to invalid type
-		return nil, nil, errors.New("dstDB is not specified or invalid : " + ctx.String(utils.DstDbTypeFlag.Name))
+		return nil, errors.New("dstDB is not specified or invalid : " + ctx.String(utils.DstDbTypeFlag.Name))
 	}
 
-	return srcDBC, dstDBC, nil
+	return dstDBC, nil
 }
 
 // TODO When it is stopped, store previous db migration info.
@@ -96,7 +96,7 @@ func init() {
 		if err := debug.Setup(ctx); err != nil {
 			return err
 		}
-		metricutils.StartMetricCollectionAndExport(ctx)
+		metricutils.StartMetricCollectionAndExport(ctx, utils.MakeMetricLabels(ctx))
 		setupNetwork(ctx)
 		return nil
 	}
@@ -0,0 +1,181 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klaytn/klaytn/blockchain"
+	"github.com/klaytn/klaytn/blockchain/state"
+	"github.com/klaytn/klaytn/blockchain/types"
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	istanbulBackend "github.com/klaytn/klaytn/consensus/istanbul/backend"
+	"github.com/klaytn/klaytn/crypto"
+	"github.com/klaytn/klaytn/event"
+	"github.com/klaytn/klaytn/governance"
+	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/reward"
+	"github.com/klaytn/klaytn/storage/database"
+	"github.com/urfave/cli/v2"
+)
+
+var VerifyConsensusCommand = &cli.Command{
+	Name:      "verify-consensus",
+	Usage:     "Verify the proposers and committed seals of the stored blocks",
+	ArgsUsage: " ",
+	Category:  "BLOCKCHAIN COMMANDS",
+	Action:    utils.MigrateFlags(verifyConsensus),
+	Flags:     append([]cli.Flag{utils.VerifyConsensusFromFlag, utils.VerifyConsensusToFlag}, utils.SnapshotFlags...),
+	Description: `
+The verify-consensus command reads the blocks from --from to --to in the local
+database, reconstructs the validator set of each block from its parent, and
+checks that the block is proposed by the proposer of its round and committed
+by enough validators. It stops at the first block which fails the check and
+reports its number, hash and the reason.
+
+The database is opened read-only, so the command never modifies the chain data.
+LevelDB and BadgerDB can't be opened while a node is using them, but RocksDB
+is opened as a secondary instance and DynamoDB can be shared with a node.
+(e.g. verify-consensus --datadir ~/kspn_home --from 1000 --to 2000)`,
+}
+
+// consensusChain is a read-only chain over the stored headers, blocks and
+// states, which is enough to replay the consensus without a blockchain.
+type consensusChain struct {
+	*blockchain.HeaderChain
+	db        database.DBManager
+	stateDB   state.Database
+	headFeed  event.Feed
+	headScope event.SubscriptionScope
+}
+
+func newConsensusChain(db database.DBManager, config *params.ChainConfig, engine consensus.Engine) (*consensusChain, error) {
+	hc, err := blockchain.NewHeaderChain(db, config, engine, func() bool { return false })
+	if err != nil {
+		return nil, err
+	}
+	return &consensusChain{HeaderChain: hc, db: db, stateDB: state.NewDatabase(db)}, nil
+}
+
+func (c *consensusChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return c.db.ReadBlock(hash, number)
+}
+
+func (c *consensusChain) GetBlockByNumber(number uint64) *types.Block {
+	hash := c.db.ReadCanonicalHash(number)
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return c.db.ReadBlock(hash, number)
+}
+
+func (c *consensusChain) CurrentBlock() *types.Block {
+	head := c.CurrentHeader()
+	return c.GetBlock(head.Hash(), head.Number.Uint64())
+}
+
+func (c *consensusChain) StateAt(root common.Hash) (*state.StateDB, error) {
+	return state.New(root, c.stateDB, nil, nil)
+}
+
+func (c *consensusChain) State() (*state.StateDB, error) {
+	return c.StateAt(c.CurrentHeader().Root)
+}
+
+// SubscribeChainHeadEvent returns a subscription which never receives an event,
+// because no block is inserted into the chain.
+func (c *consensusChain) SubscribeChainHeadEvent(ch chan<- blockchain.ChainHeadEvent) event.Subscription {
+	return c.headScope.Track(c.headFeed.Subscribe(ch))
+}
+
+// consensusVerifier is implemented by the consensus engines which can replay
+// the consensus of stored blocks.
+type consensusVerifier interface {
+	VerifyConsensus(chain consensus.ChainReader, header *types.Header) error
+}
+
+func verifyConsensus(ctx *cli.Context) error {
+	stack, _ := utils.MakeConfigNode(ctx)
+	dbc := getConfig(ctx)
+	dbc.ReadOnly = true
+	db := stack.OpenDatabase(dbc)
+	defer db.Close()
+
+	genesisHash := db.ReadCanonicalHash(0)
+	if genesisHash == (common.Hash{}) {
+		return errors.New("empty database")
+	}
+	chainConfig := db.ReadChainConfig(genesisHash)
+	if chainConfig == nil || chainConfig.Istanbul == nil {
+		return errors.New("the chain config of the database is not for istanbul")
+	}
+	chainConfig.SetDefaults()
+
+	// the node key is only used to sign, which never happens here
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	gov := governance.NewMixedEngine(chainConfig, db)
+	engine := istanbulBackend.New(common.Address{}, istanbul.DefaultConfig, key, db, gov, common.ENDPOINTNODE)
+	chain, err := newConsensusChain(db, chainConfig, engine)
+	if err != nil {
+		return err
+	}
+	gov.SetBlockchain(chain)
+	reward.NewStakingManager(chain, gov, db)
+
+	from, to := ctx.Uint64(utils.VerifyConsensusFromFlag.Name), chain.CurrentHeader().Number.Uint64()
+	if ctx.IsSet(utils.VerifyConsensusToFlag.Name) {
+		to = ctx.Uint64(utils.VerifyConsensusToFlag.Name)
+	}
+	if from > to {
+		return fmt.Errorf("the first block %d is after the last block %d", from, to)
+	}
+	logger.Info("Verifying the consensus of the stored blocks", "from", from, "to", to)
+	return verifyConsensusRange(chain, engine.(consensusVerifier), from, to)
+}
+
+// verifyConsensusRange verifies the consensus of the canonical blocks from the
+// first to the last one, and returns the error of the first failed block.
+func verifyConsensusRange(chain consensus.ChainReader, verifier consensusVerifier, from, to uint64) error {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for num := from; num <= to; num++ {
+		header := chain.GetHeaderByNumber(num)
+		if header == nil {
+			return fmt.Errorf("block %d is not found", num)
+		}
+		if err := verifier.VerifyConsensus(chain, header); err != nil {
+			logger.Error("Found a block which fails the consensus", "number", num, "hash", header.Hash(), "err", err)
+			return fmt.Errorf("block %d (%s) fails the consensus: %w", num, header.Hash().Hex(), err)
+		}
+		if time.Since(logged) > 8*time.Second {
+			logger.Info("Verifying the consensus", "number", num, "to", to, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	logger.Info("Verified the consensus of the blocks", "from", from, "to", to, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
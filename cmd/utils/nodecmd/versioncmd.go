@@ -23,6 +23,7 @@ package nodecmd
 import (
 	"fmt"
 
+	"github.com/klaytn/klaytn/cmd/utils"
 	"github.com/klaytn/klaytn/params"
 	"github.com/urfave/cli/v2"
 )
@@ -35,6 +36,11 @@ var (
 	gitTag = ""
 )
 
+func init() {
+	// the running node reports the same git commit as the version command
+	utils.GitCommit = GetGitCommit
+}
+
 var VersionCommand = &cli.Command{
 	Action:    version,
 	Name:      "version",
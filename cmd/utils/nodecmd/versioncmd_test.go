@@ -0,0 +1,45 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package nodecmd
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/klaytn/klaytn/cmd/utils"
+	"github.com/klaytn/klaytn/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeBuildInfo(t *testing.T) {
+	defer func(commit string) { gitCommit = commit }(gitCommit)
+	gitCommit = "0123456789abcdef0123456789abcdef01234567"
+
+	cfg := utils.DefaultNodeConfig()
+	cfg.DataDir = ""
+	cfg.NodeType = "spn"
+	stack, err := node.New(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := node.NewPublicKlayAPI(stack).NodeBuildInfo()
+	assert.Equal(t, GetGitCommit(), info.GitCommit)
+	assert.Contains(t, info.Version, gitCommit[:10])
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, "spn", info.NodeType)
+}
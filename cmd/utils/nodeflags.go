@@ -195,6 +195,7 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewUintFlag(NumStateTrieShardsFlag),
 	altsrc.NewIntFlag(LevelDBCompressionTypeFlag),
 	altsrc.NewBoolFlag(LevelDBNoBufferPoolFlag),
+	altsrc.NewIntFlag(MinFreeDiskSpaceFlag),
 	altsrc.NewBoolFlag(DBNoPerformanceMetricsFlag),
 	altsrc.NewBoolFlag(RocksDBSecondaryFlag),
 	altsrc.NewUint64Flag(RocksDBCacheSizeFlag),
@@ -211,6 +212,20 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewInt64Flag(DynamoDBReadCapacityFlag),
 	altsrc.NewInt64Flag(DynamoDBWriteCapacityFlag),
 	altsrc.NewBoolFlag(DynamoDBReadOnlyFlag),
+	altsrc.NewBoolFlag(DynamoDBSkipWriteCheckFlag),
+	altsrc.NewBoolFlag(DynamoDBLogRequestsFlag),
+	altsrc.NewStringFlag(DynamoDBAWSLogLevelFlag),
+	altsrc.NewStringFlag(DynamoDBEndpointFlag),
+	altsrc.NewStringFlag(DynamoDBS3EndpointFlag),
+	altsrc.NewStringFlag(DynamoDBS3BucketFlag),
+	altsrc.NewStringFlag(DynamoDBS3KeyPrefixFlag),
+	altsrc.NewIntFlag(DynamoDBS3CompressionThresholdFlag),
+	altsrc.NewIntFlag(DynamoDBS3MultipartThresholdFlag),
+	altsrc.NewIntFlag(DynamoDBS3ReadMaxRetriesFlag),
+	altsrc.NewIntFlag(DynamoDBS3WriteMaxRetriesFlag),
+	altsrc.NewDurationFlag(DynamoDBSlowOpThresholdFlag),
+	altsrc.NewBoolFlag(DynamoDBCoalesceGetsFlag),
+	altsrc.NewBoolFlag(DynamoDBEventuallyConsistentReadsFlag),
 	altsrc.NewIntFlag(LevelDBCacheSizeFlag),
 	altsrc.NewBoolFlag(NoParallelDBWriteFlag),
 	altsrc.NewBoolFlag(SenderTxHashIndexingFlag),
@@ -254,6 +269,7 @@ var CommonNodeFlags = []cli.Flag{
 	altsrc.NewBoolFlag(MetricsEnabledFlag),
 	altsrc.NewBoolFlag(PrometheusExporterFlag),
 	altsrc.NewIntFlag(PrometheusExporterPortFlag),
+	altsrc.NewBoolFlag(PrometheusBridgeFlag),
 	altsrc.NewStringFlag(ExtraDataFlag),
 	altsrc.NewStringFlag(SrvTypeFlag),
 	altsrc.NewBoolFlag(AutoRestartFlag),
@@ -318,6 +334,7 @@ var BNFlags = []cli.Flag{
 	altsrc.NewBoolFlag(MetricsEnabledFlag),
 	altsrc.NewBoolFlag(PrometheusExporterFlag),
 	altsrc.NewIntFlag(PrometheusExporterPortFlag),
+	altsrc.NewBoolFlag(PrometheusBridgeFlag),
 	altsrc.NewStringFlag(AuthorizedNodesFlag),
 	altsrc.NewUint64Flag(NetworkIdFlag),
 }
@@ -328,6 +345,10 @@ var KCNFlags = []cli.Flag{
 	altsrc.NewBoolFlag(BaobabFlag),
 	altsrc.NewInt64Flag(BlockGenerationIntervalFlag),
 	altsrc.NewDurationFlag(BlockGenerationTimeLimitFlag),
+	altsrc.NewBoolFlag(VerifyCommitRLPFlag),
+	altsrc.NewUint64Flag(RoundChangeHistorySizeFlag),
+	altsrc.NewBoolFlag(PrioritizePreprepareFlag),
+	altsrc.NewUint64Flag(QuarantineThresholdFlag),
 }
 
 var KPNFlags = []cli.Flag{
@@ -374,6 +395,10 @@ var KSCNFlags = []cli.Flag{
 	altsrc.NewStringFlag(RewardbaseFlag),
 	altsrc.NewInt64Flag(BlockGenerationIntervalFlag),
 	altsrc.NewDurationFlag(BlockGenerationTimeLimitFlag),
+	altsrc.NewBoolFlag(VerifyCommitRLPFlag),
+	altsrc.NewUint64Flag(RoundChangeHistorySizeFlag),
+	altsrc.NewBoolFlag(PrioritizePreprepareFlag),
+	altsrc.NewUint64Flag(QuarantineThresholdFlag),
 	altsrc.NewStringFlag(ServiceChainSignerFlag),
 	altsrc.NewUint64Flag(AnchoringPeriodFlag),
 	altsrc.NewUint64Flag(SentChainTxsLimit),
@@ -538,6 +563,7 @@ var DBMigrationDstFlags = []cli.Flag{
 	altsrc.NewBoolFlag(DstDynamoDBIsProvisionedFlag),
 	altsrc.NewInt64Flag(DstDynamoDBReadCapacityFlag),
 	altsrc.NewInt64Flag(DstDynamoDBWriteCapacityFlag),
+	altsrc.NewBoolFlag(DstDynamoDBIdempotentImportFlag),
 	altsrc.NewUint64Flag(DstRocksDBCacheSizeFlag),
 	altsrc.NewBoolFlag(DstRocksDBDumpMallocStatFlag),
 	altsrc.NewBoolFlag(DstRocksDBDisableMetricsFlag),
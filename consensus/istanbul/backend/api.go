@@ -191,6 +191,30 @@ func (api *API) Discard(address common.Address) {
 	delete(api.istanbul.candidates, address)
 }
 
+// IsProposer returns true if the node is the proposer of the current view.
+// It returns false if the node is not a validator or the engine is not started.
+func (api *API) IsProposer() bool {
+	proposer := api.istanbul.currentProposer()
+	return proposer != nil && proposer.Address() == api.istanbul.Address()
+}
+
+// CurrentProposer returns the address of the proposer of the current view.
+// It returns nil if the engine is not started.
+func (api *API) CurrentProposer() *common.Address {
+	proposer := api.istanbul.currentProposer()
+	if proposer == nil {
+		return nil
+	}
+	addr := proposer.Address()
+	return &addr
+}
+
+// RoundChangeHistory returns the recent round changes of the node from the
+// oldest one, which shows the heights struggled to reach consensus.
+func (api *API) RoundChangeHistory() []istanbul.RoundChangeEvent {
+	return api.istanbul.core.RoundChangeHistory()
+}
+
 // API extended by Klaytn developers
 type APIExtension struct {
 	chain    consensus.ChainReader
@@ -289,6 +313,31 @@ func (api *APIExtension) GetCommittee(number *rpc.BlockNumber) ([]common.Address
 	return addresses, nil
 }
 
+// CommitteeList is the committee of a view returned by GetCommitteeList.
+type CommitteeList struct {
+	Committee     []common.Address `json:"committee"`
+	ProposerIndex int              `json:"proposerIndex"`
+}
+
+// GetCommitteeList returns the committee of the given round of the block next
+// to the block with blockHash, in the order composed by the consensus, with
+// the index of the proposer in it.
+func (api *APIExtension) GetCommitteeList(blockHash common.Hash, round uint64) (*CommitteeList, error) {
+	header := api.chain.GetHeaderByHash(blockHash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	view := &istanbul.View{
+		Sequence: new(big.Int).Add(header.Number, common.Big1),
+		Round:    new(big.Int).SetUint64(round),
+	}
+	committee, proposerIdx, err := api.istanbul.GetCommitteeList(blockHash, view)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitteeList{Committee: committee, ProposerIndex: proposerIdx}, nil
+}
+
 func (api *APIExtension) GetCommitteeSize(number *rpc.BlockNumber) (int, error) {
 	committee, err := api.GetCommittee(number)
 	if err == nil {
@@ -0,0 +1,136 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPI_CurrentProposer(t *testing.T) {
+	chain, engine := newBlockChain(4)
+	defer engine.Stop()
+	api := &API{chain: chain, istanbul: engine}
+
+	validators := make(map[common.Address]bool)
+	for _, addr := range addrs {
+		validators[addr] = true
+	}
+
+	// the proposer rotates among the validators as the round increases
+	proposerRounds := 0
+	for round := int64(0); round < 4; round++ {
+		engine.SetCurrentView(&istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(round)})
+
+		proposer := api.CurrentProposer()
+		if assert.NotNil(t, proposer) {
+			assert.True(t, validators[*proposer])
+			assert.Equal(t, *proposer == engine.Address(), api.IsProposer())
+		}
+		if api.IsProposer() {
+			proposerRounds++
+		}
+	}
+	assert.Equal(t, 1, proposerRounds)
+
+	// a node which is not a validator is never the proposer
+	engine.SetCurrentView(&istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(0)})
+	proposer := api.CurrentProposer()
+	engine.address = common.HexToAddress("0x1")
+	assert.False(t, api.IsProposer())
+	assert.Equal(t, proposer, api.CurrentProposer())
+}
+
+func TestAPI_CurrentProposer_Concurrent(t *testing.T) {
+	chain, engine := newBlockChain(4)
+	defer engine.Stop()
+	api := &API{chain: chain, istanbul: engine}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for round := int64(0); round < 10; round++ {
+				if i == 0 {
+					engine.SetCurrentView(&istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(round)})
+				}
+				api.IsProposer()
+				assert.NotNil(t, api.CurrentProposer())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAPI_CurrentProposer_Stopped(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	api := &API{chain: chain, istanbul: engine}
+	assert.True(t, api.IsProposer())
+
+	engine.Stop()
+	assert.False(t, api.IsProposer())
+	assert.Nil(t, api.CurrentProposer())
+}
+
+func TestAPIExtension_GetCommitteeList(t *testing.T) {
+	chain, engine := newBlockChain(7, subGroupSize(4))
+	defer engine.Stop()
+	api := &APIExtension{chain: chain, istanbul: engine}
+
+	genesis := chain.Genesis()
+	valSet := engine.getValidators(0, genesis.Hash())
+	for round := uint64(0); round < 10; round++ {
+		list, err := api.GetCommitteeList(genesis.Hash(), round)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		// the committee falls back to all the validators if it can't be composed
+		assert.True(t, len(list.Committee) == 4 || len(list.Committee) == len(addrs), "round %d", round)
+
+		// the committee is the one checked by the consensus in the view
+		view := &istanbul.View{Sequence: big.NewInt(1), Round: new(big.Int).SetUint64(round)}
+		viewValSet := valSet.Copy()
+		viewValSet.CalcProposer(common.Address{}, round)
+		members := make(map[common.Address]bool)
+		for _, addr := range list.Committee {
+			members[addr] = true
+		}
+		assert.Len(t, members, len(list.Committee))
+		for _, addr := range addrs {
+			assert.Equal(t, members[addr], viewValSet.CheckInSubList(genesis.Hash(), view, addr), "round %d", round)
+		}
+		if assert.True(t, list.ProposerIndex >= 0) {
+			assert.Equal(t, viewValSet.GetProposer().Address(), list.Committee[list.ProposerIndex])
+		}
+
+		// the committee is deterministic
+		again, err := api.GetCommitteeList(genesis.Hash(), round)
+		assert.NoError(t, err)
+		assert.Equal(t, list, again)
+	}
+
+	_, err := api.GetCommitteeList(common.HexToHash("0x1"), 0)
+	assert.ErrorIs(t, err, errUnknownBlock)
+	_, _, err = engine.GetCommitteeList(genesis.Hash(), &istanbul.View{Sequence: big.NewInt(2), Round: big.NewInt(0)})
+	assert.ErrorIs(t, err, errInvalidCommitteeView)
+}
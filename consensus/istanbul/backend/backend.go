@@ -21,8 +21,12 @@
 package backend
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"fmt"
+	"io"
 	"math/big"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,6 +44,7 @@ import (
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/reward"
+	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
 )
 
@@ -71,9 +76,26 @@ func New(rewardbase common.Address, config *istanbul.Config, privateKey *ecdsa.P
 		governance:        governance,
 		nodetype:          nodetype,
 		rewardDistributor: reward.NewRewardDistributor(governance),
+		valSetEvents:      newValidatorSetEvents(),
 	}
+	if config.PrioritizePreprepare {
+		backend.sender = newPrioritySender()
+	}
+	backend.quarantine = newPeerQuarantine(config.QuarantineThreshold,
+		time.Duration(config.QuarantineWindow)*time.Millisecond, time.Duration(config.QuarantineCooldown)*time.Millisecond)
 	backend.currentView.Store(&istanbul.View{Sequence: big.NewInt(0), Round: big.NewInt(0)})
 	backend.core = istanbulCore.New(backend)
+	backend.core.SetRoundChangeHistorySize(int(config.RoundChangeHistorySize))
+	backend.core.SetQuorumSize(config.QuorumSize)
+	backend.core.SetBroadcastRetry(config.BroadcastRetries, time.Duration(config.BroadcastRetryDelay)*time.Millisecond)
+
+	if config.MessageCacheFile != "" {
+		if n, err := backend.loadKnownMessages(config.MessageCacheFile); err != nil {
+			logger.Warn("Failed to load the istanbul message cache", "file", config.MessageCacheFile, "err", err)
+		} else if n > 0 {
+			logger.Info("Loaded the istanbul message cache", "file", config.MessageCacheFile, "messages", n)
+		}
+	}
 	return backend
 }
 
@@ -123,6 +145,15 @@ type backend struct {
 
 	// Node type
 	nodetype common.ConnType
+
+	// sends the preprepare of the local proposer first, nil if disabled
+	sender *prioritySender
+
+	// notifies the changes of the validator set
+	valSetEvents *validatorSetEvents
+
+	// quarantine disconnects and refuses the peers sending malformed messages, nil if disabled
+	quarantine *peerQuarantine
 }
 
 func (sb *backend) NodeType() common.ConnType {
@@ -162,6 +193,8 @@ func (sb *backend) Broadcast(prevHash common.Hash, valSet istanbul.ValidatorSet,
 }
 
 // Broadcast implements istanbul.Backend.Gossip
+// The messages sent by Gossip have no PrevHash, so they are rejected by HandleMsg
+// of the receivers. GossipSubPeer is used to send consensus messages instead.
 func (sb *backend) Gossip(valSet istanbul.ValidatorSet, payload []byte) error {
 	hash := istanbul.RLPHash(payload)
 	sb.knownMessages.Add(hash, true)
@@ -201,6 +234,46 @@ func (sb *backend) checkInSubList(prevHash common.Hash, valSet istanbul.Validato
 	return valSet.CheckInSubList(prevHash, sb.currentView.Load().(*istanbul.View), sb.Address())
 }
 
+// currentProposer returns the proposer of the current view, or nil if the
+// engine is not started. The proposer is calculated on a copy of the validator
+// set, so it can be called concurrently with the consensus.
+func (sb *backend) currentProposer() istanbul.Validator {
+	sb.coreMu.RLock()
+	started := sb.coreStarted
+	sb.coreMu.RUnlock()
+	if !started {
+		return nil
+	}
+
+	valSet, _, _ := sb.currentValidators()
+	if valSet == nil {
+		return nil
+	}
+	return valSet.GetProposer()
+}
+
+// currentValidators returns a copy of the validator set whose proposer is
+// calculated for the current view, with the last proposal and the view. It
+// returns a nil validator set if the validators are unknown.
+func (sb *backend) currentValidators() (istanbul.ValidatorSet, istanbul.Proposal, *istanbul.View) {
+	lastProposal, lastProposer := sb.LastProposal()
+	if lastProposal == nil {
+		return nil, nil, nil
+	}
+	valSet := sb.Validators(lastProposal).Copy()
+	if valSet.Size() == 0 {
+		return nil, nil, nil
+	}
+
+	// the view of the previous sequence is kept until the core starts a new round
+	view := &istanbul.View{Sequence: new(big.Int).Add(lastProposal.Number(), common.Big1), Round: new(big.Int)}
+	if cv, ok := sb.currentView.Load().(*istanbul.View); ok && cv.Sequence.Cmp(view.Sequence) == 0 {
+		view.Round.Set(cv.Round)
+	}
+	valSet.CalcProposer(lastProposer, view.Round.Uint64())
+	return valSet, lastProposal, view
+}
+
 // getTargetReceivers returns a map of nodes which need to receive a message
 func (sb *backend) getTargetReceivers(prevHash common.Hash, valSet istanbul.ValidatorSet) map[common.Address]bool {
 	targets := make(map[common.Address]bool)
@@ -239,6 +312,7 @@ func (sb *backend) GossipSubPeer(prevHash common.Hash, valSet istanbul.Validator
 	sb.knownMessages.Add(hash, true)
 
 	targets := sb.getTargetReceivers(prevHash, valSet)
+	priority := sb.sender != nil && istanbulCore.IsPreprepare(payload, sb.Address())
 
 	if sb.broadcaster != nil && len(targets) > 0 {
 		ps := sb.broadcaster.FindCNPeers(targets)
@@ -263,6 +337,10 @@ func (sb *backend) GossipSubPeer(prevHash common.Hash, valSet istanbul.Validator
 				Payload:  payload,
 			}
 
+			if sb.sender != nil {
+				sb.sender.send(addr, p, cmsg, priority)
+				continue
+			}
 			go p.Send(IstanbulMsg, cmsg)
 		}
 	}
@@ -288,6 +366,13 @@ func (sb *backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
 	// update block's header
 	block = block.WithSeal(h)
 
+	if sb.config.VerifyCommitRLP {
+		if err := verifyRLPRoundTrip(block); err != nil {
+			sb.logger.Error("Committed block can't be read back from its RLP encoding", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+			return err
+		}
+	}
+
 	sb.logger.Info("Committed", "number", proposal.Number().Uint64(), "hash", proposal.Hash(), "address", sb.Address())
 	// - if the proposed and committed blocks are the same, send the proposed hash
 	//   to commit channel, which is being watched inside the engine.Seal() function.
@@ -307,11 +392,43 @@ func (sb *backend) Commit(proposal istanbul.Proposal, seals [][]byte) error {
 	return nil
 }
 
+// verifyRLPRoundTrip encodes v, which should be a pointer, in RLP and decodes
+// it back to a new value of the same type. It returns an error if the new value
+// is not encoded the same as v.
+func verifyRLPRoundTrip(v interface{}) error {
+	_, r, err := rlp.EncodeToReader(v)
+	if err != nil {
+		return err
+	}
+	enc, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := rlp.DecodeBytes(enc, decoded); err != nil {
+		return fmt.Errorf("%w: %v", errRLPRoundTripMismatch, err)
+	}
+	reenc, err := rlp.EncodeToBytes(decoded)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRLPRoundTripMismatch, err)
+	}
+	if !bytes.Equal(enc, reenc) {
+		return errRLPRoundTripMismatch
+	}
+	return nil
+}
+
 // EventMux implements istanbul.Backend.EventMux
 func (sb *backend) EventMux() *event.TypeMux {
 	return sb.istanbulEventMux
 }
 
+// SubscribePhaseEvent subscribes the consensus phase transitions of the core.
+// The delivery never blocks consensus; events are dropped for a slow subscriber.
+func (sb *backend) SubscribePhaseEvent(ch chan<- istanbul.PhaseEvent) event.Subscription {
+	return sb.core.SubscribePhaseEvent(ch)
+}
+
 // Verify implements istanbul.Backend.Verify
 func (sb *backend) Verify(proposal istanbul.Proposal) (time.Duration, error) {
 	// Check if the proposal is a valid block
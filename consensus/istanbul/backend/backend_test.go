@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"fmt"
+	"io"
 	"math/big"
 	"sort"
 	"strings"
@@ -37,6 +38,7 @@ import (
 	"github.com/klaytn/klaytn/crypto"
 	"github.com/klaytn/klaytn/governance"
 	"github.com/klaytn/klaytn/params"
+	"github.com/klaytn/klaytn/rlp"
 	"github.com/klaytn/klaytn/storage/database"
 )
 
@@ -995,6 +997,41 @@ func TestCommit(t *testing.T) {
 	}
 }
 
+// lossyRLP is encoded with both fields, but the second field is dropped when decoded.
+type lossyRLP struct {
+	Kept, Dropped uint64
+}
+
+func (l *lossyRLP) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, []uint64{l.Kept, l.Dropped})
+}
+
+func (l *lossyRLP) DecodeRLP(s *rlp.Stream) error {
+	var fields []uint64
+	if err := s.Decode(&fields); err != nil {
+		return err
+	}
+	l.Kept = fields[0]
+	return nil
+}
+
+func TestVerifyRLPRoundTrip(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	defer engine.Stop()
+
+	block := makeBlock(chain, engine, chain.Genesis())
+	if err := verifyRLPRoundTrip(block); err != nil {
+		t.Errorf("committed block failed the verification: %v", err)
+	}
+
+	if err := verifyRLPRoundTrip(&lossyRLP{Kept: 1, Dropped: 0}); err != nil {
+		t.Errorf("zero field is expected to survive the round-trip: %v", err)
+	}
+	if err := verifyRLPRoundTrip(&lossyRLP{Kept: 1, Dropped: 2}); err != errRLPRoundTripMismatch {
+		t.Errorf("error mismatch: have %v, want %v", err, errRLPRoundTripMismatch)
+	}
+}
+
 func TestGetProposer(t *testing.T) {
 	chain, engine := newBlockChain(1)
 	defer engine.Stop()
@@ -0,0 +1,99 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+)
+
+// consensusSnapshot is the consensus state of the node at a moment, which is
+// logged for debugging.
+type consensusSnapshot struct {
+	started  bool
+	busy     bool // the engine is starting or stopping, so only the view is known
+	sequence uint64
+	round    uint64
+
+	proposer    common.Address
+	committee   []common.Address
+	councilSize uint64
+	isProposer  bool
+	inCommittee bool
+
+	backlogs        int // the number of backlogged future messages
+	pendingRequests int
+}
+
+// consensusSnapshot takes a snapshot of the consensus state. It only reads the
+// states which can be accessed concurrently with the consensus, and it doesn't
+// wait for the engine to be started or stopped.
+func (sb *backend) consensusSnapshot() *consensusSnapshot {
+	s := &consensusSnapshot{}
+	if cv, ok := sb.currentView.Load().(*istanbul.View); ok {
+		s.sequence, s.round = cv.Sequence.Uint64(), cv.Round.Uint64()
+	}
+
+	if !sb.coreMu.TryRLock() {
+		s.busy = true
+		return s
+	}
+	s.started = sb.coreStarted
+	sb.coreMu.RUnlock()
+	if !s.started {
+		return s
+	}
+
+	s.backlogs, s.pendingRequests = sb.core.PendingMessages()
+
+	valSet, lastProposal, view := sb.currentValidators()
+	if valSet == nil {
+		return s
+	}
+	s.sequence, s.round = view.Sequence.Uint64(), view.Round.Uint64()
+	s.councilSize = valSet.Size()
+	s.proposer = valSet.GetProposer().Address()
+	s.isProposer = s.proposer == sb.Address()
+	for _, val := range valSet.SubListWithProposer(lastProposal.Hash(), s.proposer, view) {
+		s.committee = append(s.committee, val.Address())
+		if val.Address() == sb.Address() {
+			s.inCommittee = true
+		}
+	}
+	return s
+}
+
+// logContext returns the snapshot as the context of a structured log.
+func (s *consensusSnapshot) logContext() []interface{} {
+	ctx := []interface{}{"started", s.started, "sequence", s.sequence, "round", s.round}
+	if s.busy {
+		return append(ctx, "busy", true)
+	}
+	if !s.started {
+		return ctx
+	}
+	return append(ctx,
+		"proposer", s.proposer, "isProposer", s.isProposer,
+		"councilSize", s.councilSize, "committee", s.committee, "inCommittee", s.inCommittee,
+		"backlogs", s.backlogs, "pendingRequests", s.pendingRequests)
+}
+
+// LogConsensusSnapshot logs the current consensus state, which is used to debug
+// a running node without attaching a console.
+func (sb *backend) LogConsensusSnapshot() {
+	sb.logger.Info("Consensus snapshot", sb.consensusSnapshot().logContext()...)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackend_ConsensusSnapshot(t *testing.T) {
+	_, engine := newBlockChain(4)
+
+	engine.SetCurrentView(&istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(2)})
+	s := engine.consensusSnapshot()
+	assert.True(t, s.started)
+	assert.False(t, s.busy)
+	assert.Equal(t, uint64(1), s.sequence)
+	assert.Equal(t, uint64(2), s.round)
+	assert.Equal(t, uint64(4), s.councilSize)
+	assert.Contains(t, addrs, s.proposer)
+	assert.Equal(t, s.proposer == engine.Address(), s.isProposer)
+	assert.NotEmpty(t, s.committee)
+	assert.Contains(t, s.committee, s.proposer)
+	assert.Zero(t, s.backlogs)
+	assert.Zero(t, s.pendingRequests)
+
+	// the proposer is the same as the one returned by the API
+	api := &API{istanbul: engine}
+	assert.Equal(t, s.proposer, *api.CurrentProposer())
+
+	ctx := s.logContext()
+	for _, field := range []string{"started", "sequence", "round", "proposer", "isProposer",
+		"councilSize", "committee", "inCommittee", "backlogs", "pendingRequests"} {
+		assert.Contains(t, ctx, field)
+	}
+	engine.LogConsensusSnapshot()
+
+	// the snapshot doesn't wait for the engine being started or stopped
+	engine.coreMu.Lock()
+	s = engine.consensusSnapshot()
+	engine.coreMu.Unlock()
+	assert.True(t, s.busy)
+	assert.Equal(t, uint64(2), s.round)
+	assert.Contains(t, s.logContext(), "busy")
+
+	engine.Stop()
+	s = engine.consensusSnapshot()
+	assert.False(t, s.started)
+	assert.NotContains(t, s.logContext(), "proposer")
+}
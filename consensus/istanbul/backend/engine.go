@@ -25,6 +25,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -88,6 +89,12 @@ var (
 	errEmptyCommittedSeals = errors.New("zero committed seals")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transactions hashes")
+	// errRLPRoundTripMismatch is returned if a committed block is not decoded back from its RLP encoding.
+	errRLPRoundTripMismatch = errors.New("mismatch RLP round-trip")
+	// errUnexpectedProposer is returned if a block is not proposed by the proposer of its round.
+	errUnexpectedProposer = errors.New("unexpected proposer")
+	// errInvalidCommitteeView is returned if the sequence of a view is not the number of the next block.
+	errInvalidCommitteeView = errors.New("the sequence of the view must be the next block number")
 )
 
 var (
@@ -326,7 +333,12 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	if err != nil {
 		return err
 	}
+	return sb.checkCommittedSeals(header, snap)
+}
 
+// checkCommittedSeals checks whether the committed seals of the header are
+// signed by enough validators of the snapshot of the parent.
+func (sb *backend) checkCommittedSeals(header *types.Header, snap *Snapshot) error {
 	extra, err := types.ExtractIstanbulExtra(header)
 	if err != nil {
 		return err
@@ -357,7 +369,11 @@ func (sb *backend) verifyCommittedSeals(chain consensus.ChainReader, header *typ
 	}
 
 	// The length of validSeal should be larger than number of faulty node + 1
-	if validSeal < istanbulCore.RequiredMessageCount(snap.ValSet, header.Number) {
+	quorum, err := istanbulCore.QuorumSize(snap.ValSet, header.Number, sb.config.QuorumSize)
+	if err != nil {
+		return err
+	}
+	if validSeal < quorum {
 		return errInvalidCommittedSeals
 	}
 
@@ -677,10 +693,17 @@ func (sb *backend) Stop() error {
 	if !sb.coreStarted {
 		return istanbul.ErrStoppedEngine
 	}
+	// the engine is stopped even if the core or the message cache fails to stop cleanly
+	sb.coreStarted = false
 	if err := sb.core.Stop(); err != nil {
-		return err
+		return fmt.Errorf("failed to stop istanbul core: %w", err)
+	}
+
+	if path := sb.config.MessageCacheFile; path != "" {
+		if err := sb.saveKnownMessages(path); err != nil {
+			return fmt.Errorf("failed to save istanbul message cache %s: %w", path, err)
+		}
 	}
-	sb.coreStarted = false
 	return nil
 }
 
@@ -827,6 +850,93 @@ func (sb *backend) GetConsensusInfo(block *types.Block) (consensus.ConsensusInfo
 	return cInfo, nil
 }
 
+// VerifyConsensus replays the consensus of a stored block: it checks that the
+// block is proposed by the proposer of its round and committed by enough
+// validators of the parent's validator set. Unlike VerifyHeader, it doesn't
+// write snapshots or governance states, so it can be used on a read-only DB.
+func (sb *backend) VerifyConsensus(chain consensus.ChainReader, header *types.Header) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+
+	snap, err := sb.snapshot(chain, number-1, header.ParentHash, nil, false)
+	if err != nil {
+		return err
+	}
+
+	proposer, err := ecrecover(header)
+	if err != nil {
+		return err
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+
+	expected := sb.viewValidators(snap, parent, uint64(header.Round())).GetProposer()
+	if expected == nil {
+		return errUnauthorized
+	}
+	if expected.Address() != proposer {
+		return fmt.Errorf("%w: expected %s, but proposed by %s", errUnexpectedProposer, expected.Address().Hex(), proposer.Hex())
+	}
+
+	return sb.checkCommittedSeals(header, snap)
+}
+
+// viewValidators returns a copy of the validator set of the snapshot at the
+// parent, whose proposer is calculated for the round of the next block as the
+// consensus does.
+func (sb *backend) viewValidators(snap *Snapshot, parent *types.Header, round uint64) istanbul.ValidatorSet {
+	// the author of the genesis block is the zero address, as in GetProposer
+	lastProposer, _ := sb.Author(parent)
+
+	valSet := snap.ValSet.Copy()
+	valSet.CalcProposer(lastProposer, round)
+	return valSet
+}
+
+// GetCommitteeList returns the committee of the view on top of the block with
+// blockHash in the order composed by the consensus, and the index of the
+// proposer in it. The committee is composed by SubList of the validator set of
+// the view, which CheckInSubList of the consensus is based on, so it can be
+// used to verify the committee selection independently.
+func (sb *backend) GetCommitteeList(blockHash common.Hash, view *istanbul.View) ([]common.Address, int, error) {
+	if sb.chain == nil {
+		return nil, -1, errNoChainReader
+	}
+	parent := sb.chain.GetHeaderByHash(blockHash)
+	if parent == nil {
+		return nil, -1, errUnknownBlock
+	}
+	if view == nil || view.Sequence == nil || view.Round == nil ||
+		view.Sequence.Cmp(new(big.Int).Add(parent.Number, common.Big1)) != 0 {
+		return nil, -1, errInvalidCommitteeView
+	}
+
+	snap, err := sb.snapshot(sb.chain, parent.Number.Uint64(), blockHash, nil, false)
+	if err != nil {
+		return nil, -1, err
+	}
+	valSet := sb.viewValidators(snap, parent, view.Round.Uint64())
+	proposer := valSet.GetProposer()
+	if proposer == nil {
+		return nil, -1, errUnauthorized
+	}
+
+	committee := valSet.SubList(blockHash, view)
+	addrs := make([]common.Address, len(committee))
+	proposerIdx := -1
+	for i, val := range committee {
+		addrs[i] = val.Address()
+		if addrs[i] == proposer.Address() {
+			proposerIdx = i
+		}
+	}
+	return addrs, proposerIdx, nil
+}
+
 func (sb *backend) InitSnapshot() {
 	sb.recents.Purge()
 }
@@ -23,7 +23,9 @@ package backend
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
@@ -286,6 +288,38 @@ func TestPrepare(t *testing.T) {
 	}
 }
 
+// failingStopCore wraps a core.Engine and fails on Stop after stopping it.
+type failingStopCore struct {
+	core.Engine
+	err error
+}
+
+func (c *failingStopCore) Stop() error {
+	c.Engine.Stop()
+	return c.err
+}
+
+func TestStop(t *testing.T) {
+	// a failure of the core is returned
+	_, engine := newBlockChain(1)
+	stopErr := errors.New("stop failure")
+	engine.core = &failingStopCore{Engine: engine.core, err: stopErr}
+	err := engine.Stop()
+	assert.ErrorIs(t, err, stopErr)
+	assert.False(t, engine.coreStarted)
+
+	// a failure of saving the message cache is returned
+	_, engine = newBlockChain(1)
+	config := *engine.config
+	config.MessageCacheFile = filepath.Join(t.TempDir(), "missing", "istanbul-messages.rlp")
+	engine.config = &config
+	assert.Error(t, engine.Stop())
+	assert.False(t, engine.coreStarted)
+
+	// stopping a stopped engine
+	assert.Equal(t, istanbul.ErrStoppedEngine, engine.Stop())
+}
+
 func TestSealStopChannel(t *testing.T) {
 	chain, engine := newBlockChain(4)
 	defer engine.Stop()
@@ -431,6 +465,42 @@ func TestVerifySeal(t *testing.T) {
 	}
 }
 
+func TestVerifyConsensus(t *testing.T) {
+	chain, engine := newBlockChain(1, proposerPolicy(params.RoundRobin), blockPeriod(0))
+	defer engine.Stop()
+
+	// a fixture chain committed by the validator
+	block := chain.Genesis()
+	for i := 0; i < 5; i++ {
+		block = makeBlockWithSeal(chain, engine, block)
+		_, err := chain.InsertChain(types.Blocks{block})
+		assert.NoError(t, err)
+	}
+
+	// replay the consensus by another backend which is not started, as offline tools do
+	key, _ := crypto.GenerateKey()
+	verifier := New(common.Address{}, engine.config, key, engine.db, engine.governance, common.CONSENSUSNODE).(*backend)
+	headerChain, err := blockchain.NewHeaderChain(engine.db, chain.Config(), verifier, func() bool { return false })
+	assert.NoError(t, err)
+	for i := uint64(0); i <= block.NumberU64(); i++ {
+		assert.NoError(t, verifier.VerifyConsensus(headerChain, headerChain.GetHeaderByNumber(i)), i)
+	}
+
+	// committed by a node which is not a validator
+	header := makeBlockWithSeal(chain, engine, block).Header()
+	other, _ := crypto.GenerateKey()
+	sig, _ := crypto.Sign(crypto.Keccak256(core.PrepareCommittedSeal(header.Hash())), other)
+	assert.NoError(t, writeCommittedSeals(header, [][]byte{sig}))
+	assert.ErrorIs(t, verifier.VerifyConsensus(headerChain, header), errInvalidCommittedSeals)
+
+	// proposed by a node which is not the proposer
+	header = makeBlockWithoutSeal(chain, engine, block).Header()
+	seal, _ := crypto.Sign(crypto.Keccak256(sigHash(header).Bytes()), other)
+	assert.NoError(t, writeSeal(header, seal))
+	assert.NoError(t, writeCommittedSeals(header, makeCommittedSeals(header.Hash())))
+	assert.ErrorIs(t, verifier.VerifyConsensus(headerChain, header), errUnexpectedProposer)
+}
+
 func TestVerifyHeaders(t *testing.T) {
 	chain, engine := newBlockChain(1)
 	defer engine.Stop()
@@ -22,6 +22,7 @@ package backend
 
 import (
 	"errors"
+	"fmt"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/klaytn/klaytn/common"
@@ -40,6 +41,9 @@ var (
 	errNoChainReader      = errors.New("sb.chain is nil! --mine option might be missing")
 	errInvalidPeerAddress = errors.New("invalid address")
 
+	// errMalformedConsensusMsg is returned when a decoded message has no PrevHash or Payload
+	errMalformedConsensusMsg = errors.New("malformed istanbul message")
+
 	// TODO-Klaytn-Istanbul: define Versions and Lengths with correct values.
 	IstanbulProtocol = consensus.Protocol{
 		Name:     "istanbul",
@@ -62,12 +66,18 @@ func (sb *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		if !sb.coreStarted {
 			return true, istanbul.ErrStoppedEngine
 		}
+		if sb.quarantine.quarantined(addr) {
+			return true, istanbul.ErrPeerQuarantined
+		}
 
 		var cmsg istanbul.ConsensusMsg
 
 		// var data []byte
 		if err := msg.Decode(&cmsg); err != nil {
-			return true, errDecodeFailed
+			return true, sb.malformedMsg(addr, errDecodeFailed)
+		}
+		if err := validateConsensusMsg(&cmsg); err != nil {
+			return true, sb.malformedMsg(addr, err)
 		}
 		data := cmsg.Payload
 		hash := istanbul.RLPHash(data)
@@ -99,11 +109,32 @@ func (sb *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	return false, nil
 }
 
+// malformedMsg records a malformed message of the peer, and returns
+// istanbul.ErrPeerQuarantined wrapping err if the peer is quarantined by it.
+func (sb *backend) malformedMsg(addr common.Address, err error) error {
+	if sb.quarantine.fail(addr) {
+		return fmt.Errorf("%w: %v", istanbul.ErrPeerQuarantined, err)
+	}
+	return err
+}
+
+// validateConsensusMsg checks the structure of a decoded message, so that a
+// message which can't be processed is neither cached nor posted to the core.
+func validateConsensusMsg(cmsg *istanbul.ConsensusMsg) error {
+	if common.EmptyHash(cmsg.PrevHash) || len(cmsg.Payload) == 0 {
+		return errMalformedConsensusMsg
+	}
+	return nil
+}
+
 func (sb *backend) ValidatePeerType(addr common.Address) error {
 	// istanbul.Start vs try to connect by peer
 	for sb.chain == nil {
 		return errNoChainReader
 	}
+	if sb.quarantine.quarantined(addr) {
+		return istanbul.ErrPeerQuarantined
+	}
 	validators := sb.getValidators(sb.chain.CurrentHeader().Number.Uint64(), sb.chain.CurrentHeader().Hash())
 	for _, val := range validators.List() {
 		if addr == val.Address() {
@@ -134,6 +165,8 @@ func (sb *backend) RegisterConsensusMsgCode(peer consensus.Peer) {
 }
 
 func (sb *backend) NewChainHead() error {
+	sb.checkValidatorSetChange()
+
 	sb.coreMu.RLock()
 	defer sb.coreMu.RUnlock()
 	if !sb.coreStarted {
@@ -113,6 +113,35 @@ func TestBackend_HandleMsg(t *testing.T) {
 		assert.True(t, isHandled)
 	}
 
+	// Failure case - decodable but malformed message
+	for _, malformed := range []*istanbul.ConsensusMsg{
+		{PrevHash: common.Hash{}, Payload: []byte("malformed data")},
+		{PrevHash: common.HexToHash("0x1234"), Payload: nil},
+	} {
+		size, payload, _ := rlp.EncodeToReader(malformed)
+		msg := p2p.Msg{
+			Code:    IstanbulMsg,
+			Size:    uint32(size),
+			Payload: payload,
+		}
+		malformedHash := istanbul.RLPHash(malformed.Payload)
+		isHandled, err := backend.HandleMsg(addr, msg)
+		assert.Equal(t, errMalformedConsensusMsg, err)
+		assert.True(t, isHandled)
+
+		// the message is neither cached nor posted
+		_, ok := backend.knownMessages.Get(malformedHash)
+		assert.False(t, ok)
+		recentMsg, _ := backend.recentMessages.Get(addr)
+		_, ok = recentMsg.(*lru.ARCCache).Get(malformedHash)
+		assert.False(t, ok)
+		select {
+		case event := <-eventSub.Chan():
+			t.Fatalf("unexpected event: %v", event.Data)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
 	// Failure case - stopped istanbul engine
 	{
 		msg := p2p.Msg{
@@ -120,7 +149,7 @@ func TestBackend_HandleMsg(t *testing.T) {
 			Size:    uint32(size),
 			Payload: payload,
 		}
-		_ = backend.Stop()
+		assert.NoError(t, backend.Stop())
 		isHandled, err := backend.HandleMsg(addr, msg)
 		assert.Equal(t, istanbul.ErrStoppedEngine, err)
 		assert.True(t, isHandled)
@@ -155,3 +184,69 @@ func TestBackend_ValidatePeerType(t *testing.T) {
 		assert.Equal(t, errNoChainReader, err)
 	}
 }
+
+func TestBackend_HandleMsg_Quarantine(t *testing.T) {
+	_, backend := newBlockChain(1)
+	defer backend.Stop()
+
+	now := time.Now()
+	backend.quarantine = newPeerQuarantine(3, time.Minute, 10*time.Minute)
+	backend.quarantine.now = func() time.Time { return now }
+
+	newMsg := func(data interface{}) p2p.Msg {
+		size, payload, _ := rlp.EncodeToReader(data)
+		return p2p.Msg{Code: IstanbulMsg, Size: uint32(size), Payload: payload}
+	}
+	validMsg := func() p2p.Msg {
+		return newMsg(&istanbul.ConsensusMsg{PrevHash: common.HexToHash("0x1234"), Payload: []byte(now.String())})
+	}
+	malformedMsg := func() p2p.Msg {
+		return newMsg([]byte{0x1, 0x2})
+	}
+
+	// the validator itself is the peer, so that it passes ValidatePeerType
+	addr, other := backend.address, common.StringToAddress("other")
+
+	// the failures spread over longer than the window don't quarantine the peer
+	for i := 0; i < 5; i++ {
+		_, err := backend.HandleMsg(addr, malformedMsg())
+		assert.Equal(t, errDecodeFailed, err)
+		now = now.Add(40 * time.Second)
+	}
+	_, err := backend.HandleMsg(addr, validMsg())
+	assert.NoError(t, err)
+	now = now.Add(time.Minute)
+
+	// the failures within the window quarantine the peer
+	for i := 0; i < 2; i++ {
+		_, err := backend.HandleMsg(addr, malformedMsg())
+		assert.Equal(t, errDecodeFailed, err)
+	}
+	_, err = backend.HandleMsg(addr, newMsg(&istanbul.ConsensusMsg{PrevHash: common.Hash{}, Payload: []byte("malformed")}))
+	assert.ErrorIs(t, err, istanbul.ErrPeerQuarantined)
+	assert.ErrorContains(t, err, errMalformedConsensusMsg.Error())
+
+	// the quarantined peer is refused, while the others are not affected
+	isHandled, err := backend.HandleMsg(addr, validMsg())
+	assert.True(t, isHandled)
+	assert.Equal(t, istanbul.ErrPeerQuarantined, err)
+	assert.Equal(t, istanbul.ErrPeerQuarantined, backend.ValidatePeerType(addr))
+	_, err = backend.HandleMsg(other, validMsg())
+	assert.NoError(t, err)
+
+	// the peer is re-admitted after the cooldown
+	now = now.Add(10*time.Minute + time.Second)
+	assert.NoError(t, backend.ValidatePeerType(addr))
+	_, err = backend.HandleMsg(addr, validMsg())
+	assert.NoError(t, err)
+}
+
+func TestPeerQuarantine_Disabled(t *testing.T) {
+	q := newPeerQuarantine(0, time.Minute, time.Minute)
+	assert.Nil(t, q)
+	addr := common.StringToAddress("addr")
+	for i := 0; i < 100; i++ {
+		assert.False(t, q.fail(addr))
+	}
+	assert.False(t, q.quarantined(addr))
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"os"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// maxPersistedMessages is the maximum number of known message hashes written to the message cache file.
+const maxPersistedMessages = 1024
+
+// saveKnownMessages writes the most recent hashes of knownMessages to the given file.
+// The file is written to a temporary file first and renamed, so a crash does not leave a broken file.
+func (sb *backend) saveKnownMessages(path string) error {
+	keys := sb.knownMessages.Keys()
+
+	hashes := make([]common.Hash, 0, maxPersistedMessages)
+	// Keys are ordered from the oldest, so the last ones are kept.
+	if len(keys) > maxPersistedMessages {
+		keys = keys[len(keys)-maxPersistedMessages:]
+	}
+	for _, key := range keys {
+		if hash, ok := key.(common.Hash); ok {
+			hashes = append(hashes, hash)
+		}
+	}
+
+	data, err := rlp.EncodeToBytes(hashes)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadKnownMessages adds the hashes stored in the given file to knownMessages.
+// It returns the number of loaded hashes. A missing file is not an error.
+func (sb *backend) loadKnownMessages(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	var hashes []common.Hash
+	if err := rlp.DecodeBytes(data, &hashes); err != nil {
+		return 0, err
+	}
+	if len(hashes) > maxPersistedMessages {
+		hashes = hashes[len(hashes)-maxPersistedMessages:]
+	}
+	for _, hash := range hashes {
+		sb.knownMessages.Add(hash, true)
+	}
+	return len(hashes), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/networks/p2p"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackend_KnownMessagesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "istanbul-messages.rlp")
+
+	src := newTestBackend()
+	var hashes []common.Hash
+	for i := 0; i < maxPersistedMessages+10; i++ {
+		hash := istanbul.RLPHash([]byte{byte(i), byte(i >> 8)})
+		hashes = append(hashes, hash)
+		src.knownMessages.Add(hash, true)
+	}
+	assert.NoError(t, src.saveKnownMessages(path))
+
+	// no temporary file is left
+	_, err := os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	dst := newTestBackend()
+	n, err := dst.loadKnownMessages(path)
+	assert.NoError(t, err)
+	assert.Equal(t, maxPersistedMessages, n)
+
+	// only the most recent hashes are persisted
+	for _, hash := range hashes[:10] {
+		_, ok := dst.knownMessages.Get(hash)
+		assert.False(t, ok)
+	}
+	for _, hash := range hashes[10:] {
+		_, ok := dst.knownMessages.Get(hash)
+		assert.True(t, ok)
+	}
+
+	// a missing file is not an error
+	n, err = dst.loadKnownMessages(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// a broken file is an error
+	assert.NoError(t, os.WriteFile(path, []byte("broken"), 0o600))
+	_, err = dst.loadKnownMessages(path)
+	assert.Error(t, err)
+}
+
+func TestBackend_HandleMsg_ReloadedKnownMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "istanbul-messages.rlp")
+
+	data := &istanbul.ConsensusMsg{
+		PrevHash: common.HexToHash("0x1234"),
+		Payload:  []byte("test data"),
+	}
+	hash := istanbul.RLPHash(data.Payload)
+
+	// the message cache is saved on stop
+	_, src := newBlockChain(1)
+	config := *src.config
+	config.MessageCacheFile = path
+	src.config = &config
+	src.knownMessages.Add(hash, true)
+	assert.NoError(t, src.Stop())
+
+	_, dst := newBlockChain(1)
+	defer dst.Stop()
+	n, err := dst.loadKnownMessages(path)
+	assert.NoError(t, err)
+	assert.NotZero(t, n)
+
+	eventSub := dst.istanbulEventMux.Subscribe(istanbul.MessageEvent{})
+	defer eventSub.Unsubscribe()
+
+	size, payload, _ := rlp.EncodeToReader(data)
+	isHandled, err := dst.HandleMsg(common.StringToAddress("test addr"), p2p.Msg{
+		Code:    IstanbulMsg,
+		Size:    uint32(size),
+		Payload: payload,
+	})
+	assert.NoError(t, err)
+	assert.True(t, isHandled)
+
+	// the reloaded message is not posted again
+	select {
+	case <-eventSub.Chan():
+		t.Fatal("a known message should not be posted")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
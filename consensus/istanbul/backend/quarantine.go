@@ -0,0 +1,105 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/rcrowley/go-metrics"
+)
+
+var quarantineMeter = metrics.NewRegisteredMeter("consensus/istanbul/backend/quarantine", nil)
+
+// peerQuarantine counts the malformed messages of each peer, and quarantines a
+// peer which sends threshold of them within window for cooldown. A quarantined
+// peer is released automatically when the cooldown ends.
+//
+// A nil *peerQuarantine never quarantines a peer.
+type peerQuarantine struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures map[common.Address][]time.Time // the recent failures of each peer within window
+	until    map[common.Address]time.Time   // when the quarantine of each peer ends
+}
+
+// newPeerQuarantine returns a peerQuarantine, or nil if threshold is 0.
+func newPeerQuarantine(threshold uint64, window, cooldown time.Duration) *peerQuarantine {
+	if threshold == 0 {
+		return nil
+	}
+	return &peerQuarantine{
+		threshold: int(threshold),
+		window:    window,
+		cooldown:  cooldown,
+		now:       time.Now,
+		failures:  make(map[common.Address][]time.Time),
+		until:     make(map[common.Address]time.Time),
+	}
+}
+
+// fail records a malformed message of the peer, and returns true if the peer
+// is quarantined by it.
+func (q *peerQuarantine) fail(addr common.Address) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	failures := q.failures[addr]
+	for len(failures) > 0 && now.Sub(failures[0]) > q.window {
+		failures = failures[1:]
+	}
+	failures = append(failures, now)
+	if len(failures) < q.threshold {
+		q.failures[addr] = failures
+		return false
+	}
+	delete(q.failures, addr)
+	q.until[addr] = now.Add(q.cooldown)
+	quarantineMeter.Mark(1)
+	logger.Warn("Quarantine a peer sending malformed consensus messages", "addr", addr,
+		"failures", len(failures), "cooldown", q.cooldown)
+	return true
+}
+
+// quarantined returns true if the peer is quarantined.
+func (q *peerQuarantine) quarantined(addr common.Address) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	until, exist := q.until[addr]
+	if !exist {
+		return false
+	}
+	if q.now().Before(until) {
+		return true
+	}
+	delete(q.until, addr)
+	logger.Info("Release a quarantined peer", "addr", addr)
+	return false
+}
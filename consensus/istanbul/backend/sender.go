@@ -0,0 +1,150 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+)
+
+const (
+	// maxPrioritySends is the maximum number of priority messages sent to a peer
+	// in a row while normal messages are waiting, so that they are not starved.
+	maxPrioritySends = 4
+
+	prioritySendQueueSize = 64
+	normalSendQueueSize   = 1024
+
+	// sendQueueIdleTimeout is how long the sending goroutine of a peer waits for
+	// a message before it exits.
+	sendQueueIdleTimeout = time.Minute
+)
+
+// prioritySender sends the consensus messages to each peer through a queue, where
+// the priority messages, such as the preprepare of the local proposer, are sent
+// before the normal messages waiting in the queue. A normal message is sent after
+// at most maxPrioritySends priority messages.
+type prioritySender struct {
+	mu     sync.Mutex
+	queues map[common.Address]*sendQueue
+}
+
+type sendQueue struct {
+	peer     consensus.Peer
+	priority chan *istanbul.ConsensusMsg
+	normal   chan *istanbul.ConsensusMsg
+}
+
+func newPrioritySender() *prioritySender {
+	return &prioritySender{queues: make(map[common.Address]*sendQueue)}
+}
+
+// send queues the message to the peer. If the queue is full, the message is
+// sent by a new goroutine as it is without the sender.
+func (s *prioritySender) send(addr common.Address, p consensus.Peer, msg *istanbul.ConsensusMsg, priority bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, exist := s.queues[addr]
+	if !exist || q.peer != p {
+		// the previous goroutine of a reconnected peer exits after sending its queue
+		q = &sendQueue{
+			peer:     p,
+			priority: make(chan *istanbul.ConsensusMsg, prioritySendQueueSize),
+			normal:   make(chan *istanbul.ConsensusMsg, normalSendQueueSize),
+		}
+		s.queues[addr] = q
+		go s.loop(addr, q)
+	}
+
+	ch := q.normal
+	if priority {
+		ch = q.priority
+	}
+	select {
+	case ch <- msg:
+	default:
+		logger.Debug("Consensus send queue is full", "peer", addr, "priority", priority)
+		go p.Send(IstanbulMsg, msg)
+	}
+}
+
+// loop sends the queued messages of a peer until the queue is idle for
+// sendQueueIdleTimeout.
+func (s *prioritySender) loop(addr common.Address, q *sendQueue) {
+	idle := time.NewTimer(sendQueueIdleTimeout)
+	defer idle.Stop()
+
+	prioritySends := 0
+	for {
+		// a waiting normal message is sent after maxPrioritySends priority messages
+		if prioritySends >= maxPrioritySends {
+			select {
+			case msg := <-q.normal:
+				q.peer.Send(IstanbulMsg, msg)
+				prioritySends = 0
+				continue
+			default:
+			}
+		}
+		select {
+		case msg := <-q.priority:
+			q.peer.Send(IstanbulMsg, msg)
+			prioritySends++
+			continue
+		default:
+		}
+
+		if !idle.Stop() {
+			select {
+			case <-idle.C:
+			default:
+			}
+		}
+		idle.Reset(sendQueueIdleTimeout)
+		select {
+		case msg := <-q.priority:
+			q.peer.Send(IstanbulMsg, msg)
+			prioritySends++
+		case msg := <-q.normal:
+			q.peer.Send(IstanbulMsg, msg)
+			prioritySends = 0
+		case <-idle.C:
+			if s.stopIfIdle(addr, q) {
+				return
+			}
+		}
+	}
+}
+
+// stopIfIdle removes the queue of the peer if nothing is queued, and returns
+// true if the sending goroutine should exit.
+func (s *prioritySender) stopIfIdle(addr common.Address, q *sendQueue) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(q.priority) > 0 || len(q.normal) > 0 {
+		return false
+	}
+	if s.queues[addr] == q {
+		delete(s.queues, addr)
+	}
+	return true
+}
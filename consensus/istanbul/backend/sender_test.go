@@ -0,0 +1,137 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowPeer records the sent messages. If gate is set, the sends are notified
+// to entered and blocked until gate is closed.
+type slowPeer struct {
+	gate    chan struct{}
+	entered chan struct{}
+
+	mu   sync.Mutex
+	sent []*istanbul.ConsensusMsg
+}
+
+func (p *slowPeer) Send(msgcode uint64, data interface{}) error {
+	if p.gate != nil {
+		select {
+		case p.entered <- struct{}{}:
+		default:
+		}
+		<-p.gate
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, data.(*istanbul.ConsensusMsg))
+	return nil
+}
+
+func (p *slowPeer) RegisterConsensusMsgCode(msgCode uint64) error {
+	return nil
+}
+
+func (p *slowPeer) sentMessages() []*istanbul.ConsensusMsg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*istanbul.ConsensusMsg{}, p.sent...)
+}
+
+func (p *slowPeer) waitSent(t *testing.T, n int) []*istanbul.ConsensusMsg {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if sent := p.sentMessages(); len(sent) >= n {
+			return sent
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d messages are not sent in time", n)
+	return nil
+}
+
+func TestPrioritySender_PreprepareNotDelayed(t *testing.T) {
+	sender := newPrioritySender()
+	gate := make(chan struct{})
+	peer := &slowPeer{gate: gate, entered: make(chan struct{}, 1)}
+	addr := common.HexToAddress("0x1")
+
+	// bulk traffic of the other consensus messages, whose first message holds
+	// the queue until the preprepare is queued
+	const numBulk = 200
+	sender.send(addr, peer, &istanbul.ConsensusMsg{Payload: []byte("bulk")}, false)
+	<-peer.entered
+	for i := 1; i < numBulk; i++ {
+		sender.send(addr, peer, &istanbul.ConsensusMsg{Payload: []byte("bulk")}, false)
+	}
+	preprepare := &istanbul.ConsensusMsg{Payload: []byte("preprepare")}
+	sender.send(addr, peer, preprepare, true)
+	close(gate)
+
+	sent := peer.waitSent(t, numBulk+1)
+	// the preprepare is sent right after the message being sent when it is queued
+	assert.Equal(t, preprepare, sent[1])
+}
+
+func TestPrioritySender_NoStarvation(t *testing.T) {
+	sender := newPrioritySender()
+	gate := make(chan struct{})
+	peer := &slowPeer{gate: gate, entered: make(chan struct{}, 1)}
+	addr := common.HexToAddress("0x1")
+
+	// the first message holds the queue until the others are queued
+	sender.send(addr, peer, &istanbul.ConsensusMsg{Payload: []byte("first")}, false)
+	<-peer.entered
+	const numNormal, numPriority = 5, 4 * maxPrioritySends
+	for i := 0; i < numNormal; i++ {
+		sender.send(addr, peer, &istanbul.ConsensusMsg{Payload: []byte("normal")}, false)
+	}
+	for i := 0; i < numPriority; i++ {
+		sender.send(addr, peer, &istanbul.ConsensusMsg{Payload: []byte("priority")}, true)
+	}
+	close(gate)
+
+	sent := peer.waitSent(t, 1+numNormal+numPriority)
+	var order string
+	for _, msg := range sent[1:] {
+		order += string(msg.Payload[0])
+	}
+	// a normal message is sent after every maxPrioritySends priority messages
+	assert.Equal(t, "ppppnppppnppppnppppnn", order)
+}
+
+func TestPrioritySender_Reconnect(t *testing.T) {
+	sender := newPrioritySender()
+	addr := common.HexToAddress("0x1")
+	oldPeer, newPeer := &slowPeer{}, &slowPeer{}
+
+	sender.send(addr, oldPeer, &istanbul.ConsensusMsg{Payload: []byte("old")}, false)
+	oldPeer.waitSent(t, 1)
+
+	// the messages are sent to the new peer of the same address
+	sender.send(addr, newPeer, &istanbul.ConsensusMsg{Payload: []byte("new")}, true)
+	newPeer.waitSent(t, 1)
+	assert.Len(t, oldPeer.sentMessages(), 1)
+}
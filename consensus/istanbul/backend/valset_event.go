@@ -0,0 +1,133 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"sync"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/event"
+	"github.com/rcrowley/go-metrics"
+)
+
+// valSetEventQueueSize is the number of validator set events which can be pending
+// for delivery. Events are dropped if the queue is full, so a slow subscriber never
+// delays the chain.
+const valSetEventQueueSize = 64
+
+var valSetEventDropMeter = metrics.NewRegisteredMeter("consensus/istanbul/backend/valSetEvent/drop", nil)
+
+// validatorSetEvents finds the changes of the validator set between the blocks
+// and delivers them to the subscribers.
+type validatorSetEvents struct {
+	feed  event.Feed
+	queue chan istanbul.ValidatorSetChangedEvent
+	once  sync.Once // starts the delivery loop
+
+	mu   sync.Mutex
+	last []common.Address // nil until the first validator set is seen
+}
+
+func newValidatorSetEvents() *validatorSetEvents {
+	return &validatorSetEvents{queue: make(chan istanbul.ValidatorSetChangedEvent, valSetEventQueueSize)}
+}
+
+// subscribe registers a subscription of istanbul.ValidatorSetChangedEvent.
+func (e *validatorSetEvents) subscribe(ch chan<- istanbul.ValidatorSetChangedEvent) event.Subscription {
+	e.once.Do(func() {
+		go e.loop()
+	})
+	return e.feed.Subscribe(ch)
+}
+
+// loop delivers the queued events to the subscribers.
+func (e *validatorSetEvents) loop() {
+	for ev := range e.queue {
+		e.feed.Send(ev)
+	}
+}
+
+// update compares the validators effective from the given block with the last
+// ones, and queues an event without blocking if they are changed. The first
+// validator set is only remembered.
+func (e *validatorSetEvents) update(number uint64, validators []common.Address) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	last := e.last
+	e.last = append(make([]common.Address, 0, len(validators)), validators...)
+	if last == nil {
+		return
+	}
+
+	ev := istanbul.ValidatorSetChangedEvent{
+		Number:  number,
+		Added:   subtractAddresses(validators, last),
+		Removed: subtractAddresses(last, validators),
+	}
+	if len(ev.Added) == 0 && len(ev.Removed) == 0 {
+		return
+	}
+
+	select {
+	case e.queue <- ev:
+	default:
+		valSetEventDropMeter.Mark(1)
+	}
+}
+
+// subtractAddresses returns the addresses of a which are not in b in the order of a.
+func subtractAddresses(a, b []common.Address) []common.Address {
+	in := make(map[common.Address]bool, len(b))
+	for _, addr := range b {
+		in[addr] = true
+	}
+	var diff []common.Address
+	for _, addr := range a {
+		if !in[addr] {
+			diff = append(diff, addr)
+		}
+	}
+	return diff
+}
+
+// SubscribeValidatorSetChangedEvent subscribes the changes of the validator set.
+// Events are delivered in order, but they are dropped while the subscribers are
+// not ready to receive them.
+func (sb *backend) SubscribeValidatorSetChangedEvent(ch chan<- istanbul.ValidatorSetChangedEvent) event.Subscription {
+	return sb.valSetEvents.subscribe(ch)
+}
+
+// checkValidatorSetChange finds if the validator set of the next block of the
+// current head is changed.
+func (sb *backend) checkValidatorSetChange() {
+	if sb.chain == nil {
+		return
+	}
+	head := sb.chain.CurrentHeader()
+	if head == nil {
+		return
+	}
+	// the snapshot of a block has the validators of its next block
+	valSet := sb.getValidators(head.Number.Uint64(), head.Hash())
+	validators := make([]common.Address, 0, valSet.Size())
+	for _, val := range valSet.List() {
+		validators = append(validators, val.Address())
+	}
+	sb.valSetEvents.update(head.Number.Uint64()+1, validators)
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorSetEvents(t *testing.T) {
+	v1, v2, v3, v4 := common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3"), common.HexToAddress("0x4")
+
+	events := newValidatorSetEvents()
+	ch := make(chan istanbul.ValidatorSetChangedEvent, 10)
+	sub := events.subscribe(ch)
+	defer sub.Unsubscribe()
+
+	// the first validator set and the same one are not changes
+	events.update(10, []common.Address{v1, v2, v3})
+	events.update(11, []common.Address{v1, v2, v3})
+	// v3 leaves and v4 joins between the blocks 11 and 12
+	events.update(12, []common.Address{v1, v2, v4})
+	// v1 leaves
+	events.update(13, []common.Address{v2, v4})
+
+	expected := []istanbul.ValidatorSetChangedEvent{
+		{Number: 12, Added: []common.Address{v4}, Removed: []common.Address{v3}},
+		{Number: 13, Removed: []common.Address{v1}},
+	}
+	for _, want := range expected {
+		select {
+		case ev := <-ch:
+			assert.Equal(t, want, ev)
+		case <-time.After(time.Second):
+			t.Fatalf("the event of block %d is not delivered", want.Number)
+		}
+	}
+	select {
+	case ev := <-ch:
+		t.Fatalf("unexpected event: %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestValidatorSetEvents_SlowSubscriber(t *testing.T) {
+	events := newValidatorSetEvents()
+	// nobody receives from the channel
+	sub := events.subscribe(make(chan istanbul.ValidatorSetChangedEvent))
+	defer sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*valSetEventQueueSize; i++ {
+			events.update(uint64(i), []common.Address{common.BigToAddress(common.Big1), common.BigToAddress(common.Big2)})
+			events.update(uint64(i), []common.Address{common.BigToAddress(common.Big1)})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("updating the validator set is blocked by a slow subscriber")
+	}
+}
+
+func TestBackend_NewChainHeadValidatorSet(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	defer engine.Stop()
+	defer chain.Stop()
+
+	ch := make(chan istanbul.ValidatorSetChangedEvent, 1)
+	sub := engine.SubscribeValidatorSetChangedEvent(ch)
+	defer sub.Unsubscribe()
+
+	// the validator set of the chain is remembered, and not changed
+	assert.NoError(t, engine.NewChainHead())
+	assert.NoError(t, engine.NewChainHead())
+	assert.Equal(t, []common.Address{engine.Address()}, engine.valSetEvents.last)
+	select {
+	case ev := <-ch:
+		t.Fatalf("unexpected event: %v", ev)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -34,6 +34,46 @@ type Config struct {
 	ProposerPolicy ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
 	Epoch          uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
 	SubGroupSize   uint64         `toml:",omitempty"`
+
+	// MessageCacheFile is the file the known consensus message hashes are saved to on stop and
+	// loaded from on startup. The persistence is disabled if it is empty.
+	MessageCacheFile string `toml:",omitempty"`
+
+	// BroadcastRetries is the number of retries of a broadcast of a consensus
+	// message failed by the backend, which are made BroadcastRetryDelay
+	// milliseconds apart. The messages are sent to the peers asynchronously,
+	// so the backend of a node reports no send failures, and it is disabled by
+	// default.
+	BroadcastRetries    uint64 `toml:",omitempty"`
+	BroadcastRetryDelay uint64 `toml:",omitempty"`
+
+	// VerifyCommitRLP checks that a committed block is decoded back from its RLP encoding
+	// before it is persisted. It is disabled by default for performance.
+	VerifyCommitRLP bool `toml:",omitempty"`
+
+	// RoundChangeHistorySize is the number of the recent round changes kept for
+	// the istanbul_roundChangeHistory API. 0 disables the history.
+	RoundChangeHistorySize uint64 `toml:",omitempty"`
+
+	// QuorumSize overrides the number of PREPARE/COMMIT messages and committed
+	// seals required to commit a block, which is 2f+1 of the committee if it is 0.
+	// UNSAFE: it is only for test networks such as a devnet of special topology.
+	// A quorum smaller than 2f+1 breaks the safety of the consensus, and all
+	// validators must use the same value to accept the blocks of each other.
+	QuorumSize uint64 `toml:",omitempty"`
+
+	// PrioritizePreprepare sends the preprepare of the local proposer to each peer
+	// before the other consensus messages waiting to be sent to the peer.
+	PrioritizePreprepare bool `toml:",omitempty"`
+
+	// QuarantineThreshold is the number of undecodable or malformed consensus
+	// messages of a peer within QuarantineWindow, which makes the peer
+	// quarantined for QuarantineCooldown. A quarantined peer is disconnected and
+	// refused until the cooldown ends. 0 disables the quarantine, which is the
+	// default.
+	QuarantineThreshold uint64 `toml:",omitempty"`
+	QuarantineWindow    uint64 `toml:",omitempty"` // The window counting the malformed messages in milliseconds
+	QuarantineCooldown  uint64 `toml:",omitempty"` // The duration of a quarantine in milliseconds
 	// ChainConfig	chainconfig
 }
 
@@ -44,4 +84,11 @@ var DefaultConfig = &Config{
 	ProposerPolicy: RoundRobin,
 	Epoch:          30000,
 	SubGroupSize:   21,
+
+	BroadcastRetryDelay: 50,
+
+	RoundChangeHistorySize: 128,
+
+	QuarantineWindow:   60000,
+	QuarantineCooldown: 600000,
 }
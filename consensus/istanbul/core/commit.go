@@ -100,6 +100,12 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 
 	c.acceptCommit(msg, src)
 
+	quorum, err := c.quorumSize(commit.View.Sequence)
+	if err != nil {
+		logger.Error("Failed to get the quorum of the messages", "err", err)
+		return err
+	}
+
 	// Change to Prepared state if we've received enough PREPARE/COMMIT messages or it is locked
 	// and we are in earlier state before Prepared state.
 	// Both of PREPARE and COMMIT messages are counted since the nodes which is hashlocked in
@@ -109,7 +115,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 			logger.Warn("received commit of the hash locked proposal and change state to prepared", "msgType", msgCommit)
 			c.setState(StatePrepared)
 			c.sendCommit()
-		} else if c.current.GetPrepareOrCommitSize() >= RequiredMessageCount(c.valSet, commit.View.Sequence) {
+		} else if c.current.GetPrepareOrCommitSize() >= quorum {
 			logger.Info("received a quorum of the messages and change state to prepared", "msgType", msgCommit, "valSet", c.valSet.Size())
 			c.current.LockHash()
 			c.setState(StatePrepared)
@@ -122,7 +128,7 @@ func (c *core) handleCommit(msg *message, src istanbul.Validator) error {
 	// If we already have a proposal, we may have chance to speed up the consensus process
 	// by committing the proposal without PREPARE messages.
 	//logger.Error("### consensus check","len(commits)",c.current.Commits.Size(),"f(2/3)",2*c.valSet.F(),"state",c.state.Cmp(StateCommitted))
-	if c.state.Cmp(StateCommitted) < 0 && c.current.Commits.Size() >= RequiredMessageCount(c.valSet, commit.View.Sequence) {
+	if c.state.Cmp(StateCommitted) < 0 && c.current.Commits.Size() >= quorum {
 		// Still need to call LockHash here since state can skip Prepared state and jump directly to the Committed state.
 		c.current.LockHash()
 		c.commit()
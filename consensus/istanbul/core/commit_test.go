@@ -10,6 +10,7 @@ import (
 	mock_istanbul "github.com/klaytn/klaytn/consensus/istanbul/mocks"
 	"github.com/klaytn/klaytn/fork"
 	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCore_sendCommit(t *testing.T) {
@@ -81,3 +82,93 @@ func TestCore_sendCommit(t *testing.T) {
 		mockCtrl.Finish()
 	}
 }
+
+func TestCore_handleCommit_quorumSize(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	tests := []struct {
+		name          string
+		numValidators int
+		quorumSize    uint64
+		err           error
+		committed     bool
+	}{
+		{"1 validator", 1, 1, nil, true},
+		{"relaxed quorum", 4, 1, nil, true},
+		{"standard quorum", 4, 0, nil, false},
+		{"invalid quorum", 4, 5, ErrInvalidQuorumSize, false},
+	}
+	for _, tt := range tests {
+		validatorAddrs, validatorKeyMap := genValidators(tt.numValidators)
+		mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+		lastProposal, _ := mockBackend.LastProposal()
+		lastBlock := lastProposal.(*types.Block)
+		// all validators are in the committee
+		mockBackend.Validators(lastBlock).SetSubGroupSize(uint64(tt.numValidators))
+
+		committed := false
+		mockBackend.EXPECT().Commit(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(istanbul.Proposal, [][]byte) error {
+				committed = true
+				return nil
+			}).AnyTimes()
+
+		istCore := New(mockBackend).(*core)
+		istCore.SetQuorumSize(tt.quorumSize)
+		if err := istCore.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		sender := validatorAddrs[0]
+		proposal, err := genBlock(lastBlock, validatorKeyMap[sender])
+		if err != nil {
+			t.Fatal(err)
+		}
+		istCore.current.Preprepare = &istanbul.Preprepare{
+			View:     istCore.currentView(),
+			Proposal: proposal,
+		}
+		istCore.setState(StatePreprepared)
+
+		// a commit of a validator is received
+		commitMsg, err := genIstanbulMsg(msgCommit, lastBlock.Hash(), proposal, sender, validatorKeyMap[sender])
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = istCore.handleMsg(commitMsg.Payload)
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+		assert.Equal(t, tt.committed, committed, tt.name)
+
+		istCore.Stop()
+		mockCtrl.Finish()
+	}
+}
+
+func TestQuorumSize(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, _ := genValidators(10)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+	lastProposal, _ := mockBackend.LastProposal()
+	valSet := mockBackend.Validators(lastProposal)
+	valSet.SetSubGroupSize(7)
+
+	// the standard quorum is used without the override
+	quorum, err := QuorumSize(valSet, common.Big1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, RequiredMessageCount(valSet, common.Big1), quorum)
+
+	// the override is validated against the committee, not the council
+	quorum, err = QuorumSize(valSet, common.Big1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, quorum)
+	_, err = QuorumSize(valSet, common.Big1, 8)
+	assert.ErrorIs(t, err, ErrInvalidQuorumSize)
+}
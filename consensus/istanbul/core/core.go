@@ -22,6 +22,7 @@ package core
 
 import (
 	"bytes"
+	"fmt"
 	"math"
 	"math/big"
 	"sync"
@@ -55,6 +56,7 @@ func New(backend istanbul.Backend) Engine {
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
 		consensusTimestamp: time.Time{},
+		phaseEventCh:       make(chan istanbul.PhaseEvent, phaseEventQueueSize),
 
 		roundMeter:         metrics.NewRegisteredMeter("consensus/istanbul/core/round", nil),
 		currentRoundGauge:  metrics.NewRegisteredGauge("consensus/istanbul/core/currentRound", nil),
@@ -65,6 +67,7 @@ func New(backend istanbul.Backend) Engine {
 		hashLockGauge:      metrics.NewRegisteredGauge("consensus/istanbul/core/hashLock", nil),
 	}
 	c.validateFn = c.checkValidatorSignature
+	c.SetRoundChangeHistorySize(defaultRoundChangeHistorySize)
 	return c
 }
 
@@ -112,6 +115,24 @@ type core struct {
 
 	councilSizeGauge   metrics.Gauge
 	committeeSizeGauge metrics.Gauge
+
+	// the feed of consensus phase transitions for monitoring
+	phaseSubs      phaseSubscribers
+	phaseEventCh   chan istanbul.PhaseEvent
+	phaseEventQuit chan struct{}
+	phaseEventDone chan struct{}
+
+	// the recent round changes for diagnosis
+	roundChanges atomic.Value // *roundChangeHistory
+
+	// overrides the quorum of PREPARE/COMMIT messages if it is not 0, which is UNSAFE
+	quorumSizeOverride uint64
+
+	// the retries of failed broadcasts, which are stopped by Stop
+	broadcastRetries    uint64
+	broadcastRetryDelay int64 // time.Duration
+	broadcastQuit       chan struct{}
+	broadcastWg         sync.WaitGroup
 }
 
 func (c *core) finalizeMessage(msg *message) ([]byte, error) {
@@ -160,11 +181,40 @@ func (c *core) broadcast(msg *message) {
 
 	// Broadcast payload
 	if err = c.backend.Broadcast(msg.Hash, c.valSet, payload); err != nil {
-		logger.Error("Failed to broadcast message", "msg", msg, "err", err)
-		return
+		retries := atomic.LoadUint64(&c.broadcastRetries)
+		if retries == 0 {
+			logger.Error("Failed to broadcast message", "msg", msg, "err", err)
+			return
+		}
+		// a transient network error should not cost a round change, but the
+		// core goroutine should not wait for the retries either
+		logger.Warn("Failed to broadcast message, retrying", "msg", msg, "retries", retries, "err", err)
+		c.broadcastWg.Add(1)
+		go c.retryBroadcast(logger, msg, c.valSet, payload, retries, c.broadcastQuit)
 	}
 }
 
+// retryBroadcast broadcasts the payload again up to retries times, waiting for
+// the retry delay before each attempt. It gives up if quit is closed.
+func (c *core) retryBroadcast(logger log.Logger, msg *message, valSet istanbul.ValidatorSet, payload []byte, retries uint64, quit <-chan struct{}) {
+	defer c.broadcastWg.Done()
+
+	delay := time.Duration(atomic.LoadInt64(&c.broadcastRetryDelay))
+	var err error
+	for attempt := uint64(1); attempt <= retries; attempt++ {
+		select {
+		case <-time.After(delay):
+		case <-quit:
+			return
+		}
+		if err = c.backend.Broadcast(msg.Hash, valSet, payload); err == nil {
+			logger.Debug("Broadcast message after retries", "msg", msg, "attempt", attempt)
+			return
+		}
+	}
+	logger.Error("Failed to broadcast message", "msg", msg, "retries", retries, "err", err)
+}
+
 func (c *core) currentView() *istanbul.View {
 	return &istanbul.View{
 		Sequence: new(big.Int).Set(c.current.Sequence()),
@@ -172,6 +222,22 @@ func (c *core) currentView() *istanbul.View {
 	}
 }
 
+// PendingMessages implements core.Engine.PendingMessages
+func (c *core) PendingMessages() (int, int) {
+	c.backlogsMu.Lock()
+	backlogs := 0
+	for _, backlog := range c.backlogs {
+		if backlog != nil {
+			backlogs += backlog.Size()
+		}
+	}
+	c.backlogsMu.Unlock()
+
+	c.pendingRequestsMu.Lock()
+	defer c.pendingRequestsMu.Unlock()
+	return backlogs, c.pendingRequests.Size()
+}
+
 func (c *core) isProposer() bool {
 	v := c.valSet
 	if v == nil {
@@ -250,6 +316,10 @@ func (c *core) startNewRound(round *big.Int) {
 	}
 	//}
 
+	if roundChange && round.Cmp(c.current.Round()) != 0 {
+		c.recordRoundChange(c.current.Round(), round, "received 2f+1 round change messages")
+	}
+
 	var newView *istanbul.View
 	if roundChange {
 		newView = &istanbul.View{
@@ -283,6 +353,9 @@ func (c *core) startNewRound(round *big.Int) {
 	c.valSet.CalcProposer(lastProposer, newView.Round.Uint64())
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
+	if roundChange {
+		c.postPhaseEvent(istanbul.RoundChange)
+	}
 	if roundChange && c.isProposer() && c.current != nil {
 		// If it is locked, propose the old proposal
 		// If we have pending request, propose pending request
@@ -301,17 +374,19 @@ func (c *core) startNewRound(round *big.Int) {
 	logger.Trace("New round", "new_round", newView.Round, "new_seq", newView.Sequence, "size", c.valSet.Size(), "valSet", c.valSet.List())
 }
 
-func (c *core) catchUpRound(view *istanbul.View) {
+func (c *core) catchUpRound(view *istanbul.View, reason string) {
 	logger := c.logger.NewWith("old_round", c.current.Round(), "old_seq", c.current.Sequence(), "old_proposer", c.valSet.GetProposer())
 
 	if view.Round.Cmp(c.current.Round()) > 0 {
 		c.roundMeter.Mark(new(big.Int).Sub(view.Round, c.current.Round()).Int64())
 	}
 	c.waitingForRoundChange = true
+	c.recordRoundChange(c.current.Round(), view.Round, reason)
 
 	// Need to keep block locked for round catching up
 	c.updateRoundState(view, c.valSet, true)
 	c.roundChangeSet.Clear(view.Round)
+	c.postPhaseEvent(istanbul.RoundChange)
 
 	c.newRoundChangeTimer()
 	logger.Warn("[RC] Catch up round", "new_round", view.Round, "new_seq", view.Sequence, "new_proposer", c.valSet.GetProposer())
@@ -340,6 +415,14 @@ func (c *core) updateRoundState(view *istanbul.View, validatorSet istanbul.Valid
 func (c *core) setState(state State) {
 	if c.state != state {
 		c.state = state
+		switch state {
+		case StatePreprepared:
+			c.postPhaseEvent(istanbul.EnterPreprepare)
+		case StatePrepared:
+			c.postPhaseEvent(istanbul.EnterPrepare)
+		case StateCommitted:
+			c.postPhaseEvent(istanbul.EnterCommit)
+		}
 	}
 	if state == StateAcceptRequest {
 		c.processPendingRequests()
@@ -452,3 +535,41 @@ func RequiredMessageCount(valSet istanbul.ValidatorSet, num *big.Int) int {
 		return 2*valSet.F() + 1
 	}
 }
+
+// SetQuorumSize implements core.Engine.SetQuorumSize
+func (c *core) SetQuorumSize(size uint64) {
+	if size != 0 {
+		c.logger.Warn("The quorum of the consensus is overridden. It is UNSAFE except for test networks", "quorum", size)
+	}
+	atomic.StoreUint64(&c.quorumSizeOverride, size)
+}
+
+// SetBroadcastRetry implements core.Engine.SetBroadcastRetry
+func (c *core) SetBroadcastRetry(retries uint64, delay time.Duration) {
+	atomic.StoreUint64(&c.broadcastRetries, retries)
+	atomic.StoreInt64(&c.broadcastRetryDelay, int64(delay))
+}
+
+// quorumSize returns the number of PREPARE/COMMIT messages required to prepare
+// and commit the proposal of the given sequence.
+func (c *core) quorumSize(num *big.Int) (int, error) {
+	return QuorumSize(c.valSet, num, atomic.LoadUint64(&c.quorumSizeOverride))
+}
+
+// QuorumSize returns the number of PREPARE/COMMIT messages required to prepare
+// and commit a proposal. If quorumSize is 0, it is RequiredMessageCount.
+// Otherwise, quorumSize overrides it, which is UNSAFE and only for test
+// networks, since a quorum smaller than 2f+1 breaks the finality of BFT.
+func QuorumSize(valSet istanbul.ValidatorSet, num *big.Int, quorumSize uint64) (int, error) {
+	if quorumSize == 0 {
+		return RequiredMessageCount(valSet, num), nil
+	}
+	committeeSize := valSet.Size()
+	if valSet.IsSubSet() && valSet.SubGroupSize() < committeeSize {
+		committeeSize = valSet.SubGroupSize()
+	}
+	if quorumSize > committeeSize {
+		return 0, fmt.Errorf("%w: quorum %d, committee %d", ErrInvalidQuorumSize, quorumSize, committeeSize)
+	}
+	return int(quorumSize), nil
+}
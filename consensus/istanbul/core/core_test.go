@@ -0,0 +1,91 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	mock_istanbul "github.com/klaytn/klaytn/consensus/istanbul/mocks"
+)
+
+func TestIsPreprepare(t *testing.T) {
+	proposer, other := common.HexToAddress("0x1"), common.HexToAddress("0x2")
+	payload := func(code uint64, addr common.Address) []byte {
+		p, err := (&message{Code: code, Msg: []byte("msg"), Address: addr}).Payload()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	if !IsPreprepare(payload(msgPreprepare, proposer), proposer) {
+		t.Error("the preprepare of the proposer is not detected")
+	}
+	if IsPreprepare(payload(msgPreprepare, other), proposer) {
+		t.Error("the preprepare of another node is detected")
+	}
+	if IsPreprepare(payload(msgPrepare, proposer), proposer) {
+		t.Error("a prepare is detected as a preprepare")
+	}
+	if IsPreprepare([]byte("invalid"), proposer) {
+		t.Error("an invalid payload is detected as a preprepare")
+	}
+}
+
+func TestCore_broadcastRetry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockBackend := mock_istanbul.NewMockBackend(mockCtrl)
+	mockBackend.EXPECT().Address().Return(common.Address{}).AnyTimes()
+	mockBackend.EXPECT().Sign(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// the broadcast fails once, and the same payload is sent on the retry
+	msg := &message{Code: msgPrepare, Msg: []byte("prepare"), Hash: common.HexToHash("0x1")}
+	sent := make(chan []byte, 1)
+	var failed []byte
+	gomock.InOrder(
+		mockBackend.EXPECT().Broadcast(msg.Hash, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ common.Hash, _ istanbul.ValidatorSet, payload []byte) error {
+				failed = payload
+				return errors.New("transient p2p error")
+			}),
+		mockBackend.EXPECT().Broadcast(msg.Hash, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ common.Hash, _ istanbul.ValidatorSet, payload []byte) error {
+				sent <- payload
+				return nil
+			}),
+	)
+
+	istCore := New(mockBackend).(*core)
+	istCore.SetBroadcastRetry(2, time.Millisecond)
+	istCore.broadcast(msg)
+
+	select {
+	case payload := <-sent:
+		if string(payload) != string(failed) {
+			t.Errorf("payload mismatch: %x != %x", payload, failed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the message is not broadcast after the failure")
+	}
+	istCore.broadcastWg.Wait()
+}
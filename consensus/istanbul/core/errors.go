@@ -39,4 +39,7 @@ var (
 	errInvalidMessage = errors.New("invalid message")
 	// errFailedDecodeMessageSet is returned when the message set is malformed.
 	errFailedDecodeMessageSet = errors.New("failed to decode message set")
+	// ErrInvalidQuorumSize is returned when the quorum size override is larger
+	// than the committee.
+	ErrInvalidQuorumSize = errors.New("quorum size override is larger than the committee")
 )
@@ -27,6 +27,8 @@ import (
 
 // Start implements core.Engine.Start
 func (c *core) Start() error {
+	c.broadcastQuit = make(chan struct{})
+
 	// Start a new round from last sequence + 1
 	c.startNewRound(common.Big0)
 
@@ -35,6 +37,9 @@ func (c *core) Start() error {
 	c.subscribeEvents()
 	go c.handleEvents()
 
+	c.phaseEventQuit, c.phaseEventDone = make(chan struct{}), make(chan struct{})
+	go c.phaseEventLoop(c.phaseEventQuit, c.phaseEventDone)
+
 	return nil
 }
 
@@ -45,6 +50,19 @@ func (c *core) Stop() error {
 
 	// Make sure the handler goroutine exits
 	c.handlerWg.Wait()
+
+	// the messages of the stopped core are not retried anymore
+	if c.broadcastQuit != nil {
+		close(c.broadcastQuit)
+		c.broadcastQuit = nil
+	}
+	c.broadcastWg.Wait()
+
+	if c.phaseEventQuit != nil {
+		close(c.phaseEventQuit)
+		<-c.phaseEventDone
+		c.phaseEventQuit, c.phaseEventDone = nil, nil
+	}
 	return nil
 }
 
@@ -240,7 +258,7 @@ func (c *core) handleTimeoutMsg(nextView *istanbul.View) {
 		maxRound := c.roundChangeSet.MaxRound(c.valSet.F() + 1)
 		if maxRound != nil && maxRound.Cmp(c.current.Round()) > 0 {
 			logger.Warn("[RC] Send round change because of timeout event")
-			c.sendRoundChange(maxRound)
+			c.sendRoundChange(maxRound, "timeout with f+1 round change messages")
 			return
 		}
 	}
@@ -249,6 +267,6 @@ func (c *core) handleTimeoutMsg(nextView *istanbul.View) {
 		c.logger.Trace("round change timeout, catch up latest sequence", "number", lastProposal.Number().Uint64())
 		c.startNewRound(common.Big0)
 	} else {
-		c.sendRoundChange(nextView.Round)
+		c.sendRoundChange(nextView.Round, "timeout")
 	}
 }
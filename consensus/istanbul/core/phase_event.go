@@ -0,0 +1,116 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"sync"
+
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/event"
+	"github.com/rcrowley/go-metrics"
+)
+
+// phaseEventQueueSize is the number of phase events which can be pending for delivery.
+// Events are dropped if the queue is full, so a slow subscriber never delays consensus.
+const phaseEventQueueSize = 256
+
+var phaseEventDropMeter = metrics.NewRegisteredMeter("consensus/istanbul/core/phaseEvent/drop", nil)
+
+// phaseSubscribers holds the subscriptions of istanbul.PhaseEvent. Unlike
+// event.Feed, an event is sent to each channel without blocking, so a slow or
+// abandoned subscriber only misses its own events.
+type phaseSubscribers struct {
+	mu   sync.Mutex
+	subs map[*phaseSub]struct{}
+}
+
+type phaseSub struct {
+	ch chan<- istanbul.PhaseEvent
+}
+
+func (s *phaseSubscribers) add(sub *phaseSub) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = make(map[*phaseSub]struct{})
+	}
+	s.subs[sub] = struct{}{}
+}
+
+func (s *phaseSubscribers) remove(sub *phaseSub) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs, sub)
+}
+
+// send delivers the event to the subscribers ready to receive it, and drops it
+// for the others.
+func (s *phaseSubscribers) send(ev istanbul.PhaseEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			phaseEventDropMeter.Mark(1)
+		}
+	}
+}
+
+// SubscribePhaseEvent registers a subscription of istanbul.PhaseEvent.
+// Events are delivered in order, but they are dropped while the subscriber is not ready to receive them.
+func (c *core) SubscribePhaseEvent(ch chan<- istanbul.PhaseEvent) event.Subscription {
+	sub := &phaseSub{ch: ch}
+	c.phaseSubs.add(sub)
+	return event.NewSubscription(func(unsubscribed <-chan struct{}) error {
+		<-unsubscribed
+		c.phaseSubs.remove(sub)
+		return nil
+	})
+}
+
+// postPhaseEvent queues a phase event of the current round without blocking.
+func (c *core) postPhaseEvent(phase istanbul.ConsensusPhase) {
+	if c.current == nil {
+		return
+	}
+	ev := istanbul.PhaseEvent{
+		Phase:    phase,
+		View:     *c.currentView(),
+		Prepares: c.current.Prepares.Size(),
+		Commits:  c.current.Commits.Size(),
+	}
+	select {
+	case c.phaseEventCh <- ev:
+	default:
+		phaseEventDropMeter.Mark(1)
+	}
+}
+
+// phaseEventLoop delivers queued phase events to the subscribers until quit is
+// closed, and closes done when it exits.
+func (c *core) phaseEventLoop(quit <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for {
+		select {
+		case ev := <-c.phaseEventCh:
+			c.phaseSubs.send(ev)
+		case <-quit:
+			return
+		}
+	}
+}
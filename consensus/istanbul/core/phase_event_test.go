@@ -0,0 +1,150 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCore_phaseEvents(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, _ := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istCore := New(mockBackend).(*core)
+	if err := istCore.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer istCore.Stop()
+
+	ch := make(chan istanbul.PhaseEvent, 10)
+	sub := istCore.SubscribePhaseEvent(ch)
+	defer sub.Unsubscribe()
+
+	// drive a round through the phases and then change the round
+	istCore.setState(StatePreprepared)
+	istCore.setState(StatePrepared)
+	istCore.setState(StatePrepared) // staying in the same state is not a transition
+	istCore.setState(StateCommitted)
+	istCore.catchUpRound(&istanbul.View{
+		Sequence: new(big.Int).Set(istCore.current.Sequence()),
+		Round:    common.Big1,
+	}, "test")
+
+	expected := []istanbul.ConsensusPhase{istanbul.EnterPreprepare, istanbul.EnterPrepare, istanbul.EnterCommit, istanbul.RoundChange}
+	for _, phase := range expected {
+		select {
+		case ev := <-ch:
+			assert.Equal(t, phase, ev.Phase)
+			assert.Equal(t, uint64(1), ev.View.Sequence.Uint64())
+			if phase == istanbul.RoundChange {
+				assert.Equal(t, uint64(1), ev.View.Round.Uint64())
+			} else {
+				assert.Equal(t, uint64(0), ev.View.Round.Uint64())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("phase event %v is not delivered", phase)
+		}
+	}
+}
+
+func TestCore_phaseEvents_slowSubscriber(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, _ := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istCore := New(mockBackend).(*core)
+	if err := istCore.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer istCore.Stop()
+
+	// nobody receives from the channel
+	sub := istCore.SubscribePhaseEvent(make(chan istanbul.PhaseEvent))
+	defer sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*phaseEventQueueSize; i++ {
+			istCore.postPhaseEvent(istanbul.EnterPrepare)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("posting phase events is blocked by a slow subscriber")
+	}
+}
+
+func TestCore_phaseEvents_stuckSubscriber(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, _ := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istCore := New(mockBackend).(*core)
+	if err := istCore.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a subscriber which never reads doesn't block the others
+	stuck := istCore.SubscribePhaseEvent(make(chan istanbul.PhaseEvent))
+	defer stuck.Unsubscribe()
+	ch := make(chan istanbul.PhaseEvent, 10)
+	sub := istCore.SubscribePhaseEvent(ch)
+	defer sub.Unsubscribe()
+
+	for _, phase := range []istanbul.ConsensusPhase{istanbul.EnterPrepare, istanbul.EnterCommit} {
+		istCore.postPhaseEvent(phase)
+		select {
+		case ev := <-ch:
+			assert.Equal(t, phase, ev.Phase)
+		case <-time.After(time.Second):
+			t.Fatalf("phase event %v is not delivered", phase)
+		}
+	}
+
+	// the delivery loop exits on Stop
+	stopped := make(chan struct{})
+	go func() {
+		istCore.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Stop is blocked by a subscriber which never reads")
+	}
+}
@@ -76,6 +76,12 @@ func (c *core) handlePrepare(msg *message, src istanbul.Validator) error {
 
 	c.acceptPrepare(msg, src)
 
+	quorum, err := c.quorumSize(prepare.View.Sequence)
+	if err != nil {
+		logger.Error("Failed to get the quorum of the messages", "err", err)
+		return err
+	}
+
 	// Change to Prepared state if we've received enough PREPARE/COMMIT messages or it is locked
 	// and we are in earlier state before Prepared state.
 	// Both of PREPARE and COMMIT messages are counted since the nodes which is hashlocked in
@@ -85,7 +91,7 @@ func (c *core) handlePrepare(msg *message, src istanbul.Validator) error {
 			logger.Warn("received prepare of the hash locked proposal and change state to prepared", "msgType", msgPrepare)
 			c.setState(StatePrepared)
 			c.sendCommit()
-		} else if c.current.GetPrepareOrCommitSize() >= RequiredMessageCount(c.valSet, prepare.View.Sequence) {
+		} else if c.current.GetPrepareOrCommitSize() >= quorum {
 			logger.Info("received a quorum of the messages and change state to prepared", "msgType", msgPrepare, "prepareMsgNum", c.current.Prepares.Size(), "commitMsgNum", c.current.Commits.Size(), "valSet", c.valSet.Size())
 			c.current.LockHash()
 			c.setState(StatePrepared)
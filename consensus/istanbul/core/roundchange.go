@@ -40,11 +40,12 @@ func (c *core) sendNextRoundChange(loc string) {
 		return
 	}
 	logger.Warn("[RC] sendNextRoundChange happened", "where", loc)
-	c.sendRoundChange(new(big.Int).Add(c.currentView().Round, common.Big1))
+	c.sendRoundChange(new(big.Int).Add(c.currentView().Round, common.Big1), loc)
 }
 
-// sendRoundChange sends the ROUND CHANGE message with the given round
-func (c *core) sendRoundChange(round *big.Int) {
+// sendRoundChange sends the ROUND CHANGE message with the given round.
+// The reason is recorded in the round change history.
+func (c *core) sendRoundChange(round *big.Int, reason string) {
 	logger := c.logger.NewWith("state", c.state)
 
 	cv := c.currentView()
@@ -63,7 +64,7 @@ func (c *core) sendRoundChange(round *big.Int) {
 		// The round number we'd like to transfer to.
 		Round:    new(big.Int).Set(round),
 		Sequence: new(big.Int).Set(cv.Sequence),
-	})
+	}, reason)
 
 	lastProposal, _ := c.backend.LastProposal()
 
@@ -151,7 +152,7 @@ func (c *core) handleRoundChange(msg *message, src istanbul.Validator) error {
 		if cv.Round.Cmp(roundView.Round) < 0 {
 			logger.Warn("[RC] Send round change because we have f+1 round change messages",
 				"currentRound", cv.Round.String(), "newRound", roundView.Round.String())
-			c.sendRoundChange(roundView.Round)
+			c.sendRoundChange(roundView.Round, "received f+1 round change messages")
 		}
 		return nil
 	} else if cv.Round.Cmp(roundView.Round) < 0 {
@@ -0,0 +1,86 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/consensus/istanbul"
+)
+
+// defaultRoundChangeHistorySize is the number of round changes kept if it is not configured.
+const defaultRoundChangeHistorySize = 128
+
+// roundChangeHistory is a ring buffer of the recent round changes. It can be
+// read concurrently with the consensus.
+type roundChangeHistory struct {
+	mu     sync.Mutex
+	events []istanbul.RoundChangeEvent
+	next   int  // the index the next event is stored at
+	full   bool // true if the events have wrapped around
+}
+
+func newRoundChangeHistory(size int) *roundChangeHistory {
+	return &roundChangeHistory{events: make([]istanbul.RoundChangeEvent, size)}
+}
+
+// add stores an event, overwriting the oldest one if the history is full.
+func (h *roundChangeHistory) add(ev istanbul.RoundChangeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.events) == 0 {
+		return
+	}
+	h.events[h.next] = ev
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the stored events from the oldest to the latest.
+func (h *roundChangeHistory) list() []istanbul.RoundChangeEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]istanbul.RoundChangeEvent{}, h.events[:h.next]...)
+	}
+	return append(append([]istanbul.RoundChangeEvent{}, h.events[h.next:]...), h.events[:h.next]...)
+}
+
+// RoundChangeHistory implements core.Engine.RoundChangeHistory
+func (c *core) RoundChangeHistory() []istanbul.RoundChangeEvent {
+	return c.roundChanges.Load().(*roundChangeHistory).list()
+}
+
+// SetRoundChangeHistorySize implements core.Engine.SetRoundChangeHistorySize
+func (c *core) SetRoundChangeHistorySize(size int) {
+	c.roundChanges.Store(newRoundChangeHistory(size))
+}
+
+// recordRoundChange adds a round change of the current sequence to the history.
+func (c *core) recordRoundChange(from, to *big.Int, reason string) {
+	c.roundChanges.Load().(*roundChangeHistory).add(istanbul.RoundChangeEvent{
+		Number:    c.current.Sequence().Uint64(),
+		FromRound: from.Uint64(),
+		ToRound:   to.Uint64(),
+		Reason:    reason,
+		Time:      time.Now(),
+	})
+}
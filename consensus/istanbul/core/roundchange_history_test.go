@@ -0,0 +1,89 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/fork"
+	"github.com/klaytn/klaytn/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundChangeHistory(t *testing.T) {
+	h := newRoundChangeHistory(3)
+	assert.Empty(t, h.list())
+
+	for i := uint64(0); i < 5; i++ {
+		h.add(istanbul.RoundChangeEvent{Number: i, FromRound: i, ToRound: i + 1})
+
+		// the latest events are kept from the oldest one
+		events := h.list()
+		if i < 3 {
+			assert.Len(t, events, int(i+1))
+		} else {
+			assert.Len(t, events, 3)
+		}
+		for j, ev := range events {
+			assert.Equal(t, i+1-uint64(len(events)-j), ev.Number)
+		}
+	}
+
+	// the disabled history keeps nothing
+	h = newRoundChangeHistory(0)
+	h.add(istanbul.RoundChangeEvent{Number: 1})
+	assert.Empty(t, h.list())
+}
+
+func TestCore_RoundChangeHistory(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	validatorAddrs, _ := genValidators(6)
+	mockBackend, mockCtrl := newMockBackend(t, validatorAddrs)
+	defer mockCtrl.Finish()
+
+	istCore := New(mockBackend).(*core)
+	if err := istCore.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer istCore.Stop()
+	istCore.SetRoundChangeHistorySize(2)
+
+	seq := new(big.Int).Set(istCore.current.Sequence())
+	istCore.catchUpRound(&istanbul.View{Sequence: seq, Round: common.Big1}, "timeout")
+	istCore.catchUpRound(&istanbul.View{Sequence: seq, Round: common.Big2}, "received f+1 round change messages")
+	istCore.catchUpRound(&istanbul.View{Sequence: seq, Round: common.Big3}, "commit failure")
+
+	// the oldest round change is dropped
+	events := istCore.RoundChangeHistory()
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, seq.Uint64(), events[0].Number)
+		assert.Equal(t, uint64(1), events[0].FromRound)
+		assert.Equal(t, uint64(2), events[0].ToRound)
+		assert.Equal(t, "received f+1 round change messages", events[0].Reason)
+
+		assert.Equal(t, seq.Uint64(), events[1].Number)
+		assert.Equal(t, uint64(2), events[1].FromRound)
+		assert.Equal(t, uint64(3), events[1].ToRound)
+		assert.Equal(t, "commit failure", events[1].Reason)
+		assert.False(t, events[1].Time.Before(events[0].Time))
+	}
+}
@@ -23,15 +23,40 @@ package core
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/consensus/istanbul"
+	"github.com/klaytn/klaytn/event"
 	"github.com/klaytn/klaytn/rlp"
 )
 
 type Engine interface {
 	Start() error
 	Stop() error
+
+	// SubscribePhaseEvent subscribes the consensus phase transitions of the engine.
+	SubscribePhaseEvent(ch chan<- istanbul.PhaseEvent) event.Subscription
+
+	// PendingMessages returns the number of the backlogged future messages and
+	// the pending requests. It can be called concurrently with the consensus.
+	PendingMessages() (backlogs int, requests int)
+
+	// RoundChangeHistory returns the recent round changes from the oldest one.
+	RoundChangeHistory() []istanbul.RoundChangeEvent
+
+	// SetRoundChangeHistorySize replaces the round change history with an empty
+	// one keeping the given number of round changes. 0 disables the history.
+	SetRoundChangeHistorySize(size int)
+
+	// SetQuorumSize overrides the number of PREPARE/COMMIT messages required to
+	// commit a proposal. It is UNSAFE and only for test networks. 0 restores the
+	// standard quorum.
+	SetQuorumSize(size uint64)
+
+	// SetBroadcastRetry sets the number of retries of a failed broadcast and
+	// the delay between them. The retries are done off the core goroutine.
+	SetBroadcastRetry(retries uint64, delay time.Duration)
 }
 
 type State uint64
@@ -140,6 +165,16 @@ func (m *message) FromPayload(b []byte, validateFn func([]byte, []byte) (common.
 	return err
 }
 
+// IsPreprepare returns true if the payload is a preprepare message of the given
+// address. The backend uses it to send the proposals of the local node first.
+func IsPreprepare(payload []byte, addr common.Address) bool {
+	var msg message
+	if err := rlp.DecodeBytes(payload, &msg); err != nil {
+		return false
+	}
+	return msg.Code == msgPreprepare && msg.Address == addr
+}
+
 func (m *message) Payload() ([]byte, error) {
 	return rlp.EncodeToBytes(m)
 }
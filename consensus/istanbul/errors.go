@@ -26,4 +26,7 @@ var (
 	ErrStoppedEngine = errors.New("stopped engine")
 	// ErrStartedEngine is returned if the engine is already started
 	ErrStartedEngine = errors.New("started engine")
+	// ErrPeerQuarantined is returned if a peer is quarantined for sending too
+	// many malformed messages, which should be disconnected.
+	ErrPeerQuarantined = errors.New("peer is quarantined")
 )
@@ -20,7 +20,11 @@
 
 package istanbul
 
-import "github.com/klaytn/klaytn/common"
+import (
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+)
 
 // RequestEvent is posted to propose a proposal
 type RequestEvent struct {
@@ -39,3 +43,55 @@ type CommitEvent struct {
 
 // FinalCommittedEvent is posted when a proposal is committed
 type FinalCommittedEvent struct{}
+
+// ConsensusPhase is a phase of a consensus round reported by PhaseEvent.
+type ConsensusPhase uint64
+
+const (
+	EnterPreprepare ConsensusPhase = iota // a valid preprepare is accepted
+	EnterPrepare                          // a quorum of prepares is collected
+	EnterCommit                           // a quorum of commits is collected and the proposal is committed
+	RoundChange                           // a new round of the same sequence is started
+)
+
+func (p ConsensusPhase) String() string {
+	switch p {
+	case EnterPreprepare:
+		return "EnterPreprepare"
+	case EnterPrepare:
+		return "EnterPrepare"
+	case EnterCommit:
+		return "EnterCommit"
+	case RoundChange:
+		return "RoundChange"
+	}
+	return "Unknown"
+}
+
+// PhaseEvent is sent to the subscribers of the core when the consensus phase changes.
+// Prepares and Commits are the number of messages collected in the view when the event occurred.
+type PhaseEvent struct {
+	Phase    ConsensusPhase
+	View     View
+	Prepares int
+	Commits  int
+}
+
+// ValidatorSetChangedEvent is sent to the subscribers of the backend when the
+// validator set changes between blocks. The changed validator set is effective
+// from the block of Number.
+type ValidatorSetChangedEvent struct {
+	Number  uint64
+	Added   []common.Address
+	Removed []common.Address
+}
+
+// RoundChangeEvent is a round change of a sequence, which is kept in the round
+// change history of the core for diagnosing the heights struggled to reach consensus.
+type RoundChangeEvent struct {
+	Number    uint64    `json:"number"`    // the sequence, which is the number of the block being agreed
+	FromRound uint64    `json:"fromRound"` // the round before the change
+	ToRound   uint64    `json:"toRound"`   // the round after the change
+	Reason    string    `json:"reason"`
+	Time      time.Time `json:"time"`
+}
@@ -33,6 +33,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/consensus"
 	"github.com/klaytn/klaytn/consensus/istanbul"
@@ -41,6 +42,17 @@ import (
 	"github.com/klaytn/klaytn/reward"
 )
 
+// inmemoryCommittees is the number of committees cached by a weightedCouncil.
+const inmemoryCommittees = 16
+
+// committeeKey identifies a committee composed by a council.
+type committeeKey struct {
+	prevHash common.Hash
+	proposer common.Address
+	sequence uint64
+	round    uint64
+}
+
 type weightedValidator struct {
 	address common.Address
 
@@ -113,6 +125,13 @@ type weightedCouncil struct {
 	stakingInfo *reward.StakingInfo
 
 	blockNum uint64 // block number when council is determined
+
+	// committees caches the committees composed by SubListWithProposer, which
+	// are copied in and out so that the callers cannot modify the cached ones.
+	// It is kept by the council rather than the backend, since the consensus
+	// core checks the committees through the council. It must be purged
+	// whenever the council changes.
+	committees *lru.Cache
 }
 
 func RecoverWeightedCouncilProposer(valSet istanbul.ValidatorSet, proposerAddrs []common.Address) {
@@ -135,6 +154,7 @@ func RecoverWeightedCouncilProposer(valSet istanbul.ValidatorSet, proposerAddrs
 		logger.Trace("RecoverWeightedCouncilProposer() proposers", "i", i, "address", val.Address().String())
 	}
 	weightedCouncil.proposers = proposers
+	weightedCouncil.purgeCommittees()
 }
 
 func NewWeightedCouncil(addrs []common.Address, demotedAddrs []common.Address, rewards []common.Address, votingPowers []uint64, weights []uint64, policy istanbul.ProposerPolicy, committeeSize uint64, blockNum uint64, proposersBlockNum uint64, chain consensus.ChainReader) *weightedCouncil {
@@ -145,6 +165,7 @@ func NewWeightedCouncil(addrs []common.Address, demotedAddrs []common.Address, r
 
 	valSet := &weightedCouncil{}
 	valSet.policy = policy
+	valSet.committees, _ = lru.New(inmemoryCommittees)
 
 	// prepare rewards if necessary
 	if rewards == nil {
@@ -306,6 +327,7 @@ func (valSet *weightedCouncil) SetSubGroupSize(size uint64) {
 		return
 	}
 	valSet.subSize = size
+	valSet.purgeCommittees()
 }
 
 func (valSet *weightedCouncil) List() []istanbul.Validator {
@@ -346,6 +368,13 @@ func (valSet *weightedCouncil) SubListWithProposer(prevHash common.Hash, propose
 		return validators
 	}
 
+	key := committeeKey{prevHash, proposerAddr, view.Sequence.Uint64(), view.Round.Uint64()}
+	if valSet.committees != nil {
+		if committee, ok := valSet.committees.Get(key); ok {
+			return append([]istanbul.Validator{}, committee.([]istanbul.Validator)...)
+		}
+	}
+
 	// find the proposer
 	proposerIdx, proposer := valSet.getByAddress(proposerAddr)
 	if proposerIdx < 0 {
@@ -401,6 +430,9 @@ func (valSet *weightedCouncil) SubListWithProposer(prevHash common.Hash, propose
 	logger.Trace("composed committee", "valSet.Number", valSet.blockNum, "prevHash", prevHash.Hex(),
 		"proposerAddr", proposerAddr, "committee", committee, "committee size", len(committee), "valSet.subSize", committeeSize)
 
+	if valSet.committees != nil {
+		valSet.committees.Add(key, append([]istanbul.Validator{}, committee...))
+	}
 	return committee
 }
 
@@ -526,6 +558,7 @@ func (valSet *weightedCouncil) AddValidator(address common.Address) bool {
 
 	// sort validator
 	sort.Sort(valSet.validators)
+	valSet.purgeCommittees()
 	return true
 }
 
@@ -550,6 +583,7 @@ func (valSet *weightedCouncil) RemoveValidator(address common.Address) bool {
 		if v.Address() == address {
 			valSet.validators = append(valSet.validators[:i], valSet.validators[i+1:]...)
 			valSet.removeValidatorFromProposers(address)
+			valSet.purgeCommittees()
 			return true
 		}
 	}
@@ -568,6 +602,7 @@ func (valSet *weightedCouncil) ReplaceValidators(vals []istanbul.Validator) bool
 
 	valSet.validators = istanbul.Validators(make([]istanbul.Validator, len(vals)))
 	copy(valSet.validators, istanbul.Validators(vals))
+	valSet.purgeCommittees()
 	return true
 }
 
@@ -588,6 +623,7 @@ func (valSet *weightedCouncil) Copy() istanbul.ValidatorSet {
 		proposersBlockNum: valSet.proposersBlockNum,
 		blockNum:          valSet.blockNum,
 	}
+	newWeightedCouncil.committees, _ = lru.New(inmemoryCommittees)
 	newWeightedCouncil.validators = make([]istanbul.Validator, len(valSet.validators))
 	copy(newWeightedCouncil.validators, valSet.validators)
 
@@ -701,6 +737,7 @@ func (valSet *weightedCouncil) setValidators(validators []*weightedValidator, de
 
 	valSet.validators = newValidators
 	valSet.demotedValidators = newDemoted
+	valSet.purgeCommittees()
 }
 
 // filterValidators divided the given weightedValidators into two group filtered by the minimum amount of staking.
@@ -880,10 +917,22 @@ func (valSet *weightedCouncil) refreshProposers(seed int64, blockNum uint64) {
 
 	valSet.proposers = proposers
 	valSet.proposersBlockNum = blockNum
+	valSet.purgeCommittees()
 }
 
 func (valSet *weightedCouncil) SetBlockNum(blockNum uint64) {
-	valSet.blockNum = blockNum
+	if valSet.blockNum != blockNum {
+		valSet.blockNum = blockNum
+		valSet.purgeCommittees()
+	}
+}
+
+// purgeCommittees removes the cached committees. It should be called whenever
+// validators, proposers, blockNum or subSize of the council changes.
+func (valSet *weightedCouncil) purgeCommittees() {
+	if valSet.committees != nil {
+		valSet.committees.Purge()
+	}
 }
 
 func (valSet *weightedCouncil) Proposers() []istanbul.Validator {
@@ -560,3 +560,79 @@ func TestWeightedCouncil_Copy(t *testing.T) {
 		t.Errorf("staking. original : %v, Copied : %v", valSet.stakingInfo, copiedValSet.stakingInfo)
 	}
 }
+
+func TestWeightedCouncil_CommitteeCache(t *testing.T) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	var (
+		prevHash = crypto.Keccak256Hash([]byte("This is a test"))
+		valSet   = makeTestWeightedCouncil(testNonZeroWeights)
+		uncached = makeTestWeightedCouncil(testNonZeroWeights)
+	)
+	uncached.committees = nil
+
+	valSet.SetBlockNum(1)
+	uncached.SetBlockNum(1)
+	valSet.SetSubGroupSize(5)
+	uncached.SetSubGroupSize(5)
+
+	// cached committees are the same as the composed ones
+	for round := uint64(0); round < 5; round++ {
+		view := &istanbul.View{Sequence: big.NewInt(2), Round: new(big.Int).SetUint64(round)}
+		proposer := valSet.GetProposer().Address()
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, uncached.SubListWithProposer(prevHash, proposer, view), valSet.SubListWithProposer(prevHash, proposer, view))
+		}
+		for _, val := range valSet.List() {
+			assert.Equal(t, uncached.CheckInSubList(prevHash, view, val.Address()), valSet.CheckInSubList(prevHash, view, val.Address()))
+		}
+	}
+	assert.NotZero(t, valSet.committees.Len())
+
+	// the returned committees don't share the cached one
+	view := &istanbul.View{Sequence: big.NewInt(2), Round: common.Big0}
+	proposer := valSet.GetProposer().Address()
+	committee := valSet.SubListWithProposer(prevHash, proposer, view)
+	expected := append([]istanbul.Validator{}, committee...)
+	committee[0] = nil
+	assert.Equal(t, expected, valSet.SubListWithProposer(prevHash, proposer, view))
+
+	// changes of the council purge the cache
+	valSet.SetSubGroupSize(4)
+	assert.Zero(t, valSet.committees.Len())
+
+	valSet.SubList(prevHash, view)
+	valSet.SetBlockNum(2)
+	assert.Zero(t, valSet.committees.Len())
+
+	committee = valSet.SubList(prevHash, view)
+	assert.True(t, valSet.RemoveValidator(committee[len(committee)-1].Address()))
+	assert.Zero(t, valSet.committees.Len())
+	assert.NotContains(t, valSet.SubList(prevHash, view), committee[len(committee)-1])
+}
+
+func benchmarkWeightedCouncilCheckInSubList(b *testing.B, cached bool) {
+	fork.SetHardForkBlockNumberConfig(&params.ChainConfig{})
+	defer fork.ClearHardForkBlockNumberConfig()
+
+	valSet := makeTestWeightedCouncil(testNonZeroWeights)
+	if !cached {
+		valSet.committees = nil
+	}
+	valSet.SetSubGroupSize(5)
+
+	prevHash := crypto.Keccak256Hash([]byte("This is a test"))
+	view := &istanbul.View{Sequence: big.NewInt(2), Round: common.Big0}
+	validators := valSet.List()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		valSet.CheckInSubList(prevHash, view, validators[i%len(validators)].Address())
+	}
+}
+
+func BenchmarkWeightedCouncil_CheckInSubList(b *testing.B) {
+	b.Run("uncached", func(b *testing.B) { benchmarkWeightedCouncilCheckInSubList(b, false) })
+	b.Run("cached", func(b *testing.B) { benchmarkWeightedCouncilCheckInSubList(b, true) })
+}
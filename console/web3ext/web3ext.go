@@ -835,6 +835,10 @@ web3._extend({
 			name: 'clientVersion',
 			call: 'klay_clientVersion',
 		}),
+		new web3._extend.Method({
+			name: 'nodeBuildInfo',
+			call: 'klay_nodeBuildInfo',
+		}),
 		new web3._extend.Method({
 			name: 'getBlockReceipts',
 			call: 'klay_getBlockReceipts',
@@ -901,6 +905,12 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getCommitteeList',
+			call: 'klay_getCommitteeList',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getRewards',
 			call: 'klay_getRewards',
@@ -1288,6 +1298,18 @@ web3._extend({
 		new web3._extend.Property({
 			name: 'timeout',
 			getter: 'istanbul_getTimeout'
+		}),
+		new web3._extend.Property({
+			name: 'isProposer',
+			getter: 'istanbul_isProposer'
+		}),
+		new web3._extend.Property({
+			name: 'currentProposer',
+			getter: 'istanbul_currentProposer'
+		}),
+		new web3._extend.Property({
+			name: 'roundChangeHistory',
+			getter: 'istanbul_roundChangeHistory'
 		})
 	]
 });
@@ -0,0 +1,121 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheusmetrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rcrowley/go-metrics"
+)
+
+// collectorQuantiles are the quantiles of the timers and histograms exported as
+// Prometheus summaries.
+var collectorQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Collector bridges the metrics of a go-metrics registry to Prometheus. Unlike
+// PrometheusConfig which exports every metric as a gauge, it exports each metric
+// as the Prometheus metric family of its type when it is scraped:
+//   - counters, and the counts of meters as counters
+//   - gauges as gauges
+//   - timers and histograms as summaries
+//
+// The names of the metrics are sanitized to valid Prometheus names, and the
+// metrics carry the const labels given to NewCollector.
+type Collector struct {
+	registry  metrics.Registry
+	namespace string
+	labels    prometheus.Labels
+}
+
+// NewCollector returns a Collector of the metrics of r, whose names are
+// prefixed by namespace.
+func NewCollector(r metrics.Registry, namespace string, labels prometheus.Labels) *Collector {
+	return &Collector{registry: r, namespace: namespace, labels: labels}
+}
+
+// Describe sends no descriptor, which makes the collector unchecked since the
+// metrics of the registry are not known in advance.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect sends the current values of the metrics of the registry. If several
+// metrics have the same sanitized name, only the first of them in the order of
+// the names is sent.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	all := make(map[string]interface{})
+	c.registry.Each(func(name string, i interface{}) {
+		all[name] = i
+	})
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	collected := make(map[string]bool)
+	for _, name := range names {
+		promName := SanitizeName(c.namespace + "_" + name)
+		if collected[promName] {
+			continue
+		}
+		collected[promName] = true
+
+		desc := prometheus.NewDesc(promName, name, nil, c.labels)
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(metric.Count()))
+		case metrics.Gauge:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, metric.Value())
+		case metrics.Meter:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(metric.Count()))
+		case metrics.Timer:
+			snapshot := metric.Snapshot()
+			ch <- prometheus.MustNewConstSummary(desc, uint64(snapshot.Count()), float64(snapshot.Sum()),
+				quantiles(snapshot.Percentiles(collectorQuantiles)))
+		case metrics.Histogram:
+			snapshot := metric.Snapshot()
+			ch <- prometheus.MustNewConstSummary(desc, uint64(snapshot.Count()), float64(snapshot.Sum()),
+				quantiles(snapshot.Percentiles(collectorQuantiles)))
+		}
+	}
+}
+
+func quantiles(values []float64) map[float64]float64 {
+	qs := make(map[float64]float64, len(collectorQuantiles))
+	for i, q := range collectorQuantiles {
+		qs[q] = values[i]
+	}
+	return qs
+}
+
+// SanitizeName converts a go-metrics name to a valid Prometheus metric name by
+// replacing the invalid characters with underscores.
+func SanitizeName(name string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+	if sanitized == "" || sanitized[0] >= '0' && sanitized[0] <= '9' {
+		sanitized = "_" + sanitized
+	}
+	return sanitized
+}
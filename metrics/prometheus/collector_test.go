@@ -0,0 +1,93 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheusmetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"klaytn_klay/db/dynamo/get": "klaytn_klay_db_dynamo_get",
+		"consensus.istanbul-core":   "consensus_istanbul_core",
+		"a:b c=d":                   "a:b_c_d",
+		"0ms":                       "_0ms",
+		"":                          "_",
+	} {
+		assert.Equal(t, expected, SanitizeName(name), name)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("klay/db/dynamo/errors", r).Inc(3)
+	metrics.NewRegisteredGauge("consensus/istanbul/round", r).Update(7)
+	metrics.NewRegisteredMeter("klay/db/dynamo/batchwrite/retries", r).Mark(5)
+	timer := metrics.NewRegisteredTimer("klay/db/dynamo/get/time", r)
+	timer.Update(time.Millisecond)
+	timer.Update(3 * time.Millisecond)
+	// the same name as the counter after the sanitization
+	metrics.NewRegisteredCounter("klay/db/dynamo=errors", r).Inc(1)
+
+	promRegistry := prometheus.NewRegistry()
+	promRegistry.MustRegister(NewCollector(r, "klaytn", prometheus.Labels{"backend": "DynamoDBS3", "region": "ap-northeast-2"}))
+	server := httptest.NewServer(promhttp.HandlerFor(promRegistry, promhttp.HandlerOpts{ErrorHandling: promhttp.HTTPErrorOnError}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the output is parsed as the Prometheus exposition format
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(resp.Body)
+	require.NoError(t, err)
+	assert.Len(t, families, 4)
+
+	value := func(name string, typ dto.MetricType) *dto.Metric {
+		family := families[name]
+		if !assert.NotNil(t, family, name) || !assert.Equal(t, typ, family.GetType(), name) {
+			return &dto.Metric{}
+		}
+		metric := family.GetMetric()[0]
+		labels := make(map[string]string)
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		assert.Equal(t, map[string]string{"backend": "DynamoDBS3", "region": "ap-northeast-2"}, labels, name)
+		return metric
+	}
+	assert.Equal(t, 3.0, value("klaytn_klay_db_dynamo_errors", dto.MetricType_COUNTER).GetCounter().GetValue())
+	assert.Equal(t, 7.0, value("klaytn_consensus_istanbul_round", dto.MetricType_GAUGE).GetGauge().GetValue())
+	assert.Equal(t, 5.0, value("klaytn_klay_db_dynamo_batchwrite_retries", dto.MetricType_COUNTER).GetCounter().GetValue())
+
+	summary := value("klaytn_klay_db_dynamo_get_time", dto.MetricType_SUMMARY).GetSummary()
+	assert.Equal(t, uint64(2), summary.GetSampleCount())
+	assert.Equal(t, float64(4*time.Millisecond), summary.GetSampleSum())
+	assert.Len(t, summary.GetQuantile(), len(collectorQuantiles))
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/klaytn/klaytn/log"
+	klaytnmetrics "github.com/klaytn/klaytn/metrics"
 	prometheusmetrics "github.com/klaytn/klaytn/metrics/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -41,6 +42,7 @@ const (
 	DashboardEnabledFlag       = "dashboard"
 	PrometheusExporterFlag     = "prometheus"
 	PrometheusExporterPortFlag = "prometheusport"
+	PrometheusBridgeFlag       = "prometheusbridge"
 )
 
 // Init enables or disables the metrics system. Since we need this to run before
@@ -58,15 +60,26 @@ func init() {
 }
 
 // StartMetricCollectionAndExport starts exporting to prometheus and collects process metrics.
-func StartMetricCollectionAndExport(ctx *cli.Context) {
+// The metrics bridged to Prometheus are given the labels.
+func StartMetricCollectionAndExport(ctx *cli.Context, labels map[string]string) {
 	metricsCollectionInterval := 3 * time.Second
 	if Enabled {
 		logger.Info("Enabling metrics collection")
 		if EnabledPrometheusExport {
 			logger.Info("Enabling Prometheus Exporter")
-			pClient := prometheusmetrics.NewPrometheusProvider(metrics.DefaultRegistry, MetricNamespace,
-				"", prometheus.DefaultRegisterer, metricsCollectionInterval)
-			go pClient.UpdatePrometheusMetrics()
+			if ctx.Bool(PrometheusBridgeFlag) {
+				logger.Info("Exporting metrics as Prometheus metric families", "labels", labels)
+				prometheus.DefaultRegisterer.MustRegister(prometheusmetrics.NewCollector(metrics.DefaultRegistry, MetricNamespace, labels))
+				go func() {
+					for range time.Tick(metricsCollectionInterval) {
+						klaytnmetrics.ResetMaxGauges()
+					}
+				}()
+			} else {
+				pClient := prometheusmetrics.NewPrometheusProvider(metrics.DefaultRegistry, MetricNamespace,
+					"", prometheus.DefaultRegisterer, metricsCollectionInterval)
+				go pClient.UpdatePrometheusMetrics()
+			}
 			http.Handle("/metrics", promhttp.Handler())
 			port := ctx.Int(PrometheusExporterPortFlag)
 
@@ -39,6 +39,7 @@ var (
 	ErrClientQuit                = errors.New("client is closed")
 	ErrNoResult                  = errors.New("no result in JSON-RPC response")
 	ErrSubscriptionQueueOverflow = errors.New("subscription queue overflow")
+	ErrRequestTimeout            = errors.New("request timed out")
 	errClientReconnected         = errors.New("client reconnected")
 	errDead                      = errors.New("connection lost")
 	logger                       = log.NewModuleLogger(log.NetworksRPC)
@@ -49,6 +50,10 @@ const (
 	defaultDialTimeout  = 10 * time.Second // used if context has no deadline
 	defaultWriteTimeout = 10 * time.Second // used if context has no deadline
 	subscribeTimeout    = 10 * time.Second // overall timeout eth_subscribe, rpc_modules calls
+
+	// defaultRequestTimeout is how long a request waits for the response before it
+	// fails, so that the requests to a dead server are not pending forever.
+	defaultRequestTimeout = 5 * time.Minute
 )
 
 const (
@@ -84,8 +89,9 @@ type Client struct {
 
 	services *serviceRegistry
 
-	idCounter uint32
-	isHTTP    bool
+	idCounter      uint32
+	isHTTP         bool
+	requestTimeout int64 // time.Duration, accessed atomically
 
 	// This function, if non-nil, is called when the connection is lost.
 	reconnectFunc reconnectFunc
@@ -105,6 +111,8 @@ type Client struct {
 	reqInit     chan *requestOp  // register response IDs, takes write lock
 	reqSent     chan error       // signals write completion, releases write lock
 	reqTimeout  chan *requestOp  // removes response IDs when call timeout expires
+
+	metrics atomic.Value // *clientMetrics, records the calls of each method if set
 }
 
 type reconnectFunc func(ctx context.Context) (ServerCodec, error)
@@ -137,24 +145,44 @@ type requestOp struct {
 	err  error
 	resp chan *jsonrpcMessage // receives up to len(ids) responses
 	sub  *ClientSubscription  // only set for KlaySubscribe requests
+
+	stream func(json.RawMessage) // only set for StreamContext requests
 }
 
 func (op *requestOp) wait(ctx context.Context, c *Client) (*jsonrpcMessage, error) {
+	// The request timeout does not apply to streams, which may take long to
+	// finish while receiving the chunks.
+	var timeout <-chan time.Time
+	if d := c.getRequestTimeout(); d > 0 && !c.isHTTP && op.stream == nil {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	select {
 	case <-ctx.Done():
-		// Send the timeout to dispatch so it can remove the request IDs.
-		if !c.isHTTP {
-			select {
-			case c.reqTimeout <- op:
-			case <-c.closing:
-			}
-		}
+		c.removeRequest(op)
 		return nil, ctx.Err()
+	case <-timeout:
+		c.removeRequest(op)
+		return nil, ErrRequestTimeout
 	case resp := <-op.resp:
 		return resp, op.err
 	}
 }
 
+// removeRequest sends the timed out request to dispatch so it can remove the
+// request IDs.
+func (c *Client) removeRequest(op *requestOp) {
+	if c.isHTTP {
+		return
+	}
+	select {
+	case c.reqTimeout <- op:
+	case <-c.closing:
+	}
+}
+
 // Dial creates a new client for the given URL.
 //
 // The currently supported URL schemes are "http", "https", "ws" and "wss". If rawurl is a
@@ -184,6 +212,18 @@ func DialContext(ctx context.Context, rawurl string) (*Client, error) {
 	case "ws", "wss":
 		return DialWebsocket(ctx, rawurl, "")
 	case "stdio":
+		// "stdio:?framed=true" uses the length-prefixed framed protocol
+		framed, _ := strconv.ParseBool(u.Query().Get("framed"))
+		// "stdio:?compress=true" compresses the messages if the peer supports it
+		compress, _ := strconv.ParseBool(u.Query().Get("compress"))
+		switch {
+		case framed && compress:
+			return nil, errors.New("framed stdio connections cannot be compressed")
+		case framed:
+			return DialStdIOFramed(ctx)
+		case compress:
+			return DialStdIOCompressed(ctx)
+		}
 		return DialStdIO(ctx)
 	case "":
 		return DialIPC(ctx, rawurl)
@@ -225,6 +265,8 @@ func initClient(conn ServerCodec, idgen func() ID, services *serviceRegistry) *C
 		reqInit:     make(chan *requestOp),
 		reqSent:     make(chan error, 1),
 		reqTimeout:  make(chan *requestOp),
+
+		requestTimeout: int64(defaultRequestTimeout),
 	}
 	if !isHTTP {
 		go c.dispatch(conn)
@@ -267,6 +309,19 @@ func (c *Client) Close() {
 	}
 }
 
+// SetRequestTimeout sets how long a request waits for the response. If the
+// response is not received in time, the request fails with ErrRequestTimeout
+// and is forgotten. 0 disables the timeout, so only the context of the request
+// is respected. The default is 5 minutes. It does not apply to HTTP clients and
+// streams.
+func (c *Client) SetRequestTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.requestTimeout, int64(timeout))
+}
+
+func (c *Client) getRequestTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.requestTimeout))
+}
+
 // SetHeader adds a custom HTTP header to the client's requests.
 // This method only works for clients using HTTP, it doesn't have
 // any effect for clients using another transport.
@@ -296,6 +351,16 @@ func (c *Client) Call(result interface{}, method string, args ...interface{}) er
 // The result must be a pointer so that package json can unmarshal into it. You
 // can also pass nil, in which case the result is ignored.
 func (c *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if m := c.callMetrics(); m != nil {
+		start := time.Now()
+		err := c.callContext(ctx, result, method, args...)
+		m.record(method, time.Since(start), err)
+		return err
+	}
+	return c.callContext(ctx, result, method, args...)
+}
+
+func (c *Client) callContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
 	msg, err := c.newMessage(method, args...)
 	if err != nil {
 		return err
@@ -346,6 +411,23 @@ func (c *Client) BatchCall(b []BatchElem) error {
 //
 // Note that batch calls may not be executed atomically on the server side.
 func (c *Client) BatchCallContext(ctx context.Context, b []BatchElem) error {
+	if m := c.callMetrics(); m != nil {
+		start := time.Now()
+		err := c.batchCallContext(ctx, b)
+		elapsed := time.Since(start)
+		for _, elem := range b {
+			elemErr := err
+			if elemErr == nil {
+				elemErr = elem.Error
+			}
+			m.record(elem.Method, elapsed, elemErr)
+		}
+		return err
+	}
+	return c.batchCallContext(ctx, b)
+}
+
+func (c *Client) batchCallContext(ctx context.Context, b []BatchElem) error {
 	msgs := make([]*jsonrpcMessage, len(b))
 	op := &requestOp{
 		ids:  make([]json.RawMessage, len(b)),
@@ -618,6 +700,10 @@ func (c *Client) dispatch(codec ServerCodec) {
 		case op := <-c.reqTimeout:
 			conn.handler.removeRequestOp(op)
 		}
+
+		if m := c.callMetrics(); m != nil {
+			m.updatePending(len(conn.handler.respWait))
+		}
 	}
 }
 
@@ -24,6 +24,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"net/http"
@@ -37,6 +38,7 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/rcrowley/go-metrics"
 )
 
 func TestClientRequest(t *testing.T) {
@@ -684,3 +686,135 @@ func TestClientCloseUnsubscribeRace(t *testing.T) {
 		}
 	}
 }
+
+func TestClientMetrics(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	go server.ServeCodec(NewCodec(stdioConn{in: serverIn, out: serverOut}), 0)
+
+	client, err := DialIO(context.Background(), clientIn, clientOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		// stdioConn does not close the pipes, so close them to stop reading first
+		clientIn.Close()
+		serverIn.Close()
+		client.Close()
+	}()
+
+	// disabled by default
+	var resp Result
+	if err := client.Call(&resp, "service_echo", "hello", 10, &Args{"world"}); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := metrics.NewRegistry()
+	client.SetMetricsRegistry(registry)
+	if err := client.Call(&resp, "service_echo", "hello", 10, &Args{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "service_sleep", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call(nil, "service_noSuchMethod"); err == nil {
+		t.Fatal("no error for a missing method")
+	}
+	if err := client.BatchCall([]BatchElem{{Method: "service_echo", Args: []interface{}{"hello", 10, &Args{"world"}}, Result: &resp}}); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(name string) int64 {
+		switch m := registry.Get(name).(type) {
+		case metrics.Counter:
+			return m.Count()
+		case metrics.Timer:
+			return m.Count()
+		}
+		return 0
+	}
+	for name, want := range map[string]int64{
+		"rpc/client/calls/service_echo":            2,
+		"rpc/client/duration/service_echo":         2,
+		"rpc/client/errors/service_echo":           0,
+		"rpc/client/calls/service_sleep":           1,
+		"rpc/client/duration/service_sleep":        1,
+		"rpc/client/calls/service_noSuchMethod":    1,
+		"rpc/client/errors/service_noSuchMethod":   1,
+		"rpc/client/duration/service_noSuchMethod": 1,
+	} {
+		if have := count(name); have != want {
+			t.Errorf("%s: have %d, want %d", name, have, want)
+		}
+	}
+	if min := registry.Get("rpc/client/duration/service_sleep").(metrics.Timer).Min(); min < int64(10*time.Millisecond) {
+		t.Errorf("latency of service_sleep is too short: %v", time.Duration(min))
+	}
+
+	// disable again
+	client.SetMetricsRegistry(nil)
+	if err := client.Call(&resp, "service_echo", "hello", 10, &Args{"world"}); err != nil {
+		t.Fatal(err)
+	}
+	if have := count("rpc/client/calls/service_echo"); have != 2 {
+		t.Errorf("calls are recorded after disabled: %d", have)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	// the server reads the requests but never replies like a crashed subprocess
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+	go io.Copy(io.Discard, serverConn)
+
+	client, err := DialIO(context.Background(), clientConn, clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		clientConn.Close()
+		client.Close()
+	}()
+	registry := metrics.NewRegistry()
+	client.SetMetricsRegistry(registry)
+	client.SetRequestTimeout(100 * time.Millisecond)
+
+	pending := func() int64 {
+		if g, ok := registry.Get("rpc/client/pending").(metrics.Gauge); ok {
+			return g.Value()
+		}
+		return -1
+	}
+
+	start := time.Now()
+	if err := client.Call(nil, "service_echo", "hello"); err != ErrRequestTimeout {
+		t.Fatalf("wrong error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("wrong timeout: %v", elapsed)
+	}
+	err = client.BatchCall([]BatchElem{{Method: "service_echo"}, {Method: "service_echo"}})
+	if err != ErrRequestTimeout {
+		t.Fatalf("wrong error of batch: %v", err)
+	}
+
+	// the timed out requests are removed from the pending requests
+	deadline := time.Now().Add(5 * time.Second)
+	for pending() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pending requests are not cleared: %d", pending())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the context is respected if it is shorter than the timeout
+	client.SetRequestTimeout(time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.CallContext(ctx, nil, "service_echo", "hello"); err != context.DeadlineExceeded {
+		t.Fatalf("wrong error with context: %v", err)
+	}
+}
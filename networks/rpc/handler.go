@@ -267,6 +267,10 @@ func (h *handler) handleImmediate(msg *jsonrpcMessage) bool {
 			h.handleSubscriptionResult(msg)
 			return true
 		}
+		if strings.HasSuffix(msg.Method, streamItemMethodSuffix) {
+			h.handleStreamItem(msg)
+			return true
+		}
 		return false
 	case msg.isResponse():
 		h.handleResponse(msg)
@@ -358,6 +362,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
+	if callb := h.reg.stream(msg.Method); callb != nil {
+		return h.handleStream(cp, msg, callb)
+	}
 	var callb *callback
 	if msg.isUnsubscribe() {
 		callb = h.unsubscribeCb
@@ -1,6 +1,10 @@
 package rpc
 
-import "github.com/rcrowley/go-metrics"
+import (
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
 
 var (
 	rpcTotalRequestsCounter    = metrics.NewRegisteredCounter("rpc/counts/total", nil)
@@ -12,3 +16,42 @@ var (
 	wsUnsubscriptionReqCounter = metrics.NewRegisteredCounter("ws/counts/unsubscription/request", nil)
 	wsConnCounter              = metrics.NewRegisteredCounter("ws/counts/connections/total", nil)
 )
+
+// clientMetrics records the number of calls, the number of failures and the
+// latencies of each RPC method called by a Client.
+type clientMetrics struct {
+	registry metrics.Registry
+}
+
+// SetMetricsRegistry makes the client record the number of calls, the number of
+// failures and the latencies of each RPC method called by CallContext and
+// BatchCallContext into the given registry. They are recorded as
+// "rpc/client/calls/<method>", "rpc/client/errors/<method>" and
+// "rpc/client/duration/<method>". The number of the responses waited for is
+// recorded as "rpc/client/pending". A nil registry disables it, which is the
+// default.
+func (c *Client) SetMetricsRegistry(registry metrics.Registry) {
+	if registry == nil {
+		c.metrics.Store((*clientMetrics)(nil))
+		return
+	}
+	c.metrics.Store(&clientMetrics{registry: registry})
+}
+
+// callMetrics returns the metrics of the client, or nil if disabled.
+func (c *Client) callMetrics() *clientMetrics {
+	m, _ := c.metrics.Load().(*clientMetrics)
+	return m
+}
+
+func (m *clientMetrics) record(method string, elapsed time.Duration, err error) {
+	metrics.GetOrRegisterCounter("rpc/client/calls/"+method, m.registry).Inc(1)
+	if err != nil {
+		metrics.GetOrRegisterCounter("rpc/client/errors/"+method, m.registry).Inc(1)
+	}
+	metrics.GetOrRegisterTimer("rpc/client/duration/"+method, m.registry).Update(elapsed)
+}
+
+func (m *clientMetrics) updatePending(n int) {
+	metrics.GetOrRegisterGauge("rpc/client/pending", m.registry).Update(int64(n))
+}
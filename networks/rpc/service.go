@@ -37,6 +37,7 @@ var (
 	errorType        = reflect.TypeOf((*error)(nil)).Elem()
 	subscriptionType = reflect.TypeOf(Subscription{})
 	stringType       = reflect.TypeOf("")
+	streamType       = reflect.TypeOf((*Stream)(nil))
 )
 
 type serviceRegistry struct {
@@ -49,6 +50,7 @@ type service struct {
 	name          string               // name for service
 	callbacks     map[string]*callback // registered handlers
 	subscriptions map[string]*callback // available subscriptions/notifications
+	streams       map[string]*callback // methods which stream their result
 }
 
 // callback is a method callback which was registered in the server
@@ -59,6 +61,7 @@ type callback struct {
 	errPos      int            // err return idx, of -1 when method cannot return error
 	hasCtx      bool           // method's first argument is a context (not included in argTypes)
 	isSubscribe bool           // true if this is a subscription callback
+	isStream    bool           // method's first argument after the context is a *Stream (not included in argTypes)
 }
 
 func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
@@ -82,12 +85,15 @@ func (r *serviceRegistry) registerName(name string, rcvr interface{}) error {
 			name:          name,
 			callbacks:     make(map[string]*callback),
 			subscriptions: make(map[string]*callback),
+			streams:       make(map[string]*callback),
 		}
 		r.services[name] = svc
 	}
 	for name, cb := range callbacks {
 		if cb.isSubscribe {
 			svc.subscriptions[name] = cb
+		} else if cb.isStream {
+			svc.streams[name] = cb
 		} else {
 			svc.callbacks[name] = cb
 		}
@@ -112,6 +118,17 @@ func (r *serviceRegistry) callback(method string) *callback {
 	return r.services[elem[0]].callbacks[elem[1]]
 }
 
+// stream returns the streamed method corresponding to the given RPC method name.
+func (r *serviceRegistry) stream(method string) *callback {
+	elem := strings.SplitN(method, serviceMethodSeparator, 2)
+	if len(elem) != 2 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.services[elem[0]].streams[elem[1]]
+}
+
 // subscription returns a subscription callback in the given service.
 func (r *serviceRegistry) subscription(service, name string) *callback {
 	r.mu.Lock()
@@ -169,6 +186,10 @@ func newCallback(receiver, fn reflect.Value) *callback {
 		}
 		c.errPos = 1
 	}
+	// A streamed method sends its result through the stream, so it can only return an error.
+	if c.isStream && c.errPos != 0 {
+		return nil
+	}
 	return c
 }
 
@@ -184,6 +205,10 @@ func (c *callback) makeArgTypes() {
 		c.hasCtx = true
 		firstArg++
 	}
+	if fntype.NumIn() > firstArg && fntype.In(firstArg) == streamType {
+		c.isStream = true
+		firstArg++
+	}
 	// Add all remaining parameters.
 	c.argTypes = make([]reflect.Type, fntype.NumIn()-firstArg)
 	for i := firstArg; i < fntype.NumIn(); i++ {
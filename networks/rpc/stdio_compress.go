@@ -0,0 +1,257 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// The compression of a stdio connection is negotiated by a handshake request,
+// which is the first message sent by the client. It lists the compression
+// algorithms supported by the client, and the server replies with the chosen
+// one. A server which doesn't know the handshake replies with an error, and a
+// server which supports none of them replies with an empty string. In both
+// cases, the connection stays uncompressed.
+const compressionHandshakeMethod = "rpc_compression"
+
+// compressionDeflate compresses the messages in both directions as a DEFLATE
+// stream, which is flushed after every message.
+const compressionDeflate = "deflate"
+
+// deflateLevel is the compression level of the DEFLATE streams. The messages
+// are small and flushed one by one, so they are compressed mostly by the
+// matches to the previous messages in the window. The lower levels may write
+// such a small block uncompressed, while the best compression still finds them.
+const deflateLevel = flate.BestCompression
+
+var supportedCompressions = []string{compressionDeflate}
+
+// DialStdIOCompressed creates a client on stdin/stdout which compresses the
+// messages if the peer supports it.
+func DialStdIOCompressed(ctx context.Context) (*Client, error) {
+	return DialIOCompressed(ctx, os.Stdin, os.Stdout)
+}
+
+// DialIOCompressed creates a client which uses the given IO channels. The
+// messages are compressed if the peer serves the connection with
+// NewCompressionCodec, otherwise they are sent uncompressed. If ctx is done before the handshake is answered, in is closed
+// if it is an io.Closer, so that no reader is left behind.
+func DialIOCompressed(ctx context.Context, in io.Reader, out io.Writer) (*Client, error) {
+	return NewClient(ctx, func(ctx context.Context) (ServerCodec, error) {
+		algo, r, err := requestCompression(ctx, in, out)
+		if err != nil {
+			return nil, err
+		}
+		conn := stdioConn{in: in, out: out}
+		s := newCompressionStream(conn, r)
+		s.negotiated = true
+		if algo != "" {
+			s.compress(algo)
+		}
+		logger.Debug("Negotiated compression of stdio RPC", "compression", algo)
+		return NewFuncCodec(conn, s.encode, s.decode), nil
+	})
+}
+
+// NewCompressionCodec creates a codec on the given stdio connection which
+// compresses the messages if the client requests it by the handshake. Clients
+// which don't request it are served uncompressed. The compression is opt-in, so
+// the other transports, such as IPC, are served by NewCodec.
+func NewCompressionCodec(conn Conn) ServerCodec {
+	s := newCompressionStream(conn, conn)
+	return NewFuncCodec(conn, s.encode, s.decode)
+}
+
+// requestCompression sends the handshake request and returns the accepted
+// compression algorithm, which is empty if the peer doesn't support any. The
+// returned reader should be used to read the rest of the input.
+func requestCompression(ctx context.Context, in io.Reader, out io.Writer) (string, io.Reader, error) {
+	params, err := json.Marshal(supportedCompressions)
+	if err != nil {
+		return "", nil, err
+	}
+	req := &jsonrpcMessage{Version: vsn, ID: json.RawMessage("0"), Method: compressionHandshakeMethod, Params: params}
+	if err := json.NewEncoder(out).Encode(req); err != nil {
+		return "", nil, err
+	}
+
+	type handshakeResult struct {
+		resp *jsonrpcMessage
+		err  error
+	}
+	dec := json.NewDecoder(in)
+	resCh := make(chan handshakeResult, 1)
+	go func() {
+		resp := new(jsonrpcMessage)
+		err := dec.Decode(resp)
+		resCh <- handshakeResult{resp, err}
+	}()
+
+	var res handshakeResult
+	select {
+	case res = <-resCh:
+	case <-ctx.Done():
+		// unblock the pending read, which would otherwise never return
+		if c, ok := in.(io.Closer); ok {
+			c.Close()
+		}
+		return "", nil, ctx.Err()
+	}
+	if res.err != nil {
+		return "", nil, res.err
+	}
+	r := io.MultiReader(dec.Buffered(), in)
+
+	var algo string
+	if res.resp.Error != nil || json.Unmarshal(res.resp.Result, &algo) != nil || !isSupportedCompression(algo) {
+		return "", r, nil
+	}
+	return algo, r, nil
+}
+
+func isSupportedCompression(algo string) bool {
+	for _, supported := range supportedCompressions {
+		if algo == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// compressionStream encodes and decodes the JSON-RPC messages of a connection
+// which can be switched to be compressed by the handshake.
+type compressionStream struct {
+	conn       Conn
+	r          io.Reader
+	negotiated bool // whether the first message is read or the handshake is done
+	dec        *json.Decoder
+
+	encMu sync.Mutex
+	enc   *json.Encoder
+	fw    *flate.Writer // nil if the connection is not compressed
+}
+
+func newCompressionStream(conn Conn, r io.Reader) *compressionStream {
+	s := &compressionStream{
+		conn: conn,
+		r:    r,
+		dec:  json.NewDecoder(r),
+		enc:  json.NewEncoder(conn),
+	}
+	s.dec.UseNumber()
+	return s
+}
+
+// compress switches the connection to be compressed by the given algorithm.
+func (s *compressionStream) compress(algo string) {
+	// compressionDeflate is the only supported algorithm
+	r := &newlineSkipper{r: bufio.NewReader(io.MultiReader(s.dec.Buffered(), s.r))}
+	s.dec = json.NewDecoder(flate.NewReader(r))
+	s.dec.UseNumber()
+
+	s.encMu.Lock()
+	defer s.encMu.Unlock()
+	s.fw, _ = flate.NewWriter(s.conn, deflateLevel)
+	s.enc = json.NewEncoder(s.fw)
+}
+
+func (s *compressionStream) encode(v interface{}) error {
+	s.encMu.Lock()
+	defer s.encMu.Unlock()
+
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	if s.fw != nil {
+		return s.fw.Flush()
+	}
+	return nil
+}
+
+func (s *compressionStream) decode(v interface{}) error {
+	if !s.negotiated {
+		s.negotiated = true
+
+		var raw json.RawMessage
+		if err := s.dec.Decode(&raw); err != nil {
+			return err
+		}
+		msg := new(jsonrpcMessage)
+		if json.Unmarshal(raw, msg) != nil || msg.Method != compressionHandshakeMethod {
+			// not a handshake, serve the connection uncompressed
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.UseNumber()
+			return dec.Decode(v)
+		}
+		if err := s.acceptCompression(msg); err != nil {
+			return err
+		}
+	}
+	return s.dec.Decode(v)
+}
+
+// acceptCompression replies to the handshake request with the first supported
+// algorithm among the requested ones, and switches the connection to it.
+func (s *compressionStream) acceptCompression(req *jsonrpcMessage) error {
+	var requested []string
+	json.Unmarshal(req.Params, &requested)
+
+	algo := ""
+	for _, candidate := range requested {
+		if isSupportedCompression(candidate) {
+			algo = candidate
+			break
+		}
+	}
+	result, _ := json.Marshal(algo)
+	if err := s.encode(&jsonrpcMessage{Version: vsn, ID: req.ID, Result: result}); err != nil {
+		return err
+	}
+	if algo != "" {
+		s.compress(algo)
+	}
+	logger.Debug("Negotiated compression of stdio RPC", "compression", algo)
+	return nil
+}
+
+// newlineSkipper skips the newline written by json.Encoder after the handshake
+// messages, which is not a part of the compressed stream.
+type newlineSkipper struct {
+	r       *bufio.Reader
+	skipped bool
+}
+
+func (ns *newlineSkipper) Read(b []byte) (int, error) {
+	if !ns.skipped {
+		c, err := ns.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != '\n' {
+			ns.r.UnreadByte()
+		}
+		ns.skipped = true
+	}
+	return ns.r.Read(b)
+}
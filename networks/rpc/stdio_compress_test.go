@@ -0,0 +1,244 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingWriter records everything written to the underlying writer.
+type recordingWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.mu.Lock()
+	rw.buf.Write(b)
+	rw.mu.Unlock()
+	return rw.w.Write(b)
+}
+
+func (rw *recordingWriter) recorded() []byte {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return common.CopyBytes(rw.buf.Bytes())
+}
+
+func TestDialIOCompressed(t *testing.T) {
+	tests := []struct {
+		name       string
+		newCodec   func(Conn) ServerCodec
+		dial       func(context.Context, io.Reader, io.Writer) (*Client, error)
+		compressed bool
+	}{
+		{"compressed", NewCompressionCodec, DialIOCompressed, true},
+		{"server without compression", NewCodec, DialIOCompressed, false},
+		{"client without compression", NewCompressionCodec, DialIO, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer("service", new(Service))
+			defer server.Stop()
+			assert.NoError(t, server.RegisterName("klay", new(NotificationTestService)))
+
+			clientIn, serverOut := io.Pipe()
+			serverIn, clientOut := io.Pipe()
+			wire := &recordingWriter{w: serverOut}
+
+			go server.ServeCodec(tt.newCodec(stdioConn{in: serverIn, out: wire}), 0)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			client, err := tt.dial(ctx, clientIn, clientOut)
+			assert.NoError(t, err)
+			defer func() {
+				// stdioConn does not close the pipes, so close them to stop reading first
+				clientIn.Close()
+				serverIn.Close()
+				client.Close()
+			}()
+
+			// responses
+			for i := 0; i < 3; i++ {
+				var resp Result
+				if err := client.CallContext(ctx, &resp, "service_echo", "hello", i, &Args{"world"}); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(resp, Result{"hello", i, &Args{"world"}}) {
+					t.Errorf("incorrect result %#v", resp)
+				}
+			}
+
+			// notifications
+			nc := make(chan int)
+			count := 10
+			sub, err := client.KlaySubscribe(ctx, nc, "someSubscription", count, 0)
+			if err != nil {
+				t.Fatal("can't subscribe:", err)
+			}
+			for i := 0; i < count; i++ {
+				if val := <-nc; val != i {
+					t.Fatalf("value mismatch: got %d, want %d", val, i)
+				}
+			}
+			sub.Unsubscribe()
+
+			recorded := wire.recorded()
+			if !tt.compressed {
+				assert.Contains(t, string(recorded), `"method":"klay_subscription"`)
+				return
+			}
+
+			// the handshake response is followed by a DEFLATE stream
+			r := bufio.NewReader(bytes.NewReader(recorded))
+			line, err := r.ReadString('\n')
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"jsonrpc":"2.0","id":0,"result":"deflate"}`, line)
+
+			decompressed, err := io.ReadAll(flate.NewReader(r))
+			assert.Equal(t, io.ErrUnexpectedEOF, err) // the stream is flushed, but not finished
+			assert.Contains(t, string(decompressed), `"result":{"String":"hello","Int":2,"Args":{"S":"world"}}`)
+			assert.Equal(t, count, bytes.Count(decompressed, []byte(`"method":"klay_subscription"`)))
+
+			// the repetitive messages are compressed, even though they are flushed one by one
+			compressed := len(recorded) - len(line)
+			assert.Less(t, compressed, len(decompressed)/2, "compressed %d bytes of %d", compressed, len(decompressed))
+		})
+	}
+}
+
+func TestCompressionCodec_Negotiation(t *testing.T) {
+	tests := []struct {
+		requested string
+		accepted  string
+	}{
+		{`["deflate"]`, "deflate"},
+		{`["zstd","deflate"]`, "deflate"},
+		{`["zstd"]`, ""},
+		{`[]`, ""},
+	}
+
+	for _, tt := range tests {
+		var in, out bytes.Buffer
+		in.WriteString(`{"jsonrpc":"2.0","id":0,"method":"rpc_compression","params":` + tt.requested + "}\n")
+		next := `{"jsonrpc":"2.0","id":1,"method":"test_next"}`
+		if tt.accepted == "" {
+			in.WriteString(next)
+		} else {
+			fw, _ := flate.NewWriter(&in, flate.DefaultCompression)
+			fw.Write([]byte(next))
+			fw.Close()
+		}
+
+		codec := NewCompressionCodec(stdioConn{in: &in, out: &out})
+
+		// the handshake is not passed to the handler
+		msgs, _, err := codec.readBatch()
+		assert.NoError(t, err)
+		if assert.Len(t, msgs, 1) {
+			assert.Equal(t, "test_next", msgs[0].Method)
+		}
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":0,"result":"`+tt.accepted+`"}`, out.String())
+	}
+}
+
+func TestCompressionCodec_UseNumber(t *testing.T) {
+	var out bytes.Buffer
+	in := bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"test_first","params":[12345678901234567890]}`)
+	s := newCompressionStream(stdioConn{in: in, out: &out}, in)
+
+	// the first message, which is not a handshake, is decoded like the others
+	var msg map[string]interface{}
+	assert.NoError(t, s.decode(&msg))
+	assert.Equal(t, []interface{}{json.Number("12345678901234567890")}, msg["params"])
+}
+
+func TestServeListener_NoCompression(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go server.ServeListener(l)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, dial := range []func(context.Context, io.Reader, io.Writer) (*Client, error){DialIOCompressed, DialIO} {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := dial(ctx, conn, conn)
+		assert.NoError(t, err)
+
+		var resp Result
+		assert.NoError(t, client.CallContext(ctx, &resp, "service_echo", "hello", 1, &Args{"world"}))
+		assert.Equal(t, Result{"hello", 1, &Args{"world"}}, resp)
+		conn.Close()
+		client.Close()
+	}
+
+	// the compression is not served on IPC
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	algo, _, err := requestCompression(ctx, conn, conn)
+	assert.NoError(t, err)
+	assert.Empty(t, algo)
+}
+
+func TestDialIOCompressed_Cancel(t *testing.T) {
+	// the peer never answers the handshake
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	go io.Copy(io.Discard, serverIn)
+	defer serverIn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := DialIOCompressed(ctx, clientIn, clientOut)
+	assert.Error(t, err)
+
+	// the input is closed, so the pending read of the handshake has returned
+	_, err = serverOut.Write([]byte("{}"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestDialContext_FramedCompressed(t *testing.T) {
+	_, err := DialContext(context.Background(), "stdio:?framed=true&compress=true")
+	assert.Error(t, err)
+}
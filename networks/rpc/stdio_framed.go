@@ -0,0 +1,186 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klaytn/klaytn/common"
+)
+
+// A framed message is a JSON-RPC message prefixed with framedMagic and the
+// big-endian uint32 length of the message. Any bytes which are not a part of a
+// valid frame, such as logs written to stdout by the peer, are discarded. A
+// frame longer than common.MaxRequestContentLength is discarded as a whole, and
+// answered by an error response with the id found at the start of its payload.
+var framedMagic = []byte{0x00, 'R', 'P', 'C'}
+
+const framedHeaderSize = 8 // magic and length
+
+// DialStdIOFramed creates a client on stdin/stdout using the framed protocol.
+// The peer must serve the connection with NewFramedCodec.
+func DialStdIOFramed(ctx context.Context) (*Client, error) {
+	return DialIOFramed(ctx, os.Stdin, os.Stdout)
+}
+
+// DialIOFramed creates a client which uses the given IO channels with the framed protocol.
+func DialIOFramed(ctx context.Context, in io.Reader, out io.Writer) (*Client, error) {
+	return NewClient(ctx, func(_ context.Context) (ServerCodec, error) {
+		return NewFramedCodec(stdioConn{
+			in:  in,
+			out: out,
+		}), nil
+	})
+}
+
+// NewFramedCodec creates a codec which reads and writes length-prefixed JSON-RPC
+// messages on the given connection.
+func NewFramedCodec(conn Conn) ServerCodec {
+	r := &framedReader{r: bufio.NewReaderSize(conn, framedHeaderSize+common.MaxRequestContentLength)}
+	encode := func(v interface{}) error {
+		payload, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		// write a frame at once not to be interleaved with other writes
+		frame := make([]byte, framedHeaderSize, framedHeaderSize+len(payload))
+		copy(frame, framedMagic)
+		binary.BigEndian.PutUint32(frame[len(framedMagic):], uint32(len(payload)))
+		_, err = conn.Write(append(frame, payload...))
+		return err
+	}
+	codec := NewFuncCodec(conn, encode, r.decode)
+	r.reply = codec.writeJSON
+	return codec
+}
+
+// framedReader finds and decodes the framed messages from the underlying reader.
+type framedReader struct {
+	r     *bufio.Reader
+	reply func(ctx context.Context, v interface{}) error // writes the error responses of the discarded frames
+}
+
+func (fr *framedReader) decode(v interface{}) error {
+	payload, err := fr.next()
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// next returns the payload of the next valid frame. A candidate frame is only
+// consumed if it has a valid length and a valid JSON payload. Otherwise, it is
+// skipped by a byte so that a frame starting inside it can be found. A frame
+// too large to be read is discarded and rejected by rejectTooLarge.
+func (fr *framedReader) next() ([]byte, error) {
+	discarded := 0
+	defer func() {
+		if discarded > 0 {
+			logger.Debug("Discarded non-RPC data on framed stdio", "bytes", discarded)
+		}
+	}()
+
+	for {
+		header, err := fr.r.Peek(framedHeaderSize)
+		if err != nil {
+			return nil, err
+		}
+		if idx := bytes.Index(header, framedMagic); idx != 0 {
+			// skip until a possible start of magic
+			if idx < 0 {
+				idx = framedHeaderSize - len(framedMagic) + 1
+			}
+			n, _ := fr.r.Discard(idx)
+			discarded += n
+			continue
+		}
+
+		size := int(binary.BigEndian.Uint32(header[len(framedMagic):]))
+		if size > common.MaxRequestContentLength {
+			if err := fr.rejectTooLarge(size); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		frame, err := fr.r.Peek(framedHeaderSize + size)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(frame) == framedHeaderSize+size && json.Valid(frame[framedHeaderSize:]) {
+			payload := common.CopyBytes(frame[framedHeaderSize:])
+			fr.r.Discard(len(frame))
+			return payload, nil
+		}
+		if err == io.EOF {
+			// the rest of the stream is too short to be this frame
+			n, _ := fr.r.Discard(1)
+			discarded += n
+			continue
+		}
+		n, _ := fr.r.Discard(1)
+		discarded += n
+	}
+}
+
+// rejectTooLarge discards the frame of the given size, which is too large to be
+// read, and replies an error response so that the peer doesn't wait for the
+// response of the request forever.
+func (fr *framedReader) rejectTooLarge(size int) error {
+	// the buffer holds the start of the payload, which usually has the id
+	buffered, _ := fr.r.Peek(fr.r.Size())
+	id := framedMessageID(buffered[framedHeaderSize:])
+	if _, err := fr.r.Discard(framedHeaderSize + size); err != nil {
+		return err
+	}
+	logger.Warn("Discarded a too large RPC frame on framed stdio", "bytes", size, "id", string(id))
+
+	msg := errorMessage(&invalidRequestError{fmt.Sprintf("framed message too large: %d > %d bytes", size, common.MaxRequestContentLength)})
+	msg.ID = id
+	return fr.reply(context.Background(), msg)
+}
+
+// framedMessageID returns the id of the JSON-RPC message starting with the given
+// bytes, or null if the id is not found in them.
+func framedMessageID(prefix []byte) json.RawMessage {
+	dec := json.NewDecoder(bytes.NewReader(prefix))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return null
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			break
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			break
+		}
+		if key == "id" {
+			return val
+		}
+	}
+	return null
+}
@@ -0,0 +1,189 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func frame(payload string) []byte {
+	header := make([]byte, framedHeaderSize)
+	copy(header, framedMagic)
+	binary.BigEndian.PutUint32(header[len(framedMagic):], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestFramedCodec_RecoverMessages(t *testing.T) {
+	var in bytes.Buffer
+	in.WriteString("INFO [01/01|00:00:00] a log line written to stdout\n")
+	in.Write(frame(`{"jsonrpc":"2.0","id":1,"method":"test_first"}`))
+	in.WriteString("noise with a partial magic \x00RP")
+	in.Write(frame(`{"jsonrpc":"2.0","id":2,"method":"test_second"}`))
+	// a broken frame whose payload is not a valid JSON
+	in.Write(frame(`{"jsonrpc":"2.0",`))
+	// a frame whose length is longer than the rest of the stream
+	in.Write(append(append([]byte{}, framedMagic...), 0x00, 0x00, 0x10, 0x00))
+	in.Write(frame(`{"jsonrpc":"2.0","id":3,"method":"test_third"}`))
+
+	codec := NewFramedCodec(stdioConn{in: &in, out: io.Discard})
+
+	for _, method := range []string{"test_first", "test_second", "test_third"} {
+		msgs, batch, err := codec.readBatch()
+		assert.NoError(t, err)
+		assert.False(t, batch)
+		if assert.Len(t, msgs, 1) {
+			assert.Equal(t, method, msgs[0].Method)
+		}
+	}
+	_, _, err := codec.readBatch()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestFramedCodec_TooLargeFrame(t *testing.T) {
+	var in, out bytes.Buffer
+	big := strings.Repeat("x", common.MaxRequestContentLength)
+	in.Write(frame(`{"jsonrpc":"2.0","id":7,"method":"test_big","params":["` + big + `"]}`))
+	in.Write(frame(`{"jsonrpc":"2.0","id":8,"method":"test_next"}`))
+
+	codec := NewFramedCodec(stdioConn{in: &in, out: &out})
+
+	// the too large frame is skipped as a whole
+	msgs, _, err := codec.readBatch()
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "test_next", msgs[0].Method)
+	}
+
+	// and rejected by an error response with its id
+	header := out.Next(framedHeaderSize)
+	assert.Equal(t, framedMagic, header[:len(framedMagic)])
+	var resp jsonrpcMessage
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &resp))
+	assert.Equal(t, "7", string(resp.ID))
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32600, resp.Error.Code)
+		assert.Contains(t, resp.Error.Message, "too large")
+	}
+}
+
+func TestFramedMessageID(t *testing.T) {
+	for prefix, id := range map[string]string{
+		`{"jsonrpc":"2.0","id":"abc","method":"test"`: `"abc"`,
+		`{"id":1,"params":["xx`:                       `1`,
+		`{"jsonrpc":"2.0","params":["xxx`:             `null`,
+		`[{"id":1}`:                                   `null`,
+		``:                                            `null`,
+	} {
+		assert.Equal(t, id, string(framedMessageID([]byte(prefix))), prefix)
+	}
+}
+
+func TestFramedCodec_WriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	codec := NewFramedCodec(stdioConn{in: &bytes.Buffer{}, out: &out})
+	assert.NoError(t, codec.writeJSON(context.Background(), map[string]int{"id": 1}))
+	assert.Equal(t, frame(`{"id":1}`), out.Bytes())
+}
+
+// noisyWriter writes a log line before every write to simulate a process which writes logs to stdout.
+type noisyWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (nw *noisyWriter) Write(b []byte) (int, error) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+	if _, err := nw.w.Write([]byte("WARN a log line {\"not\":\"rpc\"}\n")); err != nil {
+		return 0, err
+	}
+	return nw.w.Write(b)
+}
+
+func TestDialIOFramed(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	go server.ServeCodec(NewFramedCodec(stdioConn{in: serverIn, out: &noisyWriter{w: serverOut}}), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := DialIOFramed(ctx, clientIn, clientOut)
+	assert.NoError(t, err)
+	defer func() {
+		// stdioConn does not close the pipes, so close them to stop reading first
+		clientIn.Close()
+		serverIn.Close()
+		client.Close()
+	}()
+
+	for i := 0; i < 3; i++ {
+		var resp Result
+		if err := client.CallContext(ctx, &resp, "service_echo", "hello", i, &Args{"world"}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(resp, Result{"hello", i, &Args{"world"}}) {
+			t.Errorf("incorrect result %#v", resp)
+		}
+	}
+}
+
+func TestDialIOFramed_TooLargeRequest(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	go server.ServeCodec(NewFramedCodec(stdioConn{in: serverIn, out: serverOut}), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := DialIOFramed(ctx, clientIn, clientOut)
+	assert.NoError(t, err)
+	defer func() {
+		clientIn.Close()
+		serverIn.Close()
+		client.Close()
+	}()
+
+	// the too large request fails instead of waiting for the response forever
+	var resp Result
+	err = client.CallContext(ctx, &resp, "service_echo", strings.Repeat("x", common.MaxRequestContentLength), 1, &Args{"world"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "too large")
+	}
+
+	// the connection is still usable
+	assert.NoError(t, client.CallContext(ctx, &resp, "service_echo", "hello", 1, &Args{"world"}))
+	assert.Equal(t, Result{"hello", 1, &Args{"world"}}, resp)
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// A streamed method sends the elements of its result one by one instead of returning
+// all of them at once. A method is registered as a streamed method if its first argument,
+// after an optional context.Context, is a *Stream and it returns only an error:
+//
+//	func (s *LogService) Logs(ctx context.Context, stream *rpc.Stream, crit FilterCriteria) error
+//
+// Such a method is never called as a standard method, so its result is never built in
+// memory as a whole. The elements are sent with "<namespace>_streamItem" notifications,
+// and the final response holds the number of the sent elements.
+const streamItemMethodSuffix = "_streamItem"
+
+type streamItem struct {
+	ID     json.RawMessage `json:"stream"`
+	Result json.RawMessage `json:"result"`
+}
+
+// Stream is tied to a call of a streamed method. The method sends the elements of
+// its result through it as soon as they are produced.
+type Stream struct {
+	h         *handler
+	ctx       context.Context
+	namespace string
+	id        json.RawMessage
+	count     int
+}
+
+// Send sends an element of the result to the client.
+func (s *Stream) Send(item interface{}) error {
+	enc, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	params, err := json.Marshal(&streamItem{ID: s.id, Result: enc})
+	if err != nil {
+		return err
+	}
+	s.count++
+	return s.h.conn.writeJSON(s.ctx, &jsonrpcMessage{
+		Version: vsn,
+		Method:  s.namespace + streamItemMethodSuffix,
+		Params:  params,
+	})
+}
+
+// handleStream processes calls of streamed methods.
+func (h *handler) handleStream(cp *callProc, msg *jsonrpcMessage, callb *callback) *jsonrpcMessage {
+	if !h.allowSubscribe {
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(ErrNotificationsUnsupported)
+	}
+	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
+	if err != nil {
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(&invalidParamsError{err.Error()})
+	}
+
+	s := &Stream{h: h, ctx: cp.ctx, namespace: msg.namespace(), id: msg.ID}
+	args = append([]reflect.Value{reflect.ValueOf(s)}, args...)
+	if _, err := callb.call(cp.ctx, msg.Method, args); err != nil {
+		rpcErrorResponsesCounter.Inc(1)
+		return msg.errorResponse(err)
+	}
+	rpcSuccessResponsesCounter.Inc(1)
+	return msg.response(s.count)
+}
+
+// handleStreamItem delivers an element of a streamed result to the waiting request.
+func (h *handler) handleStreamItem(msg *jsonrpcMessage) {
+	var item streamItem
+	if err := json.Unmarshal(msg.Params, &item); err != nil {
+		logger.Debug("Dropping invalid stream item")
+		return
+	}
+	op := h.respWait[string(item.ID)]
+	if op == nil || op.stream == nil {
+		logger.Debug("Unsolicited RPC stream item", "reqid", idForLog{item.ID})
+		return
+	}
+	op.stream(item.Result)
+}
+
+// StreamContext calls the given streamed method. The elements of the result are
+// delivered to fn one by one as they arrive, so the whole result is never held in
+// memory at once. A standard call of a streamed method only returns the number of
+// the elements.
+//
+// fn is called on the goroutine reading the connection, so it must not block for
+// long or call the client. If fn returns an error, the remaining elements are
+// discarded and the error is returned after the call completes.
+//
+// The server must be reachable through a connection which supports notifications.
+func (c *Client) StreamContext(ctx context.Context, fn func(item json.RawMessage) error, method string, args ...interface{}) error {
+	if c.isHTTP {
+		return ErrNotificationsUnsupported
+	}
+	msg, err := c.newMessage(method, args...)
+	if err != nil {
+		return err
+	}
+
+	// fnErr is only accessed by dispatch until the response is delivered.
+	var fnErr error
+	op := &requestOp{
+		ids:  []json.RawMessage{msg.ID},
+		resp: make(chan *jsonrpcMessage, 1),
+		stream: func(item json.RawMessage) {
+			if fnErr == nil {
+				fnErr = fn(item)
+			}
+		},
+	}
+	if err := c.send(ctx, op, msg); err != nil {
+		return err
+	}
+
+	switch resp, err := op.wait(ctx, c); {
+	case err != nil:
+		return err
+	case resp.Error != nil:
+		return resp.Error
+	default:
+		return fnErr
+	}
+}
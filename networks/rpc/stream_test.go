@@ -0,0 +1,175 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type streamTestService struct{}
+
+// Items sends the integers from 0 to n-1.
+func (s *streamTestService) Items(stream *Stream, n int) error {
+	for i := 0; i < n; i++ {
+		if err := stream.Send(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Large produces n items of the given size and sends them as they are produced.
+func (s *streamTestService) Large(ctx context.Context, stream *Stream, n, size int) error {
+	for i := 0; i < n; i++ {
+		if err := stream.Send(strings.Repeat("x", size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fail sends an item and fails.
+func (s *streamTestService) Fail(stream *Stream) error {
+	if err := stream.Send(0); err != nil {
+		return err
+	}
+	return errors.New("stream failure")
+}
+
+// Stream is a standard method whose name must not be taken for a streamed call.
+func (s *streamTestService) Stream() string {
+	return "standard"
+}
+
+// Invalid is not registered, because a streamed method can only return an error.
+func (s *streamTestService) Invalid(stream *Stream) []int {
+	return nil
+}
+
+func TestClientStream(t *testing.T) {
+	server := newTestServer("test", new(streamTestService))
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	var items []int
+	err := client.StreamContext(context.Background(), func(item json.RawMessage) error {
+		var v int
+		if err := json.Unmarshal(item, &v); err != nil {
+			return err
+		}
+		items = append(items, v)
+		return nil
+	}, "test_items", 100)
+	assert.NoError(t, err)
+	expected := make([]int, 100)
+	for i := range expected {
+		expected[i] = i
+	}
+	assert.Equal(t, expected, items)
+
+	// an error of the callback is returned
+	fnErr := errors.New("callback error")
+	numCalls := 0
+	err = client.StreamContext(context.Background(), func(item json.RawMessage) error {
+		numCalls++
+		return fnErr
+	}, "test_items", 10)
+	assert.Equal(t, fnErr, err)
+	assert.Equal(t, 1, numCalls)
+
+	// an error of the method is returned
+	err = client.StreamContext(context.Background(), func(item json.RawMessage) error { return nil }, "test_fail")
+	assert.EqualError(t, err, "stream failure")
+
+	// a standard call of a streamed method only returns the number of the elements
+	var count int
+	assert.NoError(t, client.Call(&count, "test_items", 3))
+	assert.Equal(t, 3, count)
+
+	// a standard method is not taken for a streamed method by its name
+	var result string
+	assert.NoError(t, client.Call(&result, "test_stream"))
+	assert.Equal(t, "standard", result)
+
+	err = client.StreamContext(context.Background(), func(item json.RawMessage) error { return nil }, "test_missing")
+	assert.Error(t, err)
+	err = client.StreamContext(context.Background(), func(item json.RawMessage) error { return nil }, "test_invalid")
+	assert.Error(t, err)
+}
+
+func TestClientStream_BoundedMemory(t *testing.T) {
+	const (
+		numItems = 2000
+		itemSize = 64 * 1024 // 125 MiB in total
+		maxHeap  = 64 * 1024 * 1024
+	)
+	server := newTestServer("test", new(streamTestService))
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	runtime.GC()
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var m runtime.MemStats
+		for {
+			runtime.ReadMemStats(&m)
+			if m.HeapAlloc > atomic.LoadUint64(&peak) {
+				atomic.StoreUint64(&peak, m.HeapAlloc)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}()
+
+	received, total := 0, 0
+	err := client.StreamContext(context.Background(), func(item json.RawMessage) error {
+		received++
+		total += len(item)
+		return nil
+	}, "test_large", numItems, itemSize)
+	close(done)
+	<-sampled
+
+	assert.NoError(t, err)
+	assert.Equal(t, numItems, received)
+	assert.Equal(t, numItems*(itemSize+2), total)
+	// the heap stays far below the size of the whole result
+	assert.Less(t, atomic.LoadUint64(&peak), uint64(maxHeap), "peak heap is not bounded")
+}
+
+func TestHTTPClientStream_NotSupported(t *testing.T) {
+	client := &Client{isHTTP: true}
+	err := client.StreamContext(context.Background(), func(item json.RawMessage) error { return nil }, "test_items", 1)
+	assert.Equal(t, ErrNotificationsUnsupported, err)
+}
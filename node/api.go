@@ -23,6 +23,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"strings"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/networks/p2p"
 	"github.com/klaytn/klaytn/networks/p2p/discover"
 	"github.com/klaytn/klaytn/networks/rpc"
+	"github.com/klaytn/klaytn/params"
 	"github.com/rcrowley/go-metrics"
 )
 
@@ -438,6 +440,25 @@ func (s *PublicKlayAPI) ClientVersion() string {
 	return s.stack.Server().Name()
 }
 
+// NodeBuildInfo is the build of the running node.
+type NodeBuildInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	GoVersion string `json:"goVersion"`
+	NodeType  string `json:"nodeType"`
+}
+
+// NodeBuildInfo returns the version, git commit and Go version of the build,
+// and the type of the node, such as "cn" or "spn".
+func (s *PublicKlayAPI) NodeBuildInfo() NodeBuildInfo {
+	return NodeBuildInfo{
+		Version:   params.VersionWithCommit(s.stack.config.GitCommit),
+		GitCommit: s.stack.config.GitCommit,
+		GoVersion: runtime.Version(),
+		NodeType:  s.stack.config.NodeType,
+	}
+}
+
 // Sha3 applies the Klaytn sha3 implementation on the input.
 // It assumes the input is hex encoded.
 func (s *PublicKlayAPI) Sha3(input hexutil.Bytes) hexutil.Bytes {
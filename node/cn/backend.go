@@ -110,7 +110,8 @@ type CN struct {
 	lesServer       LesServer
 
 	// DB interfaces
-	chainDB database.DBManager // Block chain database
+	chainDB      database.DBManager // Block chain database
+	closeChainDB bool               // closes chainDB on Stop, unless it is closed by a shutdown hook of the node
 
 	eventMux       *event.TypeMux
 	engine         consensus.Engine
@@ -207,6 +208,12 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	}
 
 	chainDB := CreateDB(ctx, config, "chaindata")
+	// the node closes the chain database after the other services wrote their last data
+	closeChainDB := !ctx.RegisterShutdownHook("chaindb", node.ShutdownPriorityDB, func() error {
+		err := chainDB.Flush()
+		chainDB.Close()
+		return err
+	})
 
 	chainConfig, genesisHash, genesisErr := blockchain.SetupGenesisBlock(chainDB, config.Genesis, config.NetworkId, config.IsPrivate, false)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
@@ -226,6 +233,7 @@ func New(ctx *node.ServiceContext, config *Config) (*CN, error) {
 	cn := &CN{
 		config:            config,
 		chainDB:           chainDB,
+		closeChainDB:      closeChainDB,
 		chainConfig:       chainConfig,
 		eventMux:          ctx.EventMux,
 		accountManager:    ctx.AccountManager,
@@ -489,7 +497,7 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) database.DB
 	dbc := &database.DBConfig{
 		Dir: name, DBType: config.DBType, ParallelDBWrite: config.ParallelDBWrite, SingleDB: config.SingleDB, NumStateTrieShards: config.NumStateTrieShards,
 		LevelDBCacheSize: config.LevelDBCacheSize, OpenFilesLimit: database.GetOpenFilesLimit(), LevelDBCompression: config.LevelDBCompression,
-		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, RocksDBConfig: &config.RocksDBConfig, DynamoDBConfig: &config.DynamoDBConfig,
+		LevelDBBufferPool: config.LevelDBBufferPool, EnableDBPerfMetrics: config.EnableDBPerfMetrics, MinFreeDiskSpace: config.MinFreeDiskSpace, RocksDBConfig: &config.RocksDBConfig, DynamoDBConfig: &config.DynamoDBConfig,
 	}
 	return ctx.OpenDatabase(dbc)
 }
@@ -500,6 +508,9 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *Config, chainConfig
 	if chainConfig.Governance == nil {
 		chainConfig.Governance = params.GetDefaultGovernanceConfig()
 	}
+	if config.Istanbul.MessageCacheFile != "" {
+		config.Istanbul.MessageCacheFile = ctx.ResolvePath(config.Istanbul.MessageCacheFile)
+	}
 	return istanbulBackend.New(config.Rewardbase, &config.Istanbul, ctx.NodeKey(), db, gov, nodetype)
 }
 
@@ -707,7 +718,9 @@ func (s *CN) Stop() error {
 	s.miner.Stop()
 	reward.StakingManagerUnsubscribe()
 	s.blockchain.Stop()
-	s.chainDB.Close()
+	if s.closeChainDB {
+		s.chainDB.Close()
+	}
 	s.eventMux.Stop()
 
 	return nil
@@ -117,6 +117,7 @@ type Config struct {
 	LevelDBCompression   database.LevelDBCompressionType
 	LevelDBBufferPool    bool
 	LevelDBCacheSize     int
+	MinFreeDiskSpace     int
 	DynamoDBConfig       database.DynamoDBConfig
 	RocksDBConfig        database.RocksDBConfig
 	TrieCacheSize        int
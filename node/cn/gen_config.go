@@ -38,6 +38,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		LevelDBCompression      database.LevelDBCompressionType
 		LevelDBBufferPool       bool
 		LevelDBCacheSize        int
+		MinFreeDiskSpace        int
 		DynamoDBConfig          database.DynamoDBConfig
 		TrieCacheSize           int
 		TrieTimeout             time.Duration
@@ -92,6 +93,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.LevelDBCompression = c.LevelDBCompression
 	enc.LevelDBBufferPool = c.LevelDBBufferPool
 	enc.LevelDBCacheSize = c.LevelDBCacheSize
+	enc.MinFreeDiskSpace = c.MinFreeDiskSpace
 	enc.DynamoDBConfig = c.DynamoDBConfig
 	enc.TrieCacheSize = c.TrieCacheSize
 	enc.TrieTimeout = c.TrieTimeout
@@ -150,6 +152,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		LevelDBCompression      *database.LevelDBCompressionType
 		LevelDBBufferPool       *bool
 		LevelDBCacheSize        *int
+		MinFreeDiskSpace        *int
 		DynamoDBConfig          *database.DynamoDBConfig
 		TrieCacheSize           *int
 		TrieTimeout             *time.Duration
@@ -247,6 +250,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.LevelDBCacheSize != nil {
 		c.LevelDBCacheSize = *dec.LevelDBCacheSize
 	}
+	if dec.MinFreeDiskSpace != nil {
+		c.MinFreeDiskSpace = *dec.MinFreeDiskSpace
+	}
 	if dec.DynamoDBConfig != nil {
 		c.DynamoDBConfig = *dec.DynamoDBConfig
 	}
@@ -63,6 +63,11 @@ type Config struct {
 	// in the devp2p node identifier.
 	Version string `toml:"-"`
 
+	// GitCommit and NodeType are reported by the nodeBuildInfo API, so that the
+	// builds of the running nodes can be audited.
+	GitCommit string `toml:"-"`
+	NodeType  string `toml:"-"`
+
 	// key-value database type [LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB]
 	DBType database.DBType
 
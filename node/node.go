@@ -90,6 +90,8 @@ type Node struct {
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
+	shutdownHooks shutdownHooks // hooks run before the services are stopped, or after them from ShutdownPriorityPostStop
+
 	logger log.Logger
 }
 
@@ -197,6 +199,16 @@ func (n *Node) Start() error {
 	p2pServer := p2p.NewServer(n.serverConfig)
 	n.logger.Info("Starting peer-to-peer node", "instance", n.serverConfig.Name)
 
+	// The services may hand over what they opened to the shutdown hooks while
+	// they are constructed, so the hooks are run if the node fails to start.
+	running := false
+	defer func() {
+		if !running {
+			n.runShutdownHooks(false)
+			n.runShutdownHooks(true)
+		}
+	}()
+
 	// Otherwise copy and specialize the P2P configuration
 	coreservices := make(map[reflect.Type]Service)
 	if err := n.initService(n.coreServiceFuncs, coreservices); err != nil {
@@ -269,6 +281,7 @@ func (n *Node) Start() error {
 	}
 
 	// Finish initializing the startup
+	running = true
 	n.subservices = services
 	n.services = coreservices
 	n.server = p2pServer
@@ -294,6 +307,7 @@ func (n *Node) initService(serviceFunc []ServiceConstructor, services map[reflec
 	for _, constructor := range serviceFunc {
 		// Create a new context for the particular service
 		ctx := NewServiceContext(n.config, make(map[reflect.Type]Service), n.eventmux, n.accman)
+		ctx.shutdownHooks = &n.shutdownHooks
 		for kind, s := range services { // copy needed for threaded access
 			ctx.services[kind] = s
 		}
@@ -583,6 +597,7 @@ func (n *Node) Stop() error {
 	n.stopIPC()
 	n.stopgRPC()
 	n.rpcAPIs = nil
+	n.runShutdownHooks(false)
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
 	}
@@ -600,6 +615,7 @@ func (n *Node) Stop() error {
 		}
 	}
 	n.server.Stop()
+	n.runShutdownHooks(true)
 	n.services = nil
 	n.server = nil
 
@@ -186,6 +186,93 @@ func TestServiceLifeCycle(t *testing.T) {
 	}
 }
 
+// Tests that the shutdown hooks are run in priority order before the services
+// are stopped, and that a failing hook doesn't abort the rest.
+func TestShutdownHooks(t *testing.T) {
+	stack, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	var order []string
+	hook := func(name string, err error) ShutdownHook {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+	stack.RegisterShutdownHook("C", 10, hook("C", nil))
+	stack.RegisterShutdownHook("A", -1, hook("A", nil))
+	stack.RegisterShutdownHook("B1", 0, hook("B1", errors.New("failure")))
+	stack.RegisterShutdownHook("B2", 0, hook("B2", nil))
+
+	// the services can register hooks while they are constructed, and the
+	// post-stop hooks are run after the services are stopped
+	constructor := func(ctx *ServiceContext) (Service, error) {
+		ctx.RegisterShutdownHook("D", 20, hook("D", nil))
+		ctx.RegisterShutdownHook("E", ShutdownPriorityPostStop, hook("E", nil))
+		return &InstrumentedService{
+			stopHook: func() { order = append(order, "service") },
+		}, nil
+	}
+	if err := stack.Register(constructor); err != nil {
+		t.Fatalf("service registration failed: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	if len(order) != 0 {
+		t.Fatalf("shutdown hooks run before stop: %v", order)
+	}
+	if err := stack.Stop(); err != nil {
+		t.Fatalf("failed to stop protocol stack: %v", err)
+	}
+	want := []string{"A", "B1", "B2", "C", "D", "service", "E"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("execution order mismatch: have %v, want %v", order, want)
+	}
+
+	// the hooks are run only once
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to restart protocol stack: %v", err)
+	}
+	order = nil
+	if err := stack.Stop(); err != nil {
+		t.Fatalf("failed to stop protocol stack: %v", err)
+	}
+	if want := []string{"D", "service", "E"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("execution order mismatch after restart: have %v, want %v", order, want)
+	}
+}
+
+// Tests that the shutdown hooks registered by the services are run if the node
+// fails to start.
+func TestShutdownHooks_StartFailure(t *testing.T) {
+	stack, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	var order []string
+	constructor := func(ctx *ServiceContext) (Service, error) {
+		ctx.RegisterShutdownHook("close", ShutdownPriorityDB, func() error {
+			order = append(order, "close")
+			return nil
+		})
+		return &InstrumentedService{
+			start:    errors.New("start failure"),
+			stopHook: func() { order = append(order, "service") },
+		}, nil
+	}
+	if err := stack.Register(constructor); err != nil {
+		t.Fatalf("service registration failed: %v", err)
+	}
+	if err := stack.Start(); err == nil {
+		t.Fatal("the failing service is started")
+	}
+	if want := []string{"close"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("execution order mismatch: have %v, want %v", order, want)
+	}
+}
+
 // Tests that services are restarted cleanly as new instances.
 func TestServiceRestarts(t *testing.T) {
 	stack, err := New(testNodeConfig())
@@ -37,10 +37,23 @@ type ServiceContext struct {
 	services       map[reflect.Type]Service
 	EventMux       *event.TypeMux
 	AccountManager *accounts.Manager
+
+	shutdownHooks *shutdownHooks // the hooks of the node, nil if the service is not run by a node
 }
 
 func NewServiceContext(conf *Config, srv map[reflect.Type]Service, mux *event.TypeMux, am *accounts.Manager) *ServiceContext {
-	return &ServiceContext{conf, srv, mux, am}
+	return &ServiceContext{config: conf, services: srv, EventMux: mux, AccountManager: am}
+}
+
+// RegisterShutdownHook registers a hook run when the node stops, like
+// Node.RegisterShutdownHook. It returns false without registering the hook if
+// the service is not run by a node.
+func (ctx *ServiceContext) RegisterShutdownHook(name string, priority int, hook ShutdownHook) bool {
+	if ctx.shutdownHooks == nil {
+		return false
+	}
+	ctx.shutdownHooks.register(name, priority, hook)
+	return true
 }
 
 // OpenDatabase opens an existing database with the given name (or creates one
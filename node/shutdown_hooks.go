@@ -0,0 +1,91 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ShutdownHook is a function run when the node stops.
+type ShutdownHook func() error
+
+// The hooks of a priority lower than ShutdownPriorityPostStop are run before
+// the services are stopped, and the others after the services are stopped.
+const (
+	ShutdownPriorityPostStop = 100
+	ShutdownPriorityDB       = ShutdownPriorityPostStop // closes the databases after the services wrote their last data
+)
+
+type shutdownHook struct {
+	name     string
+	priority int
+	hook     ShutdownHook
+}
+
+// shutdownHooks is the registry of the hooks run when the node stops. It has
+// its own lock, since the services register the hooks while the node is
+// starting with the node lock held.
+type shutdownHooks struct {
+	mu    sync.Mutex
+	hooks []shutdownHook
+}
+
+// RegisterShutdownHook registers a named hook which is run when the node stops.
+// The hooks are run in the ascending order of the priority after the RPC
+// endpoints are closed, and the hooks of the same priority are run in the order
+// of registration. The hooks of a priority lower than ShutdownPriorityPostStop
+// are run before the services are stopped, and the others after them. If the
+// node fails to start, the hooks are run after the started services are stopped.
+func (n *Node) RegisterShutdownHook(name string, priority int, hook ShutdownHook) {
+	n.shutdownHooks.register(name, priority, hook)
+}
+
+func (hs *shutdownHooks) register(name string, priority int, hook ShutdownHook) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	hs.hooks = append(hs.hooks, shutdownHook{name, priority, hook})
+}
+
+// runShutdownHooks runs the registered hooks of the phase, before or after the
+// services are stopped, and removes them. A failing hook is logged and doesn't
+// stop the rest from running.
+func (n *Node) runShutdownHooks(postStop bool) {
+	var hooks []shutdownHook
+	n.shutdownHooks.mu.Lock()
+	remaining := n.shutdownHooks.hooks[:0]
+	for _, h := range n.shutdownHooks.hooks {
+		if (h.priority >= ShutdownPriorityPostStop) == postStop {
+			hooks = append(hooks, h)
+		} else {
+			remaining = append(remaining, h)
+		}
+	}
+	n.shutdownHooks.hooks = remaining
+	n.shutdownHooks.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].priority < hooks[j].priority })
+	for _, h := range hooks {
+		start := time.Now()
+		if err := h.hook(); err != nil {
+			n.logger.Error("Shutdown hook failed", "name", h.name, "priority", h.priority, "elapsed", time.Since(start), "err", err)
+			continue
+		}
+		n.logger.Info("Shutdown hook done", "name", h.name, "priority", h.priority, "elapsed", time.Since(start))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/klaytn/klaytn/log"
+)
+
+const awsRequestLoggerName = "klaytn.AWSRequestLogger"
+
+// awsRequestLogger returns a handler which logs the request IDs of the AWS calls,
+// which are asked by AWS support to investigate the calls. The failed calls are
+// logged as warnings, and the other calls are logged at debug level if logAll
+// is true. It should be added to the Complete handlers, which are run once per
+// call after all retries.
+func awsRequestLogger(l log.Logger, logAll bool) request.NamedHandler {
+	return request.NamedHandler{
+		Name: awsRequestLoggerName,
+		Fn: func(r *request.Request) {
+			if r.Error == nil && !logAll {
+				return
+			}
+			ctx := []interface{}{"service", r.ClientInfo.ServiceName, "requestID", r.RequestID}
+			if r.Operation != nil {
+				ctx = append(ctx, "operation", r.Operation.Name)
+			}
+			// S3 identifies a call with the extended request ID as well
+			if r.HTTPResponse != nil {
+				if hostID := r.HTTPResponse.Header.Get("X-Amz-Id-2"); hostID != "" {
+					ctx = append(ctx, "hostID", hostID)
+				}
+			}
+			if r.Error != nil {
+				l.Warn("AWS request failed", append(ctx, "retryCount", r.RetryCount, "err", r.Error)...)
+			} else {
+				l.Debug("AWS request completed", ctx...)
+			}
+		},
+	}
+}
+
+// awsLogLevels are the names of the log levels of the AWS SDK, which are given
+// by AWSLogLevel of DynamoDBConfig.
+var awsLogLevels = map[string]aws.LogLevelType{
+	"":                             aws.LogOff,
+	"off":                          aws.LogOff,
+	"debug":                        aws.LogDebug,
+	"debug-with-signing":           aws.LogDebugWithSigning,
+	"debug-with-http-body":         aws.LogDebugWithHTTPBody,
+	"debug-with-request-retries":   aws.LogDebugWithRequestRetries,
+	"debug-with-request-errors":    aws.LogDebugWithRequestErrors,
+	"debug-with-event-stream-body": aws.LogDebugWithEventStreamBody,
+}
+
+// parseAWSLogLevel returns the log level of the AWS SDK with the given name.
+func parseAWSLogLevel(name string) (aws.LogLevelType, error) {
+	level, ok := awsLogLevels[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(awsLogLevels))
+		for n := range awsLogLevels {
+			if n != "" {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		return aws.LogOff, fmt.Errorf("unknown AWS log level %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return level, nil
+}
+
+// setAWSLogLevel enables the logs of the AWS SDK itself at the given level,
+// which are routed to the klaytn logger. The SDK doesn't log if it is LogOff.
+func setAWSLogLevel(conf *aws.Config, level aws.LogLevelType, l log.Logger) *aws.Config {
+	if level == aws.LogOff {
+		return conf
+	}
+	return conf.WithLogLevel(level).WithLogger(awsLogger(l))
+}
+
+// awsLogger returns a logger of the AWS SDK which forwards the log lines to the
+// klaytn logger. The lines are logged at info level, because they are only
+// written when AWSLogLevel is set explicitly.
+func awsLogger(l log.Logger) aws.Logger {
+	return aws.LoggerFunc(func(args ...interface{}) {
+		l.Info("AWS SDK", "msg", strings.TrimSpace(fmt.Sprint(args...)))
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAWSSession(t *testing.T, endpoint string, l *testLogger, logAll bool) *session.Session {
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("test", "test", ""),
+		S3ForcePathStyle: aws.Bool(true),
+		MaxRetries:       aws.Int(0),
+	})
+	assert.NoError(t, err)
+	sess.Handlers.Complete.PushBackNamed(awsRequestLogger(l, logAll))
+	return sess
+}
+
+func TestAWSRequestLogger_DynamoDB(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-Requestid", "dynamo-request-id")
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"Table":{"TableStatus":"ACTIVE"}}`)
+	}))
+	defer server.Close()
+
+	// only the failed calls are logged by default
+	l := &testLogger{Logger: logger}
+	client := dynamodb.New(newTestAWSSession(t, server.URL, l, false))
+	_, err := client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("table")})
+	assert.Error(t, err)
+
+	fail = false
+	_, err = client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("table")})
+	assert.NoError(t, err)
+
+	msgs := l.messages()
+	if assert.Len(t, msgs, 1) {
+		assert.Contains(t, msgs[0], "WARN: AWS request failed")
+		assert.Contains(t, msgs[0], "requestID dynamo-request-id")
+		assert.Contains(t, msgs[0], "operation DescribeTable")
+		assert.Contains(t, msgs[0], "ResourceNotFoundException")
+	}
+
+	// all calls are logged if logAll is set
+	l = &testLogger{Logger: logger}
+	client = dynamodb.New(newTestAWSSession(t, server.URL, l, true))
+	_, err = client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("table")})
+	assert.NoError(t, err)
+
+	msgs = l.messages()
+	if assert.Len(t, msgs, 1) {
+		assert.Contains(t, msgs[0], "DEBUG: AWS request completed")
+		assert.Contains(t, msgs[0], "requestID dynamo-request-id")
+	}
+}
+
+func TestAWSRequestLogger_S3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amz-Request-Id", "s3-request-id")
+		w.Header().Set("X-Amz-Id-2", "s3-host-id")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+	}))
+	defer server.Close()
+
+	l := &testLogger{Logger: logger}
+	client := s3.New(newTestAWSSession(t, server.URL, l, false))
+	_, err := client.PutObject(&s3.PutObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	assert.Error(t, err)
+
+	msgs := l.messages()
+	if assert.Len(t, msgs, 1) {
+		assert.Contains(t, msgs[0], "requestID s3-request-id")
+		assert.Contains(t, msgs[0], "hostID s3-host-id")
+		assert.Contains(t, msgs[0], "operation PutObject")
+	}
+}
+
+func TestParseAWSLogLevel(t *testing.T) {
+	for name, expected := range map[string]aws.LogLevelType{
+		"":                     aws.LogOff,
+		"off":                  aws.LogOff,
+		"debug":                aws.LogDebug,
+		"Debug-With-HTTP-Body": aws.LogDebugWithHTTPBody,
+	} {
+		level, err := parseAWSLogLevel(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, level, name)
+	}
+
+	_, err := parseAWSLogLevel("verbose")
+	assert.ErrorContains(t, err, `unknown AWS log level "verbose"`)
+}
+
+func TestSetAWSLogLevel(t *testing.T) {
+	// the config is left as it is if the log is off
+	conf := setAWSLogLevel(&aws.Config{}, aws.LogOff, logger)
+	assert.Nil(t, conf.LogLevel)
+	assert.Nil(t, conf.Logger)
+
+	l := &testLogger{Logger: logger}
+	conf = setAWSLogLevel(&aws.Config{}, aws.LogDebugWithHTTPBody, l)
+	assert.Equal(t, aws.LogDebugWithHTTPBody, conf.LogLevel.Value())
+
+	// the logs of the SDK are forwarded to the klaytn logger
+	conf.Logger.Log("DEBUG: Request dynamodb/DescribeTable Details:\n")
+	msgs := l.messages()
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "INFO: AWS SDK [msg DEBUG: Request dynamodb/DescribeTable Details:]", msgs[0])
+	}
+}
+
+func TestSetAWSLogLevel_Session(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Table":{"TableStatus":"ACTIVE"}}`)
+	}))
+	defer server.Close()
+
+	l := &testLogger{Logger: logger}
+	sess := newTestAWSSession(t, server.URL, &testLogger{Logger: logger}, false)
+	setAWSLogLevel(sess.Config, aws.LogDebugWithHTTPBody, l)
+	_, err := dynamodb.New(sess).DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String("table")})
+	assert.NoError(t, err)
+
+	msgs := l.messages()
+	if assert.NotEmpty(t, msgs) {
+		assert.Contains(t, msgs[0], "DescribeTable")
+	}
+}
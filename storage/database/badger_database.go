@@ -46,6 +46,10 @@ func getBadgerDBOptions(dbDir string) badger.Options {
 }
 
 func NewBadgerDB(dbDir string) (*badgerDB, error) {
+	return newBadgerDB(dbDir, false)
+}
+
+func newBadgerDB(dbDir string, readOnly bool) (*badgerDB, error) {
 	localLogger := logger.NewWith("dbDir", dbDir)
 
 	if fi, err := os.Stat(dbDir); err == nil {
@@ -61,6 +65,7 @@ func NewBadgerDB(dbDir string) (*badgerDB, error) {
 	}
 
 	opts := getBadgerDBOptions(dbDir)
+	opts.ReadOnly = readOnly
 	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make badgerDB while opening the DB. dbDir: %v, err: %v", dbDir, err)
@@ -179,7 +184,12 @@ func (bg *badgerDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return nil
 }
 
-func (bg *badgerDB) Close() {
+func (bg *badgerDB) NewIteratorWithRange(start, end []byte) Iterator {
+	logger.CritWithStack("badgerDB doesn't support NewIteratorWithRange")
+	return nil
+}
+
+func (bg *badgerDB) Close() error {
 	close(bg.closeCh)
 	err := bg.db.Close()
 	if err == nil {
@@ -187,6 +197,7 @@ func (bg *badgerDB) Close() {
 	} else {
 		bg.logger.Error("Failed to close database", "err", err)
 	}
+	return err
 }
 
 func (bg *badgerDB) LDB() *badger.DB {
@@ -198,6 +209,11 @@ func (bg *badgerDB) NewBatch() Batch {
 	return &badgerBatch{db: bg.db, txn: txn}
 }
 
+// NewBatchWithSize is the same as NewBatch since the size hint is not supported.
+func (bg *badgerDB) NewBatchWithSize(n int) Batch {
+	return bg.NewBatch()
+}
+
 func (bg *badgerDB) Meter(prefix string) {
 	logger.Warn("badgerDB does not support metrics!")
 }
@@ -24,8 +24,13 @@ package database
 // write.
 const IdealBatchSize = 100 * 1024
 
+// batchItemSizeHint is the estimated size of a key-value pair in bytes.
+// It is used to pre-allocate byte buffers of a batch from the number of items.
+const batchItemSizeHint = 128
+
 // Batch is a write-only database that commits changes to its host database
-// when Write is called. A Batch cannot be used concurrently.
+// when Write is called. A Batch must be used by a single goroutine at a time,
+// and it should be Reset after Write before it is modified again.
 type Batch interface {
 	KeyValueWriter
 
@@ -45,9 +50,34 @@ type Batch interface {
 	Replay(w KeyValueWriter) error
 }
 
+// AsyncBatch is a Batch which can be written without waiting for the write.
+type AsyncBatch interface {
+	Batch
+
+	// WriteAsync flushes the accumulated data like Write, but it returns without
+	// waiting for the data to be written. callback is called with the error of
+	// the write when it is done, and the callbacks are called in the order of
+	// the WriteAsync calls.
+	WriteAsync(callback func(error))
+}
+
+// WriteBatchAsync writes the batch asynchronously if it is an AsyncBatch.
+// Otherwise, it writes the batch synchronously and calls callback before it returns.
+func WriteBatchAsync(b Batch, callback func(error)) {
+	if ab, ok := b.(AsyncBatch); ok {
+		ab.WriteAsync(callback)
+		return
+	}
+	callback(b.Write())
+}
+
 // Batcher wraps the NewBatch method of a backing data store.
 type Batcher interface {
 	// NewBatch creates a write-only database that buffers changes to its host db
 	// until a final write is called.
 	NewBatch() Batch
+
+	// NewBatchWithSize creates a write-only database batch with pre-allocated
+	// buffers for about n items.
+	NewBatchWithSize(n int) Batch
 }
@@ -0,0 +1,211 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/klaytn/klaytn/common"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescingDB lets concurrent reads of the same key share one read of the
+// underlying database, and all of them receive its result. It is useful for
+// a remote database like DynamoDB, where every read costs.
+// The reads in flight are forgotten on every write of the key, so that the
+// reads after the write don't receive the value read before it.
+type coalescingDB struct {
+	Database
+	reads singleflight.Group
+}
+
+// NewCoalescingDatabase returns a database which coalesces concurrent reads of
+// the same key to db.
+func NewCoalescingDatabase(db Database) Database {
+	return &coalescingDB{Database: db}
+}
+
+// The reads of different kinds are not coalesced, because they can return
+// different results. They are distinguished by the first byte of the group key.
+const (
+	coalescedGet byte = iota
+	coalescedStrongGet
+	coalescedEventualGet
+	coalescedGetWithMeta
+)
+
+// coalescedRead is the result of a read shared by the coalesced callers.
+type coalescedRead struct {
+	val  []byte
+	meta ReadMeta
+}
+
+func (db *coalescingDB) Get(key []byte) ([]byte, error) {
+	val, _, err := db.read(coalescedGet, key, func() ([]byte, ReadMeta, error) {
+		val, err := db.Database.Get(key)
+		return val, ReadMeta{}, err
+	})
+	return val, err
+}
+
+// GetWithConsistency keeps the consistency of reads selectable if the
+// underlying database supports it.
+func (db *coalescingDB) GetWithConsistency(key []byte, strong bool) ([]byte, error) {
+	kind := coalescedEventualGet
+	if strong {
+		kind = coalescedStrongGet
+	}
+	val, _, err := db.read(kind, key, func() ([]byte, ReadMeta, error) {
+		val, err := GetWithConsistency(db.Database, key, strong)
+		return val, ReadMeta{}, err
+	})
+	return val, err
+}
+
+// GetContext is not coalesced, because the reads with different deadlines
+// cannot share one result.
+func (db *coalescingDB) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	return GetContext(ctx, db.Database, key)
+}
+
+// GetWithMeta returns the meta of the underlying database, which is shared by
+// the coalesced callers with the value.
+func (db *coalescingDB) GetWithMeta(key []byte) ([]byte, ReadMeta, error) {
+	return db.read(coalescedGetWithMeta, key, func() ([]byte, ReadMeta, error) {
+		return GetWithMeta(db.Database, key)
+	})
+}
+
+func (db *coalescingDB) Put(key []byte, value []byte) error {
+	defer db.forget(key)
+	return db.Database.Put(key, value)
+}
+
+func (db *coalescingDB) Delete(key []byte) error {
+	defer db.forget(key)
+	return db.Database.Delete(key)
+}
+
+func (db *coalescingDB) TransactWrite(items []KV) error {
+	defer func() {
+		for _, item := range items {
+			db.forget(item.Key)
+		}
+	}()
+	return TransactWrite(db.Database, items)
+}
+
+func (db *coalescingDB) PutContext(ctx context.Context, key []byte, value []byte) error {
+	defer db.forget(key)
+	return PutContext(ctx, db.Database, key, value)
+}
+
+func (db *coalescingDB) DeleteContext(ctx context.Context, key []byte) error {
+	defer db.forget(key)
+	return DeleteContext(ctx, db.Database, key)
+}
+
+func (db *coalescingDB) PutReturningOld(key []byte, value []byte) ([]byte, error) {
+	defer db.forget(key)
+	return PutReturningOld(db.Database, key, value)
+}
+
+// BulkLoad doesn't forget the reads in flight, since it loads a database
+// before the database is read.
+func (db *coalescingDB) BulkLoad(it Iterator, quit <-chan struct{}) (int, error) {
+	return BulkLoad(db.Database, it, quit)
+}
+
+func (db *coalescingDB) Flush() error {
+	return Flush(db.Database)
+}
+
+func (db *coalescingDB) NewBatch() Batch {
+	return &coalescingBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *coalescingDB) NewBatchWithSize(size int) Batch {
+	return &coalescingBatch{Batch: db.Database.NewBatchWithSize(size), db: db}
+}
+
+// forget makes the reads of the key after it not share the reads in flight.
+func (db *coalescingDB) forget(key []byte) {
+	groupKey := make([]byte, 1+len(key))
+	copy(groupKey[1:], key)
+	for _, kind := range []byte{coalescedGet, coalescedStrongGet, coalescedEventualGet, coalescedGetWithMeta} {
+		groupKey[0] = kind
+		db.reads.Forget(string(groupKey))
+	}
+}
+
+func (db *coalescingDB) read(kind byte, key []byte, fn func() ([]byte, ReadMeta, error)) ([]byte, ReadMeta, error) {
+	groupKey := make([]byte, 1+len(key))
+	groupKey[0] = kind
+	copy(groupKey[1:], key)
+
+	v, err, shared := db.reads.Do(string(groupKey), func() (interface{}, error) {
+		val, meta, err := fn()
+		return coalescedRead{val, meta}, err
+	})
+	read := v.(coalescedRead)
+	if shared && read.val != nil {
+		// the callers may modify the value they received
+		read.val = append([]byte{}, read.val...)
+	}
+	return read.val, read.meta, err
+}
+
+// coalescingBatch forgets the reads in flight of the written keys when it is written.
+type coalescingBatch struct {
+	Batch
+	db   *coalescingDB
+	keys [][]byte
+}
+
+func (b *coalescingBatch) Put(key []byte, value []byte) error {
+	b.keys = append(b.keys, common.CopyBytes(key))
+	return b.Batch.Put(key, value)
+}
+
+func (b *coalescingBatch) Delete(key []byte) error {
+	b.keys = append(b.keys, common.CopyBytes(key))
+	return b.Batch.Delete(key)
+}
+
+func (b *coalescingBatch) Write() error {
+	defer b.forget(b.keys)
+	return b.Batch.Write()
+}
+
+func (b *coalescingBatch) WriteAsync(callback func(error)) {
+	keys := b.keys
+	WriteBatchAsync(b.Batch, func(err error) {
+		b.forget(keys)
+		callback(err)
+	})
+}
+
+func (b *coalescingBatch) Reset() {
+	b.Batch.Reset()
+	b.keys = nil
+}
+
+func (b *coalescingBatch) forget(keys [][]byte) {
+	for _, key := range keys {
+		b.db.forget(key)
+	}
+}
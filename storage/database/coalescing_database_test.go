@@ -0,0 +1,149 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDB counts the reads, which are blocked until release is closed.
+type countingDB struct {
+	Database
+	gets    int32
+	release chan struct{}
+}
+
+func (db *countingDB) Get(key []byte) ([]byte, error) {
+	atomic.AddInt32(&db.gets, 1)
+	<-db.release
+	return db.Database.Get(key)
+}
+
+func TestCoalescingDB_Get(t *testing.T) {
+	backend := &countingDB{Database: NewMemDB(), release: make(chan struct{})}
+	assert.NoError(t, backend.Put([]byte("hot"), []byte("val")))
+	db := NewCoalescingDatabase(backend)
+
+	const numReaders = 100
+	var (
+		started, done sync.WaitGroup
+		vals          = make([][]byte, numReaders)
+		errs          = make([]error, numReaders)
+	)
+	started.Add(numReaders)
+	done.Add(numReaders)
+	for i := 0; i < numReaders; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			vals[i], errs[i] = db.Get([]byte("hot"))
+		}(i)
+	}
+	started.Wait()
+	// let all readers wait for the read in flight
+	time.Sleep(100 * time.Millisecond)
+	close(backend.release)
+	done.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&backend.gets))
+	for i := 0; i < numReaders; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, []byte("val"), vals[i])
+	}
+
+	// the values received are not shared
+	vals[0][0] = 'x'
+	assert.Equal(t, []byte("val"), vals[1])
+
+	// the reads after the read in flight are not coalesced with it
+	val, err := db.Get([]byte("hot"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val"), val)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&backend.gets))
+
+	_, err = db.Get([]byte("missing"))
+	assert.Equal(t, dataNotFoundErr, err)
+}
+
+func TestCoalescingDB_ForgetOnWrite(t *testing.T) {
+	writes := []struct {
+		name  string
+		write func(db Database, key, val []byte) error
+	}{
+		{"Put", func(db Database, key, val []byte) error { return db.Put(key, val) }},
+		{"Delete", func(db Database, key, val []byte) error { return db.Delete(key) }},
+		{"PutContext", func(db Database, key, val []byte) error { return PutContext(context.Background(), db, key, val) }},
+		{"DeleteContext", func(db Database, key, val []byte) error { return DeleteContext(context.Background(), db, key) }},
+		{"Batch", func(db Database, key, val []byte) error {
+			batch := db.NewBatch()
+			if err := batch.Put(key, val); err != nil {
+				return err
+			}
+			return batch.Write()
+		}},
+		{"WriteAsync", func(db Database, key, val []byte) error {
+			batch := db.NewBatch()
+			if err := batch.Put(key, val); err != nil {
+				return err
+			}
+			errCh := make(chan error, 1)
+			WriteBatchAsync(batch, func(err error) { errCh <- err })
+			return <-errCh
+		}},
+	}
+	for _, tt := range writes {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &countingDB{Database: NewMemDB(), release: make(chan struct{})}
+			assert.NoError(t, backend.Put([]byte("key"), []byte("old")))
+			db := NewCoalescingDatabase(backend)
+
+			// a read is in flight before the write
+			before := make(chan []byte, 1)
+			go func() {
+				val, _ := db.Get([]byte("key"))
+				before <- val
+			}()
+			for atomic.LoadInt32(&backend.gets) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			assert.NoError(t, tt.write(db, []byte("key"), []byte("new")))
+
+			// the read after the write doesn't share the read in flight
+			after := make(chan []byte, 1)
+			go func() {
+				val, _ := db.Get([]byte("key"))
+				after <- val
+			}()
+			assert.Eventually(t, func() bool { return atomic.LoadInt32(&backend.gets) == 2 }, time.Second, time.Millisecond,
+				"the read after the write shares the read in flight")
+			close(backend.release)
+			<-before
+			assert.Equal(t, int32(2), atomic.LoadInt32(&backend.gets))
+			if tt.name == "Delete" || tt.name == "DeleteContext" {
+				assert.Nil(t, <-after)
+			} else {
+				assert.Equal(t, []byte("new"), <-after)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+)
+
+// The ids of the compression codecs, which are prepended to the stored values.
+const (
+	CompressionNone   byte = 0
+	CompressionSnappy byte = 1
+	CompressionGzip   byte = 2
+)
+
+var (
+	errUnknownCompressionCodec = errors.New("unknown compression codec")
+	errDecompressValue         = errors.New("failed to decompress value")
+)
+
+// CompressionCodec compresses the values stored with its id, and decompresses
+// them back.
+type CompressionCodec struct {
+	Name       string
+	Compress   func(val []byte) ([]byte, error)
+	Decompress func(val []byte) ([]byte, error)
+}
+
+var (
+	compressionCodecsMu sync.RWMutex
+	compressionCodecs   = map[byte]CompressionCodec{
+		CompressionSnappy: {
+			Name:       "snappy",
+			Compress:   func(val []byte) ([]byte, error) { return snappy.Encode(nil, val), nil },
+			Decompress: func(val []byte) ([]byte, error) { return snappy.Decode(nil, val) },
+		},
+		CompressionGzip: {
+			Name:       "gzip",
+			Compress:   gzipCompress,
+			Decompress: gzipDecompress,
+		},
+	}
+)
+
+// RegisterCompressionCodec registers a codec with the given id. The id is
+// stored with the values, so it should not be reused for another codec while
+// the values compressed by the codec remain. The id 0 is reserved for the
+// values stored without compression.
+func RegisterCompressionCodec(id byte, codec CompressionCodec) error {
+	if id == CompressionNone {
+		return fmt.Errorf("the compression codec id %d is reserved", id)
+	}
+	if codec.Compress == nil || codec.Decompress == nil {
+		return fmt.Errorf("the compression codec %q should compress and decompress values", codec.Name)
+	}
+	compressionCodecsMu.Lock()
+	defer compressionCodecsMu.Unlock()
+	if registered, exist := compressionCodecs[id]; exist {
+		return fmt.Errorf("the compression codec id %d is already registered by %q", id, registered.Name)
+	}
+	compressionCodecs[id] = codec
+	return nil
+}
+
+// CompressionCodecID returns the id of the codec with the given name.
+func CompressionCodecID(name string) (byte, error) {
+	if name == "" || name == "none" {
+		return CompressionNone, nil
+	}
+	compressionCodecsMu.RLock()
+	defer compressionCodecsMu.RUnlock()
+	for id, codec := range compressionCodecs {
+		if codec.Name == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", errUnknownCompressionCodec, name)
+}
+
+func compressionCodec(id byte) (CompressionCodec, error) {
+	compressionCodecsMu.RLock()
+	defer compressionCodecsMu.RUnlock()
+	codec, exist := compressionCodecs[id]
+	if !exist {
+		return CompressionCodec{}, fmt.Errorf("%w: id %d", errUnknownCompressionCodec, id)
+	}
+	return codec, nil
+}
+
+// compressValue returns the value compressed by the codec of the id, prefixed
+// with the id.
+func compressValue(id byte, val []byte) ([]byte, error) {
+	if id == CompressionNone {
+		return append([]byte{CompressionNone}, val...), nil
+	}
+	codec, err := compressionCodec(id)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := codec.Compress(val)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{id}, compressed...), nil
+}
+
+// decompressValue returns the original value of a value returned by
+// compressValue, with any of the registered codecs.
+func decompressValue(val []byte) ([]byte, error) {
+	if len(val) == 0 {
+		return nil, fmt.Errorf("%w: no codec id", errDecompressValue)
+	}
+	if val[0] == CompressionNone {
+		return val[1:], nil
+	}
+	codec, err := compressionCodec(val[0])
+	if err != nil {
+		return nil, err
+	}
+	dec, err := codec.Decompress(val[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errDecompressValue, codec.Name, err)
+	}
+	return dec, nil
+}
+
+func gzipDecompress(val []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(val))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	return io.ReadAll(gr)
+}
+
+// compressedDB compresses the values larger than a threshold with a codec
+// before they are written to the underlying database. Every value is stored
+// with the id of its codec, or CompressionNone, so the values are decompressed
+// by their own codecs when they are read, and the values written before the
+// codec is changed remain readable. Since every stored value should carry an
+// id, it should wrap a database whose values are all written through it.
+type compressedDB struct {
+	Database
+	codec     byte
+	threshold int
+}
+
+// NewCompressedDatabase returns a database which compresses the values larger
+// than threshold bytes with the codec of the given id.
+func NewCompressedDatabase(db Database, codec byte, threshold int) (Database, error) {
+	if codec != CompressionNone {
+		if _, err := compressionCodec(codec); err != nil {
+			return nil, err
+		}
+	}
+	return &compressedDB{Database: db, codec: codec, threshold: threshold}, nil
+}
+
+// compress returns the value to be stored.
+func (db *compressedDB) compress(value []byte) ([]byte, error) {
+	if len(value) <= db.threshold {
+		return compressValue(CompressionNone, value)
+	}
+	return compressValue(db.codec, value)
+}
+
+func (db *compressedDB) Put(key []byte, value []byte) error {
+	comp, err := db.compress(value)
+	if err != nil {
+		return err
+	}
+	return db.Database.Put(key, comp)
+}
+
+func (db *compressedDB) PutContext(ctx context.Context, key []byte, value []byte) error {
+	comp, err := db.compress(value)
+	if err != nil {
+		return err
+	}
+	return PutContext(ctx, db.Database, key, comp)
+}
+
+func (db *compressedDB) DeleteContext(ctx context.Context, key []byte) error {
+	return DeleteContext(ctx, db.Database, key)
+}
+
+func (db *compressedDB) Get(key []byte) ([]byte, error) {
+	val, err := db.Database.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return decompressValue(val)
+}
+
+func (db *compressedDB) GetWithConsistency(key []byte, strong bool) ([]byte, error) {
+	val, err := GetWithConsistency(db.Database, key, strong)
+	if err != nil {
+		return nil, err
+	}
+	return decompressValue(val)
+}
+
+func (db *compressedDB) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	val, err := GetContext(ctx, db.Database, key)
+	if err != nil {
+		return nil, err
+	}
+	return decompressValue(val)
+}
+
+func (db *compressedDB) GetWithMeta(key []byte) ([]byte, ReadMeta, error) {
+	val, meta, err := GetWithMeta(db.Database, key)
+	if err != nil {
+		return nil, meta, err
+	}
+	val, err = decompressValue(val)
+	return val, meta, err
+}
+
+func (db *compressedDB) TransactWrite(items []KV) error {
+	compressed := make([]KV, len(items))
+	for i, item := range items {
+		comp, err := db.compress(item.Value)
+		if err != nil {
+			return err
+		}
+		compressed[i] = KV{Key: item.Key, Value: comp, IfAbsent: item.IfAbsent}
+	}
+	return TransactWrite(db.Database, compressed)
+}
+
+func (db *compressedDB) PutReturningOld(key []byte, value []byte) ([]byte, error) {
+	comp, err := db.compress(value)
+	if err != nil {
+		return nil, err
+	}
+	old, err := PutReturningOld(db.Database, key, comp)
+	if err != nil || old == nil {
+		return nil, err
+	}
+	return decompressValue(old)
+}
+
+func (db *compressedDB) Flush() error {
+	return Flush(db.Database)
+}
+
+func (db *compressedDB) NewBatch() Batch {
+	return &compressedBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *compressedDB) NewBatchWithSize(n int) Batch {
+	return &compressedBatch{Batch: db.Database.NewBatchWithSize(n), db: db}
+}
+
+func (db *compressedDB) NewIterator(prefix []byte, start []byte) Iterator {
+	it := db.Database.NewIterator(prefix, start)
+	if it == nil {
+		return nil
+	}
+	return &compressedIterator{Iterator: it}
+}
+
+func (db *compressedDB) NewIteratorWithRange(start, end []byte) Iterator {
+	it := db.Database.NewIteratorWithRange(start, end)
+	if it == nil {
+		return nil
+	}
+	return &compressedIterator{Iterator: it}
+}
+
+// compressedBatch compresses the values written like its database.
+type compressedBatch struct {
+	Batch
+	db *compressedDB
+}
+
+func (b *compressedBatch) Put(key, value []byte) error {
+	comp, err := b.db.compress(value)
+	if err != nil {
+		return err
+	}
+	return b.Batch.Put(key, comp)
+}
+
+// Replay replays the batch contents with the decompressed values.
+func (b *compressedBatch) Replay(w KeyValueWriter) error {
+	return b.Batch.Replay(&valueDecompressor{w: w})
+}
+
+// valueDecompressor decompresses the values written to w.
+type valueDecompressor struct {
+	w KeyValueWriter
+}
+
+func (d *valueDecompressor) Put(key []byte, value []byte) error {
+	dec, err := decompressValue(value)
+	if err != nil {
+		return err
+	}
+	return d.w.Put(key, dec)
+}
+
+func (d *valueDecompressor) Delete(key []byte) error {
+	return d.w.Delete(key)
+}
+
+// compressedIterator presents the decompressed values of the underlying
+// iterator. A value failed to be decompressed is presented as nil, and the
+// failure is returned by Error.
+type compressedIterator struct {
+	Iterator
+	err error
+}
+
+func (it *compressedIterator) Value() []byte {
+	if it.Iterator.Value() == nil {
+		return nil
+	}
+	val, err := decompressValue(it.Iterator.Value())
+	if err != nil {
+		if it.err == nil {
+			it.err = err
+		}
+		return nil
+	}
+	return val
+}
+
+func (it *compressedIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressedDB_Codecs(t *testing.T) {
+	memDB := NewMemDB()
+	val := bytes.Repeat([]byte("compressible value "), 100)
+	small := []byte("small")
+
+	// the values are written with each codec to the same database
+	codecs := map[string]byte{"none": CompressionNone, "snappy": CompressionSnappy, "gzip": CompressionGzip}
+	dbs := make(map[string]Database)
+	for name, id := range codecs {
+		db, err := NewCompressedDatabase(memDB, id, 64)
+		assert.NoError(t, err)
+		dbs[name] = db
+
+		assert.NoError(t, db.Put([]byte(name), val))
+		assert.NoError(t, db.Put([]byte(name+"-small"), small))
+
+		batch := db.NewBatch()
+		assert.NoError(t, batch.Put([]byte(name+"-batch"), val))
+		assert.NoError(t, batch.Write())
+
+		// the large values are stored with the codec, the small ones without
+		stored, _ := memDB.Get([]byte(name))
+		assert.Equal(t, id, stored[0])
+		if id != CompressionNone {
+			assert.Less(t, len(stored), len(val))
+		}
+		stored, _ = memDB.Get([]byte(name + "-small"))
+		assert.Equal(t, append([]byte{CompressionNone}, small...), stored)
+	}
+
+	// all the values are read back by any of the databases
+	for _, db := range dbs {
+		for name := range codecs {
+			for _, key := range []string{name, name + "-batch"} {
+				got, err := db.Get([]byte(key))
+				assert.NoError(t, err)
+				assert.Equal(t, val, got, key)
+			}
+			got, err := db.Get([]byte(name + "-small"))
+			assert.NoError(t, err)
+			assert.Equal(t, small, got)
+		}
+
+		it := db.NewIterator(nil, nil)
+		n := 0
+		for it.Next() {
+			if strings.HasSuffix(string(it.Key()), "-small") {
+				assert.Equal(t, small, it.Value())
+			} else {
+				assert.Equal(t, val, it.Value())
+			}
+			n++
+		}
+		assert.NoError(t, it.Error())
+		assert.Equal(t, 9, n)
+		it.Release()
+	}
+}
+
+func TestCompressedDB_Replay(t *testing.T) {
+	db, err := NewCompressedDatabase(NewMemDB(), CompressionSnappy, 0)
+	assert.NoError(t, err)
+	batch := db.NewBatch()
+	assert.NoError(t, batch.Put([]byte("key"), []byte("value")))
+	assert.NoError(t, batch.Delete([]byte("deleted")))
+
+	replayed := NewMemDB()
+	assert.NoError(t, batch.Replay(replayed))
+	got, err := replayed.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), got)
+}
+
+func TestCompressedDB_UnknownCodec(t *testing.T) {
+	memDB := NewMemDB()
+	db, err := NewCompressedDatabase(memDB, CompressionNone, 0)
+	assert.NoError(t, err)
+
+	assert.NoError(t, memDB.Put([]byte("unknown"), []byte{200, 1, 2, 3}))
+	assert.NoError(t, memDB.Put([]byte("corrupt"), []byte{CompressionGzip, 1, 2, 3}))
+	_, err = db.Get([]byte("unknown"))
+	assert.ErrorIs(t, err, errUnknownCompressionCodec)
+	_, err = db.Get([]byte("corrupt"))
+	assert.ErrorIs(t, err, errDecompressValue)
+
+	_, err = NewCompressedDatabase(memDB, 200, 0)
+	assert.ErrorIs(t, err, errUnknownCompressionCodec)
+	_, err = CompressionCodecID("lz4")
+	assert.ErrorIs(t, err, errUnknownCompressionCodec)
+}
+
+func TestRegisterCompressionCodec(t *testing.T) {
+	reverse := func(val []byte) ([]byte, error) {
+		rev := make([]byte, len(val))
+		for i, b := range val {
+			rev[len(val)-1-i] = b
+		}
+		return rev, nil
+	}
+	const id = 250
+	assert.NoError(t, RegisterCompressionCodec(id, CompressionCodec{Name: "reverse", Compress: reverse, Decompress: reverse}))
+	defer func() {
+		compressionCodecsMu.Lock()
+		delete(compressionCodecs, id)
+		compressionCodecsMu.Unlock()
+	}()
+
+	codec, err := CompressionCodecID("reverse")
+	assert.NoError(t, err)
+	assert.Equal(t, byte(id), codec)
+
+	memDB := NewMemDB()
+	db, err := NewCompressedDatabase(memDB, codec, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Put([]byte("key"), []byte("abc")))
+	stored, _ := memDB.Get([]byte("key"))
+	assert.Equal(t, []byte{id, 'c', 'b', 'a'}, stored)
+	got, err := db.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("abc"), got)
+
+	// the reserved and the registered ids
+	assert.Error(t, RegisterCompressionCodec(CompressionNone, CompressionCodec{Name: "none", Compress: reverse, Decompress: reverse}))
+	assert.Error(t, RegisterCompressionCodec(CompressionGzip, CompressionCodec{Name: "gzip2", Compress: reverse, Decompress: reverse}))
+	assert.Error(t, RegisterCompressionCodec(251, CompressionCodec{Name: "incomplete"}))
+}
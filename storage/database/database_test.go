@@ -618,3 +618,46 @@ func (ts *commonDatabaseTestSuite) Test_BatchWrite() {
 		assert.Equal(ts.T(), d.v, actual)
 	}
 }
+
+func (ts *commonDatabaseTestSuite) Test_NewBatchWithSize() {
+	batch := ts.database.NewBatchWithSize(100)
+	defer batch.Release()
+
+	testData, err := insertRandomData(batch, []byte("prefix"), 100)
+	ts.NoError(err)
+	ts.NoError(batch.Write())
+
+	for _, data := range testData {
+		val, err := ts.database.Get(data.k)
+		ts.NoError(err)
+		ts.Equal(data.v, val)
+	}
+}
+
+func benchmarkBatchPut(b *testing.B, newDB func() (Database, func(), string), withSize bool) {
+	db, remove, _ := newDB()
+	defer remove()
+
+	const numItems = 10000
+	key, val := make([]byte, 32), make([]byte, 100)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var batch Batch
+		if withSize {
+			batch = db.NewBatchWithSize(numItems)
+		} else {
+			batch = db.NewBatch()
+		}
+		for j := 0; j < numItems; j++ {
+			batch.Put(key, val)
+		}
+		batch.Release()
+	}
+}
+
+func BenchmarkMemDB_NewBatch(b *testing.B)         { benchmarkBatchPut(b, newTestMemDB, false) }
+func BenchmarkMemDB_NewBatchWithSize(b *testing.B) { benchmarkBatchPut(b, newTestMemDB, true) }
+func BenchmarkLDB_NewBatch(b *testing.B)           { benchmarkBatchPut(b, newTestLDB, false) }
+func BenchmarkLDB_NewBatchWithSize(b *testing.B)   { benchmarkBatchPut(b, newTestLDB, true) }
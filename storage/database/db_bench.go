@@ -0,0 +1,227 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dbBenchKeyPrefix is the prefix of the keys written by RunDBBench.
+var dbBenchKeyPrefix = []byte("db-bench-")
+
+var errDBBenchValueMismatch = errors.New("the value read is different from the written one")
+
+// DBBenchConfig is the workload of RunDBBench.
+type DBBenchConfig struct {
+	Duration      time.Duration // how long the operations are performed
+	Concurrency   int           // the number of concurrent workers
+	ReadRatio     float64       // the ratio of Get among all operations
+	ValueSize     int           // the size of the values written by Put
+	OversizedSize int           // the size of the oversized values, which are larger than a single item of the database
+	OversizedRate float64       // the ratio of oversized values among the values written by Put
+}
+
+func (c *DBBenchConfig) validate() error {
+	switch {
+	case c.Duration <= 0:
+		return fmt.Errorf("duration must be positive: %v", c.Duration)
+	case c.Concurrency <= 0:
+		return fmt.Errorf("concurrency must be positive: %d", c.Concurrency)
+	case c.ReadRatio < 0 || c.ReadRatio > 1:
+		return fmt.Errorf("read ratio must be between 0 and 1: %v", c.ReadRatio)
+	case c.OversizedRate < 0 || c.OversizedRate > 1:
+		return fmt.Errorf("oversized rate must be between 0 and 1: %v", c.OversizedRate)
+	case c.ValueSize < 0 || c.OversizedSize < 0:
+		return fmt.Errorf("value sizes must not be negative: %d, %d", c.ValueSize, c.OversizedSize)
+	}
+	return nil
+}
+
+// DBBenchLatency is the latency distribution of an operation.
+// The latencies are in nanoseconds.
+type DBBenchLatency struct {
+	Count  int           `json:"count"`
+	Errors int           `json:"errors"`
+	Min    time.Duration `json:"min"`
+	Mean   time.Duration `json:"mean"`
+	P50    time.Duration `json:"p50"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// DBBenchResult is the result of RunDBBench.
+type DBBenchResult struct {
+	Elapsed    time.Duration `json:"elapsed"`
+	Ops        int           `json:"ops"`
+	Errors     int           `json:"errors"`
+	ErrorRate  float64       `json:"errorRate"`
+	Throughput float64       `json:"throughput"` // operations per second
+
+	Get          DBBenchLatency `json:"get"`
+	Put          DBBenchLatency `json:"put"`
+	PutOversized DBBenchLatency `json:"putOversized"`
+}
+
+type dbBenchOp int
+
+const (
+	dbBenchGet dbBenchOp = iota
+	dbBenchPut
+	dbBenchPutOversized
+	dbBenchNumOps
+)
+
+// dbBenchSample holds the latencies of an operation measured by a worker.
+type dbBenchSample struct {
+	latencies []time.Duration
+	errors    int
+}
+
+// RunDBBench performs a mix of Get and Put on the database for the duration of
+// the config, and returns the latency distribution of each operation.
+// Get reads one of the keys written by the benchmark, so the first operations
+// are Put until a key is written. The written keys are not deleted, so the
+// benchmark should be run on a database which is not in service.
+func RunDBBench(db Database, config DBBenchConfig) (*DBBenchResult, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		writtenMu sync.RWMutex
+		written   []uint64 // the indices of the keys successfully written
+		next      uint64   // the index of the next key to be written
+
+		samples  = make([][dbBenchNumOps]dbBenchSample, config.Concurrency)
+		wg       sync.WaitGroup
+		start    = time.Now()
+		deadline = start.Add(config.Duration)
+	)
+	for i := 0; i < config.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(time.Now().UnixNano() + int64(worker)))
+			s := &samples[worker]
+
+			for time.Now().Before(deadline) {
+				op, idx := dbBenchPut, uint64(0)
+				writtenMu.RLock()
+				if len(written) > 0 && rnd.Float64() < config.ReadRatio {
+					op, idx = dbBenchGet, written[rnd.Intn(len(written))]
+				}
+				writtenMu.RUnlock()
+				if op != dbBenchGet {
+					idx = atomic.AddUint64(&next, 1) - 1
+					if rnd.Float64() < config.OversizedRate {
+						op = dbBenchPutOversized
+					}
+				}
+
+				key := dbBenchKey(idx)
+				opStart := time.Now()
+				var err error
+				if op == dbBenchGet {
+					var val []byte
+					if val, err = db.Get(key); err == nil && !bytes.Equal(val, dbBenchValue(key, len(val))) {
+						err = errDBBenchValueMismatch
+					}
+				} else {
+					size := config.ValueSize
+					if op == dbBenchPutOversized {
+						size = config.OversizedSize
+					}
+					err = db.Put(key, dbBenchValue(key, size))
+				}
+				s[op].latencies = append(s[op].latencies, time.Since(opStart))
+				if err != nil {
+					s[op].errors++
+					continue
+				}
+				if op != dbBenchGet {
+					writtenMu.Lock()
+					written = append(written, idx)
+					writtenMu.Unlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	result := &DBBenchResult{Elapsed: time.Since(start)}
+	for op, latency := range []*DBBenchLatency{&result.Get, &result.Put, &result.PutOversized} {
+		var merged dbBenchSample
+		for _, s := range samples {
+			merged.latencies = append(merged.latencies, s[op].latencies...)
+			merged.errors += s[op].errors
+		}
+		*latency = merged.distribution()
+		result.Ops += latency.Count
+		result.Errors += latency.Errors
+	}
+	if result.Ops > 0 {
+		result.ErrorRate = float64(result.Errors) / float64(result.Ops)
+	}
+	result.Throughput = float64(result.Ops) / result.Elapsed.Seconds()
+	return result, nil
+}
+
+func (s *dbBenchSample) distribution() DBBenchLatency {
+	d := DBBenchLatency{Count: len(s.latencies), Errors: s.errors}
+	if d.Count == 0 {
+		return d
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+
+	var sum time.Duration
+	for _, l := range s.latencies {
+		sum += l
+	}
+	percentile := func(p float64) time.Duration {
+		return s.latencies[int(p*float64(d.Count-1))]
+	}
+	d.Min, d.Max = s.latencies[0], s.latencies[d.Count-1]
+	d.Mean = sum / time.Duration(d.Count)
+	d.P50, d.P95, d.P99 = percentile(0.50), percentile(0.95), percentile(0.99)
+	return d
+}
+
+func dbBenchKey(idx uint64) []byte {
+	key := make([]byte, len(dbBenchKeyPrefix)+8)
+	copy(key, dbBenchKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(dbBenchKeyPrefix):], idx)
+	return key
+}
+
+// dbBenchValue returns a value of the given size derived from the key, which
+// lets Get verify the value without remembering it.
+func dbBenchValue(key []byte, size int) []byte {
+	val := make([]byte, size)
+	for i := 0; i < size; i += len(key) {
+		copy(val[i:], key)
+	}
+	return val
+}
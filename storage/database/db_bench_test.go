@@ -0,0 +1,95 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingPutDB fails every Put.
+type failingPutDB struct {
+	*MemDB
+}
+
+func (db failingPutDB) Put(key, val []byte) error {
+	return errors.New("put failure")
+}
+
+func TestRunDBBench(t *testing.T) {
+	config := DBBenchConfig{
+		Duration:      50 * time.Millisecond,
+		Concurrency:   4,
+		ReadRatio:     0.5,
+		ValueSize:     100,
+		OversizedSize: 1000,
+		OversizedRate: 0.1,
+	}
+	db := NewMemDB()
+	result, err := RunDBBench(db, config)
+	assert.NoError(t, err)
+
+	assert.Greater(t, result.Get.Count, 0)
+	assert.Greater(t, result.Put.Count, 0)
+	assert.Greater(t, result.PutOversized.Count, 0)
+	assert.Equal(t, result.Get.Count+result.Put.Count+result.PutOversized.Count, result.Ops)
+	assert.Equal(t, result.Put.Count+result.PutOversized.Count, db.Len())
+	assert.Zero(t, result.Errors)
+	assert.Greater(t, result.Throughput, 0.0)
+	for _, l := range []DBBenchLatency{result.Get, result.Put, result.PutOversized} {
+		assert.True(t, l.Min <= l.P50 && l.P50 <= l.P95 && l.P95 <= l.P99 && l.P99 <= l.Max)
+	}
+
+	// the result is machine-parseable
+	encoded, err := json.Marshal(result)
+	assert.NoError(t, err)
+	var decoded DBBenchResult
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, *result, decoded)
+}
+
+func TestRunDBBench_Errors(t *testing.T) {
+	config := DBBenchConfig{Duration: 10 * time.Millisecond, Concurrency: 2, ReadRatio: 0.5, ValueSize: 10}
+	result, err := RunDBBench(failingPutDB{NewMemDB()}, config)
+	assert.NoError(t, err)
+
+	// nothing is read since no key is written
+	assert.Zero(t, result.Get.Count)
+	assert.Greater(t, result.Put.Count, 0)
+	assert.Equal(t, result.Put.Count, result.Errors)
+	assert.Equal(t, 1.0, result.ErrorRate)
+}
+
+func TestRunDBBench_InvalidConfig(t *testing.T) {
+	valid := DBBenchConfig{Duration: time.Second, Concurrency: 1, ReadRatio: 0.5, ValueSize: 10}
+	for _, modify := range []func(c *DBBenchConfig){
+		func(c *DBBenchConfig) { c.Duration = 0 },
+		func(c *DBBenchConfig) { c.Concurrency = 0 },
+		func(c *DBBenchConfig) { c.ReadRatio = 1.5 },
+		func(c *DBBenchConfig) { c.OversizedRate = -0.1 },
+		func(c *DBBenchConfig) { c.ValueSize = -1 },
+	} {
+		config := valid
+		modify(&config)
+		_, err := RunDBBench(NewMemDB(), config)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errIterationNotSupported = errors.New("the database does not support iteration")
+
+// ErrDiffInterrupted is returned if the comparison of DBs is interrupted, so
+// the differences found until then are not all the differences.
+var ErrDiffInterrupted = errors.New("the comparison of DBs is interrupted")
+
+// DBDiff is a key which is different between two DBs. The value of the DB
+// missing the key is nil.
+type DBDiff struct {
+	Key    []byte
+	AValue []byte
+	BValue []byte
+}
+
+// diffDB compares two DBs and calls fn for each key which is in only one of
+// them or has different values. Both DBs are iterated in the key order and
+// merge-joined, so only the current items are held in memory. The migration
+// cursors are not compared. It returns the number of the compared keys, and
+// ErrDiffInterrupted if quit is closed before all the keys are compared.
+func diffDB(name string, a, b Database, fn func(DBDiff) error, quit chan struct{}) (int, error) {
+	aIter, bIter := a.NewIterator(nil, nil), b.NewIterator(nil, nil)
+	if aIter == nil || bIter == nil {
+		return 0, errIterationNotSupported
+	}
+	defer aIter.Release()
+	defer bIter.Release()
+
+	// next moves the iterator to the next key which is not a migration cursor
+	next := func(it Iterator) bool {
+		for it.Next() {
+			if !isMigrationCursorKey(it.Key()) {
+				return true
+			}
+		}
+		return false
+	}
+	// copy the bytes since they are only valid until the next call to Next
+	clone := func(b []byte) []byte {
+		return append([]byte{}, b...)
+	}
+
+	start := time.Now()
+	compared := 0
+	aOk, bOk := next(aIter), next(bIter)
+	for ; aOk || bOk; compared++ {
+		cmp := 0
+		switch {
+		case !bOk:
+			cmp = -1
+		case !aOk:
+			cmp = 1
+		default:
+			cmp = bytes.Compare(aIter.Key(), bIter.Key())
+		}
+
+		var diff *DBDiff
+		switch {
+		case cmp < 0:
+			diff = &DBDiff{Key: clone(aIter.Key()), AValue: clone(aIter.Value())}
+			aOk = next(aIter)
+		case cmp > 0:
+			diff = &DBDiff{Key: clone(bIter.Key()), BValue: clone(bIter.Value())}
+			bOk = next(bIter)
+		default:
+			if !bytes.Equal(aIter.Value(), bIter.Value()) {
+				diff = &DBDiff{Key: clone(aIter.Key()), AValue: clone(aIter.Value()), BValue: clone(bIter.Value())}
+			}
+			aOk, bOk = next(aIter), next(bIter)
+		}
+		if diff != nil {
+			if err := fn(*diff); err != nil {
+				return compared, err
+			}
+		}
+
+		if compared%reportCycle == 0 {
+			logger.Info("DB compared", "db", name, "comparedTotal", compared, "elapsedTotal", time.Since(start))
+		}
+
+		select {
+		case <-quit:
+			logger.Warn("exit called", "db", name, "comparedTotal", compared, "elapsedTotal", time.Since(start))
+			return compared, ErrDiffInterrupted
+		default:
+		}
+	}
+
+	if err := aIter.Error(); err != nil {
+		return compared, errors.WithMessage(err, "failed to iterate")
+	}
+	if err := bIter.Error(); err != nil {
+		return compared, errors.WithMessage(err, "failed to iterate")
+	}
+	logger.Info("Finish DB comparison", "db", name, "comparedTotal", compared, "elapsedTotal", time.Since(start))
+	return compared, nil
+}
+
+// DiffDB compares the DBs with the DBs of another DBManager and calls fn for each
+// different key with the name of the DB. The DBs are compared one by one, so fn
+// does not need to be thread-safe. Both DBManagers should be single DB or not.
+// It returns ErrDiffInterrupted if the process is interrupted before all the
+// DBs are compared.
+func (dbm *databaseManager) DiffDB(other DBManager, fn func(string, DBDiff) error) error {
+	if dbm.config.SingleDB != other.GetDBConfig().SingleDB {
+		return errors.New("single DB can only be compared with single DB")
+	}
+	quit := newQuitChannel()
+
+	if dbm.config.SingleDB {
+		_, err := diffDB("single", dbm.getDatabase(0), other.getDatabase(0), func(diff DBDiff) error {
+			return fn("single", diff)
+		}, quit)
+		return err
+	}
+
+	for et := MiscDB; et < databaseEntryTypeSize; et++ {
+		name := dbBaseDirs[et]
+		a, b := dbm.getDatabase(et), other.getDatabase(et)
+		if a == nil || b == nil {
+			logger.Warn("skip nil db", "db", name)
+			continue
+		}
+		if _, err := diffDB(name, a, b, func(diff DBDiff) error {
+			return fn(name, diff)
+		}, quit); err != nil {
+			return errors.WithMessagef(err, "failed to compare %s", name)
+		}
+	}
+	return nil
+}
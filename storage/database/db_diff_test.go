@@ -0,0 +1,109 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// noIterDB is a DB without an iterator like dynamoDB.
+type noIterDB struct {
+	*MemDB
+}
+
+func (db *noIterDB) NewIterator(prefix []byte, start []byte) Iterator {
+	return nil
+}
+
+func collectDiffs(t *testing.T, a, b Database) []DBDiff {
+	var diffs []DBDiff
+	_, err := diffDB("test", a, b, func(diff DBDiff) error {
+		diffs = append(diffs, diff)
+		return nil
+	}, make(chan struct{}))
+	assert.NoError(t, err)
+	return diffs
+}
+
+func TestDiffDB(t *testing.T) {
+	a, b := NewMemDB(), NewMemDB()
+	for i := 0; i < 100; i++ {
+		key, val := []byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("val%03d", i))
+		assert.NoError(t, a.Put(key, val))
+		assert.NoError(t, b.Put(key, val))
+	}
+	assert.Empty(t, collectDiffs(t, a, b))
+
+	// inject the differences at the edges and in the middle
+	assert.NoError(t, a.Put([]byte("a-only"), []byte("a")))
+	assert.NoError(t, b.Put([]byte("z-only"), []byte("b")))
+	assert.NoError(t, a.Delete([]byte("key010")))
+	assert.NoError(t, b.Delete([]byte("key020")))
+	assert.NoError(t, b.Put([]byte("key030"), []byte("changed")))
+	// the migration cursors are not compared
+	assert.NoError(t, b.Put(migrationCursorKey("test"), []byte("key099")))
+
+	assert.Equal(t, []DBDiff{
+		{Key: []byte("a-only"), AValue: []byte("a")},
+		{Key: []byte("key010"), BValue: []byte("val010")},
+		{Key: []byte("key020"), AValue: []byte("val020")},
+		{Key: []byte("key030"), AValue: []byte("val030"), BValue: []byte("changed")},
+		{Key: []byte("z-only"), BValue: []byte("b")},
+	}, collectDiffs(t, a, b))
+}
+
+func TestDiffDB_Empty(t *testing.T) {
+	a, b := NewMemDB(), NewMemDB()
+	assert.Empty(t, collectDiffs(t, a, b))
+
+	assert.NoError(t, b.Put([]byte("key"), []byte("val")))
+	assert.Equal(t, []DBDiff{{Key: []byte("key"), BValue: []byte("val")}}, collectDiffs(t, a, b))
+	assert.Equal(t, []DBDiff{{Key: []byte("key"), AValue: []byte("val")}}, collectDiffs(t, b, a))
+}
+
+func TestDiffDB_Errors(t *testing.T) {
+	a, b := NewMemDB(), NewMemDB()
+	assert.NoError(t, a.Put([]byte("key1"), []byte("val")))
+	assert.NoError(t, a.Put([]byte("key2"), []byte("val")))
+
+	// the error of the callback stops the comparison
+	errStop := errors.New("stop")
+	calls := 0
+	_, err := diffDB("test", a, b, func(diff DBDiff) error {
+		calls++
+		return errStop
+	}, make(chan struct{}))
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+
+	// the interruption stops the comparison
+	quit := make(chan struct{})
+	close(quit)
+	_, err = diffDB("test", a, b, func(diff DBDiff) error {
+		return nil
+	}, quit)
+	assert.ErrorIs(t, err, ErrDiffInterrupted)
+
+	_, err = diffDB("test", a, &noIterDB{MemDB: b}, func(diff DBDiff) error {
+		return nil
+	}, make(chan struct{}))
+	assert.ErrorIs(t, err, errIterationNotSupported)
+}
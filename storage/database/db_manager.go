@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -55,6 +56,7 @@ type DBManager interface {
 	getStateTrieMigrationInfo() uint64
 
 	Close()
+	Flush() error
 	NewBatch(dbType DBEntryType) Batch
 	getDBDir(dbEntry DBEntryType) string
 	setDBDir(dbEntry DBEntryType, newDBDir string)
@@ -300,6 +302,9 @@ type DBManager interface {
 
 	// DB migration related function
 	StartDBMigration(DBManager) error
+	StartDBBulkMigration(DBManager) error
+	ImportRLPDump(io.ReadSeeker) error
+	DiffDB(DBManager, func(string, DBDiff) error) error
 
 	// ChainDataFetcher checkpoint function
 	WriteChainDataFetcherCheckpoint(checkpoint uint64) error
@@ -404,6 +409,10 @@ func getDBEntryConfig(originalDBC *DBConfig, i DBEntryType, dbDir string) *DBCon
 	if newDBC.DynamoDBConfig != nil {
 		newDynamoDBConfig := *originalDBC.DynamoDBConfig
 		newDynamoDBConfig.TableName += "-" + dbDir
+		// The tables sharing a bucket keep their objects under their own prefixes.
+		if newDynamoDBConfig.S3Bucket != "" {
+			newDynamoDBConfig.S3KeyPrefix += dbDir + "/"
+		}
 		newDBC.DynamoDBConfig = &newDynamoDBConfig
 	}
 
@@ -452,6 +461,8 @@ type DBConfig struct {
 	ParallelDBWrite     bool
 	OpenFilesLimit      int
 	EnableDBPerfMetrics bool // If true, read and write performance will be logged
+	MinFreeDiskSpace    int  // minimum free disk space in MiB to open a DB on the local disk, 0 means unchecked
+	ReadOnly            bool // opens the DBs without writing to them, which is used by offline tools
 
 	// LevelDB related configurations.
 	LevelDBCacheSize   int // LevelDBCacheSize = BlockCacheCapacity + WriteBuffer
@@ -463,6 +474,9 @@ type DBConfig struct {
 
 	// DynamoDB related configurations
 	DynamoDBConfig *DynamoDBConfig
+
+	// MemoryDB related configurations
+	MemDBMaxSize int // maximum bytes of keys and values in each MemoryDB, 0 means unlimited
 }
 
 const dbMetricPrefix = "klay/db/chaindata/"
@@ -470,6 +484,9 @@ const dbMetricPrefix = "klay/db/chaindata/"
 // singleDatabaseDBManager returns DBManager which handles one single Database.
 // Each Database will share one common Database.
 func singleDatabaseDBManager(dbc *DBConfig) (DBManager, error) {
+	if err := checkFreeDiskSpace(dbc); err != nil {
+		return nil, err
+	}
 	dbm := newDatabaseManager(dbc)
 	db, err := newDatabase(dbc, 0)
 	if err != nil {
@@ -498,6 +515,9 @@ func newMiscDB(dbc *DBConfig) Database {
 // databaseDBManager returns DBManager which handles Databases.
 // Each Database will have its own separated Database.
 func databaseDBManager(dbc *DBConfig) (*databaseManager, error) {
+	if err := checkFreeDiskSpace(dbc); err != nil {
+		return nil, err
+	}
 	dbm := newDatabaseManager(dbc)
 	var db Database
 	var err error
@@ -546,12 +566,22 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 	case LevelDB:
 		return NewLevelDB(dbc, entryType)
 	case RocksDB:
+		if dbc.ReadOnly && dbc.RocksDBConfig != nil {
+			config := *dbc.RocksDBConfig
+			config.Secondary = true
+			return NewRocksDB(dbc.Dir, &config)
+		}
 		return NewRocksDB(dbc.Dir, dbc.RocksDBConfig)
 	case BadgerDB:
-		return NewBadgerDB(dbc.Dir)
+		return newBadgerDB(dbc.Dir, dbc.ReadOnly)
 	case MemoryDB:
-		return NewMemDB(), nil
+		return NewMemDBWithMaxSize(dbc.MemDBMaxSize), nil
 	case DynamoDB:
+		if dbc.ReadOnly && dbc.DynamoDBConfig != nil {
+			config := *dbc.DynamoDBConfig
+			config.ReadOnly = true
+			return NewDynamoDB(&config)
+		}
 		return NewDynamoDB(dbc.DynamoDBConfig)
 	default:
 		logger.Info("database type is not set, fall back to default LevelDB")
@@ -559,6 +589,19 @@ func newDatabase(dbc *DBConfig, entryType DBEntryType) (Database, error) {
 	}
 }
 
+// NewDatabase creates a database of the given type, which is used to open a
+// single database without DBManager, such as in tools. The other fields of the
+// config than DBType are used by the constructor of the type.
+func NewDatabase(dbtype DBType, dbc *DBConfig) (Database, error) {
+	dbtype, err := ParseDBType(string(dbtype))
+	if err != nil {
+		return nil, err
+	}
+	config := *dbc
+	config.DBType = dbtype
+	return newDatabase(&config, MiscDB)
+}
+
 // newDatabaseManager returns the pointer of databaseManager with default configuration.
 func newDatabaseManager(dbc *DBConfig) *databaseManager {
 	return &databaseManager{
@@ -911,16 +954,41 @@ func (dbm *databaseManager) getDatabase(dbEntryType DBEntryType) Database {
 func (dbm *databaseManager) Close() {
 	// If single DB, only close the first database.
 	if dbm.config.SingleDB {
-		dbm.dbs[0].Close()
+		if err := dbm.dbs[0].Close(); err != nil {
+			logger.Error("Failed to close the database", "err", err)
+		}
 		return
 	}
 
 	// If not single DB, close all databases.
-	for _, db := range dbm.dbs {
+	for i, db := range dbm.dbs {
 		if db != nil {
-			db.Close()
+			if err := db.Close(); err != nil {
+				logger.Error("Failed to close the database", "dbEntryType", DBEntryType(i), "err", err)
+			}
+		}
+	}
+}
+
+// Flush waits for the pending writes of the databases, and returns the first error.
+func (dbm *databaseManager) Flush() error {
+	dbs := dbm.dbs
+	if dbm.config.SingleDB {
+		dbs = dbs[:1]
+	}
+	var firstErr error
+	for i, db := range dbs {
+		if db == nil {
+			continue
+		}
+		if err := Flush(db); err != nil {
+			logger.Error("Failed to flush the database", "dbEntryType", DBEntryType(i), "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
+	return firstErr
 }
 
 // TODO-Klaytn Some of below need to be invisible outside database package
@@ -1283,6 +1283,50 @@ func TestDBManager_WriteCode(t *testing.T) {
 	}
 }
 
+// TestNewDatabase_ReadOnly tests that a database opened read-only reads the
+// data written before, but rejects writes.
+func TestNewDatabase_ReadOnly(t *testing.T) {
+	for _, dbType := range []DBType{LevelDB, BadgerDB} {
+		dir, err := os.MkdirTemp(os.TempDir(), "test-read-only-db")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		db, err := newDatabase(&DBConfig{Dir: dir, DBType: dbType}, MiscDB)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Put([]byte("key"), []byte("value")))
+		db.Close()
+
+		db, err = newDatabase(&DBConfig{Dir: dir, DBType: dbType, ReadOnly: true}, MiscDB)
+		assert.NoError(t, err, dbType)
+		val, err := db.Get([]byte("key"))
+		assert.NoError(t, err, dbType)
+		assert.Equal(t, []byte("value"), val, dbType)
+		assert.Error(t, db.Put([]byte("key"), []byte("other")), dbType)
+		db.Close()
+	}
+}
+
+// TestGetDBEntryConfig_S3KeyPrefix tests that the tables sharing an S3 bucket
+// keep their objects under their own prefixes.
+func TestGetDBEntryConfig_S3KeyPrefix(t *testing.T) {
+	dbc := &DBConfig{DBType: DynamoDB, DynamoDBConfig: &DynamoDBConfig{TableName: "klaytn"}}
+	header := getDBEntryConfig(dbc, headerDB, dbBaseDirs[headerDB]).DynamoDBConfig
+	assert.Equal(t, "klaytn-header", header.TableName)
+	assert.Equal(t, "", header.S3Bucket)
+	assert.Equal(t, "", header.S3KeyPrefix)
+
+	dbc.DynamoDBConfig.S3Bucket, dbc.DynamoDBConfig.S3KeyPrefix = "klaytn-shared", "node0/"
+	header = getDBEntryConfig(dbc, headerDB, dbBaseDirs[headerDB]).DynamoDBConfig
+	body := getDBEntryConfig(dbc, BodyDB, dbBaseDirs[BodyDB]).DynamoDBConfig
+	assert.Equal(t, "node0/header/", header.S3KeyPrefix)
+	assert.Equal(t, "node0/body/", body.S3KeyPrefix)
+	assert.Equal(t, "node0/", dbc.DynamoDBConfig.S3KeyPrefix)
+
+	dbc.DynamoDBConfig.S3KeyPrefix = ""
+	header = getDBEntryConfig(dbc, headerDB, dbBaseDirs[headerDB]).DynamoDBConfig
+	assert.Equal(t, "header/", header.S3KeyPrefix)
+}
+
 func genRandomData() (common.Hash, []byte) {
 	rb := common.MakeRandomBytes(common.HashLength)
 	hash := common.BytesToHash(rb)
@@ -17,12 +17,15 @@
 package database
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"os/signal"
 	"path"
 	"syscall"
 	"time"
 
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/pkg/errors"
 )
 
@@ -30,11 +33,49 @@ const (
 	reportCycle = IdealBatchSize * 20
 )
 
+// migrationCursorKey returns the key of dstDB where the last migrated key of
+// the DB is stored.
+func migrationCursorKey(name string) []byte {
+	return append(append([]byte{}, migrationCursorPrefix...), name...)
+}
+
+// isMigrationCursorKey returns true if the key is reserved for a migration
+// cursor, which is not migrated as an item of the DB.
+func isMigrationCursorKey(key []byte) bool {
+	return bytes.HasPrefix(key, migrationCursorPrefix)
+}
+
+// readMigrationCursor returns the last migrated key of the DB stored in dstDB,
+// or nil if the migration is not started yet.
+func readMigrationCursor(name string, dstDB Database) ([]byte, error) {
+	cursorKey := migrationCursorKey(name)
+	if has, err := dstDB.Has(cursorKey); err != nil || !has {
+		return nil, err
+	}
+	return dstDB.Get(cursorKey)
+}
+
 // copyDB migrates a DB to another DB.
 // This feature uses Iterator. A src DB should have implementation of Iteratee to use this function.
+//
+// The last key of each written batch is stored in dstDB as a cursor, so that an
+// interrupted migration resumes from the next key of the cursor. The cursor is
+// deleted when the migration is finished.
 func copyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
+	cursorKey := migrationCursorKey(name)
+	cursor, err := readMigrationCursor(name, dstDB)
+	if err != nil {
+		return errors.WithMessage(err, "failed to read the migration cursor")
+	}
+	var startKey []byte
+	if cursor != nil {
+		// the smallest key greater than the cursor
+		startKey = append(append([]byte{}, cursor...), 0)
+		logger.Info("Resume DB migration", "db", name, "cursor", hexutil.Encode(cursor))
+	}
+
 	// create src iterator and dst batch
-	srcIter := srcDB.NewIterator(nil, nil)
+	srcIter := srcDB.NewIterator(nil, startKey)
 	dstBatch := dstDB.NewBatch()
 	defer dstBatch.Release()
 
@@ -42,7 +83,28 @@ func copyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
 	start := time.Now()
 	fetched := 0
 
+	// writeBatch writes the batch and stores its last key as the cursor
+	var lastKey []byte
+	writeBatch := func() error {
+		if err := dstBatch.Write(); err != nil {
+			return err
+		}
+		dstBatch.Reset()
+		if lastKey == nil {
+			return nil
+		}
+		if err := dstDB.Put(cursorKey, lastKey); err != nil {
+			return errors.WithMessage(err, "failed to write the migration cursor")
+		}
+		return nil
+	}
+
 	for fetched = 0; srcIter.Next(); fetched++ {
+		// the cursors of the previous migrations are not migrated
+		if isMigrationCursorKey(srcIter.Key()) {
+			continue
+		}
+
 		// fetch keys and values
 		// Contents of srcIter.Key() and srcIter.Value() should not be modified, and
 		// only valid until the next call to Next.
@@ -56,12 +118,12 @@ func copyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
 		if err := dstBatch.Put(key, val); err != nil {
 			return errors.WithMessage(err, "failed to put batch")
 		}
+		lastKey = key
 
 		if dstBatch.ValueSize() > IdealBatchSize {
-			if err := dstBatch.Write(); err != nil {
+			if err := writeBatch(); err != nil {
 				return err
 			}
-			dstBatch.Reset()
 		}
 
 		// make a report
@@ -79,26 +141,57 @@ func copyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
 		}
 	}
 
-	if err := dstBatch.Write(); err != nil {
+	if err := writeBatch(); err != nil {
 		return errors.WithMessage(err, "failed to write items")
 	}
-	dstBatch.Reset()
-
-	logger.Info("Finish DB migration", "db", name, "fetchedTotal", fetched, "elapsedTotal", time.Since(start))
 
 	srcIter.Release()
 	if err := srcIter.Error(); err != nil { // any accumulated error from iterator
 		return errors.WithMessage(err, "failed to iterate")
 	}
+	if err := dstDB.Delete(cursorKey); err != nil {
+		return errors.WithMessage(err, "failed to delete the migration cursor")
+	}
 
+	logger.Info("Finish DB migration", "db", name, "fetchedTotal", fetched, "elapsedTotal", time.Since(start))
 	return nil
 }
 
-// StartDBMigration migrates a DB to another DB.
-// (e.g. LevelDB -> LevelDB, LevelDB -> BadgerDB, LevelDB -> DynamoDB)
-// Do not migrate db while a node is executing.
-func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
-	// settings for quit signal from os
+// bulkCopyDB migrates a DB to another DB by the bulk load of dstDB, or by
+// copyDB if dstDB does not support it. The migration cursors are not used since
+// the bulk loads are not resumable.
+func bulkCopyDB(name string, srcDB, dstDB Database, quit chan struct{}) error {
+	start := time.Now()
+	srcIter := &migrationIterator{Iterator: srcDB.NewIterator(nil, nil)}
+	defer srcIter.Release()
+
+	loaded, err := BulkLoad(dstDB, srcIter, quit)
+	if errors.Is(err, errBulkLoadNotSupported) {
+		logger.Warn("dst DB does not support bulk load, migrate by batches", "db", name, "type", dstDB.Type())
+		return copyDB(name, srcDB, dstDB, quit)
+	} else if err != nil {
+		return errors.WithMessage(err, "failed to bulk load")
+	}
+	logger.Info("Finish DB bulk migration", "db", name, "loadedTotal", loaded, "elapsedTotal", time.Since(start))
+	return nil
+}
+
+// migrationIterator skips the migration cursors of the previous migrations.
+type migrationIterator struct {
+	Iterator
+}
+
+func (it *migrationIterator) Next() bool {
+	for it.Iterator.Next() {
+		if !isMigrationCursorKey(it.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
+// newQuitChannel returns a channel closed when the process is interrupted.
+func newQuitChannel() chan struct{} {
 	quit := make(chan struct{})
 	go func() {
 		sigc := make(chan os.Signal, 1)
@@ -114,6 +207,28 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 			}
 		}
 	}()
+	return quit
+}
+
+// StartDBMigration migrates a DB to another DB.
+// (e.g. LevelDB -> LevelDB, LevelDB -> BadgerDB, LevelDB -> DynamoDB)
+// Do not migrate db while a node is executing.
+func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
+	return dbm.migrateDB(dstdbm, copyDB)
+}
+
+// StartDBBulkMigration migrates a DB to another DB like StartDBMigration, but
+// the items are written by the bulk loads of the dst DBs which support it, such
+// as DynamoDB staging the items in S3. It is faster for a large cold start
+// migration, but an interrupted bulk load is started again from the beginning.
+func (dbm *databaseManager) StartDBBulkMigration(dstdbm DBManager) error {
+	return dbm.migrateDB(dstdbm, bulkCopyDB)
+}
+
+// migrateDB migrates the DBs to the DBs of dstdbm by copyFn.
+func (dbm *databaseManager) migrateDB(dstdbm DBManager, copyFn func(name string, srcDB, dstDB Database, quit chan struct{}) error) error {
+	// settings for quit signal from os
+	quit := newQuitChannel()
 
 	// from non single DB
 	if !dbm.config.SingleDB {
@@ -140,7 +255,7 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 
 			dbIdx := et
 			go func() {
-				errChan <- copyDB(dbBaseDirs[dbIdx], srcDB, dstDB, quit)
+				errChan <- copyFn(dbBaseDirs[dbIdx], srcDB, dstDB, quit)
 			}()
 		}
 
@@ -161,7 +276,7 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 	srcDB := dbm.getDatabase(0)
 	dstDB := dstdbm.getDatabase(0)
 
-	if err := copyDB("single", srcDB, dstDB, quit); err != nil {
+	if err := copyFn("single", srcDB, dstDB, quit); err != nil {
 		return err
 	}
 
@@ -175,3 +290,13 @@ func (dbm *databaseManager) StartDBMigration(dstdbm DBManager) error {
 
 	return nil
 }
+
+// ImportRLPDump imports an RLP dump into the DB. Since a dump holds the items of
+// a single database, it is only provided when the DB is single DB.
+func (dbm *databaseManager) ImportRLPDump(r io.ReadSeeker) error {
+	if !dbm.config.SingleDB {
+		return errors.New("rlp dump can only be imported into single DB")
+	}
+	_, err := ImportRLPDump(r, dbm.getDatabase(0))
+	return err
+}
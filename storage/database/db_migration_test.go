@@ -0,0 +1,129 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// crashingDB fails the batch writes after failAfter writes, which simulates a
+// crash in the middle of a migration, and records the keys put by batches.
+type crashingDB struct {
+	*MemDB
+	failAfter int // the number of successful writes, or -1 to never fail
+	writes    int
+	putKeys   [][]byte
+}
+
+var errTestCrash = errors.New("crashed")
+
+func (db *crashingDB) NewBatch() Batch {
+	return &crashingBatch{Batch: db.MemDB.NewBatch(), db: db}
+}
+
+type crashingBatch struct {
+	Batch
+	db *crashingDB
+}
+
+func (b *crashingBatch) Put(key, val []byte) error {
+	b.db.putKeys = append(b.db.putKeys, key)
+	return b.Batch.Put(key, val)
+}
+
+func (b *crashingBatch) Write() error {
+	if b.db.failAfter >= 0 && b.db.writes >= b.db.failAfter {
+		return errTestCrash
+	}
+	b.db.writes++
+	return b.Batch.Write()
+}
+
+func TestCopyDB_Resume(t *testing.T) {
+	srcDB := NewMemDB()
+	val := make([]byte, 10*1024) // about 10 items in a batch
+	var keys [][]byte
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		assert.NoError(t, srcDB.Put(key, append(append([]byte{}, val...), key...)))
+		keys = append(keys, key)
+	}
+	// the cursor of a migration into srcDB is not migrated
+	assert.NoError(t, srcDB.Put(migrationCursorKey("other"), []byte("key")))
+
+	// the migration crashes after writing 3 batches
+	dstDB := &crashingDB{MemDB: NewMemDB(), failAfter: 3}
+	assert.ErrorIs(t, copyDB("test", srcDB, dstDB, make(chan struct{})), errTestCrash)
+
+	cursor, err := readMigrationCursor("test", dstDB)
+	assert.NoError(t, err)
+	assert.NotNil(t, cursor)
+	migrated := 0
+	for _, key := range keys {
+		has, _ := dstDB.Has(key)
+		if bytes.Compare(key, cursor) <= 0 {
+			assert.True(t, has, "a key before the cursor is not migrated: %s", key)
+			migrated++
+		} else {
+			assert.False(t, has, "a key after the cursor is migrated: %s", key)
+		}
+	}
+	assert.Greater(t, migrated, 0)
+	assert.Less(t, migrated, len(keys))
+
+	// the migration resumes from the next key of the cursor
+	dstDB.failAfter, dstDB.putKeys = -1, nil
+	assert.NoError(t, copyDB("test", srcDB, dstDB, make(chan struct{})))
+	assert.Equal(t, keys[migrated:], dstDB.putKeys)
+
+	for _, key := range keys {
+		expected, _ := srcDB.Get(key)
+		actual, err := dstDB.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	// the cursor is deleted when the migration is finished
+	cursor, err = readMigrationCursor("test", dstDB)
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+	assert.Equal(t, len(keys), dstDB.Len())
+}
+
+func TestCopyDB_Quit(t *testing.T) {
+	srcDB := NewMemDB()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, srcDB.Put([]byte(fmt.Sprintf("key%03d", i)), []byte("val")))
+	}
+	quit := make(chan struct{})
+	close(quit)
+
+	// nothing is written before quit, so the migration starts over
+	dstDB := NewMemDB()
+	assert.NoError(t, copyDB("test", srcDB, dstDB, quit))
+	cursor, err := readMigrationCursor("test", dstDB)
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	assert.NoError(t, copyDB("test", srcDB, dstDB, make(chan struct{})))
+	assert.Equal(t, srcDB.Len(), dstDB.Len())
+}
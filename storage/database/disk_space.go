@@ -0,0 +1,80 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	errLowDiskSpace             = errors.New("not enough free disk space")
+	errFreeDiskSpaceUnsupported = errors.New("free disk space is not supported on this platform")
+)
+
+// freeDiskSpace returns the free disk space in bytes of the file system
+// containing the given directory. It is replaced in tests.
+var freeDiskSpace = getFreeDiskSpace
+
+// usesLocalDisk returns if the db stores its data in the local directory.
+func (db DBType) usesLocalDisk() bool {
+	switch db {
+	case LevelDB, RocksDB, BadgerDB:
+		return true
+	}
+	return false
+}
+
+// checkFreeDiskSpace returns an error if the free disk space of the data
+// directory is less than MinFreeDiskSpace of the config, which prevents a
+// database from being corrupted by a full disk. The databases without local
+// storage and the databases opened read-only are not checked.
+func checkFreeDiskSpace(dbc *DBConfig) error {
+	if dbc.MinFreeDiskSpace <= 0 || dbc.ReadOnly || !dbc.DBType.usesLocalDisk() {
+		return nil
+	}
+
+	// the data directory is created later if it doesn't exist yet
+	dir := dbc.Dir
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	free, err := freeDiskSpace(dir)
+	if errors.Is(err, errFreeDiskSpaceUnsupported) {
+		logger.Warn("Skipped checking the free disk space", "dir", dir, "err", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the free disk space of %s: %w", dir, err)
+	}
+	const MiB = 1024 * 1024
+	if free < uint64(dbc.MinFreeDiskSpace)*MiB {
+		return fmt.Errorf("%w in %s: %d MiB is free, but at least %d MiB is required",
+			errLowDiskSpace, dir, free/MiB, dbc.MinFreeDiskSpace)
+	}
+	return nil
+}
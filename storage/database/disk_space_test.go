@@ -0,0 +1,97 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestFreeDiskSpace stubs the free disk space query and returns the
+// directories queried.
+func setTestFreeDiskSpace(t *testing.T, free uint64) *[]string {
+	var queried []string
+	orig := freeDiskSpace
+	freeDiskSpace = func(dir string) (uint64, error) {
+		queried = append(queried, dir)
+		return free, nil
+	}
+	t.Cleanup(func() { freeDiskSpace = orig })
+	return &queried
+}
+
+func TestCheckFreeDiskSpace_StartupAborts(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-test-disk-space")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	setTestFreeDiskSpace(t, 99*1024*1024)
+
+	dbc := &DBConfig{Dir: filepath.Join(dir, "chaindata"), DBType: LevelDB, SingleDB: true, MinFreeDiskSpace: 100, LevelDBCacheSize: 16, OpenFilesLimit: 16}
+	_, err = singleDatabaseDBManager(dbc)
+	assert.ErrorIs(t, err, errLowDiskSpace)
+
+	dbc.SingleDB, dbc.NumStateTrieShards = false, 1
+	_, err = databaseDBManager(dbc)
+	assert.ErrorIs(t, err, errLowDiskSpace)
+
+	// nothing is created on the disk
+	_, err = os.Stat(dbc.Dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCheckFreeDiskSpace_StartupProceeds(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-test-disk-space")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	queried := setTestFreeDiskSpace(t, 100*1024*1024)
+
+	dbc := &DBConfig{Dir: filepath.Join(dir, "chaindata"), DBType: LevelDB, SingleDB: true, MinFreeDiskSpace: 100, LevelDBCacheSize: 16, OpenFilesLimit: 16}
+	dbm, err := singleDatabaseDBManager(dbc)
+	require.NoError(t, err)
+	dbm.Close()
+
+	// the nearest existing directory is checked if the data directory doesn't exist
+	assert.Equal(t, []string{dir}, *queried)
+}
+
+func TestCheckFreeDiskSpace_Skipped(t *testing.T) {
+	queried := setTestFreeDiskSpace(t, 0)
+
+	for _, dbc := range []*DBConfig{
+		{Dir: "chaindata", DBType: DynamoDB, MinFreeDiskSpace: 100},
+		{Dir: "chaindata", DBType: MemoryDB, MinFreeDiskSpace: 100},
+		{Dir: "chaindata", DBType: LevelDB, MinFreeDiskSpace: 0},
+	} {
+		assert.NoError(t, checkFreeDiskSpace(dbc), dbc.DBType)
+	}
+	assert.Empty(t, *queried)
+}
+
+func TestCheckFreeDiskSpace_QueryError(t *testing.T) {
+	orig := freeDiskSpace
+	defer func() { freeDiskSpace = orig }()
+	errQuery := errors.New("query failed")
+	freeDiskSpace = func(string) (uint64, error) { return 0, errQuery }
+
+	err := checkFreeDiskSpace(&DBConfig{Dir: os.TempDir(), DBType: LevelDB, MinFreeDiskSpace: 1})
+	assert.ErrorIs(t, err, errQuery)
+}
@@ -27,20 +27,27 @@ package database
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	klaytnmetrics "github.com/klaytn/klaytn/metrics"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/log"
 	"github.com/pkg/errors"
@@ -50,30 +57,74 @@ import (
 var overSizedDataPrefix = []byte("oversizeditem")
 
 // Performance of batch operations of DynamoDB are collected by default.
-var dynamoBatchWriteTimeMeter metrics.Meter = &metrics.NilMeter{}
+var (
+	dynamoBatchWriteTimeMeter        metrics.Meter = &metrics.NilMeter{}
+	dynamoBatchWriteCountMeter       metrics.Meter = &metrics.NilMeter{}
+	dynamoBatchWriteBytesMeter       metrics.Meter = &metrics.NilMeter{}
+	dynamoBatchWriteTimePerItemMeter metrics.Meter = &metrics.NilMeter{}
+	dynamoBatchWriteTimePerByteMeter metrics.Meter = &metrics.NilMeter{}
+	dynamoUnprocessedItemMeter       metrics.Meter = &metrics.NilMeter{}
+)
 
 // errors
-var dataNotFoundErr = errors.New("data is not found with the given key")
+
+// ErrKeyNotFound is returned if the data of the given key does not exist.
+var ErrKeyNotFound = errors.New("data is not found with the given key")
+
+var dataNotFoundErr = ErrKeyNotFound
+
+// errKeyTooLong is returned if a key is longer than the maximum key length of
+// the database. The returned error wraps it with the length of the key.
+var errKeyTooLong = errors.New("key is too long")
+
+// errEmptyKey is returned for a zero-length key if StrictEmptyKeys is set.
+var errEmptyKey = errors.New("key is empty")
+
+// errDynamoCloseTimeout is returned by Close if the pending batch writes are not
+// flushed in dynamoCloseTimeout.
+var errDynamoCloseTimeout = errors.New("timed out flushing the pending batch writes")
+
+// errDynamoClosed is returned by the batches of a closed database, whose writes
+// are not dispatched anymore.
+var errDynamoClosed = errors.New("dynamoDB is closed")
+
+// errConcurrentBatchUse and errBatchNotReset are returned for the misuses of a
+// dynamoBatch, which would otherwise corrupt the items shared with the batch
+// write workers or panic in the WaitGroup of the batch.
+var (
+	errConcurrentBatchUse = errors.New("dynamoDB batch is used by multiple goroutines at once")
+	errBatchNotReset      = errors.New("dynamoDB batch is modified after Write without Reset")
+)
 
 var (
 	nilDynamoConfigErr = errors.New("attempt to create DynamoDB with nil configuration")
 	noTableNameErr     = errors.New("dynamoDB table name not provided")
+	noRegionErr        = errors.New("dynamoDB region not provided")
+)
+
+// default capacity units applied on demand when the capacity units are not given.
+const (
+	defaultDynamoReadCapacityUnits  = 10000
+	defaultDynamoWriteCapacityUnits = 10000
 )
 
 // batch write size
 const dynamoWriteSizeLimit = 399 * 1024 // The maximum write size is 400KB including attribute names and values
+const dynamoMaxKeyLength = 2048         // The maximum length of a binary partition key is 2KB
 const (
 	dynamoBatchSize = 25
 	dynamoMaxRetry  = 20
 	dynamoTimeout   = 10 * time.Second
+
+	dynamoCloseTimeout = 30 * time.Second // the time to wait for the pending batch writes on Close
 )
 
 // batch write
-const WorkerNum = 10
-const itemChanSize = WorkerNum * 2
+const WorkerNum = 10               // the default number of the batch write workers
+const itemChanSize = WorkerNum * 2 // the default buffer size of dynamoWriteCh
 
 var (
-	dynamoDBClient    *dynamodb.DynamoDB          // handles dynamoDB connections
+	dynamoDBClient    dynamodbiface.DynamoDBAPI   // handles dynamoDB connections
 	dynamoWriteCh     chan *batchWriteWorkerInput // use global write channel for shared worker
 	dynamoOnceWorker  = &sync.Once{}              // makes sure worker is created once
 	dynamoOpenedDBNum uint
@@ -88,13 +139,392 @@ type DynamoDBConfig struct {
 	ReadCapacityUnits  int64  // read capacity when provisioned
 	WriteCapacityUnits int64  // write capacity when provisioned
 	ReadOnly           bool   // disables write
+	SkipWriteCheck     bool   // skips checking if DynamoDB and S3 are writable on startup
 	PerfCheck          bool
+	LogAWSRequests     bool // logs the request IDs of all AWS calls at debug level, not only failed ones
+
+	// EndpointResolver resolves the endpoints of the AWS services, such as
+	// dynamodb, s3 and sts, overriding the default resolution of the AWS SDK.
+	// Endpoint and S3Endpoint take precedence over it if they are given.
+	// NewServiceEndpointResolver returns a resolver from the URLs of the services.
+	EndpointResolver endpoints.Resolver `toml:"-"`
+
+	// AWSLogLevel enables the logs of the AWS SDK itself, such as "debug" or
+	// "debug-with-http-body", which are routed to the logger of this module.
+	// The SDK doesn't log if it is empty or "off".
+	AWSLogLevel string
+
+	// SlowOpThreshold is the duration above which a Get, Put or BatchWrite is
+	// logged as a slow operation. The warnings are rate-limited, and they are
+	// disabled if it is 0.
+	SlowOpThreshold time.Duration
+
+	// S3CompressionThreshold is the size above which the values stored in S3 are
+	// gzip-compressed. Smaller oversized values are stored raw, and the
+	// compression is disabled if it is 0.
+	S3CompressionThreshold int
+
+	// S3MultipartThreshold is the size above which the values are written to S3
+	// by multipart upload instead of a single PutObject. The multipart upload
+	// is disabled if it is 0, and the default config uses the size of a part.
+	S3MultipartThreshold int
+
+	// S3ReadMaxRetries and S3WriteMaxRetries are the maximum numbers of retries
+	// of reading and writing an S3 object. The default values are used for 0.
+	S3ReadMaxRetries  int
+	S3WriteMaxRetries int
+
+	// S3ServerSideEncryption is the server-side encryption of the S3 objects,
+	// "AES256" or "aws:kms". The objects are encrypted by the default of the
+	// bucket if it is empty. S3SSEKMSKeyID is the KMS key used by "aws:kms",
+	// and the AWS managed key is used if it is empty.
+	S3ServerSideEncryption string
+	S3SSEKMSKeyID          string
+
+	// S3CacheSize is the number of the S3 objects of the oversized items kept
+	// in memory after they are read. The cache is disabled if it is 0.
+	S3CacheSize int
+
+	// S3Bucket is the bucket of the S3 objects of the oversized items, and
+	// TableName is used if it is empty. S3KeyPrefix is prepended to the keys of
+	// the objects, so that the tables sharing a bucket with different prefixes
+	// don't overwrite the objects of each other. Resetting a table with a
+	// prefix removes only the objects under the prefix, keeping the bucket.
+	// A bucket other than TableName requires a prefix, and the databases of a
+	// DBManager append their directories to it, e.g. "prefix/header/".
+	S3Bucket    string
+	S3KeyPrefix string
+
+	// TTLAttributeName enables the expiry of the items by the TTL of DynamoDB.
+	// The items are written with the attribute holding the time TTLDuration
+	// after the write in Unix epoch seconds, and the attribute is registered as
+	// the TTL of the table when the table is created. DynamoDB deletes the
+	// expired items in the background, so they can be read for a while after
+	// the expiry. The S3 objects of the oversized items don't expire. The items
+	// don't expire if it is empty.
+	TTLAttributeName string
+	TTLDuration      time.Duration
+
+	// BatchGetMaxRetries is the maximum number of retries of the unprocessed
+	// keys of a BatchGetItem request. The default value is used for 0.
+	BatchGetMaxRetries int
+
+	// AllowRegionRedirect lets S3 switch to the region expected by the server
+	// if the configured region is rejected, which helps S3-compatible endpoints.
+	AllowRegionRedirect bool
+
+	// EventuallyConsistentReads makes Get read the items with eventually
+	// consistent reads, which consume a half of read capacity but may return
+	// stale values. GetWithMeta reports the consistency of the reads.
+	EventuallyConsistentReads bool
+
+	// CoalesceGets lets concurrent reads of the same key share one request,
+	// which saves read capacity for hot keys.
+	CoalesceGets bool
+
+	// EncryptionKey encrypts the values of the keys with EncryptedKeyPrefixes with
+	// AES-GCM before they are written to DynamoDB and S3 if it is set. It should
+	// be 16, 24 or 32 bytes.
+	EncryptionKey        []byte   `toml:"-"`
+	EncryptedKeyPrefixes [][]byte `toml:"-"`
+
+	// ValueCompression is the name of the codec compressing the values larger
+	// than ValueCompressionThreshold, such as "snappy", "gzip" or "none". If it is
+	// set, every value is stored with the id of its codec, so it should be set
+	// for a new table, but it can be changed later since the values are
+	// decompressed by the codecs they are stored with.
+	ValueCompression          string
+	ValueCompressionThreshold int
+
+	// StrictEmptyKeys makes Put, Get, Has and Delete return errEmptyKey for a
+	// zero-length key, which helps to catch the callers passing an empty key by
+	// mistake. Otherwise, a zero-length key is ignored: Put and Delete do nothing,
+	// and Get and Has find nothing.
+	StrictEmptyKeys bool
+
+	// StrictDeletes makes Delete return dataNotFoundErr for a key which does
+	// not exist, or whose oversized value does not exist in S3, which helps to
+	// catch the callers deleting the keys they wrongly think exist. Otherwise,
+	// deleting a missing key succeeds. The deletes of the batches are not checked.
+	StrictDeletes bool
+
+	// IdempotentImport skips the batch puts of the keys which already exist in
+	// the table, which makes an interrupted import resumable without overwriting.
+	IdempotentImport bool
+
+	// Circuit breaker of DynamoDB requests. It is disabled if BreakerThreshold is 0.
+	BreakerThreshold int           // the number of consecutive failures which opens the breaker
+	BreakerWindow    time.Duration // consecutive failures are counted within this window
+	BreakerCooldown  time.Duration // how long the breaker stays open before probing
+
+	// Adaptive size of batch write requests, which shrinks under throttling and
+	// grows back as the writes succeed. It is disabled if AdaptiveBatchMin is 0.
+	AdaptiveBatchMin      int     // the minimum number of items in a request
+	AdaptiveBatchMax      int     // the maximum number of items in a request, up to 25
+	AdaptiveBatchDecrease float64 // the factor multiplied to the size when items are left unprocessed
+	AdaptiveBatchIncrease int     // the number of items added to the size when a request is fully processed
+
+	// A table deleted at runtime makes the requests fail fast until the table is
+	// active again, which is checked every TableCheckInterval. The table is
+	// recreated by the check if AutoCreateTable is set.
+	AutoCreateTable    bool
+	TableCheckInterval time.Duration
+
+	// BatchWriteRetryLimit is how long the items of a batch write are retried
+	// before they are abandoned, which makes the batch write fail and marks the
+	// database as degraded until a batch write succeeds.
+	BatchWriteRetryLimit time.Duration
+
+	// A request of a batch write which fails or leaves items unprocessed is
+	// retried after BatchWriteBackoff, which is doubled for each retry up to
+	// BatchWriteMaxBackoff with a random jitter. The request is abandoned after
+	// BatchWriteMaxRetries retries, which is unlimited if 0.
+	BatchWriteBackoff    time.Duration
+	BatchWriteMaxBackoff time.Duration
+	BatchWriteMaxRetries int
+
+	// BatchWriteRetryRate is the number of retries per second allowed to the
+	// batch write workers in total, which are shared by all the databases. It is
+	// set by the first database, and the default value is used for 0.
+	BatchWriteRetryRate float64
+
+	// WorkerNum is the number of the batch write workers, which are shared by
+	// all the databases, and the buffer of their write channel is twice as
+	// large. It is set by the first database, and WorkerNum of the package is
+	// used for 0.
+	WorkerNum int
+
+	// MaxBufferedBytes is the maximum bytes of the items dispatched by the
+	// batches of all the databases but not written yet. A batch dispatching the
+	// items beyond it waits for the batch write workers to write the buffered
+	// items, which bounds the memory during the write bursts against a throttled
+	// table. It is set by the first database, and the buffered bytes are not
+	// bounded if it is 0.
+	MaxBufferedBytes int
+
+	// ScanReadAhead is the number of Scan pages read ahead by the scan
+	// iterators while the current page is consumed. The read-ahead is disabled
+	// if it is 0.
+	ScanReadAhead int
+
+	// IteratorMaxItems is the maximum number of the items in the range of an
+	// iterator created by NewIterator or NewIteratorWithRange, which are held
+	// in memory to be sorted. The iterator stops with an error beyond it, and
+	// NewScanIterator should be used instead. The default value is used for 0.
+	IteratorMaxItems int
+
+	// BulkLoadChunkSize is the size of the chunks of the items staged in S3 by
+	// BulkLoad, and BulkLoadConcurrency is the number of the loaders writing the
+	// staged chunks to the table. The default values are used for 0.
+	BulkLoadChunkSize   int
+	BulkLoadConcurrency int
+
+	// OversizedSweepInterval is the interval of the sweeps verifying that the
+	// S3 objects of the oversized items exist, which are disabled if it is 0.
+	// A sweep scans 1/OversizedSweepSegments of the table in turn, and its Scan
+	// and S3 requests are limited to OversizedSweepRate per second. The default
+	// values are used for 0. The missing objects are logged and metered, and
+	// written back from OversizedRepairSource if it is set.
+	OversizedSweepInterval time.Duration
+	OversizedSweepSegments int
+	OversizedSweepRate     float64
+	OversizedRepairSource  OversizedRepairSource `toml:"-"`
+
+	// S3KeyDeriver derives the S3 object keys of oversized items. If it is nil,
+	// the hex encoded item key is used.
+	S3KeyDeriver S3KeyDeriver `toml:"-"`
+
+	// ItemCodec converts the key-value pairs to DynamoDB items and back. If it
+	// is nil, the items are stored as DynamoData.
+	ItemCodec DynamoItemCodec `toml:"-"`
+
+	// ItemSizer counts the size of the items in a batch, which is reported by
+	// ValueSize. If it is nil, the keys, values and attribute names are counted
+	// with a fixed per-item overhead.
+	ItemSizer DynamoItemSizer `toml:"-"`
 }
 
 type batchWriteWorkerInput struct {
 	tableName string
 	items     []*dynamodb.WriteRequest
 	wg        *sync.WaitGroup
+	slowOps   *slowOpLogger
+	result    *batchWriteResult  // collects the error of the items, which can be nil
+	batchSize *adaptiveBatchSize // splits the items into smaller requests under throttling, which can be nil
+	table     *tableWatcher      // detects the table deleted at runtime, which can be nil
+	retries   *batchWriteRetries // bounds the retries of the items, which can be nil
+	buffered  int                // the bytes of the items in dynamoWriteBuffer, released when they are written
+
+	fileDeletes *batchFileDeletes // deletes the fileDB items of the deleted oversized items, which can be nil
+}
+
+// batchFileDeletes deletes the fileDB items of the oversized items deleted by
+// a batch write. The oversized items are found before the write, since the
+// deleted items can't be read after it, and their fileDB items are deleted
+// after the write succeeds.
+type batchFileDeletes struct {
+	db   *dynamoDB
+	keys [][]byte // the deleted keys, which may not be oversized
+}
+
+// oversizedKeys returns the deleted keys whose values are stored in fileDB.
+func (d *batchFileDeletes) oversizedKeys() [][]byte {
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(d.keys))
+	for _, key := range d.keys {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{"Key": {B: key}})
+	}
+	var oversized [][]byte
+	for start := 0; start < len(keys); start += dynamoBatchGetSize {
+		end := start + dynamoBatchGetSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		items, err := d.db.batchGetItems(keys[start:end])
+		if err != nil {
+			d.db.logger.Error("cannot find the oversized items to be deleted from fileDB", "err", err, "numKeys", end-start)
+			continue
+		}
+		for _, item := range items {
+			key, val, err := d.db.codec().Decode(item)
+			if err == nil && bytes.Equal(val, overSizedDataPrefix) {
+				oversized = append(oversized, key)
+			}
+		}
+	}
+	return oversized
+}
+
+// deleteFiles deletes the fileDB items of the keys.
+func (d *batchFileDeletes) deleteFiles(keys [][]byte) {
+	for _, key := range keys {
+		if err := d.db.fdb.delete(key); err != nil {
+			d.db.logger.Error("cannot delete an item from fileDB. check the status of s3",
+				"err", err, "key", hexutil.Encode(key))
+		}
+	}
+}
+
+// batchWriteResult holds the first error of the items dispatched by a batch write.
+type batchWriteResult struct {
+	mu  sync.Mutex
+	err error
+
+	writes *dynamoWrites // the writes of the database, which can be nil
+}
+
+func (r *batchWriteResult) fail(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+	if r.writes != nil {
+		r.writes.result.fail(err)
+	}
+}
+
+// add marks that an item set is dispatched, and done marks that it is written.
+// add returns errDynamoClosed if the database is closed.
+func (r *batchWriteResult) add() error {
+	if r != nil && r.writes != nil {
+		return r.writes.add()
+	}
+	return nil
+}
+
+func (r *batchWriteResult) done() {
+	if r != nil && r.writes != nil {
+		r.writes.done()
+	}
+}
+
+func (r *batchWriteResult) error() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// dynamoWrites tracks the batch writes of a database which are not written yet,
+// and holds the first error of them, which is returned by Close.
+type dynamoWrites struct {
+	mu      sync.RWMutex
+	closed  bool          // set by Close, after which no writes are dispatched
+	pending int           // the number of the writes not written yet
+	idle    chan struct{} // closed when pending drops to zero
+	result  batchWriteResult
+}
+
+func (w *dynamoWrites) add() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errDynamoClosed
+	}
+	if w.pending == 0 {
+		w.idle = make(chan struct{})
+	}
+	w.pending++
+	return nil
+}
+
+func (w *dynamoWrites) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending--
+	if w.pending == 0 {
+		close(w.idle)
+	}
+}
+
+// checkOpen returns errDynamoClosed if the database is closed.
+func (w *dynamoWrites) checkOpen() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errDynamoClosed
+	}
+	return nil
+}
+
+// close stops accepting the writes, so that the pending writes waited by wait
+// are not added anymore.
+func (w *dynamoWrites) close() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
+
+// newResult returns a batchWriteResult whose error is also kept by the writes.
+func (w *dynamoWrites) newResult() *batchWriteResult {
+	return &batchWriteResult{writes: w}
+}
+
+// wait waits for the pending writes until timeout, and returns their first error.
+func (w *dynamoWrites) wait(timeout time.Duration) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	pending, idle := w.pending, w.idle
+	w.mu.RUnlock()
+	if pending == 0 {
+		return w.result.error()
+	}
+	select {
+	case <-idle:
+	case <-time.After(timeout):
+		return fmt.Errorf("%w after %v", errDynamoCloseTimeout, timeout)
+	}
+	return w.result.error()
 }
 
 // TODO-Klaytn refactor the structure : there are common configs that are placed separated
@@ -103,6 +533,14 @@ type dynamoDB struct {
 	fdb    fileDB     // where over size items are stored
 	logger log.Logger // Contextual logger tracking the database path
 
+	breaker   *circuitBreaker    // fails fast during sustained outages, nil if disabled
+	slowOps   *slowOpLogger      // warns about slow operations, nil if disabled
+	batchSize *adaptiveBatchSize // adjusts the size of batch write requests under throttling, nil if disabled
+	table     *tableWatcher      // detects the table deleted at runtime
+	retries   *batchWriteRetries // bounds the retries of batch writes, nil if disabled
+	writes    *dynamoWrites      // the batch writes not written yet, which are flushed by Close
+	sweeper   *oversizedSweeper  // verifies the S3 objects of the oversized items, nil if disabled
+
 	// metrics
 	getTimer klaytnmetrics.HybridTimer
 	putTimer klaytnmetrics.HybridTimer
@@ -122,9 +560,17 @@ type CustomRetryer struct {
 }
 
 // ShouldRetry overrides AWS SDK's built in DefaultRetryer to retry in all error cases.
+// A request rejected due to a region mismatch is not retried, since it fails
+// in the same way until the region is changed. So is a read of an invalid range.
 func (r CustomRetryer) ShouldRetry(req *request.Request) bool {
 	logger.Debug("dynamoDB client retry", "error", req.Error, "retryCnt", req.RetryCount, "retryDelay",
 		req.RetryDelay, "maxRetry", r.MaxRetries())
+	if expectedRegion(req.Error) != "" {
+		return false
+	}
+	if aerr, ok := req.Error.(awserr.Error); ok && aerr.Code() == s3ErrCodeInvalidRange {
+		return false
+	}
 	return req.Error != nil && req.RetryCount < r.MaxRetries()
 }
 
@@ -140,19 +586,250 @@ func GetDefaultDynamoDBConfig() *DynamoDBConfig {
 		Endpoint:           "", // nil or "" means the default generated endpoint
 		TableName:          "klaytn-default" + strconv.Itoa(time.Now().Nanosecond()),
 		IsProvisioned:      false,
-		ReadCapacityUnits:  10000,
-		WriteCapacityUnits: 10000,
+		ReadCapacityUnits:  defaultDynamoReadCapacityUnits,
+		WriteCapacityUnits: defaultDynamoWriteCapacityUnits,
 		ReadOnly:           false,
 		PerfCheck:          true,
+		BreakerThreshold:   0,
+		BreakerWindow:      time.Minute,
+		BreakerCooldown:    30 * time.Second,
+		SlowOpThreshold:    defaultDynamoSlowOpThreshold,
+
+		S3MultipartThreshold: defaultS3MultipartThreshold,
 	}
 }
 
+// validateAndSetDefaults checks that the required fields of the config are given
+// and fills the optional fields with their default values.
+// All problems found are reported together in the returned error.
+func (c *DynamoDBConfig) validateAndSetDefaults() error {
+	if c == nil {
+		return nilDynamoConfigErr
+	}
+
+	var errs []string
+	if len(c.TableName) == 0 {
+		errs = append(errs, noTableNameErr.Error())
+	}
+	if len(c.Region) == 0 {
+		errs = append(errs, noRegionErr.Error())
+	} else if len(c.Endpoint) == 0 {
+		resolved, err := c.endpointResolver().EndpointFor(dynamodb.EndpointsID, c.Region)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to resolve dynamoDB endpoint of region %q: %v", c.Region, err))
+		} else {
+			c.Endpoint = resolved.URL
+		}
+	}
+
+	// the capacity units are used only by a provisioned table, which must be
+	// given them explicitly. They are ignored on demand.
+	if c.IsProvisioned {
+		if c.ReadCapacityUnits <= 0 {
+			errs = append(errs, fmt.Sprintf("dynamoDB read capacity units must be positive in the provisioned mode: %d", c.ReadCapacityUnits))
+		}
+		if c.WriteCapacityUnits <= 0 {
+			errs = append(errs, fmt.Sprintf("dynamoDB write capacity units must be positive in the provisioned mode: %d", c.WriteCapacityUnits))
+		}
+	} else {
+		if c.ReadCapacityUnits == 0 {
+			c.ReadCapacityUnits = defaultDynamoReadCapacityUnits
+		}
+		if c.WriteCapacityUnits == 0 {
+			c.WriteCapacityUnits = defaultDynamoWriteCapacityUnits
+		}
+	}
+	if c.S3CompressionThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("S3 compression threshold must not be negative: %d", c.S3CompressionThreshold))
+	}
+	if c.S3MultipartThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("S3 multipart threshold must not be negative: %d", c.S3MultipartThreshold))
+	}
+	if c.S3ReadMaxRetries < 0 || c.S3WriteMaxRetries < 0 {
+		errs = append(errs, fmt.Sprintf("S3 max retries must not be negative: read %d, write %d", c.S3ReadMaxRetries, c.S3WriteMaxRetries))
+	}
+	switch c.S3ServerSideEncryption {
+	case "", s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms:
+	default:
+		errs = append(errs, fmt.Sprintf("S3 server-side encryption must be %q or %q: %q",
+			s3.ServerSideEncryptionAes256, s3.ServerSideEncryptionAwsKms, c.S3ServerSideEncryption))
+	}
+	if c.S3SSEKMSKeyID != "" && c.S3ServerSideEncryption != s3.ServerSideEncryptionAwsKms {
+		errs = append(errs, fmt.Sprintf("S3 KMS key ID requires the server-side encryption %q", s3.ServerSideEncryptionAwsKms))
+	}
+	if c.S3CacheSize < 0 {
+		errs = append(errs, fmt.Sprintf("S3 cache size must not be negative: %d", c.S3CacheSize))
+	}
+	// a table without a prefix owns the whole bucket, which is removed on reset
+	if c.S3Bucket != "" && c.S3Bucket != c.TableName && c.S3KeyPrefix == "" {
+		errs = append(errs, fmt.Sprintf("S3 key prefix is required for the bucket %q of the table %q", c.S3Bucket, c.TableName))
+	}
+	switch {
+	case c.TTLAttributeName == "" && c.TTLDuration != 0:
+		errs = append(errs, "TTL duration requires the TTL attribute name")
+	case c.TTLAttributeName == "Key":
+		errs = append(errs, "TTL attribute must not be the partition key \"Key\"")
+	case c.TTLAttributeName != "" && c.TTLDuration <= 0:
+		errs = append(errs, fmt.Sprintf("TTL duration must be positive: %v", c.TTLDuration))
+	}
+	if c.BatchGetMaxRetries < 0 {
+		errs = append(errs, fmt.Sprintf("BatchGetItem max retries must not be negative: %d", c.BatchGetMaxRetries))
+	}
+	if _, err := CompressionCodecID(c.ValueCompression); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if c.ValueCompressionThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("value compression threshold must not be negative: %d", c.ValueCompressionThreshold))
+	}
+	switch len(c.EncryptionKey) {
+	case 0, 16, 24, 32:
+	default:
+		errs = append(errs, fmt.Sprintf("encryption key must be 16, 24 or 32 bytes: %d", len(c.EncryptionKey)))
+	}
+	if _, err := parseAWSLogLevel(c.AWSLogLevel); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if c.SlowOpThreshold < 0 {
+		errs = append(errs, fmt.Sprintf("slow operation threshold must not be negative: %v", c.SlowOpThreshold))
+	}
+	if c.AdaptiveBatchMin > 0 {
+		if c.AdaptiveBatchMax == 0 {
+			c.AdaptiveBatchMax = dynamoBatchSize
+		}
+		if c.AdaptiveBatchDecrease == 0 {
+			c.AdaptiveBatchDecrease = defaultAdaptiveBatchDecrease
+		}
+		if c.AdaptiveBatchIncrease == 0 {
+			c.AdaptiveBatchIncrease = defaultAdaptiveBatchIncrease
+		}
+		if c.AdaptiveBatchMin > c.AdaptiveBatchMax || c.AdaptiveBatchMax > dynamoBatchSize {
+			errs = append(errs, fmt.Sprintf("adaptive batch size must be within 1 and %d: min %d, max %d", dynamoBatchSize, c.AdaptiveBatchMin, c.AdaptiveBatchMax))
+		}
+		if c.AdaptiveBatchDecrease <= 0 || c.AdaptiveBatchDecrease >= 1 {
+			errs = append(errs, fmt.Sprintf("adaptive batch decrease must be between 0 and 1: %v", c.AdaptiveBatchDecrease))
+		}
+		if c.AdaptiveBatchIncrease < 0 {
+			errs = append(errs, fmt.Sprintf("adaptive batch increase must be positive: %d", c.AdaptiveBatchIncrease))
+		}
+	} else if c.AdaptiveBatchMin < 0 {
+		errs = append(errs, fmt.Sprintf("adaptive batch min must not be negative: %d", c.AdaptiveBatchMin))
+	}
+	if c.TableCheckInterval == 0 {
+		c.TableCheckInterval = defaultDynamoTableCheckInterval
+	} else if c.TableCheckInterval < 0 {
+		errs = append(errs, fmt.Sprintf("table check interval must be positive: %v", c.TableCheckInterval))
+	}
+	if c.BatchWriteRetryLimit == 0 {
+		c.BatchWriteRetryLimit = defaultDynamoBatchWriteRetryLimit
+	} else if c.BatchWriteRetryLimit < 0 {
+		errs = append(errs, fmt.Sprintf("batch write retry limit must be positive: %v", c.BatchWriteRetryLimit))
+	}
+	if c.BatchWriteBackoff == 0 {
+		c.BatchWriteBackoff = defaultDynamoBatchWriteBackoff
+	} else if c.BatchWriteBackoff < 0 {
+		errs = append(errs, fmt.Sprintf("batch write backoff must be positive: %v", c.BatchWriteBackoff))
+	}
+	if c.BatchWriteMaxBackoff == 0 {
+		c.BatchWriteMaxBackoff = defaultDynamoBatchWriteMaxBackoff
+	}
+	if c.BatchWriteMaxBackoff < c.BatchWriteBackoff {
+		errs = append(errs, fmt.Sprintf("batch write max backoff %v must not be less than the backoff %v", c.BatchWriteMaxBackoff, c.BatchWriteBackoff))
+	}
+	if c.BatchWriteMaxRetries < 0 {
+		errs = append(errs, fmt.Sprintf("batch write max retries must not be negative: %d", c.BatchWriteMaxRetries))
+	}
+	if c.WorkerNum == 0 {
+		c.WorkerNum = WorkerNum
+	} else if c.WorkerNum < 0 {
+		errs = append(errs, fmt.Sprintf("dynamoDB worker number must be positive: %d", c.WorkerNum))
+	}
+	if c.BatchWriteRetryRate == 0 {
+		c.BatchWriteRetryRate = defaultDynamoBatchWriteRetryRate
+	} else if c.BatchWriteRetryRate < 0 {
+		errs = append(errs, fmt.Sprintf("batch write retry rate must be positive: %v", c.BatchWriteRetryRate))
+	}
+	if c.MaxBufferedBytes < 0 {
+		errs = append(errs, fmt.Sprintf("max buffered bytes must not be negative: %d", c.MaxBufferedBytes))
+	}
+	if c.ScanReadAhead < 0 {
+		errs = append(errs, fmt.Sprintf("scan read-ahead must not be negative: %d", c.ScanReadAhead))
+	}
+	if c.IteratorMaxItems == 0 {
+		c.IteratorMaxItems = defaultIteratorMaxItems
+	} else if c.IteratorMaxItems < 0 {
+		errs = append(errs, fmt.Sprintf("iterator max items must be positive: %d", c.IteratorMaxItems))
+	}
+	if c.BulkLoadChunkSize == 0 {
+		c.BulkLoadChunkSize = defaultBulkLoadChunkSize
+	} else if c.BulkLoadChunkSize < 0 {
+		errs = append(errs, fmt.Sprintf("bulk load chunk size must be positive: %d", c.BulkLoadChunkSize))
+	}
+	if c.BulkLoadConcurrency == 0 {
+		c.BulkLoadConcurrency = defaultBulkLoadConcurrency
+	} else if c.BulkLoadConcurrency < 0 {
+		errs = append(errs, fmt.Sprintf("bulk load concurrency must be positive: %d", c.BulkLoadConcurrency))
+	}
+
+	if c.OversizedSweepInterval < 0 {
+		errs = append(errs, fmt.Sprintf("oversized sweep interval must not be negative: %v", c.OversizedSweepInterval))
+	}
+	if c.OversizedSweepSegments == 0 {
+		c.OversizedSweepSegments = defaultOversizedSweepSegments
+	} else if c.OversizedSweepSegments < 0 {
+		errs = append(errs, fmt.Sprintf("oversized sweep segments must be positive: %d", c.OversizedSweepSegments))
+	}
+	if c.OversizedSweepRate == 0 {
+		c.OversizedSweepRate = defaultOversizedSweepRate
+	} else if c.OversizedSweepRate < 0 {
+		errs = append(errs, fmt.Sprintf("oversized sweep rate must be positive: %v", c.OversizedSweepRate))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid dynamoDB config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // NewDynamoDB creates either dynamoDB or dynamoDBReadOnly depending on config.ReadOnly.
 func NewDynamoDB(config *DynamoDBConfig) (Database, error) {
+	if err := config.validateAndSetDefaults(); err != nil {
+		logger.Error("invalid dynamoDB config", "err", err)
+		return nil, err
+	}
+	var (
+		db  Database
+		err error
+	)
 	if config.ReadOnly {
-		return newDynamoDBReadOnly(config)
+		db, err = newDynamoDBReadOnly(config)
+	} else {
+		db, err = newDynamoDB(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(config.EncryptionKey) > 0 {
+		encDB, err := NewEncryptedDatabase(db, config.EncryptionKey, config.EncryptedKeyPrefixes)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		db = encDB
+	}
+	// the values are compressed before they are encrypted
+	if config.ValueCompression != "" {
+		codec, _ := CompressionCodecID(config.ValueCompression)
+		compDB, err := NewCompressedDatabase(db, codec, config.ValueCompressionThreshold)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		db = compDB
 	}
-	return newDynamoDB(config)
+	if config.CoalesceGets {
+		db = NewCoalescingDatabase(db)
+	}
+	return db, nil
 }
 
 // newDynamoDB creates dynamoDB. dynamoDB can be used to create dynamoDBReadOnly.
@@ -166,36 +843,27 @@ func newDynamoDB(config *DynamoDBConfig) (*dynamoDB, error) {
 
 	config.TableName = strings.ReplaceAll(config.TableName, "_", "-")
 
-	s3FileDB, err := newS3FileDB(config.Region, config.S3Endpoint, config.TableName)
+	s3FileDB, err := newS3FileDBWithConfig(config)
 	if err != nil {
 		logger.Error("Unable to create/get S3FileDB", "DB", config.TableName)
 		return nil, err
 	}
 
-	if dynamoDBClient == nil {
-		dynamoDBClient = dynamodb.New(session.Must(session.NewSessionWithOptions(session.Options{
-			Config: aws.Config{
-				Retryer: CustomRetryer{
-					DefaultRetryer: client.DefaultRetryer{
-						NumMaxRetries:    dynamoMaxRetry,
-						MaxRetryDelay:    time.Second,
-						MaxThrottleDelay: time.Second,
-					},
-				},
-				Endpoint:         aws.String(config.Endpoint),
-				Region:           aws.String(config.Region),
-				S3ForcePathStyle: aws.Bool(true),
-				MaxRetries:       aws.Int(dynamoMaxRetry),
-				HTTPClient:       &http.Client{Timeout: dynamoTimeout}, // default client is &http.Client{}
-			},
-		})))
-	}
+	initDynamoDBClient(config)
 	dynamoDB := &dynamoDB{
-		config: *config,
-		fdb:    s3FileDB,
+		config:  *config,
+		fdb:     newCachedFileDB(s3FileDB, config.S3CacheSize),
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerWindow, config.BreakerCooldown),
+		batchSize: newAdaptiveBatchSize(config.AdaptiveBatchMin, config.AdaptiveBatchMax,
+			config.AdaptiveBatchDecrease, config.AdaptiveBatchIncrease),
+		writes: &dynamoWrites{},
 	}
 
 	dynamoDB.logger = logger.NewWith("region", config.Region, "tableName", dynamoDB.config.TableName)
+	dynamoDB.slowOps = newSlowOpLogger(config.SlowOpThreshold, slowOpLogInterval, dynamoDB.logger)
+	dynamoDB.table = newTableWatcher(config.TableCheckInterval, dynamoDB.checkTable, dynamoDB.logger)
+	dynamoDB.retries = newBatchWriteRetries(config.BatchWriteRetryLimit).withBackoff(
+		config.BatchWriteBackoff, config.BatchWriteMaxBackoff, config.BatchWriteMaxRetries)
 
 	// Check if the table is ready to serve
 	for {
@@ -216,13 +884,21 @@ func newDynamoDB(config *DynamoDBConfig) (*dynamoDB, error) {
 		switch tableStatus {
 		case dynamodb.TableStatusActive:
 			if !dynamoDB.config.ReadOnly {
+				if !dynamoDB.config.SkipWriteCheck {
+					if err := dynamoDB.checkWritable(); err != nil {
+						dynamoDB.logger.Error("DynamoDB or S3 is not writable", "err", err)
+						return nil, err
+					}
+				}
 				// count successful table creating
 				dynamoOpenedDBNum++
 				// create workers on the first successful table creation
 				dynamoOnceWorker.Do(func() {
-					createBatchWriteWorkerPool()
+					createBatchWriteWorkerPool(dynamoDB.config.WorkerNum, dynamoDB.config.BatchWriteRetryRate, dynamoDB.config.MaxBufferedBytes)
 				})
 			}
+			dynamoDB.sweeper = newOversizedSweeper(dynamoDB)
+			dynamoDB.sweeper.start()
 			dynamoDB.logger.Info("successfully created dynamoDB session")
 			return dynamoDB, nil
 		case dynamodb.TableStatusDeleting, dynamodb.TableStatusArchiving, dynamodb.TableStatusArchived:
@@ -234,9 +910,55 @@ func newDynamoDB(config *DynamoDBConfig) (*dynamoDB, error) {
 	}
 }
 
+// initDynamoDBClient creates the dynamoDB client shared by all tables if it is not created yet.
+func initDynamoDBClient(config *DynamoDBConfig) {
+	if dynamoDBClient == nil {
+		awsLogLevel, _ := parseAWSLogLevel(config.AWSLogLevel) // validated by validateAndSetDefaults
+		sess := session.Must(session.NewSessionWithOptions(session.Options{
+			Config: *setAWSLogLevel(&aws.Config{
+				Retryer: CustomRetryer{
+					DefaultRetryer: client.DefaultRetryer{
+						NumMaxRetries:    dynamoMaxRetry,
+						MaxRetryDelay:    time.Second,
+						MaxThrottleDelay: time.Second,
+					},
+				},
+				Endpoint:         aws.String(config.Endpoint),
+				EndpointResolver: config.endpointResolver(),
+				Region:           aws.String(config.Region),
+				S3ForcePathStyle: aws.Bool(true),
+				MaxRetries:       aws.Int(dynamoMaxRetry),
+				HTTPClient:       &http.Client{Timeout: dynamoTimeout}, // default client is &http.Client{}
+			}, awsLogLevel, logger),
+		}))
+		sess.Handlers.Complete.PushBackNamed(awsRequestLogger(logger, config.LogAWSRequests))
+		dynamoDBClient = dynamodb.New(sess)
+	}
+}
+
+// newS3FileDBWithConfig creates the s3FileDB storing the oversized items of the table.
+func newS3FileDBWithConfig(config *DynamoDBConfig) (*s3FileDB, error) {
+	awsLogLevel, _ := parseAWSLogLevel(config.AWSLogLevel) // validated by validateAndSetDefaults
+	bucket := config.S3Bucket
+	if bucket == "" {
+		bucket = config.TableName
+	}
+	return newS3FileDB(config.Region, config.S3Endpoint, bucket,
+		withS3KeyDeriver(config.S3KeyDeriver),
+		withS3KeyPrefix(config.S3KeyPrefix),
+		withS3RegionRedirect(config.AllowRegionRedirect),
+		withS3RequestLogging(config.LogAWSRequests),
+		withS3AWSLogLevel(awsLogLevel),
+		withS3EndpointResolver(config.EndpointResolver),
+		withS3CompressionThreshold(config.S3CompressionThreshold),
+		withS3MultipartThreshold(config.S3MultipartThreshold),
+		withS3MaxRetries(config.S3ReadMaxRetries, config.S3WriteMaxRetries),
+		withS3ServerSideEncryption(config.S3ServerSideEncryption, config.S3SSEKMSKeyID))
+}
+
 func (dynamo *dynamoDB) createTable() error {
 	input := &dynamodb.CreateTableInput{
-		BillingMode: aws.String("PAY_PER_REQUEST"),
+		BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{
 			{
 				AttributeName: aws.String("Key"),
@@ -254,7 +976,7 @@ func (dynamo *dynamoDB) createTable() error {
 	}
 
 	if dynamo.config.IsProvisioned {
-		input.BillingMode = aws.String("PROVISIONED")
+		input.BillingMode = aws.String(dynamodb.BillingModeProvisioned)
 		input.ProvisionedThroughput = &dynamodb.ProvisionedThroughput{
 			ReadCapacityUnits:  aws.Int64(dynamo.config.ReadCapacityUnits),
 			WriteCapacityUnits: aws.Int64(dynamo.config.WriteCapacityUnits),
@@ -268,6 +990,9 @@ func (dynamo *dynamoDB) createTable() error {
 		return err
 	}
 	dynamo.logger.Warn("Requesting create dynamoDB table. You will be charged until the table is deleted.")
+	if dynamo.config.TTLAttributeName != "" {
+		return dynamo.enableTTL()
+	}
 	return nil
 }
 
@@ -304,46 +1029,96 @@ func (dynamo *dynamoDB) Type() DBType {
 
 // Put inserts the given key and value pair to the database.
 func (dynamo *dynamoDB) Put(key []byte, val []byte) error {
+	return dynamo.PutContext(context.Background(), key, val)
+}
+
+// PutContext inserts the given key and value pair as Put does until ctx is
+// done. It returns the error of ctx if ctx is done before the write completes.
+func (dynamo *dynamoDB) PutContext(ctx context.Context, key []byte, val []byte) error {
+	start := time.Now()
+	err := dynamo.put(ctx, key, val)
+	elapsed := time.Since(start)
 	if dynamo.config.PerfCheck {
-		start := time.Now()
-		err := dynamo.put(key, val)
-		dynamo.putTimer.Update(time.Since(start))
-		return err
+		dynamo.putTimer.Update(elapsed)
 	}
-	return dynamo.put(key, val)
+	dynamo.slowOps.observe("put", key, len(val), elapsed)
+	return err
 }
 
-func (dynamo *dynamoDB) put(key []byte, val []byte) error {
-	if len(key) == 0 {
-		return nil
+// checkEmptyKey returns errEmptyKey for a zero-length key if StrictEmptyKeys is
+// set. Otherwise, it returns true for a zero-length key, which should be ignored.
+func (dynamo *dynamoDB) checkEmptyKey(key []byte) (bool, error) {
+	if len(key) != 0 {
+		return false, nil
+	}
+	if dynamo.config.StrictEmptyKeys {
+		return false, errEmptyKey
+	}
+	return true, nil
+}
+
+func (dynamo *dynamoDB) put(ctx context.Context, key []byte, val []byte) error {
+	if ignore, err := dynamo.checkEmptyKey(key); ignore || err != nil {
+		return err
+	}
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return err
 	}
 
 	if len(val) > dynamoWriteSizeLimit {
 		_, err := dynamo.fdb.write(item{key: key, val: val})
 		if err != nil {
-			return err
+			return dynamo.opError("PutObject", key, err)
 		}
-		return dynamo.Put(key, overSizedDataPrefix)
+		// the object is left in S3 if ctx is done, and overwritten by the next put
+		val = overSizedDataPrefix
 	}
 
-	data := DynamoData{Key: key, Val: val}
-	marshaledData, err := dynamodbattribute.MarshalMap(data)
+	_, err := dynamo.putItem(ctx, key, val, false)
+	return err
+}
+
+// putItem writes the item by a PutItem request. If returnOld is set, it
+// returns the attributes of the overwritten item, which are empty if the key
+// did not exist.
+func (dynamo *dynamoDB) putItem(ctx context.Context, key []byte, val []byte, returnOld bool) (map[string]*dynamodb.AttributeValue, error) {
+	marshaledData, err := dynamo.encodeItem(key, val)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	params := &dynamodb.PutItemInput{
 		TableName: aws.String(dynamo.config.TableName),
 		Item:      marshaledData,
 	}
+	if returnOld {
+		params.ReturnValues = aws.String(dynamodb.ReturnValueAllOld)
+	}
 
-	_, err = dynamoDBClient.PutItem(params)
+	if err := dynamo.table.allow(); err != nil {
+		return nil, err
+	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return nil, err
+	}
+	output, err := dynamoDBClient.PutItemWithContext(ctx, params)
+	if ctx.Err() != nil {
+		// the cancellation by the caller is not a result of DynamoDB
+		dynamo.breaker.release()
+		return nil, ctx.Err()
+	}
+	dynamo.breaker.done(err)
 	if err != nil {
-		dynamo.logger.Crit("failed to put an item", "err", err, "key", hexutil.Encode(data.Key))
-		return err
+		if dynamo.table.observe(err) {
+			return nil, dynamo.opError("PutItem", key, err)
+		}
+		dynamo.logFailure("failed to put an item", "err", err, "key", hexutil.Encode(key))
+		return nil, dynamo.opError("PutItem", key, err)
 	}
-
-	return nil
+	if output == nil {
+		return nil, nil
+	}
+	return output.Attributes, nil
 }
 
 // Has returns true if the corresponding value to the given key exists.
@@ -358,17 +1133,55 @@ func (dynamo *dynamoDB) Has(key []byte) (bool, error) {
 }
 
 // Get returns the corresponding value to the given key if exists.
+// Get reads the item with a strongly consistent read unless the eventually
+// consistent reads are configured.
 func (dynamo *dynamoDB) Get(key []byte) ([]byte, error) {
+	return dynamo.GetWithConsistency(key, !dynamo.config.EventuallyConsistentReads)
+}
+
+// GetWithMeta reads the item as Get does, and reports if the read is strongly
+// consistent. The age of the value is not reported, since DynamoDB doesn't
+// tell when an item was written.
+func (dynamo *dynamoDB) GetWithMeta(key []byte) ([]byte, ReadMeta, error) {
+	strong := !dynamo.config.EventuallyConsistentReads
+	val, err := dynamo.GetWithConsistency(key, strong)
+	return val, ReadMeta{Consistent: strong}, err
+}
+
+// GetWithConsistency reads the item with a strongly consistent read if strong is true,
+// or with an eventually consistent read which consumes a half of read capacity otherwise.
+func (dynamo *dynamoDB) GetWithConsistency(key []byte, strong bool) ([]byte, error) {
+	return dynamo.getContext(context.Background(), key, strong)
+}
+
+// GetContext reads the item as Get does within the deadline of ctx. If ctx has
+// a deadline, GetItem is given a half of the remaining time, so that the rest is
+// left for reading an oversized value from S3 and the whole read respects the
+// deadline. It returns the error of ctx if the deadline is exceeded.
+func (dynamo *dynamoDB) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	return dynamo.getContext(ctx, key, !dynamo.config.EventuallyConsistentReads)
+}
+
+func (dynamo *dynamoDB) getContext(ctx context.Context, key []byte, strong bool) ([]byte, error) {
+	start := time.Now()
+	val, err := dynamo.get(ctx, key, strong)
+	elapsed := time.Since(start)
 	if dynamo.config.PerfCheck {
-		start := time.Now()
-		val, err := dynamo.get(key)
-		dynamo.getTimer.Update(time.Since(start))
-		return val, err
+		dynamo.getTimer.Update(elapsed)
 	}
-	return dynamo.get(key)
+	dynamo.slowOps.observe("get", key, len(val), elapsed)
+	return val, err
 }
 
-func (dynamo *dynamoDB) get(key []byte) ([]byte, error) {
+func (dynamo *dynamoDB) get(ctx context.Context, key []byte, strong bool) ([]byte, error) {
+	if ignore, err := dynamo.checkEmptyKey(key); err != nil {
+		return nil, err
+	} else if ignore {
+		return nil, dataNotFoundErr
+	}
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return nil, err
+	}
 	params := &dynamodb.GetItemInput{
 		TableName: aws.String(dynamo.config.TableName),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -376,42 +1189,92 @@ func (dynamo *dynamoDB) get(key []byte) ([]byte, error) {
 				B: key,
 			},
 		},
-		ConsistentRead: aws.Bool(true),
+		ConsistentRead: aws.Bool(strong),
 	}
 
-	result, err := dynamoDBClient.GetItem(params)
-	if err != nil {
-		dynamo.logger.Crit("failed to get an item", "err", err, "key", hexutil.Encode(key))
+	if err := dynamo.table.allow(); err != nil {
 		return nil, err
 	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return nil, err
+	}
+	getCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		getCtx, cancel = context.WithTimeout(ctx, time.Until(deadline)/2)
+		defer cancel()
+	}
+	result, err := dynamoDBClient.GetItemWithContext(getCtx, params)
+	if getCtx.Err() != nil {
+		// the deadline of the caller is not a result of DynamoDB
+		dynamo.breaker.release()
+		return nil, getCtx.Err()
+	}
+	dynamo.breaker.done(err)
+	if err != nil {
+		if dynamo.table.observe(err) {
+			return nil, dynamo.opError("GetItem", key, err)
+		}
+		dynamo.logFailure("failed to get an item", "err", err, "key", hexutil.Encode(key))
+		return nil, dynamo.opError("GetItem", key, err)
+	}
 
 	if result.Item == nil {
 		return nil, dataNotFoundErr
 	}
 
-	var data DynamoData
-	if err := dynamodbattribute.UnmarshalMap(result.Item, &data); err != nil {
+	_, val, err := dynamo.codec().Decode(result.Item)
+	if err != nil {
 		dynamo.logger.Crit("failed to unmarshal dynamodb data", "err", err)
 		return nil, err
 	}
 
-	if data.Val == nil {
+	if val == nil {
 		return []byte{}, nil
 	}
 
-	if bytes.Equal(data.Val, overSizedDataPrefix) {
-		ret, err := dynamo.fdb.read(key)
+	if bytes.Equal(val, overSizedDataPrefix) {
+		ret, err := dynamo.fdb.readContext(ctx, key)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if err != nil {
 			dynamo.logger.Crit("failed to read filedb data", "err", err, "key", hexutil.Encode(key))
 		}
-		return ret, err
+		return ret, dynamo.opError("GetObject", key, err)
 	}
 
+	return val, nil
+}
+
+// itemValue returns the value of a DynamoDB item. The binary attribute is read
+// directly in the common case, and dynamodbattribute.UnmarshalMap, which is
+// based on reflection, is used only if the attribute is missing or not binary.
+func itemValue(item map[string]*dynamodb.AttributeValue) ([]byte, error) {
+	if av := item["Val"]; av != nil && av.NULL == nil && av.B != nil {
+		return av.B, nil
+	}
+	var data DynamoData
+	if err := dynamodbattribute.UnmarshalMap(item, &data); err != nil {
+		return nil, err
+	}
 	return data.Val, nil
 }
 
 // Delete deletes the key from the queue and database
 func (dynamo *dynamoDB) Delete(key []byte) error {
+	return dynamo.DeleteContext(context.Background(), key)
+}
+
+// DeleteContext deletes the key as Delete does until ctx is done. It returns
+// the error of ctx if ctx is done before the deletion completes.
+func (dynamo *dynamoDB) DeleteContext(ctx context.Context, key []byte) error {
+	if ignore, err := dynamo.checkEmptyKey(key); ignore || err != nil {
+		return err
+	}
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return err
+	}
 	params := &dynamodb.DeleteItemInput{
 		TableName: aws.String(dynamo.config.TableName),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -419,32 +1282,156 @@ func (dynamo *dynamoDB) Delete(key []byte) error {
 				B: key,
 			},
 		},
+		// the deleted item tells if its value is stored in S3
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	}
+	if dynamo.config.StrictDeletes {
+		params.ConditionExpression = aws.String("attribute_exists(#k)")
+		params.ExpressionAttributeNames = map[string]*string{"#k": aws.String("Key")}
+	}
+
+	if err := dynamo.table.allow(); err != nil {
+		return err
+	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return err
+	}
+	output, err := dynamoDBClient.DeleteItemWithContext(ctx, params)
+	if ctx.Err() != nil {
+		dynamo.breaker.release()
+		return ctx.Err()
 	}
 
-	_, err := dynamoDBClient.DeleteItem(params)
+	// a missing key is not a failure of DynamoDB
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		dynamo.breaker.done(nil)
+		return dataNotFoundErr
+	}
+	dynamo.breaker.done(err)
 	if err != nil {
-		dynamo.logger.Crit("failed to delete an item", "err", err, "key", hexutil.Encode(key))
+		if dynamo.table.observe(err) {
+			return dynamo.opError("DeleteItem", key, err)
+		}
+		dynamo.logFailure("failed to delete an item", "err", err, "key", hexutil.Encode(key))
+		return dynamo.opError("DeleteItem", key, err)
+	}
+
+	if output == nil || len(output.Attributes) == 0 {
+		return nil
+	}
+	_, val, err := dynamo.codec().Decode(output.Attributes)
+	if err != nil || !bytes.Equal(val, overSizedDataPrefix) {
+		return nil
+	}
+	if dynamo.config.StrictDeletes {
+		err = dynamo.fdb.deleteExisting(key)
+	} else {
+		err = dynamo.fdb.delete(key)
+	}
+	if err == dataNotFoundErr {
 		return err
 	}
+	if err != nil {
+		dynamo.logger.Error("failed to delete filedb data", "err", err, "key", hexutil.Encode(key))
+	}
+	return dynamo.opError("DeleteObject", key, err)
+}
+
+// checkKeyLength returns an error wrapping errKeyTooLong if the key is longer
+// than maxLen. It is checked before a request is sent, so that the request
+// doesn't fail with an obscure error of the backend.
+func checkKeyLength(key []byte, maxLen int) error {
+	if len(key) > maxLen {
+		return fmt.Errorf("%w: %d bytes, the maximum is %d bytes", errKeyTooLong, len(key), maxLen)
+	}
 	return nil
 }
 
-func (dynamo *dynamoDB) Close() {
+// logFailure logs a failed DynamoDB request. A failure is critical unless the circuit
+// breaker is enabled, in which case the node keeps running and reports the degraded state.
+func (dynamo *dynamoDB) logFailure(msg string, ctx ...interface{}) {
+	if dynamo.breaker != nil {
+		dynamo.logger.Error(msg, ctx...)
+		return
+	}
+	dynamo.logger.Crit(msg, ctx...)
+}
+
+// Close stops accepting the batch writes, and waits for the pending ones to be
+// flushed, up to dynamoCloseTimeout.
+// It returns the first error of the batch writes of the database, or an error if
+// they are not flushed in time.
+func (dynamo *dynamoDB) Close() error {
+	dynamo.table.stop()
+	dynamo.sweeper.stop()
+	dynamo.writes.close()
+	err := dynamo.writes.wait(dynamoCloseTimeout)
+	if err != nil {
+		dynamo.logger.Error("Failed to flush the pending batch writes", "err", err)
+	}
 	if dynamoOpenedDBNum > 0 {
 		dynamoOpenedDBNum--
 	}
 	if dynamoOpenedDBNum == 0 && dynamoWriteCh != nil {
 		close(dynamoWriteCh)
 	}
+	return err
+}
+
+// Flush waits for the pending batch writes without closing the database.
+func (dynamo *dynamoDB) Flush() error {
+	return dynamo.writes.wait(dynamoCloseTimeout)
 }
 
 func (dynamo *dynamoDB) Meter(prefix string) {
 	dynamo.getTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"get/time", nil)
 	dynamo.putTimer = klaytnmetrics.NewRegisteredHybridTimer(prefix+"put/time", nil)
 	dynamoBatchWriteTimeMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/time", nil)
+	dynamoBatchWriteCountMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/count", nil)
+	dynamoBatchWriteBytesMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/bytes", nil)
+	dynamoBatchWriteTimePerItemMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/timeperitem", nil)
+	dynamoBatchWriteTimePerByteMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/timeperbyte", nil)
+	dynamoUnprocessedItemMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/unprocessed", nil)
+	dynamoRetryBudgetExhaustedMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/retrybudget/exhausted", nil)
+	dynamoBufferedBytesGauge = metrics.NewRegisteredGauge(prefix+"batchwrite/buffered", nil)
+	if dynamo.breaker != nil {
+		dynamo.breaker.stateGauge = metrics.NewRegisteredGauge(prefix+"breaker/state", nil)
+	}
+	if cache, ok := dynamo.fdb.(*cachedFileDB); ok {
+		cache.hitMeter = metrics.NewRegisteredMeter(prefix+"s3cache/hit", nil)
+		cache.missMeter = metrics.NewRegisteredMeter(prefix+"s3cache/miss", nil)
+	}
+	if dynamo.table != nil {
+		dynamo.table.missingGauge = metrics.NewRegisteredGauge(prefix+"table/missing", nil)
+	}
+	if dynamo.retries != nil {
+		dynamo.retries.retryMeter = metrics.NewRegisteredMeter(prefix+"batchwrite/retries", nil)
+		dynamo.retries.retryingGauge = metrics.NewRegisteredGauge(prefix+"batchwrite/retrying", nil)
+		dynamo.retries.degradedGauge = metrics.NewRegisteredGauge(prefix+"batchwrite/degraded", nil)
+	}
+	if dynamo.sweeper != nil {
+		dynamo.sweeper.checkedMeter = metrics.NewRegisteredMeter(prefix+"oversized/sweep/checked", nil)
+		dynamo.sweeper.missingMeter = metrics.NewRegisteredMeter(prefix+"oversized/sweep/missing", nil)
+		dynamo.sweeper.repairedMeter = metrics.NewRegisteredMeter(prefix+"oversized/sweep/repaired", nil)
+	}
+	if dynamo.batchSize != nil {
+		dynamo.batchSize.sizeGauge = metrics.NewRegisteredGauge(prefix+"batchwrite/size", nil)
+		dynamo.batchSize.sizeGauge.Update(int64(dynamo.batchSize.size()))
+	}
 }
 
 func (dynamo *dynamoDB) GetProperty(name string) string {
+	switch name {
+	case dynamoBreakerProperty:
+		if dynamo.breaker == nil {
+			return "disabled"
+		}
+		return dynamo.breaker.State().String()
+	case dynamoTableProperty:
+		return dynamo.table.state()
+	case dynamoBatchWriteProperty:
+		return dynamo.retries.state()
+	}
 	return ""
 }
 
@@ -452,17 +1439,16 @@ func (dynamo *dynamoDB) TryCatchUpWithPrimary() error {
 	return nil
 }
 
-func (dynamo *dynamoDB) NewIterator(prefix []byte, start []byte) Iterator {
-	// TODO-Klaytn: implement this later.
-	return nil
-}
-
-func createBatchWriteWorkerPool() {
-	dynamoWriteCh = make(chan *batchWriteWorkerInput, itemChanSize)
-	for i := 0; i < WorkerNum; i++ {
+func createBatchWriteWorkerPool(workerNum int, retryRate float64, maxBufferedBytes int) {
+	dynamoRetryBudget = newRetryBudget(retryRate, workerNum)
+	if maxBufferedBytes > 0 {
+		dynamoWriteBuffer = newWriteBuffer(maxBufferedBytes)
+	}
+	dynamoWriteCh = make(chan *batchWriteWorkerInput, workerNum*2)
+	for i := 0; i < workerNum; i++ {
 		go createBatchWriteWorker(dynamoWriteCh)
 	}
-	logger.Info("made dynamo batch write workers", "workerNum", WorkerNum)
+	logger.Info("made dynamo batch write workers", "workerNum", workerNum, "retryRate", retryRate, "maxBufferedBytes", maxBufferedBytes)
 }
 
 func createBatchWriteWorker(writeCh <-chan *batchWriteWorkerInput) {
@@ -470,83 +1456,237 @@ func createBatchWriteWorker(writeCh <-chan *batchWriteWorkerInput) {
 	logger.Debug("generate a dynamoDB batchWrite worker")
 
 	for batchInput := range writeCh {
-		batchWriteInput := &dynamodb.BatchWriteItemInput{
-			RequestItems: map[string][]*dynamodb.WriteRequest{},
-		}
-		batchWriteInput.RequestItems[batchInput.tableName] = batchInput.items
-
-		BatchWriteItemOutput, err := dynamoDBClient.BatchWriteItem(batchWriteInput)
-		numUnprocessed := len(BatchWriteItemOutput.UnprocessedItems[batchInput.tableName])
-		for err != nil || numUnprocessed != 0 {
-			if err != nil {
-				// ValidationException occurs when a required parameter is missing, a value is out of range,
-				// or data types mismatch and so on. If this is the case, check if there is a duplicated key,
-				// batch length out of range, null value and so on.
-				// When ValidationException occurs, retrying won't fix the problem.
-				if strings.Contains(err.Error(), "ValidationException") {
-					logger.Crit("Invalid input for dynamoDB BatchWrite",
-						"err", err, "tableName", batchInput.tableName, "itemNum", len(batchInput.items))
-				}
-				failCount++
-				logger.Warn("dynamoDB failed to write batch items",
-					"tableName", batchInput.tableName, "err", err, "failCnt", failCount)
-				if failCount > dynamoMaxRetry {
-					logger.Error("dynamoDB failed many times. sleep a second and retry",
-						"tableName", batchInput.tableName, "failCnt", failCount)
-					time.Sleep(time.Second)
-				}
-			}
+		writeStart := time.Now()
 
-			if numUnprocessed != 0 {
-				logger.Debug("dynamoDB batchWrite remains unprocessedItem",
-					"tableName", batchInput.tableName, "numUnprocessedItem", numUnprocessed)
-				batchWriteInput.RequestItems[batchInput.tableName] = BatchWriteItemOutput.UnprocessedItems[batchInput.tableName]
+		var oversized [][]byte
+		if batchInput.fileDeletes != nil {
+			oversized = batchInput.fileDeletes.oversizedKeys()
+		}
+
+		// the items are split into smaller requests if the adaptive batch size is reduced
+		abandoned := false
+		for items := batchInput.items; len(items) > 0 && !abandoned; {
+			n := batchInput.batchSize.size()
+			if n > len(items) {
+				n = len(items)
 			}
+			abandoned = batchWriteItems(batchInput, items[:n], &failCount)
+			items = items[n:]
+		}
+		batchInput.retries.done(batchInput, abandoned)
+		dynamoWriteBuffer.release(batchInput.buffered)
+		// the fileDB items are kept if the deletes may not be written
+		if len(oversized) > 0 && !abandoned {
+			batchInput.fileDeletes.deleteFiles(oversized)
+		}
 
-			start := time.Now()
-			BatchWriteItemOutput, err = dynamoDBClient.BatchWriteItem(batchWriteInput)
-			dynamoBatchWriteTimeMeter.Mark(int64(time.Since(start)))
-			numUnprocessed = len(BatchWriteItemOutput.UnprocessedItems)
+		// the elapsed time includes the retries of the unprocessed items
+		elapsed := time.Since(writeStart)
+		size := 0
+		for _, req := range batchInput.items {
+			size += requestSize(req, defaultDynamoItemSize)
+		}
+		markBatchWriteMeters(len(batchInput.items), size, elapsed)
+		if batchInput.slowOps != nil {
+			batchInput.slowOps.observe("batchWrite", writeRequestKey(batchInput.items[0]), size,
+				elapsed, "items", len(batchInput.items))
 		}
 
 		failCount = 0
+		batchInput.result.done()
 		batchInput.wg.Done()
 	}
 	logger.Debug("close a dynamoDB batchWrite worker")
 }
 
+// batchWriteItems writes the items by a batch write request, and retries the
+// unprocessed items until all of them are written. It returns true if the
+// items are abandoned since they are retried longer than the retry limit.
+func batchWriteItems(batchInput *batchWriteWorkerInput, items []*dynamodb.WriteRequest, failCount *int) bool {
+	batchWriteInput := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]*dynamodb.WriteRequest{},
+	}
+	batchWriteInput.RequestItems[batchInput.tableName] = items
+
+	BatchWriteItemOutput, err := dynamoDBClient.BatchWriteItem(batchWriteInput)
+	numUnprocessed := len(BatchWriteItemOutput.UnprocessedItems[batchInput.tableName])
+	for attempt := 1; err != nil || numUnprocessed != 0; attempt++ {
+		if batchInput.retries.retry(batchInput, attempt) {
+			logger.Error("dynamoDB batch write is abandoned after retrying too long", "tableName", batchInput.tableName,
+				"err", err, "numUnprocessedItem", numUnprocessed, "limit", batchInput.retries.limit, "attempts", attempt)
+			if err == nil {
+				err = fmt.Errorf("%d items are left unprocessed", numUnprocessed)
+			}
+			batchInput.result.fail(fmt.Errorf("%w: %v", errBatchWriteAbandoned, err))
+			return true
+		}
+		if err != nil {
+			// ValidationException occurs when a required parameter is missing, a value is out of range,
+			// or data types mismatch and so on. If this is the case, check if there is a duplicated key,
+			// batch length out of range, null value and so on.
+			// When ValidationException occurs, retrying won't fix the problem, so the error
+			// is returned by the batch write.
+			if strings.Contains(err.Error(), "ValidationException") {
+				logger.Error("Invalid input for dynamoDB BatchWrite",
+					"err", err, "tableName", batchInput.tableName, "itemNum", len(items))
+				batchInput.result.fail(err)
+				return false
+			}
+			// the items are retried until the table is active again, up to the retry limit
+			batchInput.table.observe(err)
+			*failCount++
+			logger.Warn("dynamoDB failed to write batch items",
+				"tableName", batchInput.tableName, "err", err, "failCnt", *failCount)
+		}
+
+		if numUnprocessed != 0 {
+			logger.Debug("dynamoDB batchWrite remains unprocessedItem",
+				"tableName", batchInput.tableName, "numUnprocessedItem", numUnprocessed)
+			batchInput.batchSize.throttled()
+			batchWriteInput.RequestItems[batchInput.tableName] = BatchWriteItemOutput.UnprocessedItems[batchInput.tableName]
+			dynamoHotKeys.observe(batchInput.tableName, batchWriteInput.RequestItems[batchInput.tableName])
+		}
+
+		// the retries back off not to hammer a throttled table, and the
+		// retries of all the workers share the budget
+		batchInput.retries.wait(attempt)
+		dynamoRetryBudget.wait()
+
+		BatchWriteItemOutput, err = dynamoDBClient.BatchWriteItem(batchWriteInput)
+		numUnprocessed = len(BatchWriteItemOutput.UnprocessedItems)
+	}
+	batchInput.batchSize.processed()
+	return false
+}
+
+// markBatchWriteMeters marks the elapsed time of a batch write in nanoseconds,
+// the number and the size of its items, and the time taken per item and per byte.
+func markBatchWriteMeters(items, size int, elapsed time.Duration) {
+	dynamoBatchWriteTimeMeter.Mark(int64(elapsed))
+	dynamoBatchWriteCountMeter.Mark(int64(items))
+	dynamoBatchWriteBytesMeter.Mark(int64(size))
+	if items > 0 {
+		dynamoBatchWriteTimePerItemMeter.Mark(int64(elapsed) / int64(items))
+	}
+	if size > 0 {
+		dynamoBatchWriteTimePerByteMeter.Mark(int64(elapsed) / int64(size))
+	}
+}
+
 func (dynamo *dynamoDB) NewBatch() Batch {
-	return &dynamoBatch{db: dynamo, tableName: dynamo.config.TableName, wg: &sync.WaitGroup{}, keyMap: map[string]struct{}{}}
+	return dynamo.NewBatchWithSize(0)
+}
+
+// NewBatchWithSize returns a dynamoBatch whose item buffer is pre-allocated for
+// n items, up to dynamoBatchSize which is the maximum number of un-dispatched items.
+func (dynamo *dynamoDB) NewBatchWithSize(n int) Batch {
+	if n > dynamoBatchSize {
+		n = dynamoBatchSize
+	}
+	return &dynamoBatch{
+		db: dynamo, tableName: dynamo.config.TableName, wg: &sync.WaitGroup{}, result: dynamo.writes.newResult(), sizeHint: n,
+		batchItems: make([]*dynamodb.WriteRequest, 0, n),
+		keyMap:     make(map[string]int, n), fileWrites: map[string]chan struct{}{},
+	}
 }
 
 type dynamoBatch struct {
 	db         *dynamoDB
 	tableName  string
 	batchItems []*dynamodb.WriteRequest
-	keyMap     map[string]struct{} // checks duplication of keys
+	keyMap     map[string]int // index of the write request of each key in batchItems
 	size       int
-	wg         *sync.WaitGroup
+	sizeHint   int // the number of items pre-allocated for batchItems and keyMap
+
+	// wg and result track the items dispatched since the last write. They are
+	// replaced by WriteAsync, and lastAsyncWrite is closed when the callback of
+	// the last WriteAsync is called.
+	wg             *sync.WaitGroup
+	result         *batchWriteResult
+	lastAsyncWrite chan struct{}
+
+	// fileWrites holds a channel for each oversized key, which is closed when the last
+	// fileDB write of the key is done. It keeps the writes of the same key in order.
+	fileWrites map[string]chan struct{}
+
+	inUse   int32 // set while a method modifying the batch is called, to detect concurrent uses
+	written bool  // set by Write and WriteAsync, and cleared by Reset
+}
+
+// enter marks the batch in use, and returns errConcurrentBatchUse if it is
+// already used by another goroutine. leave should be called if it succeeds.
+// The detection is best-effort, which catches the calls overlapping in time.
+func (batch *dynamoBatch) enter() error {
+	if !atomic.CompareAndSwapInt32(&batch.inUse, 0, 1) {
+		batch.db.logger.ErrorWithStack("dynamoDB batch is used concurrently; a Batch must be used by a single goroutine")
+		return errConcurrentBatchUse
+	}
+	return nil
+}
+
+func (batch *dynamoBatch) leave() {
+	atomic.StoreInt32(&batch.inUse, 0)
+}
+
+// enterModify marks the batch in use like enter, and also returns
+// errBatchNotReset if the batch is written but not reset yet, or
+// errDynamoClosed if the database is closed.
+func (batch *dynamoBatch) enterModify() error {
+	if err := batch.enter(); err != nil {
+		return err
+	}
+	if batch.written {
+		batch.leave()
+		return errBatchNotReset
+	}
+	if err := batch.db.writes.checkOpen(); err != nil {
+		batch.leave()
+		return err
+	}
+	return nil
 }
 
 // Put adds an item to dynamo batch.
 // If the number of items in batch reaches dynamoBatchSize, a write request to dynamoDB is made.
 // Each batch write is executed in thread. (There is an worker pool for dynamo batch write)
 //
-// Note: If there is a duplicated key in a batch, only the first value is written.
+// Note: If there is a duplicated key in the un-dispatched items, the previous item is
+// replaced with the new one, so only the last value is written.
+//
+// A batch must be used by a single goroutine, and it must be Reset after Write
+// to be reused. Put and Delete return errConcurrentBatchUse or errBatchNotReset
+// for the misuses, and errDynamoClosed after the database is closed.
 func (batch *dynamoBatch) Put(key, val []byte) error {
-	// if there is an duplicated key in batch, skip
-	if _, exist := batch.keyMap[string(key)]; exist {
-		return nil
+	if err := batch.enterModify(); err != nil {
+		return err
 	}
-	batch.keyMap[string(key)] = struct{}{}
-
-	data := DynamoData{Key: key, Val: val}
-	dataSize := len(val)
+	defer batch.leave()
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return err
+	}
+	itemVal := val
 
 	// If the size of the item is larger than the limit, it should be handled in different way
-	if dataSize > dynamoWriteSizeLimit {
+	if len(val) > dynamoWriteSizeLimit {
+		result := batch.result
+		if err := result.add(); err != nil {
+			return err
+		}
+
+		// wait for the previous fileDB write of the same key not to be overwritten by it
+		prevWrite := batch.fileWrites[string(key)]
+		done := make(chan struct{})
+		batch.fileWrites[string(key)] = done
+
 		batch.wg.Add(1)
 		go func() {
+			defer batch.wg.Done()
+			defer result.done()
+			defer close(done)
+			if prevWrite != nil {
+				<-prevWrite
+			}
+
 			failCnt := 0
 			batch.db.logger.Debug("write large size data into fileDB")
 
@@ -560,38 +1700,212 @@ func (batch *dynamoBatch) Put(key, val []byte) error {
 				batch.db.logger.Warn("retrying write an item into fileDB")
 				_, err = batch.db.fdb.write(item{key: key, val: val})
 			}
-			batch.wg.Done()
 		}()
-		data.Val = overSizedDataPrefix
-		dataSize = len(data.Val)
+		itemVal = overSizedDataPrefix
 	}
 
-	marshaledData, err := dynamodbattribute.MarshalMap(data)
+	marshaledData, err := batch.db.encodeItem(key, itemVal)
 	if err != nil {
 		batch.db.logger.Error("err while batch put", "err", err, "len(val)", len(val))
 		return err
 	}
-
-	batch.batchItems = append(batch.batchItems, &dynamodb.WriteRequest{
+	writeRequest := &dynamodb.WriteRequest{
 		PutRequest: &dynamodb.PutRequest{Item: marshaledData},
-	})
-	batch.size += dataSize
-
-	if len(batch.batchItems) == dynamoBatchSize {
-		batch.wg.Add(1)
-		dynamoWriteCh <- &batchWriteWorkerInput{batch.tableName, batch.batchItems, batch.wg}
-		batch.Reset()
 	}
+	batch.addRequest(key, writeRequest)
 	return nil
 }
 
-// Delete inserts the a key removal into the batch for later committing.
+// Delete adds a key removal to dynamo batch. Puts and deletes are dispatched
+// together in the same batch write requests.
+// If the key is written to fileDB by this batch, the fileDB item is also deleted
+// after the write. Otherwise, the batch write worker checks if the existing item
+// is oversized, and deletes its fileDB item after the item is deleted.
 func (batch *dynamoBatch) Delete(key []byte) error {
-	logger.CritWithStack("Delete should not be called when using dynamodb batch")
+	if err := batch.enterModify(); err != nil {
+		return err
+	}
+	defer batch.leave()
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return err
+	}
+	if prevWrite, exist := batch.fileWrites[string(key)]; exist {
+		result := batch.result
+		if err := result.add(); err != nil {
+			return err
+		}
+		done := make(chan struct{})
+		batch.fileWrites[string(key)] = done
+
+		batch.wg.Add(1)
+		go func() {
+			defer batch.wg.Done()
+			defer result.done()
+			defer close(done)
+			<-prevWrite
+
+			if err := batch.db.fdb.delete(key); err != nil {
+				batch.db.logger.Error("cannot delete an item from fileDB. check the status of s3",
+					"err", err, "key", hexutil.Encode(key))
+			}
+		}()
+	}
+
+	writeRequest := &dynamodb.WriteRequest{
+		DeleteRequest: &dynamodb.DeleteRequest{
+			Key: map[string]*dynamodb.AttributeValue{"Key": {B: key}},
+		},
+	}
+	batch.addRequest(key, writeRequest)
 	return nil
 }
 
+// addRequest adds a write request of the key to the un-dispatched items, and
+// dispatches them if the number of items reaches dynamoBatchSize.
+func (batch *dynamoBatch) addRequest(key []byte, writeRequest *dynamodb.WriteRequest) {
+	sizer := batch.db.itemSizer()
+	size := requestSize(writeRequest, sizer)
+
+	// if there is an duplicated key in batch, overwrite the previous item
+	if idx, exist := batch.keyMap[string(key)]; exist {
+		prevSize := requestSize(batch.batchItems[idx], sizer)
+		batch.size -= prevSize
+		batch.batchItems[idx] = writeRequest
+		batch.size += size
+		return
+	}
+	batch.keyMap[string(key)] = len(batch.batchItems)
+	batch.batchItems = append(batch.batchItems, writeRequest)
+	batch.size += size
+
+	if len(batch.batchItems) == dynamoBatchSize {
+		batch.dispatch(batch.batchItems)
+		batch.resetItems()
+	}
+}
+
+// dispatch sends the write requests to a batch write worker.
+func (batch *dynamoBatch) dispatch(items []*dynamodb.WriteRequest) {
+	if batch.db.config.IdempotentImport {
+		if items = batch.db.skipExistingItems(items); len(items) == 0 {
+			return
+		}
+	}
+	// the items dispatched after Close are dropped, and Write returns the error
+	if err := batch.result.add(); err != nil {
+		batch.result.fail(err)
+		return
+	}
+
+	// the bytes of the items are reserved when they are sent to the workers,
+	// and released by the worker when they are written
+	buffered := 0
+	if dynamoWriteBuffer != nil {
+		sizer := batch.db.itemSizer()
+		for _, item := range items {
+			buffered += requestSize(item, sizer)
+		}
+		dynamoWriteBuffer.acquire(buffered)
+	}
+	batch.wg.Add(1)
+	dynamoWriteCh <- &batchWriteWorkerInput{batch.tableName, items, batch.wg, batch.db.slowOps, batch.result, batch.db.batchSize, batch.db.table, batch.db.retries, buffered, batch.fileDeletes(items)}
+}
+
+// fileDeletes returns the batchFileDeletes of the deleted keys which are not
+// written to fileDB by this batch, or nil if there is none. The fileDB items
+// written by this batch are deleted by Delete in order with the writes.
+func (batch *dynamoBatch) fileDeletes(items []*dynamodb.WriteRequest) *batchFileDeletes {
+	if batch.db.fdb == nil {
+		return nil
+	}
+	var keys [][]byte
+	for _, item := range items {
+		if item.DeleteRequest == nil {
+			continue
+		}
+		key := writeRequestKey(item)
+		if _, written := batch.fileWrites[string(key)]; !written {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return &batchFileDeletes{db: batch.db, keys: keys}
+}
+
+// requestSize returns the size of a write request counted in ValueSize. A put
+// request is sized by its item, and a delete request by its key.
+func requestSize(writeRequest *dynamodb.WriteRequest, sizer DynamoItemSizer) int {
+	if writeRequest.DeleteRequest != nil {
+		return sizer(writeRequest.DeleteRequest.Key)
+	}
+	return sizer(writeRequest.PutRequest.Item)
+}
+
+// writeRequestKey returns the item key of a write request, or nil if it is missing.
+func writeRequestKey(writeRequest *dynamodb.WriteRequest) []byte {
+	var keyAttr *dynamodb.AttributeValue
+	switch {
+	case writeRequest.PutRequest != nil:
+		keyAttr = writeRequest.PutRequest.Item["Key"]
+	case writeRequest.DeleteRequest != nil:
+		keyAttr = writeRequest.DeleteRequest.Key["Key"]
+	}
+	if keyAttr == nil {
+		return nil
+	}
+	return keyAttr.B
+}
+
+// Write dispatches the un-dispatched items, and waits for all items dispatched
+// since the last write, including the pending asynchronous writes.
 func (batch *dynamoBatch) Write() error {
+	if err := batch.enter(); err != nil {
+		return err
+	}
+	defer batch.leave()
+	batch.written = true
+	batch.dispatchAll()
+	if batch.lastAsyncWrite != nil {
+		<-batch.lastAsyncWrite
+	}
+	batch.wg.Wait()
+
+	err := batch.result.error()
+	batch.result = batch.db.writes.newResult()
+	return err
+}
+
+// WriteAsync dispatches the un-dispatched items like Write, but it returns
+// without waiting for them. callback is called with the error of the items
+// dispatched since the last write when they are written. The callbacks are
+// called in the order of the WriteAsync calls, so an importer can advance its
+// cursor in the callbacks.
+func (batch *dynamoBatch) WriteAsync(callback func(error)) {
+	if err := batch.enter(); err != nil {
+		callback(err)
+		return
+	}
+	defer batch.leave()
+	batch.written = true
+	batch.dispatchAll()
+
+	wg, result, prevWrite := batch.wg, batch.result, batch.lastAsyncWrite
+	done := make(chan struct{})
+	batch.wg, batch.result, batch.lastAsyncWrite = &sync.WaitGroup{}, batch.db.writes.newResult(), done
+	go func() {
+		defer close(done)
+		if prevWrite != nil {
+			<-prevWrite
+		}
+		wg.Wait()
+		callback(result.error())
+	}()
+}
+
+// dispatchAll dispatches the un-dispatched items in chunks of dynamoBatchSize.
+func (batch *dynamoBatch) dispatchAll() {
 	var writeRequest []*dynamodb.WriteRequest
 	numRemainedItems := len(batch.batchItems)
 
@@ -602,22 +1916,36 @@ func (batch *dynamoBatch) Write() error {
 		} else {
 			writeRequest = batch.batchItems
 		}
-		batch.wg.Add(1)
-		dynamoWriteCh <- &batchWriteWorkerInput{batch.tableName, writeRequest, batch.wg}
+		batch.dispatch(writeRequest)
 		numRemainedItems -= len(writeRequest)
 	}
-
-	batch.wg.Wait()
-	return nil
 }
 
+// ValueSize returns the size of the un-dispatched items, which is counted by the
+// ItemSizer of the config. By default, it includes the keys and the per-item
+// overhead as well as the values, so that it reflects the size of the batch
+// write requests.
 func (batch *dynamoBatch) ValueSize() int {
 	return batch.size
 }
 
+// Reset clears the batch for reuse. It panics if the batch is used by another
+// goroutine, since it can't return the error.
 func (batch *dynamoBatch) Reset() {
-	batch.batchItems = []*dynamodb.WriteRequest{}
-	batch.keyMap = map[string]struct{}{}
+	if err := batch.enter(); err != nil {
+		panic(err)
+	}
+	defer batch.leave()
+	batch.written = false
+	batch.resetItems()
+	batch.fileWrites = map[string]chan struct{}{}
+}
+
+// resetItems clears the un-dispatched items.
+func (batch *dynamoBatch) resetItems() {
+	// batchItems is not reused since the dispatched items are still referred by workers
+	batch.batchItems = make([]*dynamodb.WriteRequest, 0, batch.sizeHint)
+	batch.keyMap = make(map[string]int, batch.sizeHint)
 	batch.size = 0
 }
 
@@ -0,0 +1,106 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"sync"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// default adjustment factors of the adaptive batch size.
+const (
+	defaultAdaptiveBatchDecrease = 0.5
+	defaultAdaptiveBatchIncrease = 1
+)
+
+// adaptiveBatchSize adjusts the number of items in a batch write request in the
+// AIMD way. The size is multiplied by `decrease` when a request leaves
+// unprocessed items, which happens under throttling, and `increase` is added to
+// it when a request is fully processed. Under throttling, smaller requests
+// achieve more goodput than the maximum ones which are mostly rejected.
+//
+// A nil *adaptiveBatchSize is valid and always allows dynamoBatchSize items.
+type adaptiveBatchSize struct {
+	min      int
+	max      int
+	decrease float64
+	increase int
+
+	mu        sync.Mutex
+	cur       int
+	sizeGauge metrics.Gauge
+}
+
+// newAdaptiveBatchSize returns an adaptiveBatchSize starting from max, or nil
+// if min is not positive.
+func newAdaptiveBatchSize(min, max int, decrease float64, increase int) *adaptiveBatchSize {
+	if min <= 0 {
+		return nil
+	}
+	return &adaptiveBatchSize{
+		min:       min,
+		max:       max,
+		decrease:  decrease,
+		increase:  increase,
+		cur:       max,
+		sizeGauge: metrics.NilGauge{},
+	}
+}
+
+// size returns the current number of items in a batch write request.
+func (s *adaptiveBatchSize) size() int {
+	if s == nil {
+		return dynamoBatchSize
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur
+}
+
+// throttled decreases the size after a request left unprocessed items.
+func (s *adaptiveBatchSize) throttled() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(int(float64(s.cur) * s.decrease))
+}
+
+// processed increases the size after a request was fully processed.
+func (s *adaptiveBatchSize) processed() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(s.cur + s.increase)
+}
+
+func (s *adaptiveBatchSize) set(size int) {
+	if size < s.min {
+		size = s.min
+	} else if size > s.max {
+		size = s.max
+	}
+	if size != s.cur {
+		logger.Trace("dynamoDB batch size is adjusted", "prev", s.cur, "size", size)
+		s.cur = size
+	}
+	s.sizeGauge.Update(int64(size))
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveBatchSize(t *testing.T) {
+	// a nil adaptiveBatchSize is disabled
+	var disabled *adaptiveBatchSize
+	disabled.throttled()
+	disabled.processed()
+	assert.Equal(t, dynamoBatchSize, disabled.size())
+	assert.Nil(t, newAdaptiveBatchSize(0, dynamoBatchSize, 0.5, 1))
+
+	s := newAdaptiveBatchSize(2, dynamoBatchSize, 0.5, 1)
+	assert.Equal(t, dynamoBatchSize, s.size())
+
+	// multiplicative decrease down to the minimum
+	for _, want := range []int{12, 6, 3, 2, 2} {
+		s.throttled()
+		assert.Equal(t, want, s.size())
+	}
+	// additive increase up to the maximum
+	for want := 3; want <= dynamoBatchSize; want++ {
+		s.processed()
+		assert.Equal(t, want, s.size())
+	}
+	s.processed()
+	assert.Equal(t, dynamoBatchSize, s.size())
+}
+
+func TestDynamoDBConfig_AdaptiveBatchSize(t *testing.T) {
+	config := GetTestDynamoConfig()
+	config.AdaptiveBatchMin = 5
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, dynamoBatchSize, config.AdaptiveBatchMax)
+	assert.Equal(t, defaultAdaptiveBatchDecrease, config.AdaptiveBatchDecrease)
+	assert.Equal(t, defaultAdaptiveBatchIncrease, config.AdaptiveBatchIncrease)
+
+	for _, invalid := range []func(c *DynamoDBConfig){
+		func(c *DynamoDBConfig) { c.AdaptiveBatchMin = -1 },
+		func(c *DynamoDBConfig) { c.AdaptiveBatchMin, c.AdaptiveBatchMax = 10, 5 },
+		func(c *DynamoDBConfig) { c.AdaptiveBatchMin, c.AdaptiveBatchMax = 1, dynamoBatchSize+1 },
+		func(c *DynamoDBConfig) { c.AdaptiveBatchMin, c.AdaptiveBatchDecrease = 1, 1 },
+		func(c *DynamoDBConfig) { c.AdaptiveBatchMin, c.AdaptiveBatchIncrease = 1, -1 },
+	} {
+		config := GetTestDynamoConfig()
+		invalid(config)
+		assert.Error(t, config.validateAndSetDefaults())
+	}
+}
+
+func TestBatchWriteWorker_AdaptiveBatchSize(t *testing.T) {
+	tableName := GetTestDynamoConfig().TableName
+
+	// DynamoDB processes up to capacity items of each request, and leaves the
+	// rest unprocessed like under throttling
+	var (
+		capacity     int
+		requestSizes []int
+	)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			items := input.RequestItems[tableName]
+			requestSizes = append(requestSizes, len(items))
+			if len(items) <= capacity {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			}
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{
+				tableName: items[capacity:],
+			}}, nil
+		},
+	})()
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	batchSize := newAdaptiveBatchSize(1, dynamoBatchSize, 0.5, 1)
+	write := func() {
+		items := make([]*dynamodb.WriteRequest, dynamoBatchSize)
+		for i := range items {
+			items[i] = newTestWriteRequest(fmt.Sprintf("key-%d", i))
+		}
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		writeCh <- &batchWriteWorkerInput{tableName, items, wg, nil, nil, batchSize, nil, nil, 0, nil}
+		wg.Wait()
+	}
+
+	// the batch size shrinks under throttling
+	capacity = 5
+	for i := 0; i < 5; i++ {
+		write()
+	}
+	assert.Less(t, batchSize.size(), dynamoBatchSize)
+	requestSizes = nil
+	write()
+	for _, size := range requestSizes {
+		assert.LessOrEqual(t, size, 2*capacity)
+	}
+
+	// and it recovers as the writes succeed
+	capacity = dynamoBatchSize
+	for i := 0; i < dynamoBatchSize && batchSize.size() < dynamoBatchSize; i++ {
+		write()
+	}
+	assert.Equal(t, dynamoBatchSize, batchSize.size())
+	requestSizes = nil
+	write()
+	assert.Equal(t, []int{dynamoBatchSize}, requestSizes)
+}
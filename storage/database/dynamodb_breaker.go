@@ -0,0 +1,186 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// errCircuitOpen is returned without contacting DynamoDB while the circuit breaker is open.
+var errCircuitOpen = errors.New("dynamoDB circuit breaker is open")
+
+// dynamoBreakerProperty is the property name used to query the breaker state via GetProperty.
+const dynamoBreakerProperty = "dynamodb.breaker"
+
+type breakerState int32
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// circuitBreaker stops issuing requests to DynamoDB during a sustained outage.
+// It opens after `threshold` consecutive failures observed within `window`, rejects
+// every call with errCircuitOpen for `cooldown`, and then lets a single probe
+// through (half-open). A successful probe closes the breaker, a failed one reopens it.
+//
+// The batch writes of the workers are not guarded by the breaker, since the
+// workers retry them with their own backoff and retry budget until they are
+// written, and rejecting them would drop the writes of the batches.
+//
+// A nil *circuitBreaker is valid and never rejects a call.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+
+	mu           sync.Mutex
+	state        breakerState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+
+	now        func() time.Time
+	stateGauge metrics.Gauge
+}
+
+// newCircuitBreaker returns a circuitBreaker, or nil if threshold is not positive.
+func newCircuitBreaker(threshold int, window, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold:  threshold,
+		window:     window,
+		cooldown:   cooldown,
+		now:        time.Now,
+		stateGauge: metrics.NilGauge{},
+	}
+}
+
+// allow returns errCircuitOpen if the call must not be issued.
+func (cb *circuitBreaker) allow() error {
+	if cb == nil {
+		return nil
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return errCircuitOpen
+		}
+		cb.setState(breakerHalfOpen)
+		cb.probing = true
+		return nil
+	case breakerHalfOpen:
+		// only one probe is in flight at a time
+		if cb.probing {
+			return errCircuitOpen
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+// done records the result of a call which was allowed by allow.
+func (cb *circuitBreaker) done(err error) {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		cb.failures = 0
+		cb.probing = false
+		if cb.state != breakerClosed {
+			logger.Info("dynamoDB circuit breaker is closed")
+			cb.setState(breakerClosed)
+		}
+		return
+	}
+
+	now := cb.now()
+	switch cb.state {
+	case breakerHalfOpen:
+		cb.probing = false
+		cb.open(now, err)
+	case breakerClosed:
+		if cb.failures == 0 || (cb.window > 0 && now.Sub(cb.firstFailure) > cb.window) {
+			cb.failures = 0
+			cb.firstFailure = now
+		}
+		cb.failures++
+		if cb.failures >= cb.threshold {
+			cb.open(now, err)
+		}
+	}
+}
+
+// release ends a call which was allowed by allow without its result, such as a
+// call cancelled by the caller. It lets another probe through in the half-open
+// state, but it doesn't change the state.
+func (cb *circuitBreaker) release() {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+func (cb *circuitBreaker) open(now time.Time, err error) {
+	logger.Error("dynamoDB circuit breaker is open", "failures", cb.failures, "cooldown", cb.cooldown, "err", err)
+	cb.failures = 0
+	cb.openedAt = now
+	cb.setState(breakerOpen)
+}
+
+func (cb *circuitBreaker) setState(state breakerState) {
+	cb.state = state
+	cb.stateGauge.Update(int64(state))
+}
+
+// State returns the current state of the breaker.
+func (cb *circuitBreaker) State() breakerState {
+	if cb == nil {
+		return breakerClosed
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	cb := newCircuitBreaker(0, time.Minute, time.Second)
+	assert.Nil(t, cb)
+
+	// nil breaker always allows calls
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, cb.allow())
+		cb.done(errors.New("failure"))
+	}
+	assert.Equal(t, breakerClosed, cb.State())
+}
+
+func TestCircuitBreaker_OpenCooldownRecover(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker(3, time.Minute, 10*time.Second)
+	cb.now = func() time.Time { return now }
+
+	failure := errors.New("failure")
+
+	// the breaker stays closed below the threshold
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, cb.allow())
+		cb.done(failure)
+	}
+	assert.Equal(t, breakerClosed, cb.State())
+
+	// the third consecutive failure opens the breaker
+	assert.NoError(t, cb.allow())
+	cb.done(failure)
+	assert.Equal(t, breakerOpen, cb.State())
+
+	// calls fail fast during the cooldown
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, errCircuitOpen, cb.allow())
+
+	// after the cooldown, only one probe is allowed
+	now = now.Add(5 * time.Second)
+	assert.NoError(t, cb.allow())
+	assert.Equal(t, breakerHalfOpen, cb.State())
+	assert.Equal(t, errCircuitOpen, cb.allow())
+
+	// a failed probe reopens the breaker
+	cb.done(failure)
+	assert.Equal(t, breakerOpen, cb.State())
+	assert.Equal(t, errCircuitOpen, cb.allow())
+
+	// a successful probe closes the breaker
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, cb.allow())
+	cb.done(nil)
+	assert.Equal(t, breakerClosed, cb.State())
+	assert.NoError(t, cb.allow())
+}
+
+func TestCircuitBreaker_Release(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker(1, time.Minute, 10*time.Second)
+	cb.now = func() time.Time { return now }
+
+	assert.NoError(t, cb.allow())
+	cb.done(errors.New("failure"))
+	assert.Equal(t, breakerOpen, cb.State())
+
+	// a released probe lets another probe through without closing the breaker
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, cb.allow())
+	cb.release()
+	assert.Equal(t, breakerHalfOpen, cb.State())
+	assert.NoError(t, cb.allow())
+	assert.Equal(t, errCircuitOpen, cb.allow())
+	cb.done(nil)
+	assert.Equal(t, breakerClosed, cb.State())
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	now := time.Now()
+	cb := newCircuitBreaker(2, time.Minute, 10*time.Second)
+	cb.now = func() time.Time { return now }
+
+	failure := errors.New("failure")
+
+	assert.NoError(t, cb.allow())
+	cb.done(failure)
+
+	// a failure out of the window starts a new count
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, cb.allow())
+	cb.done(failure)
+	assert.Equal(t, breakerClosed, cb.State())
+
+	// a success resets the count
+	assert.NoError(t, cb.allow())
+	cb.done(nil)
+	assert.NoError(t, cb.allow())
+	cb.done(failure)
+	assert.Equal(t, breakerClosed, cb.State())
+
+	assert.NoError(t, cb.allow())
+	cb.done(failure)
+	assert.Equal(t, breakerOpen, cb.State())
+}
+
+func TestDynamoDB_CircuitBreaker(t *testing.T) {
+	failing := true
+	numCalls := 0
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			numCalls++
+			if failing {
+				return nil, errors.New("RequestError: send request failed")
+			}
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": {B: input.Key["Key"].B},
+				"Val": {B: []byte("val")},
+			}}, nil
+		},
+	})()
+
+	config := GetTestDynamoConfig()
+	config.BreakerThreshold = 2
+	config.BreakerWindow = time.Minute
+	config.BreakerCooldown = time.Hour
+	dynamo := newStubDynamoDB(config)
+	now := time.Now()
+	dynamo.breaker.now = func() time.Time { return now }
+
+	key := []byte("key")
+	assert.Equal(t, "closed", dynamo.GetProperty(dynamoBreakerProperty))
+
+	// open the breaker
+	for i := 0; i < 2; i++ {
+		_, err := dynamo.Get(key)
+		assert.Error(t, err)
+		assert.NotEqual(t, errCircuitOpen, err)
+	}
+	assert.Equal(t, "open", dynamo.GetProperty(dynamoBreakerProperty))
+
+	// fail fast without contacting DynamoDB
+	_, err := dynamo.Get(key)
+	assert.Equal(t, errCircuitOpen, err)
+	assert.Equal(t, errCircuitOpen, dynamo.Put(key, []byte("val")))
+	assert.Equal(t, errCircuitOpen, dynamo.Delete(key))
+	assert.Equal(t, 2, numCalls)
+
+	// recover after the cooldown
+	failing = false
+	now = now.Add(time.Hour)
+	val, err := dynamo.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val"), val)
+	assert.Equal(t, "closed", dynamo.GetProperty(dynamoBreakerProperty))
+	assert.Equal(t, 3, numCalls)
+
+	// failures are not critical when the breaker is enabled
+	for _, msg := range dynamo.logger.(*testLogger).messages() {
+		assert.NotContains(t, msg, "CRIT")
+	}
+}
@@ -0,0 +1,357 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// BulkLoad stages the items in S3 and loads them into the table in parallel,
+// which is much faster than the batches for a large initial import such as a
+// cold start migration.
+//
+// The items of the iterator are encoded into chunks of BulkLoadChunkSize, and
+// the chunks are written to S3 as objects while the iterator is read. The
+// staged chunks are read back and split into batch write requests, which are
+// written by BulkLoadConcurrency writers, not by the shared batch write
+// workers. A chunk is deleted from S3 when all of its items are written.
+// Since the reads and the writes are decoupled by the staged chunks, a slow
+// table does not block the iterator until the chunks pile up.
+//
+// The chunks are loaded out of order, so an interrupted bulk load is not
+// resumed but started again, which skips the loaded items if IdempotentImport
+// is set.
+
+const (
+	defaultBulkLoadChunkSize   = 4 * 1024 * 1024
+	defaultBulkLoadConcurrency = 8 * WorkerNum
+
+	bulkLoadStagers        = 4  // the number of the goroutines writing the chunks to S3
+	bulkLoadReaders        = 4  // the number of the goroutines reading the chunks from S3
+	bulkLoadStagedChunks   = 16 // the number of the staged chunks waiting for the readers
+	bulkLoadReportInterval = 8 * time.Second
+)
+
+// bulkLoadChunkPrefix is the prefix of the S3 keys of the staged chunks.
+var bulkLoadChunkPrefix = []byte("klay-bulkload-chunk-")
+
+var errMalformedBulkLoadChunk = errors.New("malformed bulk load chunk")
+
+// bulkLoadChunkKey returns the S3 key of the seq-th chunk.
+func bulkLoadChunkKey(seq uint64) []byte {
+	key := make([]byte, len(bulkLoadChunkPrefix)+8)
+	copy(key, bulkLoadChunkPrefix)
+	binary.BigEndian.PutUint64(key[len(bulkLoadChunkPrefix):], seq)
+	return key
+}
+
+// appendBulkLoadItem appends a key-value pair to a chunk, which is encoded as
+// the uvarint length prefixed key and value.
+func appendBulkLoadItem(chunk, key, val []byte) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, field := range [][]byte{key, val} {
+		n := binary.PutUvarint(buf, uint64(len(field)))
+		chunk = append(append(chunk, buf[:n]...), field...)
+	}
+	return chunk
+}
+
+// decodeBulkLoadChunk returns the key-value pairs of a chunk.
+func decodeBulkLoadChunk(chunk []byte) ([]KV, error) {
+	var (
+		r   = bytes.NewReader(chunk)
+		kvs []KV
+	)
+	readField := func() ([]byte, error) {
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if length > uint64(len(chunk)) {
+			return nil, errMalformedBulkLoadChunk
+		}
+		field := make([]byte, length)
+		if _, err := io.ReadFull(r, field); err != nil {
+			return nil, errMalformedBulkLoadChunk
+		}
+		return field, nil
+	}
+	for {
+		key, err := readField()
+		if err == io.EOF {
+			return kvs, nil
+		} else if err != nil {
+			return nil, errMalformedBulkLoadChunk
+		}
+		val, err := readField()
+		if err != nil {
+			return nil, errMalformedBulkLoadChunk
+		}
+		kvs = append(kvs, KV{Key: key, Value: val})
+	}
+}
+
+// bulkLoad holds the state of a BulkLoad, which is stopped by the first error.
+type bulkLoad struct {
+	dynamo *dynamoDB
+
+	loaded  int64 // the number of the loaded items
+	errOnce sync.Once
+	err     error
+	failed  chan struct{} // closed by the first error
+}
+
+func (l *bulkLoad) fail(err error) {
+	l.errOnce.Do(func() {
+		l.err = err
+		close(l.failed)
+	})
+}
+
+// BulkLoad writes the items of the iterator by staging them in S3, and returns
+// the number of the written items. The items after the interruption are not
+// written if quit is closed.
+func (dynamo *dynamoDB) BulkLoad(it Iterator, quit <-chan struct{}) (int, error) {
+	chunkSize, concurrency := dynamo.config.BulkLoadChunkSize, dynamo.config.BulkLoadConcurrency
+	if chunkSize <= 0 {
+		chunkSize = defaultBulkLoadChunkSize
+	}
+	if concurrency <= 0 {
+		concurrency = defaultBulkLoadConcurrency
+	}
+
+	var (
+		load     = &bulkLoad{dynamo: dynamo, failed: make(chan struct{})}
+		chunks   = make(chan KV, bulkLoadStagers)
+		staged   = make(chan []byte, bulkLoadStagedChunks)
+		requests = make(chan bulkLoadRequest, concurrency)
+		start    = time.Now()
+
+		stagers, readers, writers sync.WaitGroup
+	)
+	for i := 0; i < bulkLoadStagers; i++ {
+		stagers.Add(1)
+		go func() {
+			defer stagers.Done()
+			load.stage(chunks, staged)
+		}()
+	}
+	for i := 0; i < bulkLoadReaders; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			load.read(staged, requests)
+		}()
+	}
+	for i := 0; i < concurrency; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			load.write(requests)
+		}()
+	}
+
+	// the iterator is read until it ends, fails, or the bulk load is stopped
+	var (
+		seq     uint64
+		chunk   []byte
+		fetched int
+		report  = time.Now()
+	)
+	send := func() bool {
+		select {
+		case chunks <- KV{Key: bulkLoadChunkKey(seq), Value: chunk}:
+			seq++
+			chunk = nil
+			return true
+		case <-load.failed:
+			return false
+		}
+	}
+loop:
+	for it.Next() {
+		chunk = appendBulkLoadItem(chunk, it.Key(), it.Value())
+		fetched++
+		if len(chunk) >= chunkSize && !send() {
+			break
+		}
+		select {
+		case <-quit:
+			dynamo.logger.Warn("bulk load is interrupted", "fetched", fetched, "elapsed", time.Since(start))
+			break loop
+		default:
+		}
+		if time.Since(report) > bulkLoadReportInterval {
+			dynamo.logger.Info("Bulk loading", "fetched", fetched, "staged", seq,
+				"loaded", atomic.LoadInt64(&load.loaded), "elapsed", time.Since(start))
+			report = time.Now()
+		}
+	}
+	if len(chunk) > 0 {
+		send()
+	}
+	close(chunks)
+	stagers.Wait()
+	close(staged)
+	readers.Wait()
+	close(requests)
+	writers.Wait()
+
+	if err := it.Error(); err != nil {
+		load.fail(fmt.Errorf("failed to iterate: %w", err))
+	}
+	loaded := int(atomic.LoadInt64(&load.loaded))
+	if load.err != nil {
+		return loaded, load.err
+	}
+	dynamo.logger.Info("Finish bulk load", "loaded", loaded, "chunks", seq, "elapsed", time.Since(start))
+	return loaded, nil
+}
+
+// stage writes the chunks to S3, and passes their keys to the readers.
+func (l *bulkLoad) stage(chunks <-chan KV, staged chan<- []byte) {
+	for chunk := range chunks {
+		select {
+		case <-l.failed:
+			continue
+		default:
+		}
+		if _, err := l.dynamo.fdb.write(item{key: chunk.Key, val: chunk.Value}); err != nil {
+			l.fail(fmt.Errorf("failed to stage a bulk load chunk: %w", err))
+			continue
+		}
+		staged <- chunk.Key
+	}
+}
+
+// bulkLoadRequest is a batch write request of the items of a staged chunk. wg
+// is done when the request is written.
+type bulkLoadRequest struct {
+	items []*dynamodb.WriteRequest
+	wg    *sync.WaitGroup
+}
+
+// read splits the staged chunks into batch write requests, and deletes the
+// chunks from S3 when their requests are written. The chunks are deleted
+// without loading after a failure.
+func (l *bulkLoad) read(staged <-chan []byte, requests chan<- bulkLoadRequest) {
+	for key := range staged {
+		select {
+		case <-l.failed:
+		default:
+			wg := &sync.WaitGroup{}
+			if err := l.readChunk(key, wg, requests); err != nil {
+				l.fail(err)
+			}
+			wg.Wait()
+		}
+		if err := l.dynamo.fdb.delete(key); err != nil {
+			l.dynamo.logger.Warn("failed to delete a staged bulk load chunk", "err", err, "key", string(key))
+		}
+	}
+}
+
+func (l *bulkLoad) readChunk(key []byte, wg *sync.WaitGroup, requests chan<- bulkLoadRequest) error {
+	dynamo := l.dynamo
+	data, err := dynamo.fdb.read(key)
+	if err != nil {
+		return fmt.Errorf("failed to read a staged bulk load chunk: %w", err)
+	}
+	kvs, err := decodeBulkLoadChunk(data)
+	if err != nil {
+		return err
+	}
+
+	items := make([]*dynamodb.WriteRequest, 0, dynamoBatchSize)
+	for i, kv := range kvs {
+		val := kv.Value
+		if len(val) > dynamoWriteSizeLimit {
+			if _, err := dynamo.fdb.write(item{key: kv.Key, val: val}); err != nil {
+				return fmt.Errorf("failed to write an oversized item to fileDB: %w", err)
+			}
+			val = overSizedDataPrefix
+		}
+		marshaledData, err := dynamo.encodeItem(kv.Key, val)
+		if err != nil {
+			return err
+		}
+		items = append(items, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: marshaledData}})
+
+		if len(items) == dynamoBatchSize || i == len(kvs)-1 {
+			wg.Add(1)
+			requests <- bulkLoadRequest{items: items, wg: wg}
+			items = make([]*dynamodb.WriteRequest, 0, dynamoBatchSize)
+		}
+	}
+	return nil
+}
+
+// write writes the requests like a batch write worker. The requests are
+// skipped after a failure.
+func (l *bulkLoad) write(requests <-chan bulkLoadRequest) {
+	for req := range requests {
+		select {
+		case <-l.failed:
+		default:
+			if err := l.writeItems(req.items); err != nil {
+				l.fail(err)
+			} else {
+				atomic.AddInt64(&l.loaded, int64(len(req.items)))
+			}
+		}
+		req.wg.Done()
+	}
+}
+
+func (l *bulkLoad) writeItems(items []*dynamodb.WriteRequest) error {
+	dynamo := l.dynamo
+	if dynamo.config.IdempotentImport {
+		if items = dynamo.skipExistingItems(items); len(items) == 0 {
+			return nil
+		}
+	}
+	input := &batchWriteWorkerInput{
+		tableName: dynamo.config.TableName,
+		items:     items,
+		slowOps:   dynamo.slowOps,
+		result:    &batchWriteResult{},
+		batchSize: dynamo.batchSize,
+		table:     dynamo.table,
+		retries:   dynamo.retries,
+	}
+	failCount := 0
+	abandoned := false
+	for remained := items; len(remained) > 0 && !abandoned; {
+		n := input.batchSize.size()
+		if n > len(remained) {
+			n = len(remained)
+		}
+		abandoned = batchWriteItems(input, remained[:n], &failCount)
+		remained = remained[n:]
+	}
+	input.retries.done(input, abandoned)
+	return input.result.error()
+}
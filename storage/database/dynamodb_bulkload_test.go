@@ -0,0 +1,243 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBulkLoadFixture returns a MemDB of n items, some of which are oversized.
+func newBulkLoadFixture(n int) *MemDB {
+	db := NewMemDB()
+	for i := 0; i < n; i++ {
+		val := []byte(fmt.Sprintf("val%06d", i))
+		if i%1000 == 0 {
+			val = common.MakeRandomBytes(dynamoWriteSizeLimit + 1)
+		}
+		db.Put([]byte(fmt.Sprintf("key%06d", i)), val)
+	}
+	return db
+}
+
+func TestDynamoDB_BulkLoadChunk(t *testing.T) {
+	var chunk []byte
+	chunk = appendBulkLoadItem(chunk, []byte("key1"), []byte("val1"))
+	chunk = appendBulkLoadItem(chunk, []byte("key2"), nil)
+
+	kvs, err := decodeBulkLoadChunk(chunk)
+	assert.NoError(t, err)
+	assert.Equal(t, []KV{{Key: []byte("key1"), Value: []byte("val1")}, {Key: []byte("key2"), Value: []byte{}}}, kvs)
+
+	// a truncated chunk is not decoded
+	for size := 1; size < len(chunk); size++ {
+		if size == 10 { // only the whole first item is left
+			continue
+		}
+		_, err := decodeBulkLoadChunk(chunk[:size])
+		assert.ErrorIs(t, err, errMalformedBulkLoadChunk, "size %d", size)
+	}
+}
+
+// TestDynamoDB_BulkLoad compares the items written by the bulk load with the
+// items written by the batches.
+func TestDynamoDB_BulkLoad(t *testing.T) {
+	src := newBulkLoadFixture(5000)
+	expected := make(map[string][]byte)
+	it := src.NewIterator(nil, nil)
+	for it.Next() {
+		expected[string(it.Key())] = append([]byte{}, it.Value()...)
+	}
+	it.Release()
+
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	go createBatchWriteWorker(writeCh)
+	defer close(writeCh)
+
+	load := func(bulk bool) (map[string]map[string]*dynamodb.AttributeValue, *stubFileDB) {
+		items := make(map[string]map[string]*dynamodb.AttributeValue)
+		defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+
+		config := GetTestDynamoConfig()
+		config.BulkLoadChunkSize = 64 * 1024
+		config.BulkLoadConcurrency = 8
+		dynamo := newStubDynamoDB(config)
+		fdb := newStubFileDB()
+		dynamo.fdb = fdb
+
+		it := src.NewIterator(nil, nil)
+		defer it.Release()
+		if bulk {
+			loaded, err := dynamo.BulkLoad(it, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, len(expected), loaded)
+		} else {
+			batch := dynamo.NewBatch()
+			for it.Next() {
+				assert.NoError(t, batch.Put(append([]byte{}, it.Key()...), append([]byte{}, it.Value()...)))
+			}
+			assert.NoError(t, batch.Write())
+		}
+
+		for key, val := range expected {
+			got, err := dynamo.Get([]byte(key))
+			if !assert.NoError(t, err, key) || !assert.Equal(t, val, got, key) {
+				break
+			}
+		}
+		return items, fdb
+	}
+
+	bulkItems, bulkFDB := load(true)
+	batchItems, batchFDB := load(false)
+	assert.Equal(t, batchItems, bulkItems)
+
+	// the staged chunks are deleted, and only the oversized items are left
+	assert.Equal(t, batchFDB.items, bulkFDB.items)
+	for key := range bulkFDB.items {
+		assert.False(t, strings.HasPrefix(key, string(bulkLoadChunkPrefix)))
+	}
+}
+
+func TestDynamoDB_BulkLoad_Error(t *testing.T) {
+	src := newBulkLoadFixture(1000)
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	client := newMemoryDynamoDBClient(items)
+	client.batchWriteItem = func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		return &dynamodb.BatchWriteItemOutput{}, errors.New("ValidationException: invalid item")
+	}
+	defer setTestDynamoDBClient(client)()
+
+	config := GetTestDynamoConfig()
+	config.BulkLoadChunkSize = 1024
+	dynamo := newStubDynamoDB(config)
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	it := src.NewIterator(nil, nil)
+	defer it.Release()
+	loaded, err := dynamo.BulkLoad(it, nil)
+	assert.ErrorContains(t, err, "ValidationException")
+	assert.Zero(t, loaded)
+	assert.Empty(t, items)
+
+	// the staged chunks are deleted after the failure
+	for key := range fdb.items {
+		assert.False(t, strings.HasPrefix(key, string(bulkLoadChunkPrefix)))
+	}
+
+	// the chunks can't be staged
+	fdb.err = errors.New("s3 is not available")
+	it = src.NewIterator(nil, nil)
+	defer it.Release()
+	_, err = dynamo.BulkLoad(it, nil)
+	assert.ErrorIs(t, err, fdb.err)
+}
+
+func TestDynamoDB_BulkLoad_Quit(t *testing.T) {
+	src := newBulkLoadFixture(1000)
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+
+	quit := make(chan struct{})
+	close(quit)
+	it := src.NewIterator(nil, nil)
+	defer it.Release()
+
+	// the items fetched before the interruption are loaded
+	loaded, err := dynamo.BulkLoad(it, quit)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+	assert.Len(t, items, 1)
+}
+
+func TestBulkCopyDB(t *testing.T) {
+	src := newBulkLoadFixture(100)
+	assert.NoError(t, src.Put(migrationCursorKey("other"), []byte("key000010")))
+
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+
+	// the migration cursors are not migrated
+	assert.NoError(t, bulkCopyDB("single", src, NewCoalescingDatabase(dynamo), nil))
+	assert.Len(t, items, 100)
+
+	// the DBs without bulk load are migrated by batches
+	dst := NewMemDB()
+	assert.NoError(t, bulkCopyDB("single", src, NewCoalescingDatabase(dst), nil))
+	assert.Equal(t, 100, dst.Len())
+}
+
+// BenchmarkDynamoDB_BulkLoad compares the throughput of the bulk load with the
+// batches, where a batch write request takes 5 milliseconds.
+func BenchmarkDynamoDB_BulkLoad(b *testing.B) {
+	src := NewMemDB()
+	for i := 0; i < 20000; i++ {
+		src.Put([]byte(fmt.Sprintf("key%06d", i)), common.MakeRandomBytes(100))
+	}
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	client := newMemoryDynamoDBClient(items)
+	batchWriteItem := client.batchWriteItem
+	client.batchWriteItem = func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		time.Sleep(5 * time.Millisecond)
+		return batchWriteItem(input)
+	}
+	// the migration cursors are deleted
+	client.deleteItem = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		return &dynamodb.DeleteItemOutput{}, nil
+	}
+	defer setTestDynamoDBClient(client)()
+
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	for i := 0; i < WorkerNum; i++ {
+		go createBatchWriteWorker(writeCh)
+	}
+	defer close(writeCh)
+
+	config := GetTestDynamoConfig()
+	config.BulkLoadChunkSize = defaultBulkLoadChunkSize
+	config.BulkLoadConcurrency = defaultBulkLoadConcurrency
+	dynamo := newStubDynamoDB(config)
+	dynamo.fdb = newStubFileDB()
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			assert.NoError(b, copyDB(fmt.Sprintf("batch%d", i), src, dynamo, nil))
+		}
+		b.ReportMetric(float64(src.Len()*b.N)/b.Elapsed().Seconds(), "items/s")
+	})
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			assert.NoError(b, bulkCopyDB(fmt.Sprintf("bulk%d", i), src, dynamo, nil))
+		}
+		b.ReportMetric(float64(src.Len()*b.N)/b.Elapsed().Seconds(), "items/s")
+	})
+}
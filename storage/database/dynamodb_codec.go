@@ -0,0 +1,103 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// DynamoItemCodec converts a key-value pair to a DynamoDB item and back, which
+// determines the layout of the items stored in the table. An encoded item must
+// have the binary "Key" attribute holding the key, since it is the partition
+// key of the table, while the value can be stored in any attributes.
+type DynamoItemCodec interface {
+	Encode(key, val []byte) (map[string]*dynamodb.AttributeValue, error)
+	Decode(item map[string]*dynamodb.AttributeValue) (key, val []byte, err error)
+}
+
+// dynamoDataCodec is the default codec, which stores an item as DynamoData.
+type dynamoDataCodec struct{}
+
+func (dynamoDataCodec) Encode(key, val []byte) (map[string]*dynamodb.AttributeValue, error) {
+	return dynamodbattribute.MarshalMap(DynamoData{Key: key, Val: val})
+}
+
+func (dynamoDataCodec) Decode(item map[string]*dynamodb.AttributeValue) ([]byte, []byte, error) {
+	val, err := itemValue(item)
+	if err != nil {
+		return nil, nil, err
+	}
+	var key []byte
+	if av := item["Key"]; av != nil {
+		key = av.B
+	}
+	return key, val, nil
+}
+
+// codec returns the item codec of the database, which is dynamoDataCodec if
+// it is not configured.
+func (dynamo *dynamoDB) codec() DynamoItemCodec {
+	if dynamo.config.ItemCodec == nil {
+		return dynamoDataCodec{}
+	}
+	return dynamo.config.ItemCodec
+}
+
+// DynamoItemSizer returns the size of an item in a batch write request, which
+// is accumulated in the ValueSize of a batch. The item is the attributes of a
+// put request, or the key attributes of a delete request.
+type DynamoItemSizer func(item map[string]*dynamodb.AttributeValue) int
+
+// dynamoItemOverhead approximates the bytes which DynamoDB adds to each item of
+// a request, such as the attribute types and the request framing.
+const dynamoItemOverhead = 100
+
+// defaultDynamoItemSize counts an item in the same way as DynamoDB: the lengths
+// of the attribute names and values, including the key. dynamoItemOverhead is
+// added, so that the sum of the items doesn't underestimate the request size.
+func defaultDynamoItemSize(item map[string]*dynamodb.AttributeValue) int {
+	size := dynamoItemOverhead
+	for name, attr := range item {
+		size += len(name) + attributeValueSize(attr)
+	}
+	return size
+}
+
+// attributeValueSize returns the size of the scalar attribute value.
+func attributeValueSize(attr *dynamodb.AttributeValue) int {
+	switch {
+	case attr == nil:
+		return 0
+	case attr.S != nil:
+		return len(*attr.S)
+	case attr.N != nil:
+		return len(*attr.N)
+	case attr.BOOL != nil, attr.NULL != nil:
+		return 1
+	}
+	return len(attr.B)
+}
+
+// itemSizer returns the item sizer of the database, which is
+// defaultDynamoItemSize if it is not configured.
+func (dynamo *dynamoDB) itemSizer() DynamoItemSizer {
+	if dynamo.config.ItemSizer == nil {
+		return defaultDynamoItemSize
+	}
+	return dynamo.config.ItemSizer
+}
@@ -0,0 +1,342 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// combinedCodec stores the key and the value together in a single attribute.
+type combinedCodec struct{}
+
+func (combinedCodec) Encode(key, val []byte) (map[string]*dynamodb.AttributeValue, error) {
+	kv := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(key)+len(val))
+	kv = kv[:binary.PutUvarint(kv, uint64(len(key)))]
+	kv = append(append(kv, key...), val...)
+	return map[string]*dynamodb.AttributeValue{"Key": {B: key}, "KV": {B: kv}}, nil
+}
+
+func (combinedCodec) Decode(item map[string]*dynamodb.AttributeValue) ([]byte, []byte, error) {
+	kv := item["KV"]
+	if kv == nil {
+		return nil, nil, errors.New("missing KV attribute")
+	}
+	keyLen, n := binary.Uvarint(kv.B)
+	if n <= 0 || uint64(len(kv.B)-n) < keyLen {
+		return nil, nil, errors.New("malformed KV attribute")
+	}
+	return kv.B[n : n+int(keyLen)], kv.B[n+int(keyLen):], nil
+}
+
+// memoryScanPageSize is the number of the items read by a Scan request of the
+// memory client before they are filtered, as DynamoDB applies the limit.
+const memoryScanPageSize = 4
+
+// newMemoryDynamoDBClient returns a client which keeps the items in the given map.
+// Scan returns the items in descending key order, and evaluates the key
+// conditions of keyFilter. DeleteItem fails the conditional deletes of the
+// missing items, TransactWriteItems checks the attribute_not_exists conditions
+// of the puts, and DescribeTable reports an active table.
+func newMemoryDynamoDBClient(items map[string]map[string]*dynamodb.AttributeValue) *stubDynamoDBClient {
+	var mu sync.Mutex
+	return &stubDynamoDBClient{
+		scan: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var keys []string
+			for key := range items {
+				keys = append(keys, key)
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+			start := 0
+			if input.ExclusiveStartKey != nil {
+				for start < len(keys) && keys[start] != string(input.ExclusiveStartKey["Key"].B) {
+					start++
+				}
+				start++
+			}
+			end := start + memoryScanPageSize
+			if end >= len(keys) {
+				end = len(keys)
+			}
+			output := &dynamodb.ScanOutput{}
+			for _, key := range keys[start:end] {
+				if matchKeyFilter(input, []byte(key)) {
+					output.Items = append(output.Items, items[key])
+				}
+			}
+			if end < len(keys) {
+				output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{"Key": {B: []byte(keys[end-1])}}
+			}
+			return output, nil
+		},
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return &dynamodb.GetItemOutput{Item: items[string(input.Key["Key"].B)]}, nil
+		},
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			key := string(input.Item["Key"].B)
+			output := &dynamodb.PutItemOutput{}
+			if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld {
+				output.Attributes = items[key]
+			}
+			items[key] = input.Item
+			return output, nil
+		},
+		deleteItem: func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			key := string(input.Key["Key"].B)
+			item, ok := items[key]
+			if !ok && input.ConditionExpression != nil {
+				return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+			}
+			output := &dynamodb.DeleteItemOutput{}
+			if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld {
+				output.Attributes = item
+			}
+			delete(items, key)
+			return output, nil
+		},
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+			for tableName, keys := range input.RequestItems {
+				for _, key := range keys.Keys {
+					if item, ok := items[string(key["Key"].B)]; ok {
+						output.Responses[tableName] = append(output.Responses[tableName], item)
+					}
+				}
+			}
+			return output, nil
+		},
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, reqs := range input.RequestItems {
+				for _, req := range reqs {
+					if req.DeleteRequest != nil {
+						delete(items, string(req.DeleteRequest.Key["Key"].B))
+					} else {
+						items[string(req.PutRequest.Item["Key"].B)] = req.PutRequest.Item
+					}
+				}
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+		describeTable: func(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+			return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+				TableName:   input.TableName,
+				TableStatus: aws.String(dynamodb.TableStatusActive),
+			}}, nil
+		},
+		transactWrite: func(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			var (
+				reasons  []*dynamodb.CancellationReason
+				canceled bool
+			)
+			// the transaction is written all or nothing, checking the
+			// attribute_not_exists conditions
+			for _, item := range input.TransactItems {
+				_, exist := items[string(item.Put.Item["Key"].B)]
+				if item.Put.ConditionExpression != nil && exist {
+					reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String("ConditionalCheckFailed")})
+					canceled = true
+				} else {
+					reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String("None")})
+				}
+			}
+			if canceled {
+				return nil, &dynamodb.TransactionCanceledException{
+					Message_:            aws.String("Transaction cancelled"),
+					CancellationReasons: reasons,
+				}
+			}
+			for _, item := range input.TransactItems {
+				items[string(item.Put.Item["Key"].B)] = item.Put.Item
+			}
+			return &dynamodb.TransactWriteItemsOutput{}, nil
+		},
+	}
+}
+
+// memoryItems returns the items of the memory client holding the given values.
+func memoryItems(vals map[string][]byte) map[string]map[string]*dynamodb.AttributeValue {
+	items := make(map[string]map[string]*dynamodb.AttributeValue, len(vals))
+	for key, val := range vals {
+		items[key] = map[string]*dynamodb.AttributeValue{"Key": {B: []byte(key)}, "Val": {B: val}}
+	}
+	return items
+}
+
+// memoryValues returns the values of the items of the memory client.
+func memoryValues(items map[string]map[string]*dynamodb.AttributeValue) map[string][]byte {
+	vals := make(map[string][]byte, len(items))
+	for key, item := range items {
+		vals[key] = item["Val"].B
+	}
+	return vals
+}
+
+// matchKeyFilter evaluates the key conditions set by keyFilter.
+func matchKeyFilter(input *dynamodb.ScanInput, key []byte) bool {
+	values := input.ExpressionAttributeValues
+	if v, ok := values[":prefix"]; ok && !bytes.HasPrefix(key, v.B) {
+		return false
+	}
+	if v, ok := values[":start"]; ok && bytes.Compare(key, v.B) < 0 {
+		return false
+	}
+	if v, ok := values[":end"]; ok && bytes.Compare(key, v.B) >= 0 {
+		return false
+	}
+	return true
+}
+
+func TestDynamoDB_ItemCodec(t *testing.T) {
+	tests := []struct {
+		name      string
+		codec     DynamoItemCodec
+		valueAttr string
+	}{
+		{"default", nil, "Val"},
+		{"combined", combinedCodec{}, "KV"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make(map[string]map[string]*dynamodb.AttributeValue)
+			defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+			writeCh, restore := setTestDynamoWriteCh()
+			defer restore()
+			go createBatchWriteWorker(writeCh)
+
+			config := GetTestDynamoConfig()
+			config.ItemCodec = tt.codec
+			dynamo := newStubDynamoDB(config)
+			dynamo.fdb = newStubFileDB()
+
+			// put and get
+			assert.NoError(t, dynamo.Put([]byte("key"), []byte("val")))
+			val, err := dynamo.Get([]byte("key"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("val"), val)
+
+			// the item is stored in the layout of the codec
+			if assert.Contains(t, items, "key") {
+				assert.Contains(t, items["key"], tt.valueAttr)
+			}
+
+			// batch
+			largeVal := common.MakeRandomBytes(dynamoWriteSizeLimit + 1)
+			batch := dynamo.NewBatch()
+			assert.NoError(t, batch.Put([]byte("batch"), []byte("batchVal")))
+			assert.NoError(t, batch.Put([]byte("large"), largeVal))
+			assert.NoError(t, batch.Delete([]byte("key")))
+			assert.NoError(t, batch.Write())
+
+			val, err = dynamo.Get([]byte("batch"))
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("batchVal"), val)
+			val, err = dynamo.Get([]byte("large"))
+			assert.NoError(t, err)
+			assert.Equal(t, largeVal, val)
+			assert.NotContains(t, items, "key")
+			assert.Contains(t, items["batch"], tt.valueAttr)
+		})
+	}
+}
+
+func TestDynamoBatch_ValueSize_LargeKeys(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	// the dispatched requests must be under the limit of BatchWriteItem
+	const batchWriteItemLimit = 16 * 1024 * 1024
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for input := range writeCh {
+			size := 0
+			for _, req := range input.items {
+				size += requestSize(req, defaultDynamoItemSize)
+			}
+			assert.LessOrEqual(t, size, batchWriteItemLimit)
+			input.wg.Done()
+		}
+	}()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	batch := dynamo.NewBatch()
+
+	key := make([]byte, dynamoMaxKeyLength)
+	assert.NoError(t, batch.Put(key, []byte("val")))
+	assert.Equal(t, dynamoItemOverhead+len("Key")+len(key)+len("Val"+"val"), batch.ValueSize())
+	assert.NoError(t, batch.Delete(common.MakeRandomBytes(dynamoMaxKeyLength)))
+	assert.Equal(t, 2*dynamoItemOverhead+2*len("Key")+2*len(key)+len("Val"+"val"), batch.ValueSize())
+	batch.Reset()
+
+	// the batch is flushed by ValueSize as the callers do, which counts the keys
+	val := make([]byte, dynamoWriteSizeLimit-dynamoMaxKeyLength)
+	for i := 0; i < 3*dynamoBatchSize; i++ {
+		written := batch.ValueSize()
+		assert.NoError(t, batch.Put(common.MakeRandomBytes(dynamoMaxKeyLength), val))
+		assert.GreaterOrEqual(t, batch.ValueSize()-written, len(val)+dynamoMaxKeyLength)
+		if batch.ValueSize() >= IdealBatchSize {
+			assert.NoError(t, batch.Write())
+			batch.Reset()
+		}
+	}
+	assert.NoError(t, batch.Write())
+	close(writeCh)
+	<-done
+}
+
+func TestDynamoBatch_ItemSizer(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	config := GetTestDynamoConfig()
+	config.ItemSizer = func(map[string]*dynamodb.AttributeValue) int { return 1 }
+	batch := newStubDynamoDB(config).NewBatch()
+
+	assert.NoError(t, batch.Put([]byte("key"), []byte("val")))
+	assert.NoError(t, batch.Put([]byte("key"), []byte("new")))
+	assert.NoError(t, batch.Delete([]byte("other")))
+	assert.Equal(t, 2, batch.ValueSize())
+
+	go func() { assert.NoError(t, batch.Write()) }()
+	input := <-writeCh
+	input.wg.Done()
+}
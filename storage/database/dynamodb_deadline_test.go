@@ -0,0 +1,178 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDelayedGetItemClient returns a memory client holding val at key, whose
+// GetItem takes delay.
+func newDelayedGetItemClient(key, val []byte, delay time.Duration) *stubDynamoDBClient {
+	client := newMemoryDynamoDBClient(memoryItems(map[string][]byte{string(key): val}))
+	getItem := client.getItem
+	client.getItem = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		time.Sleep(delay)
+		return getItem(input)
+	}
+	return client
+}
+
+func TestDynamoDB_GetContext(t *testing.T) {
+	key := []byte("key")
+	const budget = 400 * time.Millisecond
+
+	testcases := []struct {
+		name      string
+		val       []byte
+		getDelay  time.Duration
+		readDelay time.Duration
+		maxTime   time.Duration // the maximum time of the whole read
+		err       error
+	}{
+		{"value in budget", []byte("val"), 10 * time.Millisecond, 0, budget, nil},
+		{"oversized value in budget", overSizedDataPrefix, 10 * time.Millisecond, 10 * time.Millisecond, budget, nil},
+		// GetItem is given a half of the budget
+		{"slow GetItem", []byte("val"), 2 * time.Second, 0, budget * 3 / 4, context.DeadlineExceeded},
+		// S3 is given the rest of the budget
+		{"slow S3 read", overSizedDataPrefix, 10 * time.Millisecond, 2 * time.Second, budget + 100*time.Millisecond, context.DeadlineExceeded},
+	}
+
+	for _, tc := range testcases {
+		restore := setTestDynamoDBClient(newDelayedGetItemClient(key, tc.val, tc.getDelay))
+		dynamo := newStubDynamoDB(GetTestDynamoConfig())
+		fdb := newStubFileDB()
+		fdb.items[string(key)] = []byte("oversized value")
+		fdb.readDelay = tc.readDelay
+		dynamo.fdb = fdb
+
+		ctx, cancel := context.WithTimeout(context.Background(), budget)
+		start := time.Now()
+		val, err := dynamo.GetContext(ctx, key)
+		elapsed := time.Since(start)
+		cancel()
+		restore()
+
+		assert.Less(t, elapsed, tc.maxTime, tc.name)
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err, tc.name)
+			continue
+		}
+		assert.NoError(t, err, tc.name)
+		if string(tc.val) == string(overSizedDataPrefix) {
+			assert.Equal(t, []byte("oversized value"), val, tc.name)
+		} else {
+			assert.Equal(t, tc.val, val, tc.name)
+		}
+	}
+}
+
+func TestDynamoDB_GetContext_BreakerIgnoresDeadline(t *testing.T) {
+	defer setTestDynamoDBClient(newDelayedGetItemClient([]byte("key"), []byte("val"), time.Second))()
+	config := GetTestDynamoConfig()
+	config.BreakerThreshold, config.BreakerWindow, config.BreakerCooldown = 1, time.Minute, time.Minute
+	dynamo := newStubDynamoDB(config)
+	dynamo.fdb = newStubFileDB()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err := dynamo.GetContext(ctx, []byte("key"))
+		cancel()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	// the deadlines of the callers do not open the breaker
+	assert.NoError(t, dynamo.breaker.allow())
+}
+
+func TestGetContext_NotSupported(t *testing.T) {
+	db := NewMemDB()
+	assert.NoError(t, db.Put([]byte("key"), []byte("val")))
+
+	for _, db := range []Database{db, NewNamespacedDatabase(db, []byte{}), NewCoalescingDatabase(db)} {
+		val, err := GetContext(context.Background(), db, []byte("key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("val"), val)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = GetContext(ctx, db, []byte("key"))
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+// newBlockingWriteClient returns a memory client whose writes block until
+// release is closed.
+func newBlockingWriteClient(release <-chan struct{}) *stubDynamoDBClient {
+	client := newMemoryDynamoDBClient(make(map[string]map[string]*dynamodb.AttributeValue))
+	putItem, deleteItem := client.putItem, client.deleteItem
+	client.putItem = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		<-release
+		return putItem(input)
+	}
+	client.deleteItem = func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		<-release
+		return deleteItem(input)
+	}
+	return client
+}
+
+func TestDynamoDB_PutContext_DeleteContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	defer setTestDynamoDBClient(newBlockingWriteClient(release))()
+	config := GetTestDynamoConfig()
+	config.BreakerThreshold, config.BreakerWindow, config.BreakerCooldown = 1, time.Minute, time.Minute
+	dynamo := newStubDynamoDB(config)
+	dynamo.fdb = newStubFileDB()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	assert.ErrorIs(t, dynamo.PutContext(ctx, []byte("key"), []byte("val")), context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	assert.ErrorIs(t, dynamo.DeleteContext(ctx, []byte("key")), context.DeadlineExceeded)
+	cancel()
+
+	// the cancellations by the callers do not open the breaker
+	assert.NoError(t, dynamo.breaker.allow())
+}
+
+func TestPutContext_DeleteContext_NotSupported(t *testing.T) {
+	db := NewMemDB()
+
+	for _, db := range []Database{db, NewNamespacedDatabase(db, []byte{}), NewCoalescingDatabase(db)} {
+		assert.NoError(t, PutContext(context.Background(), db, []byte("key"), []byte("val")))
+		val, err := db.Get([]byte("key"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("val"), val)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, PutContext(ctx, db, []byte("key"), []byte("new")), context.Canceled)
+		assert.ErrorIs(t, DeleteContext(ctx, db, []byte("key")), context.Canceled)
+
+		assert.NoError(t, DeleteContext(context.Background(), db, []byte("key")))
+		has, err := db.Has([]byte("key"))
+		assert.NoError(t, err)
+		assert.False(t, has)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDB_Delete(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		items := memoryItems(map[string][]byte{
+			"key":       []byte("val"),
+			"oversized": overSizedDataPrefix,
+			"lost":      overSizedDataPrefix, // the S3 object is gone
+		})
+		restore := setTestDynamoDBClient(newMemoryDynamoDBClient(items))
+
+		config := GetTestDynamoConfig()
+		config.StrictDeletes = strict
+		dynamo := newStubDynamoDB(config)
+		fdb := newStubFileDB()
+		fdb.items["oversized"] = []byte("oversized value")
+		dynamo.fdb = fdb
+
+		assert.NoError(t, dynamo.Delete([]byte("key")), "strict %v", strict)
+		assert.NotContains(t, items, "key")
+
+		// the S3 object of an oversized value is deleted together
+		assert.NoError(t, dynamo.Delete([]byte("oversized")), "strict %v", strict)
+		assert.NotContains(t, items, "oversized")
+		assert.Empty(t, fdb.items)
+
+		// the missing keys fail only in the strict mode
+		if strict {
+			assert.ErrorIs(t, dynamo.Delete([]byte("key")), dataNotFoundErr)
+			assert.ErrorIs(t, dynamo.Delete([]byte("lost")), dataNotFoundErr)
+		} else {
+			assert.NoError(t, dynamo.Delete([]byte("key")))
+			assert.NoError(t, dynamo.Delete([]byte("lost")))
+		}
+		assert.Empty(t, items)
+
+		// a missing key does not count as a failure of DynamoDB
+		assert.NoError(t, dynamo.breaker.allow())
+		restore()
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import "github.com/aws/aws-sdk-go/aws/endpoints"
+
+// NewServiceEndpointResolver returns an endpoint resolver which resolves the
+// services in urls, keyed by their endpoint IDs such as "dynamodb", "s3" and
+// "sts", to the given URLs. It can be used to reach the services through
+// different VPC endpoints or proxies. The other services are resolved by the
+// default resolver of the AWS SDK.
+func NewServiceEndpointResolver(urls map[string]string) endpoints.Resolver {
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		resolved, err := endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+		url, exist := urls[service]
+		if !exist {
+			return resolved, err
+		}
+		if err != nil {
+			// the service is not known to the SDK in the region, so it is signed with the region as is
+			resolved = endpoints.ResolvedEndpoint{SigningRegion: region, SigningMethod: "v4"}
+		}
+		resolved.URL = url
+		return resolved, nil
+	})
+}
+
+// endpointResolver returns the endpoint resolver of the config, which is the
+// default resolver of the AWS SDK if it is not given.
+func (c *DynamoDBConfig) endpointResolver() endpoints.Resolver {
+	if c.EndpointResolver != nil {
+		return c.EndpointResolver
+	}
+	return endpoints.DefaultResolver()
+}
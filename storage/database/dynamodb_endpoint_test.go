@@ -0,0 +1,104 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServiceEndpointResolver(t *testing.T) {
+	urls := map[string]string{
+		dynamodb.EndpointsID: "https://vpce-dynamodb.example.com",
+		s3.EndpointsID:       "https://vpce-s3.example.com",
+		sts.EndpointsID:      "https://vpce-sts.example.com",
+	}
+	resolver := NewServiceEndpointResolver(urls)
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("ap-northeast-2"),
+		EndpointResolver: resolver,
+	})
+	assert.NoError(t, err)
+
+	// each service resolves to its configured endpoint
+	assert.Equal(t, urls[dynamodb.EndpointsID], dynamodb.New(sess).Endpoint)
+	assert.Equal(t, urls[s3.EndpointsID], s3.New(sess).Endpoint)
+	assert.Equal(t, urls[sts.EndpointsID], sts.New(sess).Endpoint)
+
+	// the signing name and region are kept from the default resolution
+	resolved, err := resolver.EndpointFor(sts.EndpointsID, "ap-northeast-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "ap-northeast-2", resolved.SigningRegion)
+	assert.Equal(t, "sts", resolved.SigningName)
+
+	// the other services are resolved by default
+	assert.Equal(t, "https://kms.ap-northeast-2.amazonaws.com", kms.New(sess).Endpoint)
+}
+
+func TestDynamoDBConfig_EndpointResolver(t *testing.T) {
+	resolver := NewServiceEndpointResolver(map[string]string{
+		dynamodb.EndpointsID: "https://vpce-dynamodb.example.com",
+	})
+
+	// the endpoint of DynamoDB is resolved by the resolver
+	config := &DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", EndpointResolver: resolver}
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, "https://vpce-dynamodb.example.com", config.Endpoint)
+
+	// the given endpoint takes precedence over the resolver
+	config = &DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", Endpoint: "http://localhost:4566", EndpointResolver: resolver}
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, "http://localhost:4566", config.Endpoint)
+
+	// the default resolution is kept without a resolver
+	config = &DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1"}
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, "https://dynamodb.us-east-1.amazonaws.com", config.Endpoint)
+	assert.Equal(t, endpoints.DefaultResolver(), config.endpointResolver())
+}
+
+func TestS3FileDB_EndpointResolver(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	server, objects := newFakeS3Server("test-bucket")
+	defer server.Close()
+
+	// the S3 endpoint is not given, so it is resolved by the resolver
+	resolver := NewServiceEndpointResolver(map[string]string{s3.EndpointsID: server.URL})
+	s3DB, err := newS3FileDB("us-east-1", "", "test-bucket", withS3EndpointResolver(resolver))
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, s3DB.s3.Endpoint)
+
+	key, val := []byte("key"), []byte("value")
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	got, err := s3DB.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, val, got)
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/log"
+)
+
+const (
+	hotKeyWindow     = time.Minute // the window in which unprocessed occurrences of a key are counted
+	hotKeyThreshold  = 10          // the number of occurrences in a window to regard a key as a hot key
+	maxTrackedHotKey = 10000       // the maximum number of keys counted in a window
+)
+
+// dynamoHotKeys detects hot keys from the unprocessed items of all batch write workers.
+var dynamoHotKeys = newHotKeyDetector(hotKeyWindow, hotKeyThreshold, logger)
+
+// hotKeyDetector counts how many times each key is returned as an unprocessed item of
+// BatchWriteItem, which happens when its partition is throttled. If a key is returned
+// `threshold` times within `window`, it is reported once as a suspected hot key.
+type hotKeyDetector struct {
+	window    time.Duration
+	threshold int
+	logger    log.Logger
+
+	mu          sync.Mutex
+	counts      map[string]int
+	windowStart time.Time
+	now         func() time.Time
+}
+
+func newHotKeyDetector(window time.Duration, threshold int, logger log.Logger) *hotKeyDetector {
+	return &hotKeyDetector{
+		window:    window,
+		threshold: threshold,
+		logger:    logger,
+		counts:    make(map[string]int),
+		now:       time.Now,
+	}
+}
+
+// observe counts the keys of unprocessed items of the given table.
+func (d *hotKeyDetector) observe(tableName string, items []*dynamodb.WriteRequest) {
+	dynamoUnprocessedItemMeter.Mark(int64(len(items)))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if now := d.now(); now.Sub(d.windowStart) > d.window {
+		d.counts = make(map[string]int)
+		d.windowStart = now
+	}
+	for _, item := range items {
+		key := writeRequestKey(item)
+		if key == nil {
+			continue
+		}
+		id := tableName + "/" + string(key)
+
+		count, exist := d.counts[id]
+		if !exist && len(d.counts) >= maxTrackedHotKey {
+			continue
+		}
+		d.counts[id] = count + 1
+		if count+1 == d.threshold {
+			d.logger.Warn("Suspected dynamoDB hot key. Consider the key distribution or sharding",
+				"tableName", tableName, "key", hexutil.Encode(key), "unprocessed", d.threshold, "window", d.window)
+		}
+	}
+}
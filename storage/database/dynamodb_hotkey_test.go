@@ -0,0 +1,117 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWriteRequest(key string) *dynamodb.WriteRequest {
+	return &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+		"Key": {B: []byte(key)},
+		"Val": {B: []byte("val")},
+	}}}
+}
+
+func countHotKeyWarnings(l *testLogger) int {
+	n := 0
+	for _, msg := range l.messages() {
+		if strings.HasPrefix(msg, "WARN: Suspected dynamoDB hot key") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestHotKeyDetector_Window(t *testing.T) {
+	l := &testLogger{Logger: logger}
+	d := newHotKeyDetector(time.Minute, 3, l)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	hot, cold := newTestWriteRequest("hot"), newTestWriteRequest("cold")
+	for i := 0; i < 2; i++ {
+		d.observe("table", []*dynamodb.WriteRequest{hot, cold})
+	}
+	assert.Equal(t, 0, countHotKeyWarnings(l))
+
+	// the counts are reset when the window is over
+	now = now.Add(2 * time.Minute)
+	d.observe("table", []*dynamodb.WriteRequest{hot})
+	d.observe("table", []*dynamodb.WriteRequest{hot})
+	assert.Equal(t, 0, countHotKeyWarnings(l))
+
+	// a hot key is reported only once in a window
+	for i := 0; i < 5; i++ {
+		d.observe("table", []*dynamodb.WriteRequest{hot})
+	}
+	assert.Equal(t, 1, countHotKeyWarnings(l))
+
+	// the same key of another table is counted separately
+	d.observe("other", []*dynamodb.WriteRequest{hot})
+	assert.Equal(t, 1, countHotKeyWarnings(l))
+
+	// the keys of delete requests are also counted
+	deleted := &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: map[string]*dynamodb.AttributeValue{
+		"Key": {B: []byte("deleted")},
+	}}}
+	for i := 0; i < 3; i++ {
+		d.observe("table", []*dynamodb.WriteRequest{deleted})
+	}
+	assert.Equal(t, 2, countHotKeyWarnings(l))
+}
+
+func TestBatchWriteWorker_HotKey(t *testing.T) {
+	const tableName = "table"
+	l := &testLogger{Logger: logger}
+	oldDetector := dynamoHotKeys
+	dynamoHotKeys = newHotKeyDetector(time.Minute, hotKeyThreshold, l)
+	defer func() { dynamoHotKeys = oldDetector }()
+
+	// the item of a hot key remains unprocessed for a while
+	hot := newTestWriteRequest("hot")
+	numCalls := 0
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			numCalls++
+			if numCalls > hotKeyThreshold {
+				return &dynamodb.BatchWriteItemOutput{}, nil
+			}
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{
+				tableName: {hot},
+			}}, nil
+		},
+	})()
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	writeCh <- &batchWriteWorkerInput{tableName, []*dynamodb.WriteRequest{hot, newTestWriteRequest("cold")}, wg, nil, nil, nil, nil, nil, 0, nil}
+	wg.Wait()
+
+	assert.Equal(t, hotKeyThreshold+1, numCalls)
+	assert.Equal(t, 1, countHotKeyWarnings(l))
+}
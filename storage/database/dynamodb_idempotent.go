@@ -0,0 +1,107 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// An idempotent import doesn't overwrite the items which already exist in the
+// table, so an import interrupted in the middle can be run again from the
+// start. BatchWriteItem doesn't support conditional writes, so the keys of the
+// puts in a batch are looked up by BatchGetItem before the batch is written,
+// and the puts of the existing keys are dropped.
+//
+// The oversized values are written to S3 before the lookup. They are written
+// again if they already exist, which is harmless as long as the same values are
+// imported.
+
+// skipExistingItems returns the write requests except the puts of the keys
+// which already exist in the table. If the existence can't be checked, all
+// write requests are returned, so the import goes on by overwriting them.
+func (dynamo *dynamoDB) skipExistingItems(items []*dynamodb.WriteRequest) []*dynamodb.WriteRequest {
+	keys := make([]map[string]*dynamodb.AttributeValue, 0, len(items))
+	for _, req := range items {
+		if req.PutRequest != nil {
+			keys = append(keys, map[string]*dynamodb.AttributeValue{"Key": {B: writeRequestKey(req)}})
+		}
+	}
+	if len(keys) == 0 {
+		return items
+	}
+
+	existing, err := dynamo.existingKeys(keys)
+	if err != nil {
+		dynamo.logger.Warn("failed to check the existing items, the items are overwritten", "err", err, "numItems", len(items))
+		return items
+	}
+	if len(existing) == 0 {
+		return items
+	}
+
+	filtered := make([]*dynamodb.WriteRequest, 0, len(items)-len(existing))
+	for _, req := range items {
+		if req.PutRequest != nil && existing[string(writeRequestKey(req))] {
+			continue
+		}
+		filtered = append(filtered, req)
+	}
+	dynamo.logger.Debug("skipped the existing items", "numSkipped", len(items)-len(filtered))
+	return filtered
+}
+
+// existingKeys returns the set of the given keys which exist in the table.
+// The number of keys should not exceed 100, which is the limit of BatchGetItem.
+func (dynamo *dynamoDB) existingKeys(keys []map[string]*dynamodb.AttributeValue) (map[string]bool, error) {
+	tableName := dynamo.config.TableName
+	input := &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			tableName: {
+				Keys: keys,
+				// only the keys are read, and "Key" is a reserved word of DynamoDB
+				ProjectionExpression:     aws.String("#k"),
+				ExpressionAttributeNames: map[string]*string{"#k": aws.String("Key")},
+				ConsistentRead:           aws.Bool(true),
+			},
+		},
+	}
+
+	existing := make(map[string]bool)
+	for retry := 0; ; retry++ {
+		output, err := dynamoDBClient.BatchGetItem(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range output.Responses[tableName] {
+			if key := item["Key"]; key != nil {
+				existing[string(key.B)] = true
+			}
+		}
+
+		unprocessed := output.UnprocessedKeys[tableName]
+		if unprocessed == nil || len(unprocessed.Keys) == 0 {
+			return existing, nil
+		}
+		if retry >= dynamoMaxRetry {
+			return nil, fmt.Errorf("%d keys remain unprocessed after %d retries", len(unprocessed.Keys), retry)
+		}
+		input.RequestItems = output.UnprocessedKeys
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// existenceStub answers BatchGetItem with the keys in present.
+type existenceStub struct {
+	mu        sync.Mutex
+	present   map[string]bool
+	requested []string
+}
+
+func (s *existenceStub) batchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+	for tableName, ka := range input.RequestItems {
+		for _, key := range ka.Keys {
+			s.requested = append(s.requested, string(key["Key"].B))
+			if s.present[string(key["Key"].B)] {
+				output.Responses[tableName] = append(output.Responses[tableName], key)
+			}
+		}
+	}
+	return output, nil
+}
+
+// writtenKeys consumes the batch write worker inputs from writeCh until done
+// is closed, and returns the keys of the write requests sorted.
+func writtenKeys(writeCh chan *batchWriteWorkerInput, done chan struct{}) <-chan []string {
+	result := make(chan []string, 1)
+	go func() {
+		var keys []string
+		for {
+			select {
+			case input := <-writeCh:
+				for _, req := range input.items {
+					keys = append(keys, string(writeRequestKey(req)))
+				}
+				input.wg.Done()
+			case <-done:
+				sort.Strings(keys)
+				result <- keys
+				return
+			}
+		}
+	}()
+	return result
+}
+
+func newIdempotentImportDynamoDB() *dynamoDB {
+	config := GetTestDynamoConfig()
+	config.IdempotentImport = true
+	return newStubDynamoDB(config)
+}
+
+func TestDynamoBatch_IdempotentImport(t *testing.T) {
+	stub := &existenceStub{present: map[string]bool{"k1": true, "k3": true, "k5": true}}
+	defer setTestDynamoDBClient(&stubDynamoDBClient{batchGetItem: stub.batchGetItem})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	done := make(chan struct{})
+	written := writtenKeys(writeCh, done)
+
+	batch := newIdempotentImportDynamoDB().NewBatch()
+	for _, key := range []string{"k1", "k2", "k3", "k4"} {
+		assert.NoError(t, batch.Put([]byte(key), []byte("val")))
+	}
+	assert.NoError(t, batch.Delete([]byte("k5")))
+	assert.NoError(t, batch.Write())
+	close(done)
+
+	// the existing keys are not overwritten, but the deletes are kept
+	assert.Equal(t, []string{"k2", "k4", "k5"}, <-written)
+	// only the keys of the puts are looked up
+	assert.Equal(t, []string{"k1", "k2", "k3", "k4"}, stub.requested)
+}
+
+func TestDynamoBatch_IdempotentImport_AllExisting(t *testing.T) {
+	stub := &existenceStub{present: map[string]bool{}}
+	defer setTestDynamoDBClient(&stubDynamoDBClient{batchGetItem: stub.batchGetItem})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	batch := newIdempotentImportDynamoDB().NewBatch()
+	for i := 0; i < dynamoBatchSize; i++ {
+		key := fmt.Sprintf("key%02d", i)
+		stub.present[key] = true
+		assert.NoError(t, batch.Put([]byte(key), []byte("val")))
+	}
+	// nothing is dispatched, so Write doesn't wait for any worker
+	assert.NoError(t, batch.Write())
+	assert.Len(t, writeCh, 0)
+	assert.Len(t, stub.requested, dynamoBatchSize)
+}
+
+func TestDynamoDB_ExistingKeys_Unprocessed(t *testing.T) {
+	dynamo := newIdempotentImportDynamoDB()
+	tableName := dynamo.config.TableName
+	calls := 0
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			calls++
+			ka := input.RequestItems[tableName]
+			assert.Equal(t, aws.String("#k"), ka.ProjectionExpression)
+			assert.Equal(t, aws.String("Key"), ka.ExpressionAttributeNames["#k"])
+			// the first key is found, and the others are left unprocessed
+			return &dynamodb.BatchGetItemOutput{
+				Responses: map[string][]map[string]*dynamodb.AttributeValue{
+					tableName: ka.Keys[:1],
+				},
+				UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{
+					tableName: {Keys: ka.Keys[1:], ProjectionExpression: ka.ProjectionExpression, ExpressionAttributeNames: ka.ExpressionAttributeNames},
+				},
+			}, nil
+		},
+	})()
+
+	var keys []map[string]*dynamodb.AttributeValue
+	for _, key := range []string{"a", "b", "c"} {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{"Key": {B: []byte(key)}})
+	}
+	existing, err := dynamo.existingKeys(keys)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true, "b": true, "c": true}, existing)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDynamoBatch_IdempotentImport_LookupFailure(t *testing.T) {
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			return nil, errors.New("throttled")
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	done := make(chan struct{})
+	written := writtenKeys(writeCh, done)
+
+	batch := newIdempotentImportDynamoDB().NewBatch()
+	assert.NoError(t, batch.Put([]byte("k1"), []byte("val")))
+	assert.NoError(t, batch.Put([]byte("k2"), []byte("val")))
+	assert.NoError(t, batch.Write())
+	close(done)
+
+	// the items are written if their existence is unknown
+	assert.Equal(t, []string{"k1", "k2"}, <-written)
+}
+
+func TestCopyDB_IdempotentImport(t *testing.T) {
+	srcDB := NewMemDB()
+	stub := &existenceStub{present: map[string]bool{}}
+	var expected []string
+	for i := 0; i < 3*dynamoBatchSize; i++ {
+		key := fmt.Sprintf("key%03d", i)
+		assert.NoError(t, srcDB.Put([]byte(key), []byte("val")))
+		// the items of a previous interrupted import
+		if i%2 == 0 {
+			stub.present[key] = true
+		} else {
+			expected = append(expected, key)
+		}
+	}
+	// the migration cursor is stored by single puts
+	client, _ := newMapDynamoDBClient(nil)
+	client.batchGetItem = stub.batchGetItem
+	defer setTestDynamoDBClient(client)()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	done := make(chan struct{})
+	written := writtenKeys(writeCh, done)
+	assert.NoError(t, copyDB("test", srcDB, newIdempotentImportDynamoDB(), make(chan struct{})))
+	close(done)
+
+	assert.Equal(t, expected, <-written)
+}
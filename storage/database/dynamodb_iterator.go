@@ -0,0 +1,195 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// defaultIteratorMaxItems is the default of DynamoDBConfig.IteratorMaxItems.
+const defaultIteratorMaxItems = 100000
+
+// errIteratorRangeTooLarge is returned by an iterator whose range has more
+// items than DynamoDBConfig.IteratorMaxItems.
+var errIteratorRangeTooLarge = errors.New("too many items in the range of the dynamoDB iterator")
+
+// keyFilter is the range of the keys returned by a Scan request, which is
+// applied as a FilterExpression on the binary Key attribute. A nil field leaves
+// the range unbounded on that side.
+//
+// A nil *keyFilter returns all the items.
+type keyFilter struct {
+	prefix []byte // the keys begin with prefix
+	start  []byte // the keys are equal to or greater than start
+	end    []byte // the keys are less than end
+}
+
+// apply sets the FilterExpression of the Scan request.
+func (f *keyFilter) apply(input *dynamodb.ScanInput) {
+	if f == nil {
+		return
+	}
+	var conds []string
+	values := make(map[string]*dynamodb.AttributeValue)
+	if len(f.prefix) > 0 {
+		conds = append(conds, "begins_with(#k, :prefix)")
+		values[":prefix"] = &dynamodb.AttributeValue{B: f.prefix}
+	}
+	if len(f.start) > 0 {
+		conds = append(conds, "#k >= :start")
+		values[":start"] = &dynamodb.AttributeValue{B: f.start}
+	}
+	if f.end != nil {
+		conds = append(conds, "#k < :end")
+		values[":end"] = &dynamodb.AttributeValue{B: f.end}
+	}
+	if len(conds) == 0 {
+		return
+	}
+	input.FilterExpression = aws.String(strings.Join(conds, " AND "))
+	input.ExpressionAttributeNames = map[string]*string{"#k": aws.String("Key")}
+	input.ExpressionAttributeValues = values
+}
+
+// dynamoIterator iterates the items of a key range in ascending key order.
+// The items of a table are not sorted by the hash key, so the items in the
+// range are read by Scan requests filtered by the key and sorted on the first
+// Next, which holds them in memory. It is meant for the small ranges, such as
+// the keys of a prefix, and it stops with errIteratorRangeTooLarge if the range
+// has more items than IteratorMaxItems. NewScanIterator should be used to read
+// a whole table. The oversized values are read from fileDB as they are iterated.
+type dynamoIterator struct {
+	dynamo *dynamoDB
+	filter *keyFilter
+
+	items    []KV // the items of the range sorted by the key, read on the first Next
+	loaded   bool
+	pos      int
+	value    []byte
+	err      error
+	released bool
+}
+
+// NewIterator creates an iterator over the keys with prefix, starting at
+// prefix+start, in ascending key order. See dynamoIterator for the cost of it.
+func (dynamo *dynamoDB) NewIterator(prefix []byte, start []byte) Iterator {
+	return &dynamoIterator{dynamo: dynamo, filter: &keyFilter{
+		prefix: prefix,
+		start:  append(append([]byte{}, prefix...), start...),
+	}}
+}
+
+// NewIteratorWithRange creates an iterator over the keys in [start, end) in
+// ascending key order. See dynamoIterator for the cost of it.
+func (dynamo *dynamoDB) NewIteratorWithRange(start, end []byte) Iterator {
+	return &dynamoIterator{dynamo: dynamo, filter: &keyFilter{start: start, end: end}}
+}
+
+func (it *dynamoIterator) Next() bool {
+	it.value = nil
+	if it.err != nil || it.released {
+		return false
+	}
+	if !it.loaded {
+		it.loaded, it.pos = true, -1
+		if it.err = it.load(); it.err != nil {
+			it.items = nil
+			return false
+		}
+	}
+	if it.pos+1 >= len(it.items) {
+		it.pos = len(it.items)
+		return false
+	}
+	it.pos++
+
+	item := it.items[it.pos]
+	if !bytes.Equal(item.Value, overSizedDataPrefix) {
+		it.value = item.Value
+		return true
+	}
+	val, err := it.dynamo.fdb.read(item.Key)
+	if err != nil {
+		it.err = it.dynamo.opError("GetObject", item.Key, err)
+		return false
+	}
+	it.value = val
+	return true
+}
+
+// load reads the items of the range, and sorts them by the key. It stops
+// reading if the range has more items than IteratorMaxItems.
+func (it *dynamoIterator) load() error {
+	maxItems := it.dynamo.config.IteratorMaxItems
+	if maxItems <= 0 {
+		maxItems = defaultIteratorMaxItems
+	}
+	var lastKey map[string]*dynamodb.AttributeValue
+	for {
+		page := it.dynamo.scanPage(lastKey, it.filter)
+		if page.err != nil {
+			return page.err
+		}
+		if len(it.items)+len(page.items) > maxItems {
+			return fmt.Errorf("%w: more than %d", errIteratorRangeTooLarge, maxItems)
+		}
+		for _, item := range page.items {
+			key, val, err := it.dynamo.codec().Decode(item)
+			if err != nil {
+				return fmt.Errorf("failed to unmarshal dynamodb data: %w", err)
+			}
+			if val == nil {
+				val = []byte{}
+			}
+			it.items = append(it.items, KV{Key: key, Value: val})
+		}
+		if page.lastKey == nil {
+			break
+		}
+		lastKey = page.lastKey
+	}
+	sort.Slice(it.items, func(i, j int) bool {
+		return bytes.Compare(it.items[i].Key, it.items[j].Key) < 0
+	})
+	return nil
+}
+
+func (it *dynamoIterator) Error() error {
+	return it.err
+}
+
+func (it *dynamoIterator) Key() []byte {
+	if it.value == nil {
+		return nil
+	}
+	return it.items[it.pos].Key
+}
+
+func (it *dynamoIterator) Value() []byte {
+	return it.value
+}
+
+func (it *dynamoIterator) Release() {
+	it.released, it.items, it.value = true, nil, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDB_NewIterator(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	largeVal := common.MakeRandomBytes(dynamoWriteSizeLimit + 1)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, dynamo.Put([]byte(fmt.Sprintf("a-key%d", i)), []byte(fmt.Sprintf("val%d", i))))
+		assert.NoError(t, dynamo.Put([]byte(fmt.Sprintf("b-key%d", i)), []byte("other")))
+	}
+	assert.NoError(t, dynamo.Put([]byte("a-large"), largeVal))
+	assert.NoError(t, dynamo.Put([]byte("a-empty"), []byte{}))
+
+	// the keys with the prefix from the start, in ascending order
+	it := dynamo.NewIterator([]byte("a-key"), []byte("5"))
+	for i := 5; i < 10; i++ {
+		assert.True(t, it.Next())
+		assert.Equal(t, []byte(fmt.Sprintf("a-key%d", i)), it.Key())
+		assert.Equal(t, []byte(fmt.Sprintf("val%d", i)), it.Value())
+	}
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+	assert.Nil(t, it.Value())
+	assert.NoError(t, it.Error())
+	it.Release()
+	it.Release()
+
+	// the oversized value is read from fileDB
+	it = dynamo.NewIterator([]byte("a-"), nil)
+	kvs := make(map[string][]byte)
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		kvs[string(it.Key())] = it.Value()
+	}
+	assert.NoError(t, it.Error())
+	it.Release()
+	assert.Len(t, keys, 12)
+	assert.Equal(t, []string{"a-empty", "a-key0"}, keys[:2])
+	assert.Equal(t, "a-large", keys[11])
+	assert.Equal(t, []byte{}, kvs["a-empty"])
+	assert.Equal(t, largeVal, kvs["a-large"])
+
+	// the released iterator is exhausted
+	it = dynamo.NewIterator(nil, nil)
+	assert.True(t, it.Next())
+	it.Release()
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+
+	// the oversized value missing in fileDB stops the iterator
+	delete(fdb.items, "a-large")
+	it = dynamo.NewIterator([]byte("a-large"), nil)
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Error(), ErrKeyNotFound)
+	assert.False(t, it.Next())
+	it.Release()
+}
+
+func TestDynamoDB_NewIterator_MaxItems(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	config := GetTestDynamoConfig()
+	config.IteratorMaxItems = 5
+	dynamo := newStubDynamoDB(config)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, dynamo.Put([]byte(fmt.Sprintf("a-key%d", i)), []byte("val")))
+		assert.NoError(t, dynamo.Put([]byte(fmt.Sprintf("b-key%d", i)), []byte("val")))
+	}
+
+	// the range within the limit is iterated
+	it := dynamo.NewIterator([]byte("a-"), nil)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, 5, n)
+	it.Release()
+
+	// the range beyond the limit stops the iterator without an item
+	it = dynamo.NewIterator(nil, nil)
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Error(), errIteratorRangeTooLarge)
+	assert.False(t, it.Next())
+	it.Release()
+}
+
+func TestDynamoDB_NewIterator_ScanError(t *testing.T) {
+	scanErr := fmt.Errorf("scan failed")
+	var inputs []*dynamodb.ScanInput
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		scan: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			inputs = append(inputs, input)
+			return nil, scanErr
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	it := dynamo.NewIteratorWithRange([]byte("key1"), []byte("key5"))
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Error(), scanErr)
+	assert.False(t, it.Next())
+	it.Release()
+
+	// the range is filtered by DynamoDB
+	if assert.Len(t, inputs, 1) {
+		assert.Equal(t, "#k >= :start AND #k < :end", aws.StringValue(inputs[0].FilterExpression))
+		assert.Equal(t, "Key", aws.StringValue(inputs[0].ExpressionAttributeNames["#k"]))
+		assert.Equal(t, []byte("key1"), inputs[0].ExpressionAttributeValues[":start"].B)
+		assert.Equal(t, []byte("key5"), inputs[0].ExpressionAttributeValues[":end"].B)
+	}
+
+	// no filter without a range
+	inputs = nil
+	it = dynamo.NewIterator(nil, nil)
+	assert.False(t, it.Next())
+	if assert.Len(t, inputs, 1) {
+		assert.Nil(t, inputs[0].FilterExpression)
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/klaytn/klaytn/log"
+)
+
+// stubDynamoDBClient is a DynamoDB client for unit tests which do not need localstack.
+// Only the operations assigned with a function are available.
+type stubDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+
+	getItem        func(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	putItem        func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	deleteItem     func(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+	batchWriteItem func(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+	batchGetItem   func(*dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+	scan           func(*dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	transactWrite  func(*dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
+	describeTable  func(*dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
+	createTable    func(*dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error)
+	deleteTable    func(*dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error)
+	updateTTL      func(*dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error)
+	waitUntil      func(exists bool, input *dynamodb.DescribeTableInput) error
+}
+
+func (c *stubDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return c.getItem(input)
+}
+
+// GetItemWithContext gives up waiting for getItem when ctx is done, like the
+// requests of the SDK.
+func (c *stubDynamoDBClient) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	type result struct {
+		output *dynamodb.GetItemOutput
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		output, err := c.getItem(input)
+		resultCh <- result{output, err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.output, r.err
+	case <-ctx.Done():
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+	}
+}
+
+func (c *stubDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	return c.putItem(input)
+}
+
+// PutItemWithContext gives up waiting for putItem when ctx is done.
+func (c *stubDynamoDBClient) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	type result struct {
+		output *dynamodb.PutItemOutput
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		output, err := c.putItem(input)
+		resultCh <- result{output, err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.output, r.err
+	case <-ctx.Done():
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+	}
+}
+
+func (c *stubDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return c.deleteItem(input)
+}
+
+// DeleteItemWithContext gives up waiting for deleteItem when ctx is done.
+func (c *stubDynamoDBClient) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	type result struct {
+		output *dynamodb.DeleteItemOutput
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		output, err := c.deleteItem(input)
+		resultCh <- result{output, err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.output, r.err
+	case <-ctx.Done():
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+	}
+}
+
+func (c *stubDynamoDBClient) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return c.batchWriteItem(input)
+}
+
+func (c *stubDynamoDBClient) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	return c.batchGetItem(input)
+}
+
+func (c *stubDynamoDBClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	return c.scan(input)
+}
+
+func (c *stubDynamoDBClient) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	return c.transactWrite(input)
+}
+
+func (c *stubDynamoDBClient) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	return c.describeTable(input)
+}
+
+func (c *stubDynamoDBClient) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	return c.createTable(input)
+}
+
+func (c *stubDynamoDBClient) DeleteTable(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	return c.deleteTable(input)
+}
+
+func (c *stubDynamoDBClient) UpdateTimeToLive(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	return c.updateTTL(input)
+}
+
+func (c *stubDynamoDBClient) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
+	return c.waitUntil(true, input)
+}
+
+func (c *stubDynamoDBClient) WaitUntilTableNotExists(input *dynamodb.DescribeTableInput) error {
+	return c.waitUntil(false, input)
+}
+
+// setTestDynamoDBClient replaces the global dynamoDBClient and returns a function restoring it.
+func setTestDynamoDBClient(client dynamodbiface.DynamoDBAPI) func() {
+	oldClient := dynamoDBClient
+	dynamoDBClient = client
+	return func() {
+		dynamoDBClient = oldClient
+	}
+}
+
+// newStubDynamoDB returns a dynamoDB which is not connected to any table.
+// It should be used with setTestDynamoDBClient.
+func newStubDynamoDB(config *DynamoDBConfig) *dynamoDB {
+	l := &testLogger{Logger: logger}
+	return &dynamoDB{
+		config:  *config,
+		logger:  l,
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerWindow, config.BreakerCooldown),
+		slowOps: newSlowOpLogger(config.SlowOpThreshold, slowOpLogInterval, l),
+		writes:  &dynamoWrites{},
+	}
+}
+
+// testLogger records debug, warning and error messages with their contexts
+// instead of exiting on Crit.
+type testLogger struct {
+	log.Logger
+
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *testLogger) record(lvl, msg string, ctx []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(ctx) > 0 {
+		msg = fmt.Sprintf("%s %v", msg, ctx)
+	}
+	l.msgs = append(l.msgs, fmt.Sprintf("%s: %s", lvl, msg))
+}
+
+func (l *testLogger) Debug(msg string, ctx ...interface{}) { l.record("DEBUG", msg, ctx) }
+func (l *testLogger) Info(msg string, ctx ...interface{})  { l.record("INFO", msg, ctx) }
+func (l *testLogger) Warn(msg string, ctx ...interface{})  { l.record("WARN", msg, ctx) }
+func (l *testLogger) Error(msg string, ctx ...interface{}) { l.record("ERROR", msg, ctx) }
+func (l *testLogger) Crit(msg string, ctx ...interface{})  { l.record("CRIT", msg, ctx) }
+
+func (l *testLogger) NewWith(ctx ...interface{}) log.Logger { return l }
+
+// messages returns the recorded messages.
+func (l *testLogger) messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.msgs...)
+}
+
+// setTestDynamoWriteCh replaces the global dynamoWriteCh with a channel which is not consumed by
+// batch write workers. It returns the channel and a function restoring the previous one.
+func setTestDynamoWriteCh() (chan *batchWriteWorkerInput, func()) {
+	oldCh := dynamoWriteCh
+	ch := make(chan *batchWriteWorkerInput, itemChanSize)
+	dynamoWriteCh = ch
+	return ch, func() {
+		dynamoWriteCh = oldCh
+	}
+}
+
+// stubFileDB is an in-memory fileDB which records the order of written values.
+type stubFileDB struct {
+	mu      sync.Mutex
+	items   map[string][]byte
+	written [][]byte
+	delay   func(val []byte) // called before storing an item if set
+	err     error            // returned by write if set
+
+	readDelay time.Duration // taken by readContext if set
+}
+
+func newStubFileDB() *stubFileDB {
+	return &stubFileDB{items: map[string][]byte{}}
+}
+
+func (f *stubFileDB) write(i item) (string, error) {
+	if f.delay != nil {
+		f.delay(i.val)
+	}
+	if f.err != nil {
+		return "", f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[string(i.key)] = i.val
+	f.written = append(f.written, i.val)
+	return string(i.key), nil
+}
+
+func (f *stubFileDB) read(key []byte) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	val, ok := f.items[string(key)]
+	if !ok {
+		return nil, dataNotFoundErr
+	}
+	return val, nil
+}
+
+func (f *stubFileDB) readContext(ctx context.Context, key []byte) ([]byte, error) {
+	select {
+	case <-time.After(f.readDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.read(key)
+}
+
+func (f *stubFileDB) readRange(key []byte, offset, length int64) ([]byte, error) {
+	val, err := f.read(key)
+	if err != nil {
+		return nil, err
+	}
+	return sliceFileRange(val, offset, length)
+}
+
+func (f *stubFileDB) exists(key []byte) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.items[string(key)]
+	return ok, nil
+}
+
+func (f *stubFileDB) delete(key []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, string(key))
+	return nil
+}
+
+func (f *stubFileDB) deleteExisting(key []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.items[string(key)]; !ok {
+		return dataNotFoundErr
+	}
+	delete(f.items, string(key))
+	return nil
+}
+
+func (f *stubFileDB) deleteBucket() {}
+
+func (f *stubFileDB) resetBucket() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items = make(map[string][]byte)
+	return nil
+}
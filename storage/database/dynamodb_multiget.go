@@ -0,0 +1,191 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// dynamoBatchGetSize is the maximum number of keys in a BatchGetItem request.
+const dynamoBatchGetSize = 100
+
+// multiGetFileReaders is the number of oversized values of a MultiGet read
+// from fileDB concurrently.
+const multiGetFileReaders = 8
+
+// batchGetBackoff and batchGetMaxBackoff are the initial and maximum delays
+// before retrying the unprocessed keys of a BatchGetItem request. The delay
+// doubles on every retry.
+var (
+	batchGetBackoff    = 50 * time.Millisecond
+	batchGetMaxBackoff = time.Second
+)
+
+// MultiGet returns the values of the keys in the same order as the keys,
+// along with the per-key errors. The value of a missing key is nil and its
+// error is dataNotFoundErr. The keys are read by BatchGetItem requests of up
+// to 100 keys, and the items are mapped back to the keys regardless of the
+// order of the responses. The oversized values found in the responses are read
+// from fileDB concurrently, so that they are not read one by one.
+func (dynamo *dynamoDB) MultiGet(keys [][]byte) ([][]byte, []error, error) {
+	// BatchGetItem rejects the duplicated keys, so each key is requested once
+	positions := make(map[string][]int, len(keys))
+	uniqueKeys := make([]map[string]*dynamodb.AttributeValue, 0, len(keys))
+	for i, key := range keys {
+		if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+			return nil, nil, err
+		}
+		if _, exist := positions[string(key)]; !exist {
+			uniqueKeys = append(uniqueKeys, map[string]*dynamodb.AttributeValue{"Key": {B: key}})
+		}
+		positions[string(key)] = append(positions[string(key)], i)
+	}
+
+	vals := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	for i := range errs {
+		errs[i] = dataNotFoundErr
+	}
+	set := func(key, val []byte) {
+		for _, i := range positions[string(key)] {
+			vals[i], errs[i] = val, nil
+		}
+	}
+
+	var oversized [][]byte
+	for start := 0; start < len(uniqueKeys); start += dynamoBatchGetSize {
+		end := start + dynamoBatchGetSize
+		if end > len(uniqueKeys) {
+			end = len(uniqueKeys)
+		}
+		items, err := dynamo.batchGetItems(uniqueKeys[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range items {
+			key, val, err := dynamo.codec().Decode(item)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to unmarshal dynamodb data: %w", err)
+			}
+			switch {
+			case val == nil:
+				set(key, []byte{})
+			case bytes.Equal(val, overSizedDataPrefix):
+				oversized = append(oversized, key)
+			default:
+				set(key, val)
+			}
+		}
+	}
+
+	fileVals, err := dynamo.readFiles(oversized)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, key := range oversized {
+		set(key, fileVals[i])
+	}
+	return vals, errs, nil
+}
+
+// batchGetItems reads the items of the keys by BatchGetItem, and retries the
+// unprocessed keys with backoff. The number of keys should not exceed
+// dynamoBatchGetSize.
+func (dynamo *dynamoDB) batchGetItems(keys []map[string]*dynamodb.AttributeValue) ([]map[string]*dynamodb.AttributeValue, error) {
+	tableName := dynamo.config.TableName
+	input := &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]*dynamodb.KeysAndAttributes{
+			tableName: {
+				Keys:           keys,
+				ConsistentRead: aws.Bool(!dynamo.config.EventuallyConsistentReads),
+			},
+		},
+	}
+
+	maxRetries := dynamo.config.BatchGetMaxRetries
+	if maxRetries == 0 {
+		maxRetries = dynamoMaxRetry
+	}
+
+	var items []map[string]*dynamodb.AttributeValue
+	backoff := batchGetBackoff
+	for retry := 0; ; retry++ {
+		if err := dynamo.table.allow(); err != nil {
+			return nil, err
+		}
+		if err := dynamo.breaker.allow(); err != nil {
+			return nil, err
+		}
+		output, err := dynamoDBClient.BatchGetItem(input)
+		dynamo.breaker.done(err)
+		if err != nil {
+			if !dynamo.table.observe(err) {
+				dynamo.logFailure("failed to get items", "err", err, "numKeys", len(keys))
+			}
+			return nil, err
+		}
+		items = append(items, output.Responses[tableName]...)
+
+		unprocessed := output.UnprocessedKeys[tableName]
+		if unprocessed == nil || len(unprocessed.Keys) == 0 {
+			return items, nil
+		}
+		if retry >= maxRetries {
+			return nil, fmt.Errorf("%d keys remain unprocessed after %d retries", len(unprocessed.Keys), retry)
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > batchGetMaxBackoff {
+			backoff = batchGetMaxBackoff
+		}
+		input.RequestItems = output.UnprocessedKeys
+	}
+}
+
+// readFiles reads the values of the keys from fileDB by up to
+// multiGetFileReaders concurrent reads, and returns them in the same order.
+func (dynamo *dynamoDB) readFiles(keys [][]byte) ([][]byte, error) {
+	vals := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	readers := make(chan struct{}, multiGetFileReaders)
+	for i, key := range keys {
+		wg.Add(1)
+		readers <- struct{}{}
+		go func(i int, key []byte) {
+			defer wg.Done()
+			defer func() { <-readers }()
+			vals[i], errs[i] = dynamo.fdb.read(key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			dynamo.logger.Error("failed to read filedb data", "err", err, "key", hexutil.Encode(keys[i]))
+			return nil, err
+		}
+	}
+	return vals, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// slowReadFileDB delays the reads of stubFileDB and records the maximum number
+// of concurrent reads.
+type slowReadFileDB struct {
+	*stubFileDB
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *slowReadFileDB) read(key []byte) ([]byte, error) {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return f.stubFileDB.read(key)
+}
+
+func TestDynamoDB_MultiGet(t *testing.T) {
+	config := GetTestDynamoConfig()
+	items := make(map[string][]byte)
+	var requests [][]map[string]*dynamodb.AttributeValue
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			tableName := config.TableName
+			keys := input.RequestItems[tableName].Keys
+			requests = append(requests, keys)
+
+			// the items are returned in the reverse order of the keys
+			var responses []map[string]*dynamodb.AttributeValue
+			for i := len(keys) - 1; i >= 0; i-- {
+				key := keys[i]["Key"].B
+				if val, exist := items[string(key)]; exist {
+					responses = append(responses, map[string]*dynamodb.AttributeValue{"Key": {B: key}, "Val": {B: val}})
+				}
+			}
+			return &dynamodb.BatchGetItemOutput{
+				Responses: map[string][]map[string]*dynamodb.AttributeValue{tableName: responses},
+			}, nil
+		},
+	})()
+
+	fdb := &slowReadFileDB{stubFileDB: newStubFileDB()}
+	dynamo := newStubDynamoDB(config)
+	dynamo.fdb = fdb
+
+	// a batch of inline and oversized values, more than a BatchGetItem request can hold
+	var keys, expected [][]byte
+	for i := 0; i < dynamoBatchGetSize+20; i++ {
+		key := []byte("key-" + strconv.Itoa(i))
+		val := []byte("val-" + strconv.Itoa(i))
+		if i%10 == 0 {
+			items[string(key)] = overSizedDataPrefix
+			fdb.items[string(key)] = val
+		} else {
+			items[string(key)] = val
+		}
+		keys = append(keys, key)
+		expected = append(expected, val)
+	}
+	// a missing key and a duplicated key
+	keys = append(keys, []byte("missing"), keys[10])
+	expected = append(expected, nil, expected[10])
+
+	start := time.Now()
+	vals, errs, err := dynamo.MultiGet(keys)
+	elapsed := time.Since(start)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, vals)
+	for i, err := range errs {
+		if i == len(keys)-2 {
+			assert.ErrorIs(t, err, dataNotFoundErr)
+		} else {
+			assert.NoError(t, err, "key %d", i)
+		}
+	}
+
+	// the keys are requested once by the requests of up to 100 keys
+	if assert.Len(t, requests, 2) {
+		assert.Len(t, requests[0], dynamoBatchGetSize)
+		assert.Len(t, requests[1], 21)
+	}
+
+	// the oversized values are read concurrently, not one by one
+	assert.Greater(t, fdb.maxInFlight, 1)
+	assert.LessOrEqual(t, fdb.maxInFlight, multiGetFileReaders)
+	assert.Less(t, elapsed, 12*20*time.Millisecond)
+}
+
+func TestDynamoDB_MultiGet_FileDBError(t *testing.T) {
+	config := GetTestDynamoConfig()
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			tableName := config.TableName
+			return &dynamodb.BatchGetItemOutput{
+				Responses: map[string][]map[string]*dynamodb.AttributeValue{tableName: {
+					{"Key": {B: []byte("key")}, "Val": {B: overSizedDataPrefix}},
+				}},
+			}, nil
+		},
+	})()
+
+	dynamo := newStubDynamoDB(config)
+	dynamo.fdb = newStubFileDB()
+
+	// the oversized value is missing in fileDB
+	_, _, err := dynamo.MultiGet([][]byte{[]byte("key")})
+	assert.ErrorIs(t, err, dataNotFoundErr)
+}
+
+func TestDynamoDB_MultiGet_UnprocessedKeys(t *testing.T) {
+	defer func(backoff, maxBackoff time.Duration) {
+		batchGetBackoff, batchGetMaxBackoff = backoff, maxBackoff
+	}(batchGetBackoff, batchGetMaxBackoff)
+	batchGetBackoff, batchGetMaxBackoff = 10*time.Millisecond, 20*time.Millisecond
+
+	config := GetTestDynamoConfig()
+	tableName := config.TableName
+	items := map[string][]byte{"a": []byte("val-a"), "b": []byte("val-b"), "c": []byte("val-c")}
+
+	var requests [][]string
+	var lastCall time.Time
+	var delays []time.Duration
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchGetItem: func(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+			if !lastCall.IsZero() {
+				delays = append(delays, time.Since(lastCall))
+			}
+			lastCall = time.Now()
+
+			keys := input.RequestItems[tableName].Keys
+			var requested []string
+			for _, key := range keys {
+				requested = append(requested, string(key["Key"].B))
+			}
+			requests = append(requests, requested)
+
+			// only the last requested key is processed, and the others are
+			// returned as unprocessed keys
+			output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+			last := keys[len(keys)-1]["Key"].B
+			if val, exist := items[string(last)]; exist {
+				output.Responses[tableName] = append(output.Responses[tableName],
+					map[string]*dynamodb.AttributeValue{"Key": {B: last}, "Val": {B: val}})
+			}
+			if len(keys) > 1 {
+				output.UnprocessedKeys = map[string]*dynamodb.KeysAndAttributes{
+					tableName: {Keys: keys[:len(keys)-1]},
+				}
+			}
+			return output, nil
+		},
+	})()
+
+	t.Run("retried", func(t *testing.T) {
+		requests, delays, lastCall = nil, nil, time.Time{}
+		dynamo := newStubDynamoDB(config)
+
+		keys := [][]byte{[]byte("a"), []byte("missing"), []byte("b"), []byte("c")}
+		vals, errs, err := dynamo.MultiGet(keys)
+		assert.NoError(t, err)
+
+		// the items returned in the reverse order are mapped to their keys
+		assert.Equal(t, [][]byte{items["a"], nil, items["b"], items["c"]}, vals)
+		assert.Equal(t, []error{nil, dataNotFoundErr, nil, nil}, errs)
+
+		// the unprocessed keys are retried one by one with the growing backoff
+		assert.Equal(t, [][]string{{"a", "missing", "b", "c"}, {"a", "missing", "b"}, {"a", "missing"}, {"a"}}, requests)
+		if assert.Len(t, delays, 3) {
+			assert.GreaterOrEqual(t, delays[0], 10*time.Millisecond)
+			assert.GreaterOrEqual(t, delays[1], 20*time.Millisecond)
+			assert.GreaterOrEqual(t, delays[2], 20*time.Millisecond)
+		}
+	})
+
+	t.Run("retry limit", func(t *testing.T) {
+		requests, delays, lastCall = nil, nil, time.Time{}
+		limited := *config
+		limited.BatchGetMaxRetries = 1
+		dynamo := newStubDynamoDB(&limited)
+
+		_, _, err := dynamo.MultiGet([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+		assert.EqualError(t, err, "1 keys remain unprocessed after 1 retries")
+		assert.Len(t, requests, 2)
+	})
+}
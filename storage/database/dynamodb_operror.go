@@ -0,0 +1,65 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// OpError is returned if a request to the backend of a database fails. It tells
+// which operation failed for which key, and wraps the error of the backend, so
+// that the error of AWS can be inspected by errors.As.
+//
+// A missing key is reported by ErrKeyNotFound without an OpError, but an OpError
+// of a request which found no object, like an oversized value missing in S3,
+// also matches ErrKeyNotFound by errors.Is.
+type OpError struct {
+	Op      string // the operation of the backend, such as "GetItem" or "PutObject"
+	Key     []byte
+	Backend DBType
+	Err     error
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("%s %s %s: %v", e.Backend, e.Op, hexutil.Encode(e.Key), e.Err)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports if the error is ErrKeyNotFound for the not-found errors of AWS.
+func (e *OpError) Is(target error) bool {
+	if target != ErrKeyNotFound {
+		return false
+	}
+	aerr, ok := e.Err.(awserr.Error)
+	return ok && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound")
+}
+
+// opError returns an OpError of the DynamoDB backend wrapping err, or nil if err is nil.
+func (dynamo *dynamoDB) opError(op string, key []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &OpError{Op: op, Key: common.CopyBytes(key), Backend: DynamoDB, Err: err}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDB_OpError(t *testing.T) {
+	throttled := awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			return nil, throttled
+		},
+		putItem: func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			return nil, throttled
+		},
+		deleteItem: func(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+			return nil, throttled
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	key := []byte("key")
+	_, getErr := dynamo.Get(key)
+	putErr := dynamo.Put(key, []byte("val"))
+	deleteErr := dynamo.Delete(key)
+
+	for op, err := range map[string]error{"GetItem": getErr, "PutItem": putErr, "DeleteItem": deleteErr} {
+		var opErr *OpError
+		if assert.True(t, errors.As(err, &opErr), op) {
+			assert.Equal(t, op, opErr.Op)
+			assert.Equal(t, key, opErr.Key)
+			assert.Equal(t, DBType(DynamoDB), opErr.Backend)
+		}
+		var aerr awserr.Error
+		if assert.True(t, errors.As(err, &aerr), op) {
+			assert.Equal(t, dynamodb.ErrCodeProvisionedThroughputExceededException, aerr.Code())
+		}
+		assert.False(t, errors.Is(err, ErrKeyNotFound), op)
+		assert.Contains(t, err.Error(), op)
+		assert.Contains(t, err.Error(), "0x6b6579")
+	}
+}
+
+func TestDynamoDB_OpError_KeyNotFound(t *testing.T) {
+	items := map[string]map[string]*dynamodb.AttributeValue{}
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+
+	// a missing item is reported by the sentinel itself
+	_, err := dynamo.Get([]byte("missing"))
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	// the oversized value missing in the file database
+	assert.NoError(t, dynamo.Put([]byte("oversized"), overSizedDataPrefix))
+	_, err = dynamo.Get([]byte("oversized"))
+	var opErr *OpError
+	if assert.True(t, errors.As(err, &opErr)) {
+		assert.Equal(t, "GetObject", opErr.Op)
+		assert.Equal(t, []byte("oversized"), opErr.Key)
+	}
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	// the not-found errors of S3 match the sentinel, keeping the error of AWS
+	for _, code := range []string{s3.ErrCodeNoSuchKey, "NotFound"} {
+		err = &OpError{Op: "GetObject", Key: []byte("key"), Backend: DynamoDB, Err: awserr.New(code, "not found", nil)}
+		assert.True(t, errors.Is(err, ErrKeyNotFound), code)
+		var aerr awserr.Error
+		if assert.True(t, errors.As(err, &aerr), code) {
+			assert.Equal(t, code, aerr.Code())
+		}
+	}
+	err = &OpError{Op: "GetObject", Key: []byte("key"), Backend: DynamoDB, Err: awserr.New(s3.ErrCodeNoSuchBucket, "no bucket", nil)}
+	assert.False(t, errors.Is(err, ErrKeyNotFound))
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// PutReturningOld inserts the given key and value pair, and returns the value
+// overwritten by a PutItem request with ReturnValues ALL_OLD, which saves a Get
+// before the Put. It returns nil if the key did not exist.
+//
+// An oversized old value is read from S3. If the new value is also oversized,
+// the old object is read before it is overwritten, so the old value may be
+// written by a concurrent Put of the same key after the read.
+func (dynamo *dynamoDB) PutReturningOld(key []byte, val []byte) ([]byte, error) {
+	start := time.Now()
+	old, err := dynamo.putReturningOld(key, val)
+	elapsed := time.Since(start)
+	if dynamo.config.PerfCheck {
+		dynamo.putTimer.Update(elapsed)
+	}
+	dynamo.slowOps.observe("put", key, len(val), elapsed)
+	return old, err
+}
+
+func (dynamo *dynamoDB) putReturningOld(key []byte, val []byte) ([]byte, error) {
+	if ignore, err := dynamo.checkEmptyKey(key); ignore || err != nil {
+		return nil, err
+	}
+	if err := checkKeyLength(key, dynamoMaxKeyLength); err != nil {
+		return nil, err
+	}
+
+	oversized := len(val) > dynamoWriteSizeLimit
+	var oldObject []byte
+	var oldObjectErr error
+	if oversized {
+		oldObject, oldObjectErr = dynamo.fdb.read(key)
+		if _, err := dynamo.fdb.write(item{key: key, val: val}); err != nil {
+			return nil, dynamo.opError("PutObject", key, err)
+		}
+		val = overSizedDataPrefix
+	}
+
+	attrs, err := dynamo.putItem(context.Background(), key, val, true)
+	if err != nil || len(attrs) == 0 {
+		return nil, err
+	}
+	_, old, err := dynamo.codec().Decode(attrs)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case old == nil:
+		return []byte{}, nil
+	case !bytes.Equal(old, overSizedDataPrefix):
+		return old, nil
+	case oversized:
+		old, err = oldObject, oldObjectErr
+	default:
+		// the new value is stored inline, so the old object is still in S3
+		old, err = dynamo.fdb.read(key)
+	}
+	if err != nil {
+		dynamo.logger.Error("failed to read the overwritten filedb data", "err", err, "key", hexutil.Encode(key))
+		return nil, dynamo.opError("GetObject", key, err)
+	}
+	return old, nil
+}
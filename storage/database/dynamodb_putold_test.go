@@ -0,0 +1,93 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDB_PutReturningOld(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	key := []byte("key")
+	large1 := common.MakeRandomBytes(dynamoWriteSizeLimit + 1)
+	large2 := common.MakeRandomBytes(dynamoWriteSizeLimit + 2)
+
+	tests := []struct {
+		name string
+		val  []byte
+		old  []byte
+	}{
+		{"first write", []byte("val1"), nil},
+		{"overwrite", []byte("val2"), []byte("val1")},
+		{"oversized new", large1, []byte("val2")},
+		{"oversized old and new", large2, large1},
+		{"oversized old", []byte("val3"), large2},
+		{"empty", []byte{}, []byte("val3")},
+		{"after empty", []byte("val4"), []byte{}},
+	}
+
+	for _, tt := range tests {
+		old, err := dynamo.PutReturningOld(key, tt.val)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.old, old, tt.name)
+
+		val, err := dynamo.Get(key)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.val, val, tt.name)
+	}
+
+	// the old object missing in S3 fails the put
+	assert.NoError(t, dynamo.Put(key, large1))
+	delete(fdb.items, string(key))
+	_, err := dynamo.PutReturningOld(key, []byte("val"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestPutReturningOld_Wrappers(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+
+	encKey := common.MakeRandomBytes(32)
+	enc, err := NewEncryptedDatabase(dynamo, encKey, nil)
+	assert.NoError(t, err)
+	comp, err := NewCompressedDatabase(enc, CompressionSnappy, 0)
+	assert.NoError(t, err)
+	db := NewCoalescingDatabase(NewNamespacedDatabase(comp, []byte("ns-")))
+
+	old, err := PutReturningOld(db, []byte("key"), []byte("val1"))
+	assert.NoError(t, err)
+	assert.Nil(t, old)
+	old, err = PutReturningOld(db, []byte("key"), []byte("val2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val1"), old)
+	assert.Contains(t, items, "ns-key")
+
+	// a database which cannot return the old value in the same write
+	_, err = PutReturningOld(NewMemDB(), []byte("key"), []byte("val"))
+	assert.ErrorIs(t, err, errPutReturningOldNotSupported)
+}
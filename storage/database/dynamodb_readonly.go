@@ -27,6 +27,8 @@
 
 package database
 
+import "context"
+
 // dynamoDBReadOnly uses dynamoDB.
 // Calling put, delete, batch put and batch write does nothing and returns no error.
 // Other functions such as get and has will call functions in dynamoDB.
@@ -53,13 +55,40 @@ func (dynamo *dynamoDBReadOnly) Delete(key []byte) error {
 	return nil
 }
 
-func (dynamo *dynamoDBReadOnly) Close() {
+func (dynamo *dynamoDBReadOnly) PutContext(ctx context.Context, key []byte, val []byte) error {
+	return nil
+}
+
+func (dynamo *dynamoDBReadOnly) DeleteContext(ctx context.Context, key []byte) error {
+	return nil
+}
+
+func (dynamo *dynamoDBReadOnly) TransactWrite(items []KV) error {
+	return nil
+}
+
+func (dynamo *dynamoDBReadOnly) PutReturningOld(key []byte, val []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (dynamo *dynamoDBReadOnly) BulkLoad(it Iterator, quit <-chan struct{}) (int, error) {
+	return 0, nil
+}
+
+func (dynamo *dynamoDBReadOnly) Close() error {
+	dynamo.table.stop()
+	dynamo.sweeper.stop()
+	return nil
 }
 
 func (dynamo *dynamoDBReadOnly) NewBatch() Batch {
 	return &emptyBatch{}
 }
 
+func (dynamo *dynamoDBReadOnly) NewBatchWithSize(n int) Batch {
+	return &emptyBatch{}
+}
+
 type emptyBatch struct{}
 
 func (batch *emptyBatch) Put(key, val []byte) error {
@@ -23,6 +23,7 @@ package database
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/storage"
 	"github.com/stretchr/testify/assert"
@@ -98,3 +99,25 @@ func (dynamo *dynamoDBReadOnly) deleteDB() {
 	dynamo.deleteTable()
 	dynamo.fdb.deleteBucket()
 }
+
+func TestDynamoDBReadOnly_BulkLoad(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	go createBatchWriteWorker(writeCh)
+	defer close(writeCh)
+
+	dynamo := &dynamoDBReadOnly{*newStubDynamoDB(GetTestDynamoConfig())}
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	src := newBulkLoadFixture(100)
+	it := src.NewIterator(nil, nil)
+	defer it.Release()
+	n, err := BulkLoad(dynamo, it, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Empty(t, items)
+	assert.Empty(t, fdb.items)
+}
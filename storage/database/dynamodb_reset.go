@@ -0,0 +1,90 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var errDynamoResetNotConfirmed = errors.New("the confirmation does not match the table name")
+
+// ResetDynamoDB deletes the DynamoDB table and the S3 bucket of the given config
+// with all of their data, and recreates them with the current schema. It is used
+// to recover a table which became unusable, e.g., by a manual schema change.
+// To prevent an accidental data loss, confirm must be the same as the table name.
+func ResetDynamoDB(config *DynamoDBConfig, confirm string) error {
+	if err := config.validateAndSetDefaults(); err != nil {
+		return err
+	}
+	if confirm != config.TableName {
+		return fmt.Errorf("%w: %q", errDynamoResetNotConfirmed, confirm)
+	}
+	config.TableName = strings.ReplaceAll(config.TableName, "_", "-")
+
+	fdb, err := newS3FileDBWithConfig(config)
+	if err != nil {
+		return err
+	}
+	initDynamoDBClient(config)
+
+	dynamo := &dynamoDB{
+		config: *config,
+		fdb:    fdb,
+		logger: logger.NewWith("region", config.Region, "tableName", config.TableName),
+	}
+	return dynamo.reset()
+}
+
+// reset deletes the table and the bucket, and recreates them.
+func (dynamo *dynamoDB) reset() error {
+	tableName := aws.String(dynamo.config.TableName)
+
+	_, err := dynamo.tableStatus()
+	switch {
+	case err == nil:
+		dynamo.logger.Warn("deleting the DynamoDB table with all of its data")
+		if err := dynamo.deleteTable(); err != nil {
+			return err
+		}
+		if err := dynamoDBClient.WaitUntilTableNotExists(&dynamodb.DescribeTableInput{TableName: tableName}); err != nil {
+			return fmt.Errorf("failed to wait for the table to be deleted: %w", err)
+		}
+	case strings.Contains(err.Error(), "ResourceNotFoundException"):
+		dynamo.logger.Info("the DynamoDB table does not exist")
+	default:
+		return fmt.Errorf("failed to get the table status: %w", err)
+	}
+
+	dynamo.logger.Warn("deleting the S3 bucket with all of its data")
+	if err := dynamo.fdb.resetBucket(); err != nil {
+		return fmt.Errorf("failed to reset the S3 bucket: %w", err)
+	}
+
+	if err := dynamo.createTable(); err != nil {
+		return err
+	}
+	if err := dynamoDBClient.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: tableName}); err != nil {
+		return fmt.Errorf("failed to wait for the table to be created: %w", err)
+	}
+	dynamo.logger.Info("Successfully reset the DynamoDB table and the S3 bucket")
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetDynamoDB_Confirmation(t *testing.T) {
+	// any call to AWS panics, since no function of the stub is set
+	defer setTestDynamoDBClient(&stubDynamoDBClient{})()
+
+	for _, confirm := range []string{"", "klaytn", "KLAYTN_TEST", "klaytn-test", "klaytn_test "} {
+		config := GetTestDynamoConfig()
+		config.TableName = "klaytn_test"
+		err := ResetDynamoDB(config, confirm)
+		assert.ErrorIs(t, err, errDynamoResetNotConfirmed, "confirm %q", confirm)
+	}
+
+	// the config is validated before the confirmation
+	err := ResetDynamoDB(&DynamoDBConfig{}, "")
+	assert.ErrorContains(t, err, noTableNameErr.Error())
+}
+
+func TestDynamoDB_Reset(t *testing.T) {
+	for _, exists := range []bool{true, false} {
+		var calls []string
+		restore := setTestDynamoDBClient(&stubDynamoDBClient{
+			describeTable: func(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+				calls = append(calls, "describe")
+				if !exists {
+					return nil, awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)
+				}
+				return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableStatus: aws.String(dynamodb.TableStatusActive)}}, nil
+			},
+			deleteTable: func(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+				calls = append(calls, "delete")
+				return &dynamodb.DeleteTableOutput{}, nil
+			},
+			createTable: func(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+				calls = append(calls, "create")
+				assert.Equal(t, "Key", *input.KeySchema[0].AttributeName)
+				return &dynamodb.CreateTableOutput{}, nil
+			},
+			waitUntil: func(exists bool, input *dynamodb.DescribeTableInput) error {
+				if exists {
+					calls = append(calls, "waitCreated")
+				} else {
+					calls = append(calls, "waitDeleted")
+				}
+				return nil
+			},
+		})
+
+		fdb := &stubFileDB{items: map[string][]byte{"key": []byte("val")}}
+		dynamo := newStubDynamoDB(GetTestDynamoConfig())
+		dynamo.fdb = fdb
+
+		assert.NoError(t, dynamo.reset())
+		if exists {
+			assert.Equal(t, []string{"describe", "delete", "waitDeleted", "create", "waitCreated"}, calls)
+		} else {
+			assert.Equal(t, []string{"describe", "create", "waitCreated"}, calls)
+		}
+		assert.Empty(t, fdb.items)
+		restore()
+	}
+}
+
+func TestDynamoDB_Reset_Failure(t *testing.T) {
+	errDelete := errors.New("access denied")
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		describeTable: func(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+			return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableStatus: aws.String(dynamodb.TableStatusActive)}}, nil
+		},
+		deleteTable: func(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+			return nil, errDelete
+		},
+	})()
+
+	// the bucket is kept and the table is not recreated if the table is not deleted
+	fdb := &stubFileDB{items: map[string][]byte{"key": []byte("val")}}
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = fdb
+
+	assert.ErrorIs(t, dynamo.reset(), errDelete)
+	assert.Len(t, fdb.items, 1)
+}
+
+func TestResetDynamoDB_LocalStack(t *testing.T) {
+	storage.SkipLocalTest(t)
+
+	db, remove, _ := newTestDynamoS3DB()
+	defer remove()
+	dynamo := db.(*dynamoDB)
+
+	smallKey, smallVal := common.MakeRandomBytes(32), common.MakeRandomBytes(100)
+	largeKey, largeVal := common.MakeRandomBytes(32), common.MakeRandomBytes(dynamoWriteSizeLimit+1)
+	assert.NoError(t, dynamo.Put(smallKey, smallVal))
+	assert.NoError(t, dynamo.Put(largeKey, largeVal))
+
+	config := dynamo.config
+	assert.NoError(t, ResetDynamoDB(&config, config.TableName))
+
+	// the table and the bucket are empty, but usable
+	for _, key := range [][]byte{smallKey, largeKey} {
+		_, err := dynamo.Get(key)
+		assert.Equal(t, dataNotFoundErr, err)
+	}
+	assert.NoError(t, dynamo.Put(largeKey, largeVal))
+	val, err := dynamo.Get(largeKey)
+	assert.NoError(t, err)
+	assert.Equal(t, largeVal, val)
+}
+
+func TestDynamoDB_CreateTable_BillingMode(t *testing.T) {
+	for _, provisioned := range []bool{false, true} {
+		var created *dynamodb.CreateTableInput
+		restore := setTestDynamoDBClient(&stubDynamoDBClient{
+			createTable: func(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+				created = input
+				return &dynamodb.CreateTableOutput{}, nil
+			},
+		})
+
+		config := GetTestDynamoConfig()
+		config.IsProvisioned = provisioned
+		config.ReadCapacityUnits, config.WriteCapacityUnits = 5, 7
+		dynamo := newStubDynamoDB(config)
+
+		assert.NoError(t, dynamo.createTable())
+		if provisioned {
+			assert.Equal(t, dynamodb.BillingModeProvisioned, *created.BillingMode)
+			assert.Equal(t, int64(5), *created.ProvisionedThroughput.ReadCapacityUnits)
+			assert.Equal(t, int64(7), *created.ProvisionedThroughput.WriteCapacityUnits)
+		} else {
+			assert.Equal(t, dynamodb.BillingModePayPerRequest, *created.BillingMode)
+			assert.Nil(t, created.ProvisionedThroughput)
+		}
+		restore()
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// errBatchWriteAbandoned is returned by the batch writes which keep failing
+// longer than BatchWriteRetryLimit.
+var errBatchWriteAbandoned = errors.New("dynamoDB batch write is abandoned after retrying too long")
+
+// dynamoBatchWriteProperty is the property name used to query whether a batch
+// write is abandoned via GetProperty.
+const dynamoBatchWriteProperty = "dynamodb.batchwrite"
+
+const (
+	defaultDynamoBatchWriteRetryLimit = 10 * time.Minute
+	defaultDynamoBatchWriteBackoff    = 50 * time.Millisecond
+	defaultDynamoBatchWriteMaxBackoff = 5 * time.Second
+)
+
+// batchWriteRetries bounds the retries of the batch writes of a database and
+// reports them. A batch which keeps failing or being left unprocessed longer
+// than limit, or a request retried more than maxRetries times, is abandoned,
+// and the database is marked as degraded until a batch is written again.
+//
+// A nil *batchWriteRetries never abandons a batch and retries without delay.
+type batchWriteRetries struct {
+	limit      time.Duration
+	maxRetries int // unlimited if 0
+
+	// The delay before a retry of a request is doubled from backoff up to
+	// maxBackoff. No delay is taken if backoff is 0.
+	backoff    time.Duration
+	maxBackoff time.Duration
+	sleep      func(time.Duration)
+
+	mu       sync.Mutex
+	retrying map[*batchWriteWorkerInput]time.Time // when each retrying batch is retried first
+	degraded bool
+
+	retryMeter    metrics.Meter // the number of retries
+	retryingGauge metrics.Gauge // how long the oldest retrying batch has been retried, in milliseconds
+	degradedGauge metrics.Gauge
+}
+
+func newBatchWriteRetries(limit time.Duration) *batchWriteRetries {
+	return &batchWriteRetries{
+		limit:    limit,
+		sleep:    time.Sleep,
+		retrying: make(map[*batchWriteWorkerInput]time.Time),
+	}
+}
+
+// withBackoff sets the delays between the retries of a request and the
+// maximum number of the retries.
+func (r *batchWriteRetries) withBackoff(backoff, maxBackoff time.Duration, maxRetries int) *batchWriteRetries {
+	r.backoff, r.maxBackoff, r.maxRetries = backoff, maxBackoff, maxRetries
+	return r
+}
+
+// retry records the attempt-th retry of a request of the batch, and returns
+// true if the batch has been retried longer than the limit or the request has
+// been retried more than maxRetries times, in which case it should be abandoned.
+func (r *batchWriteRetries) retry(batch *batchWriteWorkerInput, attempt int) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.retryMeter != nil {
+		r.retryMeter.Mark(1)
+	}
+	start, exist := r.retrying[batch]
+	if !exist {
+		start = time.Now()
+		r.retrying[batch] = start
+	}
+	r.updateGauges()
+	return time.Since(start) > r.limit || (r.maxRetries > 0 && attempt > r.maxRetries)
+}
+
+// wait sleeps before the attempt-th retry of a request, starting from 1.
+func (r *batchWriteRetries) wait(attempt int) {
+	if r == nil || r.backoff <= 0 {
+		return
+	}
+	r.sleep(r.delay(attempt))
+}
+
+// delay returns the backoff doubled for each retry up to maxBackoff, less a
+// random jitter of up to a half of it, which spreads the retries of the
+// workers throttled at once.
+func (r *batchWriteRetries) delay(attempt int) time.Duration {
+	d := r.backoff
+	for i := 1; i < attempt && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.maxBackoff {
+		d = r.maxBackoff
+	}
+	return d - time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// done records the end of the batch. The database is marked as degraded if the
+// batch is abandoned, and it is recovered when a batch is written.
+func (r *batchWriteRetries) done(batch *batchWriteWorkerInput, abandoned bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.retrying, batch)
+	r.degraded = abandoned
+	r.updateGauges()
+}
+
+// state returns "degraded" or "healthy", which is reported by GetProperty.
+func (r *batchWriteRetries) state() string {
+	if r == nil {
+		return "healthy"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.degraded {
+		return "degraded"
+	}
+	return "healthy"
+}
+
+func (r *batchWriteRetries) updateGauges() {
+	if r.retryingGauge != nil {
+		var longest time.Duration
+		for _, start := range r.retrying {
+			if elapsed := time.Since(start); elapsed > longest {
+				longest = elapsed
+			}
+		}
+		r.retryingGauge.Update(longest.Milliseconds())
+	}
+	if r.degradedGauge != nil {
+		if r.degraded {
+			r.degradedGauge.Update(1)
+		} else {
+			r.degradedGauge.Update(0)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchWriteWorker_RetryLimit(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		calls    int
+		failing  = true
+		retrying []int64 // the retrying gauge seen by each call
+	)
+	retries := newBatchWriteRetries(50 * time.Millisecond)
+	retries.retryMeter = metrics.NewMeter()
+	retries.retryingGauge = metrics.NewGauge()
+	retries.degradedGauge = metrics.NewGauge()
+
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			retrying = append(retrying, retries.retryingGauge.Value())
+			if failing {
+				time.Sleep(10 * time.Millisecond)
+				return &dynamodb.BatchWriteItemOutput{}, errors.New("InternalServerError: permanently failing")
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	write := func() error {
+		wg, result := &sync.WaitGroup{}, &batchWriteResult{}
+		wg.Add(1)
+		items := []*dynamodb.WriteRequest{newTestWriteRequest("key")}
+		writeCh <- &batchWriteWorkerInput{"table", items, wg, nil, result, nil, nil, retries, 0, nil}
+		wg.Wait()
+		return result.error()
+	}
+
+	// the worker gives up the permanently failing batch
+	err := write()
+	assert.ErrorIs(t, err, errBatchWriteAbandoned)
+	assert.ErrorContains(t, err, "permanently failing")
+	assert.Equal(t, "degraded", retries.state())
+	assert.Equal(t, int64(1), retries.degradedGauge.Value())
+	assert.Equal(t, int64(0), retries.retryingGauge.Value())
+
+	mu.Lock()
+	assert.Greater(t, calls, 1)
+	assert.Equal(t, int64(calls), retries.retryMeter.Count())
+	assert.Greater(t, retrying[len(retrying)-1], int64(0))
+	mu.Unlock()
+
+	// the database is recovered by a successful batch
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	assert.NoError(t, write())
+	assert.Equal(t, "healthy", retries.state())
+	assert.Equal(t, int64(0), retries.degradedGauge.Value())
+}
+
+func TestBatchWriteWorker_Backoff(t *testing.T) {
+	const (
+		backoff    = 50 * time.Millisecond
+		maxBackoff = 400 * time.Millisecond
+		maxRetries = 6
+	)
+	var (
+		mu         sync.Mutex
+		delays     []time.Duration
+		throttling int // the number of the requests to be throttled
+	)
+	retries := newBatchWriteRetries(time.Minute).withBackoff(backoff, maxBackoff, maxRetries)
+	retries.sleep = func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, d)
+	}
+
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if throttling > 0 {
+				throttling--
+				return &dynamodb.BatchWriteItemOutput{}, awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	write := func(throttled int) ([]time.Duration, error) {
+		mu.Lock()
+		throttling, delays = throttled, nil
+		mu.Unlock()
+		wg, result := &sync.WaitGroup{}, &batchWriteResult{}
+		wg.Add(1)
+		items := []*dynamodb.WriteRequest{newTestWriteRequest("key")}
+		writeCh <- &batchWriteWorkerInput{"table", items, wg, nil, result, nil, nil, retries, 0, nil}
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return delays, result.error()
+	}
+
+	// the delays are doubled up to the max backoff with a jitter of up to a half
+	got, err := write(100)
+	assert.ErrorIs(t, err, errBatchWriteAbandoned)
+	if assert.Len(t, got, maxRetries) {
+		for i, expected := range []time.Duration{50, 100, 200, 400, 400, 400} {
+			expected *= time.Millisecond
+			assert.LessOrEqual(t, got[i], expected, "retry %d", i+1)
+			assert.GreaterOrEqual(t, got[i], expected/2, "retry %d", i+1)
+		}
+	}
+
+	// the backoff starts over for the next batch
+	got, err = write(2)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 2) {
+		assert.LessOrEqual(t, got[0], backoff)
+		assert.LessOrEqual(t, got[1], 2*backoff)
+	}
+}
+
+func TestBatchWriteRetries_Nil(t *testing.T) {
+	var retries *batchWriteRetries
+	assert.False(t, retries.retry(&batchWriteWorkerInput{}, 1))
+	retries.done(&batchWriteWorkerInput{}, true)
+	assert.Equal(t, "healthy", retries.state())
+}
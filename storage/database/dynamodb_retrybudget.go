@@ -0,0 +1,62 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+	"golang.org/x/time/rate"
+)
+
+// defaultDynamoBatchWriteRetryRate is the default number of retries per second
+// allowed to the batch write workers in total.
+const defaultDynamoBatchWriteRetryRate = 100
+
+var (
+	// dynamoRetryBudget is shared by the batch write workers, so that the
+	// workers do not retry all at once and amplify the throttling during a
+	// partial outage. It is created with the workers by the first database.
+	dynamoRetryBudget *retryBudget
+
+	dynamoRetryBudgetExhaustedMeter metrics.Meter = &metrics.NilMeter{}
+)
+
+// retryBudget is a token bucket bounding the rate of the retries. Each worker
+// takes a token before retrying, and waits for a token if the budget is
+// exhausted.
+//
+// A nil *retryBudget allows all retries without waiting.
+type retryBudget struct {
+	limiter *rate.Limiter
+}
+
+// newRetryBudget returns a retryBudget allowing rps retries per second, and
+// up to burst retries at once.
+func newRetryBudget(rps float64, burst int) *retryBudget {
+	return &retryBudget{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+}
+
+// wait takes a token from the budget, waiting for one if the budget is
+// exhausted. The waits are counted by dynamoRetryBudgetExhaustedMeter.
+func (b *retryBudget) wait() {
+	if b == nil || b.limiter.Allow() {
+		return
+	}
+	dynamoRetryBudgetExhaustedMeter.Mark(1)
+	time.Sleep(b.limiter.Reserve().Delay())
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchWriteWorker_RetryBudget(t *testing.T) {
+	const (
+		workers = 4
+		rps     = 50
+		burst   = workers
+	)
+	defer func(budget *retryBudget, meter metrics.Meter) {
+		dynamoRetryBudget, dynamoRetryBudgetExhaustedMeter = budget, meter
+	}(dynamoRetryBudget, dynamoRetryBudgetExhaustedMeter)
+	dynamoRetryBudget = newRetryBudget(rps, burst)
+	dynamoRetryBudgetExhaustedMeter = metrics.NewMeter()
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return &dynamodb.BatchWriteItemOutput{}, errors.New("InternalServerError: failing")
+		},
+	})()
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	for i := 0; i < workers; i++ {
+		go createBatchWriteWorker(writeCh)
+	}
+
+	// all the workers keep failing until their batches are abandoned
+	retries := newBatchWriteRetries(300 * time.Millisecond)
+	wg, result := &sync.WaitGroup{}, &batchWriteResult{}
+	start := time.Now()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		items := []*dynamodb.WriteRequest{newTestWriteRequest("key-" + strconv.Itoa(i))}
+		writeCh <- &batchWriteWorkerInput{"table", items, wg, nil, result, nil, nil, retries, 0, nil}
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	assert.ErrorIs(t, result.error(), errBatchWriteAbandoned)
+
+	// the aggregate retries of the workers are bounded by the budget
+	mu.Lock()
+	numRetries := calls - workers
+	mu.Unlock()
+	assert.Greater(t, numRetries, 0)
+	assert.LessOrEqual(t, float64(numRetries), rps*elapsed.Seconds()+burst+1)
+	assert.Greater(t, dynamoRetryBudgetExhaustedMeter.Count(), int64(0))
+}
+
+func TestRetryBudget_Nil(t *testing.T) {
+	var budget *retryBudget
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		budget.wait()
+	}
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
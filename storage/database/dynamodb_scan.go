@@ -0,0 +1,212 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common/hexutil"
+)
+
+// scanPage is a page of the items read by a Scan request.
+type scanPage struct {
+	items   []map[string]*dynamodb.AttributeValue
+	lastKey map[string]*dynamodb.AttributeValue // the key to continue the scan, nil at the last page
+	err     error
+}
+
+// dynamoScanIterator iterates the items of a table by Scan requests, reading a
+// page of items at a time. The oversized values are read from fileDB.
+//
+// With ScanReadAhead of the config, the next pages are read by a background
+// goroutine while the current page is consumed, which overlaps the latency of
+// the Scan requests with the processing of the items.
+type dynamoScanIterator struct {
+	dynamo      *dynamoDB
+	skipCorrupt bool
+
+	page    []map[string]*dynamodb.AttributeValue
+	lastKey map[string]*dynamodb.AttributeValue // the key to continue the scan
+	started bool
+
+	readAhead int
+	pages     chan scanPage // the pages read ahead, nil until the read-ahead starts
+	quit      chan struct{} // stops the read-ahead
+
+	key, value []byte
+	stats      ScanStats
+	err        error
+	released   bool
+}
+
+// NewScanIterator creates an iterator over all items of the table in no
+// particular order. Without skipCorrupt, the iterator stops at an item which
+// cannot be decoded or whose oversized value cannot be read, and Error returns
+// the failure. With skipCorrupt, such items are logged and counted in Stats.
+func (dynamo *dynamoDB) NewScanIterator(skipCorrupt bool) ScanIterator {
+	return &dynamoScanIterator{dynamo: dynamo, skipCorrupt: skipCorrupt, readAhead: dynamo.config.ScanReadAhead}
+}
+
+func (it *dynamoScanIterator) Next() bool {
+	it.key, it.value = nil, nil
+	if it.err != nil || it.released {
+		return false
+	}
+	for {
+		for len(it.page) > 0 {
+			item := it.page[0]
+			it.page = it.page[1:]
+
+			key, val, err := it.resolve(item)
+			if err == nil {
+				it.key, it.value = key, val
+				it.stats.Items++
+				return true
+			}
+			if !it.skipCorrupt {
+				it.err = err
+				return false
+			}
+			it.stats.Skipped++
+			it.dynamo.logger.Warn("Skip a corrupt item of dynamoDB", "err", err, "key", hexutil.Encode(key))
+		}
+
+		// the scan is finished if there is no key to continue after the first page
+		if it.started && it.lastKey == nil {
+			return false
+		}
+		page := it.nextPage()
+		if page.err != nil {
+			it.err = page.err
+			return false
+		}
+		it.started = true
+		it.page, it.lastKey = page.items, page.lastKey
+	}
+}
+
+// nextPage returns the next page, which is read ahead if readAhead is set.
+func (it *dynamoScanIterator) nextPage() scanPage {
+	if it.readAhead <= 0 {
+		return it.dynamo.scanPage(it.lastKey, nil)
+	}
+	if it.pages == nil {
+		// the read-ahead goroutine holds up to readAhead pages not consumed yet,
+		// including the one being sent
+		it.pages = make(chan scanPage, it.readAhead-1)
+		it.quit = make(chan struct{})
+		go it.dynamo.readAheadScan(it.pages, it.quit)
+	}
+	return <-it.pages
+}
+
+// readAheadScan reads the pages of the table one after another until the last
+// page or an error, and sends them to pages. It stops when quit is closed.
+func (dynamo *dynamoDB) readAheadScan(pages chan<- scanPage, quit <-chan struct{}) {
+	var lastKey map[string]*dynamodb.AttributeValue
+	for {
+		page := dynamo.scanPage(lastKey, nil)
+		select {
+		case pages <- page:
+		case <-quit:
+			return
+		}
+		if page.err != nil || page.lastKey == nil {
+			return
+		}
+		lastKey = page.lastKey
+	}
+}
+
+// scanPage reads a page of the items starting after startKey. If filter is
+// given, only the items whose keys are in its range are returned.
+func (dynamo *dynamoDB) scanPage(startKey map[string]*dynamodb.AttributeValue, filter *keyFilter) scanPage {
+	if err := dynamo.table.allow(); err != nil {
+		return scanPage{err: err}
+	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return scanPage{err: err}
+	}
+	input := &dynamodb.ScanInput{
+		TableName:         aws.String(dynamo.config.TableName),
+		ConsistentRead:    aws.Bool(true),
+		ExclusiveStartKey: startKey,
+	}
+	filter.apply(input)
+	output, err := dynamoDBClient.Scan(input)
+	dynamo.breaker.done(err)
+	if err != nil {
+		dynamo.table.observe(err)
+		return scanPage{err: err}
+	}
+	page := scanPage{items: output.Items, lastKey: output.LastEvaluatedKey}
+	if len(page.lastKey) == 0 {
+		page.lastKey = nil
+	}
+	return page
+}
+
+// resolve returns the key and the value of an item, reading the value from
+// fileDB if it is oversized.
+func (it *dynamoScanIterator) resolve(item map[string]*dynamodb.AttributeValue) ([]byte, []byte, error) {
+	key, val, err := it.dynamo.codec().Decode(item)
+	if err != nil {
+		if av := item["Key"]; av != nil {
+			key = av.B
+		}
+		return key, nil, fmt.Errorf("failed to unmarshal dynamodb data: %w", err)
+	}
+	switch {
+	case val == nil:
+		return key, []byte{}, nil
+	case bytes.Equal(val, overSizedDataPrefix):
+		val, err := it.dynamo.fdb.read(key)
+		if err != nil {
+			return key, nil, fmt.Errorf("failed to read filedb data: %w", err)
+		}
+		return key, val, nil
+	}
+	return key, val, nil
+}
+
+func (it *dynamoScanIterator) Key() []byte {
+	return it.key
+}
+
+func (it *dynamoScanIterator) Value() []byte {
+	return it.value
+}
+
+func (it *dynamoScanIterator) Error() error {
+	return it.err
+}
+
+func (it *dynamoScanIterator) Stats() ScanStats {
+	return it.stats
+}
+
+func (it *dynamoScanIterator) Release() {
+	if it.quit != nil && !it.released {
+		close(it.quit)
+	}
+	it.released = true
+	it.page, it.lastKey = nil, nil
+	it.key, it.value = nil, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func scanAll(it ScanIterator) map[string]string {
+	items := make(map[string]string)
+	for it.Next() {
+		items[string(it.Key())] = string(it.Value())
+	}
+	return items
+}
+
+func TestDynamoDB_ScanIterator(t *testing.T) {
+	items := map[string][]byte{
+		"key1":      []byte("val1"),
+		"key2":      []byte("val2"),
+		"key3":      []byte("val3"),
+		"oversized": overSizedDataPrefix,
+		"lost":      overSizedDataPrefix, // the S3 object is gone
+		"key4":      []byte("val4"),
+	}
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(memoryItems(items)))()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	fdb := newStubFileDB()
+	fdb.items["oversized"] = []byte("oversized value")
+	dynamo.fdb = fdb
+
+	// the scan stops at the corrupt item by default
+	it := dynamo.NewScanIterator(false)
+	scanAll(it)
+	assert.Error(t, it.Error())
+	assert.Contains(t, it.Error().Error(), "failed to read filedb data")
+	it.Release()
+
+	// the scan completes skipping the corrupt item
+	it = dynamo.NewScanIterator(true)
+	assert.Equal(t, map[string]string{
+		"key1":      "val1",
+		"key2":      "val2",
+		"key3":      "val3",
+		"key4":      "val4",
+		"oversized": "oversized value",
+	}, scanAll(it))
+	assert.NoError(t, it.Error())
+	assert.Equal(t, ScanStats{Items: 5, Skipped: 1}, it.Stats())
+	it.Release()
+	assert.False(t, it.Next())
+}
+
+func TestDynamoDB_ScanIterator_Error(t *testing.T) {
+	errScan := errors.New("scan failed")
+	// the memory client reads the items in pages of memoryScanPageSize items
+	// in descending key order
+	client := newMemoryDynamoDBClient(memoryItems(map[string][]byte{
+		"key0": []byte("val0"),
+		"key1": []byte("val1"),
+		"key2": []byte("val2"),
+		"key3": []byte("val3"),
+		"key4": []byte("val4"),
+	}))
+	scan := client.scan
+	client.scan = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		// the second page fails
+		if input.ExclusiveStartKey != nil {
+			return nil, errScan
+		}
+		return scan(input)
+	}
+	defer setTestDynamoDBClient(client)()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+
+	// the errors of the requests are not skipped
+	it := dynamo.NewScanIterator(true)
+	assert.Equal(t, map[string]string{"key1": "val1", "key2": "val2", "key3": "val3", "key4": "val4"}, scanAll(it))
+	assert.ErrorIs(t, it.Error(), errScan)
+	assert.Equal(t, ScanStats{Items: 4}, it.Stats())
+	it.Release()
+
+	// the empty table
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(memoryItems(nil)))()
+	it = dynamo.NewScanIterator(false)
+	assert.Empty(t, scanAll(it))
+	assert.NoError(t, it.Error())
+	it.Release()
+}
+
+func TestDynamoDB_ScanIterator_ReadAhead(t *testing.T) {
+	items := make(map[string][]byte)
+	expected := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key, val := fmt.Sprintf("key%03d", i), fmt.Sprintf("val%03d", i)
+		items[key], expected[key] = []byte(val), val
+	}
+	client := newMemoryDynamoDBClient(memoryItems(items))
+	scan := client.scan
+	var scans int32
+	client.scan = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		atomic.AddInt32(&scans, 1)
+		return scan(input)
+	}
+	defer setTestDynamoDBClient(client)()
+
+	for _, readAhead := range []int{1, 3} {
+		config := GetTestDynamoConfig()
+		config.ScanReadAhead = readAhead
+		dynamo := newStubDynamoDB(config)
+		dynamo.fdb = newStubFileDB()
+
+		// the items are the same as without the read-ahead
+		atomic.StoreInt32(&scans, 0)
+		it := dynamo.NewScanIterator(false)
+		assert.Equal(t, expected, scanAll(it))
+		assert.NoError(t, it.Error())
+		assert.Equal(t, ScanStats{Items: 100}, it.Stats())
+		it.Release()
+		assert.Equal(t, int32(100/memoryScanPageSize), atomic.LoadInt32(&scans))
+
+		// the pages are read ahead up to readAhead pages, and the read-ahead
+		// stops on Release
+		atomic.StoreInt32(&scans, 0)
+		it = dynamo.NewScanIterator(false)
+		assert.True(t, it.Next())
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int32(1+readAhead), atomic.LoadInt32(&scans), "readAhead %d", readAhead)
+		it.Release()
+		assert.False(t, it.Next())
+		time.Sleep(50 * time.Millisecond)
+		assert.LessOrEqual(t, atomic.LoadInt32(&scans), int32(2+readAhead))
+	}
+}
+
+// BenchmarkDynamoDB_ScanIterator compares the iteration of a table whose Scan
+// requests take a millisecond, while each item takes 10 microseconds to process.
+func BenchmarkDynamoDB_ScanIterator(b *testing.B) {
+	items := make(map[string][]byte)
+	for i := 0; i < 100*memoryScanPageSize; i++ {
+		items[fmt.Sprintf("key%05d", i)] = []byte("value")
+	}
+	client := newMemoryDynamoDBClient(memoryItems(items))
+	scan := client.scan
+	client.scan = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		time.Sleep(time.Millisecond)
+		return scan(input)
+	}
+	defer setTestDynamoDBClient(client)()
+
+	for _, readAhead := range []int{0, 1, 4} {
+		b.Run(fmt.Sprintf("readAhead=%d", readAhead), func(b *testing.B) {
+			config := GetTestDynamoConfig()
+			config.ScanReadAhead = readAhead
+			dynamo := newStubDynamoDB(config)
+			dynamo.fdb = newStubFileDB()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				it := dynamo.NewScanIterator(false)
+				for it.Next() {
+					for start := time.Now(); time.Since(start) < 10*time.Microsecond; {
+					}
+				}
+				it.Release()
+			}
+		})
+	}
+}
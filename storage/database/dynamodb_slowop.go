@@ -0,0 +1,85 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"sync"
+	"time"
+
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/klaytn/klaytn/log"
+)
+
+const (
+	defaultDynamoSlowOpThreshold = time.Second      // the default duration above which an operation is slow
+	slowOpLogInterval            = 10 * time.Second // the minimum interval between slow operation warnings
+	slowOpKeyPrefixLen           = 8                // the number of key bytes logged as the key prefix
+)
+
+// slowOpLogger warns about the DynamoDB operations which take longer than the
+// threshold. At most one warning is logged in `interval`, and the slow
+// operations observed in between are counted and reported with the next one.
+// A nil slowOpLogger ignores all operations.
+type slowOpLogger struct {
+	threshold time.Duration
+	interval  time.Duration
+	logger    log.Logger
+
+	mu         sync.Mutex
+	lastLogged time.Time
+	suppressed int
+	now        func() time.Time
+}
+
+// newSlowOpLogger returns a slowOpLogger, or nil if the threshold is not positive.
+func newSlowOpLogger(threshold, interval time.Duration, logger log.Logger) *slowOpLogger {
+	if threshold <= 0 {
+		return nil
+	}
+	return &slowOpLogger{
+		threshold: threshold,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// observe logs the operation if it took longer than the threshold. size is the
+// number of bytes read or written by the operation.
+func (l *slowOpLogger) observe(op string, key []byte, size int, elapsed time.Duration, ctx ...interface{}) {
+	if l == nil || elapsed < l.threshold {
+		return
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	if !l.lastLogged.IsZero() && now.Sub(l.lastLogged) < l.interval {
+		l.suppressed++
+		l.mu.Unlock()
+		return
+	}
+	suppressed := l.suppressed
+	l.lastLogged, l.suppressed = now, 0
+	l.mu.Unlock()
+
+	if len(key) > slowOpKeyPrefixLen {
+		key = key[:slowOpKeyPrefixLen]
+	}
+	ctx = append([]interface{}{"op", op, "elapsed", elapsed, "threshold", l.threshold,
+		"keyPrefix", hexutil.Encode(key), "size", size}, ctx...)
+	l.logger.Warn("Slow dynamoDB operation", append(ctx, "suppressed", suppressed)...)
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func slowOpWarnings(l *testLogger) []string {
+	var warnings []string
+	for _, msg := range l.messages() {
+		if strings.HasPrefix(msg, "WARN: Slow dynamoDB operation") {
+			warnings = append(warnings, msg)
+		}
+	}
+	return warnings
+}
+
+func TestSlowOpLogger_RateLimit(t *testing.T) {
+	l := &testLogger{Logger: logger}
+	slowOps := newSlowOpLogger(time.Second, time.Minute, l)
+	now := time.Now()
+	slowOps.now = func() time.Time { return now }
+
+	key := []byte("0123456789abcdef")
+	slowOps.observe("get", key, 10, time.Millisecond) // fast
+	slowOps.observe("get", key, 10, 2*time.Second)
+	slowOps.observe("put", key, 20, 3*time.Second) // suppressed
+	slowOps.observe("put", key, 30, 4*time.Second) // suppressed
+	warnings := slowOpWarnings(l)
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0], "op get elapsed 2s")
+		assert.Contains(t, warnings[0], "keyPrefix 0x3031323334353637 size 10")
+		assert.Contains(t, warnings[0], "suppressed 0")
+	}
+
+	now = now.Add(time.Minute)
+	slowOps.observe("put", key, 40, 5*time.Second)
+	warnings = slowOpWarnings(l)
+	if assert.Len(t, warnings, 2) {
+		assert.Contains(t, warnings[1], "op put elapsed 5s")
+		assert.Contains(t, warnings[1], "suppressed 2")
+	}
+
+	// disabled
+	assert.Nil(t, newSlowOpLogger(0, time.Minute, l))
+	var disabled *slowOpLogger
+	disabled.observe("get", key, 10, time.Hour)
+}
+
+func TestDynamoDB_SlowOp(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			time.Sleep(delay)
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": input.Key["Key"],
+				"Val": {B: []byte("value")},
+			}}, nil
+		},
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			time.Sleep(delay)
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+
+	config := GetTestDynamoConfig()
+	config.SlowOpThreshold = delay / 2
+	dynamo := newStubDynamoDB(config)
+	dynamo.slowOps.interval = 0
+	l := dynamo.logger.(*testLogger)
+
+	// fast operations are not logged
+	assert.NoError(t, dynamo.Put([]byte("fast-key"), []byte("value")))
+	assert.Empty(t, slowOpWarnings(l))
+
+	val, err := dynamo.Get([]byte("slow-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), val)
+	warnings := slowOpWarnings(l)
+	if assert.Len(t, warnings, 1) {
+		assert.Contains(t, warnings[0], "op get")
+		assert.Contains(t, warnings[0], "keyPrefix 0x736c6f772d6b6579 size 5")
+	}
+
+	writeCh := make(chan *batchWriteWorkerInput)
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	items := []*dynamodb.WriteRequest{newTestWriteRequest("batch-key"), newTestWriteRequest("other")}
+	writeCh <- &batchWriteWorkerInput{config.TableName, items, wg, dynamo.slowOps, nil, nil, nil, nil, 0, nil}
+	wg.Wait()
+
+	warnings = slowOpWarnings(l)
+	if assert.Len(t, warnings, 2) {
+		assert.Contains(t, warnings[1], "op batchWrite")
+		size := requestSize(items[0], defaultDynamoItemSize) + requestSize(items[1], defaultDynamoItemSize)
+		assert.Contains(t, warnings[1], fmt.Sprintf("keyPrefix 0x62617463682d6b65 size %d", size))
+		assert.Contains(t, warnings[1], "items 2")
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/rcrowley/go-metrics"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultOversizedSweepSegments = 100
+	defaultOversizedSweepRate     = 10
+
+	oversizedSweepPageSize = 100 // the number of items read by a Scan request of a sweep
+)
+
+// OversizedRepairSource returns the value of an oversized item whose S3 object
+// is missing, which is written back to S3 by the sweeper. It should return
+// ErrKeyNotFound if the value is not available.
+type OversizedRepairSource func(key []byte) ([]byte, error)
+
+// sweepStats counts the oversized items checked by a sweep.
+type sweepStats struct {
+	Checked  int // the oversized items whose S3 objects are checked
+	Missing  int // the oversized items whose S3 objects are missing
+	Repaired int // the missing S3 objects written back from the repair source
+}
+
+// oversizedSweeper periodically verifies that the S3 objects of the oversized
+// items exist, which detects the objects lost silently before they are read.
+// The table is divided into segments, and a sweep scans one of them in turn,
+// so that the whole table is verified once every `segments` sweeps. The Scan
+// and S3 requests of the sweeps are limited to `rate` per second in total.
+//
+// A nil *oversizedSweeper is valid and never sweeps.
+type oversizedSweeper struct {
+	dynamo   *dynamoDB
+	interval time.Duration
+	segments int
+	limiter  *rate.Limiter
+	repair   OversizedRepairSource // writes back the missing objects if set
+
+	next int // the segment scanned by the next sweep
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	checkedMeter  metrics.Meter
+	missingMeter  metrics.Meter
+	repairedMeter metrics.Meter
+}
+
+// newOversizedSweeper returns a sweeper of the database, or nil if the
+// interval is 0. The sweeper does not repair the objects of a read-only database.
+func newOversizedSweeper(dynamo *dynamoDB) *oversizedSweeper {
+	config := dynamo.config
+	if config.OversizedSweepInterval <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &oversizedSweeper{
+		dynamo:   dynamo,
+		interval: config.OversizedSweepInterval,
+		segments: config.OversizedSweepSegments,
+		limiter:  rate.NewLimiter(rate.Limit(config.OversizedSweepRate), 1),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if !config.ReadOnly {
+		s.repair = config.OversizedRepairSource
+	}
+	return s
+}
+
+// start sweeps a segment every interval until the sweeper is stopped.
+func (s *oversizedSweeper) start() {
+	if s == nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				stats, err := s.sweep()
+				if err != nil && s.ctx.Err() == nil {
+					s.dynamo.logger.Warn("Failed to sweep the oversized items", "err", err, "stats", stats)
+				}
+			}
+		}
+	}()
+}
+
+// stop stops the sweeps and waits for the running one to return.
+func (s *oversizedSweeper) stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+// sweep scans the next segment, and checks the S3 objects of the oversized
+// items in it. The missing objects are logged, and written back if the repair
+// source has their values.
+func (s *oversizedSweeper) sweep() (sweepStats, error) {
+	var stats sweepStats
+	segment := s.next
+	s.next = (s.next + 1) % s.segments
+
+	var startKey map[string]*dynamodb.AttributeValue
+	for {
+		if err := s.limiter.Wait(s.ctx); err != nil {
+			return stats, err
+		}
+		output, err := s.scanSegment(segment, startKey)
+		if err != nil {
+			return stats, err
+		}
+		for _, item := range output.Items {
+			key, val, err := s.dynamo.codec().Decode(item)
+			if err != nil || !bytes.Equal(val, overSizedDataPrefix) {
+				continue
+			}
+			if err := s.check(key, &stats); err != nil {
+				return stats, err
+			}
+		}
+		if len(output.LastEvaluatedKey) == 0 {
+			return stats, nil
+		}
+		startKey = output.LastEvaluatedKey
+	}
+}
+
+// scanSegment reads a page of the segment starting after startKey. The
+// eventually consistent reads are enough, since a marker written just before
+// is checked by a later sweep.
+func (s *oversizedSweeper) scanSegment(segment int, startKey map[string]*dynamodb.AttributeValue) (*dynamodb.ScanOutput, error) {
+	dynamo := s.dynamo
+	if err := dynamo.table.allow(); err != nil {
+		return nil, err
+	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return nil, err
+	}
+	output, err := dynamoDBClient.Scan(&dynamodb.ScanInput{
+		TableName:         aws.String(dynamo.config.TableName),
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int64(oversizedSweepPageSize),
+		Segment:           aws.Int64(int64(segment)),
+		TotalSegments:     aws.Int64(int64(s.segments)),
+	})
+	dynamo.breaker.done(err)
+	if err != nil {
+		dynamo.table.observe(err)
+		return nil, dynamo.opError("Scan", nil, err)
+	}
+	return output, nil
+}
+
+// check checks the S3 object of an oversized item, and repairs it if it is missing.
+func (s *oversizedSweeper) check(key []byte, stats *sweepStats) error {
+	if err := s.limiter.Wait(s.ctx); err != nil {
+		return err
+	}
+	exists, err := s.dynamo.fdb.exists(key)
+	if err != nil {
+		return s.dynamo.opError("HeadObject", key, err)
+	}
+	stats.Checked++
+	markMeter(s.checkedMeter)
+	if exists {
+		return nil
+	}
+	stats.Missing++
+	markMeter(s.missingMeter)
+	if s.repair == nil {
+		s.dynamo.logger.Error("The S3 object of an oversized item is missing", "key", hexutil.Encode(key))
+		return nil
+	}
+	val, err := s.repair(key)
+	if err == nil {
+		_, err = s.dynamo.fdb.write(item{key: key, val: val})
+	}
+	if err != nil {
+		s.dynamo.logger.Error("The S3 object of an oversized item is missing, and failed to repair it",
+			"key", hexutil.Encode(key), "err", err)
+		return nil
+	}
+	stats.Repaired++
+	markMeter(s.repairedMeter)
+	s.dynamo.logger.Warn("Repaired the missing S3 object of an oversized item", "key", hexutil.Encode(key), "size", len(val))
+	return nil
+}
+
+func markMeter(m metrics.Meter) {
+	if m != nil {
+		m.Mark(1)
+	}
+}
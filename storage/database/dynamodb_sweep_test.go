@@ -0,0 +1,156 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOversizedSweeper(t *testing.T, config *DynamoDBConfig, items map[string][]byte) (*oversizedSweeper, *stubFileDB) {
+	client := newMemoryDynamoDBClient(memoryItems(items))
+	scan := client.scan
+	client.scan = func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		assert.Equal(t, int64(0), *input.Segment)
+		assert.Equal(t, int64(1), *input.TotalSegments)
+		return scan(input)
+	}
+	t.Cleanup(setTestDynamoDBClient(client))
+
+	config.OversizedSweepInterval = time.Hour
+	config.OversizedSweepSegments = 1
+	config.OversizedSweepRate = 1000
+	dynamo := newStubDynamoDB(config)
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	sweeper := newOversizedSweeper(dynamo)
+	sweeper.checkedMeter = metrics.NewMeter()
+	sweeper.missingMeter = metrics.NewMeter()
+	sweeper.repairedMeter = metrics.NewMeter()
+	t.Cleanup(sweeper.stop)
+	return sweeper, fdb
+}
+
+func TestOversizedSweeper(t *testing.T) {
+	items := map[string][]byte{
+		"key1":       []byte("val1"),
+		"oversized1": overSizedDataPrefix,
+		"key2":       []byte("val2"),
+		"oversized2": overSizedDataPrefix,
+		"lost":       overSizedDataPrefix, // the S3 object is gone
+	}
+	sweeper, fdb := newTestOversizedSweeper(t, GetTestDynamoConfig(), items)
+	fdb.items["oversized1"] = []byte("large1")
+	fdb.items["oversized2"] = []byte("large2")
+
+	stats, err := sweeper.sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, sweepStats{Checked: 3, Missing: 1}, stats)
+	assert.Equal(t, int64(3), sweeper.checkedMeter.Count())
+	assert.Equal(t, int64(1), sweeper.missingMeter.Count())
+	assert.Equal(t, int64(0), sweeper.repairedMeter.Count())
+	assert.Contains(t, sweeper.dynamo.logger.(*testLogger).messages(),
+		"ERROR: The S3 object of an oversized item is missing [key 0x6c6f7374]")
+	assert.NotContains(t, fdb.items, "lost")
+}
+
+func TestOversizedSweeper_Repair(t *testing.T) {
+	items := map[string][]byte{
+		"lost":        overSizedDataPrefix,
+		"unavailable": overSizedDataPrefix, // the repair source doesn't have it either
+	}
+	config := GetTestDynamoConfig()
+	config.OversizedRepairSource = func(key []byte) ([]byte, error) {
+		if string(key) == "lost" {
+			return []byte("large"), nil
+		}
+		return nil, ErrKeyNotFound
+	}
+	sweeper, fdb := newTestOversizedSweeper(t, config, items)
+
+	stats, err := sweeper.sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, sweepStats{Checked: 2, Missing: 2, Repaired: 1}, stats)
+	assert.Equal(t, int64(2), sweeper.missingMeter.Count())
+	assert.Equal(t, int64(1), sweeper.repairedMeter.Count())
+	assert.Equal(t, []byte("large"), fdb.items["lost"])
+	assert.NotContains(t, fdb.items, "unavailable")
+
+	// the repaired object is found by the next sweep
+	stats, err = sweeper.sweep()
+	assert.NoError(t, err)
+	assert.Equal(t, sweepStats{Checked: 2, Missing: 1}, stats)
+}
+
+func TestOversizedSweeper_Segments(t *testing.T) {
+	var segments []int64
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		scan: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			assert.Equal(t, int64(3), *input.TotalSegments)
+			segments = append(segments, *input.Segment)
+			return &dynamodb.ScanOutput{}, nil
+		},
+	})()
+	config := GetTestDynamoConfig()
+	config.OversizedSweepInterval = time.Hour
+	config.OversizedSweepSegments = 3
+	config.OversizedSweepRate = 1000
+	sweeper := newOversizedSweeper(newStubDynamoDB(config))
+
+	// the segments are scanned in turn
+	for i := 0; i < 4; i++ {
+		_, err := sweeper.sweep()
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []int64{0, 1, 2, 0}, segments)
+
+	// the sweeps are disabled without the interval
+	config.OversizedSweepInterval = 0
+	assert.Nil(t, newOversizedSweeper(newStubDynamoDB(config)))
+}
+
+func TestOversizedSweeper_Stop(t *testing.T) {
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		scan: func(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+			return &dynamodb.ScanOutput{}, nil
+		},
+	})()
+	config := GetTestDynamoConfig()
+	config.OversizedSweepInterval = time.Millisecond
+	config.OversizedSweepSegments = 1
+	config.OversizedSweepRate = 0.001 // the second sweep waits for the limiter
+	sweeper := newOversizedSweeper(newStubDynamoDB(config))
+	sweeper.start()
+	time.Sleep(20 * time.Millisecond)
+
+	// stop returns without waiting for the limiter
+	done := make(chan struct{})
+	go func() {
+		sweeper.stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the sweeper is not stopped")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/log"
+	"github.com/rcrowley/go-metrics"
+)
+
+// errDynamoTableMissing is returned without contacting DynamoDB while the table is missing.
+var errDynamoTableMissing = errors.New("dynamoDB table is missing")
+
+// dynamoTableProperty is the property name used to query the table state via GetProperty.
+const dynamoTableProperty = "dynamodb.table"
+
+const defaultDynamoTableCheckInterval = 10 * time.Second
+
+// isTableNotFound returns true if the request failed because the table doesn't exist.
+func isTableNotFound(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException
+}
+
+// tableWatcher detects that the table is deleted while the node is running.
+// When a request fails with ResourceNotFoundException, the table is marked as
+// missing and every call is rejected with errDynamoTableMissing. The table is
+// checked every `interval` until it becomes active again, and then the calls
+// are resumed.
+//
+// A nil *tableWatcher is valid and never rejects a call.
+type tableWatcher struct {
+	interval time.Duration
+	check    func() bool // returns true if the table is active, which may recreate the table
+	logger   log.Logger
+
+	mu      sync.Mutex
+	missing bool
+	since   time.Time
+	closed  bool
+	closeCh chan struct{}
+
+	missingGauge metrics.Gauge
+}
+
+func newTableWatcher(interval time.Duration, check func() bool, logger log.Logger) *tableWatcher {
+	return &tableWatcher{
+		interval: interval,
+		check:    check,
+		logger:   logger,
+		closeCh:  make(chan struct{}),
+	}
+}
+
+// allow returns errDynamoTableMissing if the table is missing.
+func (w *tableWatcher) allow() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.missing {
+		return errDynamoTableMissing
+	}
+	return nil
+}
+
+// observe marks the table as missing if the error says so, and starts checking
+// the table. It returns true if the error is due to the missing table.
+func (w *tableWatcher) observe(err error) bool {
+	if w == nil || !isTableNotFound(err) {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.missing || w.closed {
+		return true
+	}
+	w.missing, w.since = true, time.Now()
+	w.updateGauge()
+	w.logger.Error("DynamoDB table is missing, the requests fail until it becomes active again",
+		"err", err, "checkInterval", w.interval)
+	go w.watch()
+	return true
+}
+
+// watch checks the table until it becomes active or the watcher is stopped.
+func (w *tableWatcher) watch() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.closeCh:
+			return
+		case <-ticker.C:
+			if !w.check() {
+				continue
+			}
+			w.mu.Lock()
+			w.missing = false
+			w.updateGauge()
+			w.logger.Info("DynamoDB table is active again, the requests are resumed",
+				"missingFor", time.Since(w.since))
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// state returns "missing" or "active", which is reported by GetProperty.
+func (w *tableWatcher) state() string {
+	if w.allow() != nil {
+		return "missing"
+	}
+	return "active"
+}
+
+func (w *tableWatcher) updateGauge() {
+	if w.missingGauge == nil {
+		return
+	}
+	if w.missing {
+		w.missingGauge.Update(1)
+	} else {
+		w.missingGauge.Update(0)
+	}
+}
+
+// stop stops checking the table.
+func (w *tableWatcher) stop() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.closeCh)
+	}
+}
+
+// checkTable returns true if the table is active. A missing table is
+// recreated if AutoCreateTable is set.
+func (dynamo *dynamoDB) checkTable() bool {
+	status, err := dynamo.tableStatus()
+	switch {
+	case err == nil:
+		return status == dynamodb.TableStatusActive
+	case !isTableNotFound(err):
+		dynamo.logger.Warn("unable to get DynamoDB table status", "err", err)
+	case dynamo.config.AutoCreateTable:
+		dynamo.logger.Warn("recreating the missing DynamoDB table")
+		dynamo.createTable()
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDeletableTableClient returns a memory client of a table which can be
+// deleted and created again.
+func newDeletableTableClient() (*stubDynamoDBClient, func(exists bool)) {
+	var (
+		mu     sync.Mutex
+		exists = true
+		items  = make(map[string]map[string]*dynamodb.AttributeValue)
+	)
+	notFound := awserr.New(dynamodb.ErrCodeResourceNotFoundException, "Requested resource not found", nil)
+	client := newMemoryDynamoDBClient(items)
+	putItem, getItem, describeTable := client.putItem, client.getItem, client.describeTable
+	client.putItem = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !exists {
+			return nil, notFound
+		}
+		return putItem(input)
+	}
+	client.getItem = func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !exists {
+			return nil, notFound
+		}
+		return getItem(input)
+	}
+	client.describeTable = func(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !exists {
+			return nil, notFound
+		}
+		return describeTable(input)
+	}
+	client.createTable = func(*dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		// the table is created again without the items
+		exists = true
+		for key := range items {
+			delete(items, key)
+		}
+		return &dynamodb.CreateTableOutput{}, nil
+	}
+	setExists := func(e bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		exists = e
+	}
+	return client, setExists
+}
+
+func newTableWatchedDynamoDB(config *DynamoDBConfig) *dynamoDB {
+	dynamo := newStubDynamoDB(config)
+	dynamo.table = newTableWatcher(10*time.Millisecond, dynamo.checkTable, dynamo.logger)
+	return dynamo
+}
+
+func TestIsTableNotFound(t *testing.T) {
+	assert.True(t, isTableNotFound(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)))
+	assert.False(t, isTableNotFound(awserr.New(dynamodb.ErrCodeInternalServerError, "internal error", nil)))
+	assert.False(t, isTableNotFound(errors.New("ResourceNotFoundException")))
+	assert.False(t, isTableNotFound(nil))
+}
+
+func TestDynamoDB_TableDeletedAtRuntime(t *testing.T) {
+	client, setExists := newDeletableTableClient()
+	defer setTestDynamoDBClient(client)()
+
+	dynamo := newTableWatchedDynamoDB(GetTestDynamoConfig())
+	defer dynamo.table.stop()
+
+	assert.NoError(t, dynamo.Put([]byte("key"), []byte("val")))
+	assert.Equal(t, "active", dynamo.GetProperty(dynamoTableProperty))
+
+	// the table is deleted by someone else
+	setExists(false)
+	err := dynamo.Put([]byte("key"), []byte("val"))
+	assert.True(t, isTableNotFound(err))
+	assert.Equal(t, "missing", dynamo.GetProperty(dynamoTableProperty))
+
+	// the requests fail fast while the table is missing
+	_, err = dynamo.Get([]byte("key"))
+	assert.ErrorIs(t, err, errDynamoTableMissing)
+	assert.ErrorIs(t, dynamo.Delete([]byte("key")), errDynamoTableMissing)
+
+	// the table is missing until it is created again
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "missing", dynamo.GetProperty(dynamoTableProperty))
+
+	setExists(true)
+	assert.Eventually(t, func() bool {
+		return dynamo.GetProperty(dynamoTableProperty) == "active"
+	}, time.Second, 10*time.Millisecond)
+	val, err := dynamo.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val"), val)
+
+	// the failures are not critical, and the recovery is reported
+	msgs := dynamo.logger.(*testLogger).messages()
+	for _, msg := range msgs {
+		assert.NotContains(t, msg, "CRIT")
+	}
+	assert.Contains(t, msgs[len(msgs)-1], "INFO: DynamoDB table is active again")
+}
+
+func TestDynamoDB_TableDeletedAtRuntime_AutoCreate(t *testing.T) {
+	client, setExists := newDeletableTableClient()
+	defer setTestDynamoDBClient(client)()
+
+	config := GetTestDynamoConfig()
+	config.AutoCreateTable = true
+	dynamo := newTableWatchedDynamoDB(config)
+	defer dynamo.table.stop()
+
+	setExists(false)
+	assert.True(t, isTableNotFound(dynamo.Put([]byte("key"), []byte("val"))))
+
+	// the table is recreated by the check, and the requests are resumed
+	assert.Eventually(t, func() bool {
+		return dynamo.GetProperty(dynamoTableProperty) == "active"
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, dynamo.Put([]byte("key"), []byte("val")))
+	val, err := dynamo.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val"), val)
+}
+
+func TestTableWatcher_Stop(t *testing.T) {
+	checked := make(chan struct{}, 1)
+	w := newTableWatcher(time.Millisecond, func() bool {
+		select {
+		case checked <- struct{}{}:
+		default:
+		}
+		return false
+	}, &testLogger{Logger: logger})
+
+	assert.True(t, w.observe(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)))
+	<-checked
+	w.stop()
+	w.stop()
+
+	// a stopped watcher doesn't start checking again
+	assert.True(t, w.observe(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil)))
+	assert.Equal(t, "missing", w.state())
+}
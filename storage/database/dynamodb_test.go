@@ -20,6 +20,9 @@
 package database
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"net"
 	"strconv"
 	"strings"
@@ -27,10 +30,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/log"
 	"github.com/klaytn/klaytn/storage"
+	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -312,3 +321,1013 @@ func TestDynamoDB_Retry(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, dynamoMaxRetry+1, requestCnt)
 }
+
+func TestDynamoDBConfig_validateAndSetDefaults(t *testing.T) {
+	var nilConfig *DynamoDBConfig
+	assert.Equal(t, nilDynamoConfigErr, nilConfig.validateAndSetDefaults())
+
+	testcases := []struct {
+		name   string
+		config DynamoDBConfig
+		errs   []string
+	}{
+		{
+			name:   "missing table name",
+			config: DynamoDBConfig{Region: "us-east-1"},
+			errs:   []string{noTableNameErr.Error()},
+		},
+		{
+			name:   "missing region",
+			config: DynamoDBConfig{TableName: "klaytn-test"},
+			errs:   []string{noRegionErr.Error()},
+		},
+		{
+			name:   "missing table name and region",
+			config: DynamoDBConfig{},
+			errs:   []string{noTableNameErr.Error(), noRegionErr.Error()},
+		},
+		{
+			name:   "negative capacity units",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", IsProvisioned: true, ReadCapacityUnits: -1, WriteCapacityUnits: -1},
+			errs:   []string{"read capacity units must be positive", "write capacity units must be positive"},
+		},
+		{
+			name:   "zero capacity units in the provisioned mode",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", IsProvisioned: true},
+			errs:   []string{"read capacity units must be positive", "write capacity units must be positive"},
+		},
+		{
+			name:   "invalid batch write backoff",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", BatchWriteBackoff: time.Second, BatchWriteMaxBackoff: time.Millisecond, BatchWriteMaxRetries: -1},
+			errs:   []string{"batch write max backoff 1ms must not be less than the backoff 1s", "batch write max retries must not be negative"},
+		},
+		{
+			name:   "negative worker number",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", WorkerNum: -1},
+			errs:   []string{"dynamoDB worker number must be positive: -1"},
+		},
+		{
+			name:   "invalid S3 server-side encryption",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3ServerSideEncryption: "aws:kms:dsse"},
+			errs:   []string{`S3 server-side encryption must be "AES256" or "aws:kms": "aws:kms:dsse"`},
+		},
+		{
+			name:   "S3 KMS key without KMS encryption",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3ServerSideEncryption: "AES256", S3SSEKMSKeyID: "test-key"},
+			errs:   []string{`S3 KMS key ID requires the server-side encryption "aws:kms"`},
+		},
+		{
+			name:   "negative S3 cache size",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3CacheSize: -1},
+			errs:   []string{"S3 cache size must not be negative: -1"},
+		},
+		{
+			name:   "shared S3 bucket without key prefix",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3Bucket: "klaytn-shared"},
+			errs:   []string{`S3 key prefix is required for the bucket "klaytn-shared" of the table "klaytn-test"`},
+		},
+		{
+			name:   "TTL duration without attribute",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", TTLDuration: time.Hour},
+			errs:   []string{"TTL duration requires the TTL attribute name"},
+		},
+		{
+			name:   "TTL attribute without duration",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", TTLAttributeName: "ExpiresAt"},
+			errs:   []string{"TTL duration must be positive: 0s"},
+		},
+		{
+			name:   "TTL attribute of partition key",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", TTLAttributeName: "Key", TTLDuration: time.Hour},
+			errs:   []string{"TTL attribute must not be the partition key"},
+		},
+		{
+			name:   "negative compression threshold",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3CompressionThreshold: -1},
+			errs:   []string{"S3 compression threshold must not be negative"},
+		},
+		{
+			name:   "negative S3 max retries",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", S3ReadMaxRetries: -1},
+			errs:   []string{"S3 max retries must not be negative"},
+		},
+		{
+			name:   "negative BatchGetItem max retries",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", BatchGetMaxRetries: -1},
+			errs:   []string{"BatchGetItem max retries must not be negative"},
+		},
+		{
+			name:   "unknown AWS log level",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", AWSLogLevel: "verbose"},
+			errs:   []string{`unknown AWS log level "verbose"`},
+		},
+		{
+			name:   "negative table check interval",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", TableCheckInterval: -time.Second},
+			errs:   []string{"table check interval must be positive"},
+		},
+		{
+			name:   "negative batch write retry limit",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", BatchWriteRetryLimit: -time.Second},
+			errs:   []string{"batch write retry limit must be positive"},
+		},
+		{
+			name:   "negative batch write retry rate",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", BatchWriteRetryRate: -1},
+			errs:   []string{"batch write retry rate must be positive"},
+		},
+		{
+			name:   "negative max buffered bytes",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", MaxBufferedBytes: -1},
+			errs:   []string{"max buffered bytes must not be negative"},
+		},
+		{
+			name:   "negative scan read-ahead",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", ScanReadAhead: -1},
+			errs:   []string{"scan read-ahead must not be negative"},
+		},
+		{
+			name:   "negative iterator max items",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", IteratorMaxItems: -1},
+			errs:   []string{"iterator max items must be positive"},
+		},
+		{
+			name:   "negative bulk load settings",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", BulkLoadChunkSize: -1, BulkLoadConcurrency: -1},
+			errs:   []string{"bulk load chunk size must be positive", "bulk load concurrency must be positive"},
+		},
+		{
+			name:   "negative oversized sweep settings",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", OversizedSweepInterval: -1, OversizedSweepSegments: -1, OversizedSweepRate: -1},
+			errs:   []string{"oversized sweep interval must not be negative", "oversized sweep segments must be positive", "oversized sweep rate must be positive"},
+		},
+		{
+			name:   "unknown value compression",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", ValueCompression: "lz4"},
+			errs:   []string{`unknown compression codec: "lz4"`},
+		},
+		{
+			name:   "invalid encryption key",
+			config: DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1", EncryptionKey: []byte("short")},
+			errs:   []string{"encryption key must be 16, 24 or 32 bytes"},
+		},
+	}
+
+	for _, tc := range testcases {
+		err := tc.config.validateAndSetDefaults()
+		if !assert.Error(t, err, tc.name) {
+			continue
+		}
+		for _, msg := range tc.errs {
+			assert.Contains(t, err.Error(), msg, tc.name)
+		}
+	}
+
+	// happy path with defaults applied
+	config := &DynamoDBConfig{TableName: "klaytn-test", Region: "us-east-1"}
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, "https://dynamodb.us-east-1.amazonaws.com", config.Endpoint)
+	assert.Equal(t, int64(defaultDynamoReadCapacityUnits), config.ReadCapacityUnits)
+	assert.Equal(t, int64(defaultDynamoWriteCapacityUnits), config.WriteCapacityUnits)
+	assert.Equal(t, WorkerNum, config.WorkerNum)
+
+	// given values are kept
+	config = GetTestDynamoConfig()
+	config.IsProvisioned = true
+	config.ReadCapacityUnits, config.WriteCapacityUnits = 5, 7
+	assert.NoError(t, config.validateAndSetDefaults())
+	assert.Equal(t, "http://localhost:4566", config.Endpoint)
+	assert.Equal(t, int64(5), config.ReadCapacityUnits)
+	assert.Equal(t, int64(7), config.WriteCapacityUnits)
+
+	// the capacity units are not validated on demand
+	config = GetTestDynamoConfig()
+	config.ReadCapacityUnits, config.WriteCapacityUnits = -1, -1
+	assert.NoError(t, config.validateAndSetDefaults())
+
+	// a bucket named after the table or shared with a prefix is allowed
+	config = GetTestDynamoConfig()
+	config.S3Bucket = config.TableName
+	assert.NoError(t, config.validateAndSetDefaults())
+	config.S3Bucket, config.S3KeyPrefix = "klaytn-shared", "klaytn/"
+	assert.NoError(t, config.validateAndSetDefaults())
+}
+
+func TestDynamoBatch_CoalesceDuplicatedKeys(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	batch := dynamo.NewBatch()
+
+	key := []byte("key")
+	assert.NoError(t, batch.Put(key, []byte("old value")))
+	assert.NoError(t, batch.Put(key, []byte("new")))
+	assert.NoError(t, batch.Put([]byte("other"), []byte("val")))
+	assert.Equal(t, 2*dynamoItemOverhead+len("Key"+"key"+"Val"+"new")+len("Key"+"other"+"Val"+"val"), batch.ValueSize())
+
+	go func() { assert.NoError(t, batch.Write()) }()
+	input := <-writeCh
+	input.wg.Done()
+
+	// only one write request with the latest value is emitted for the same key
+	assert.Len(t, input.items, 2)
+	assert.Equal(t, key, input.items[0].PutRequest.Item["Key"].B)
+	assert.Equal(t, []byte("new"), input.items[0].PutRequest.Item["Val"].B)
+	assert.Equal(t, []byte("val"), input.items[1].PutRequest.Item["Val"].B)
+}
+
+func TestDynamoBatch_CoalesceOversizedKeys(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	oldVal := make([]byte, dynamoWriteSizeLimit+1)
+	newVal := make([]byte, dynamoWriteSizeLimit+2)
+
+	fdb := newStubFileDB()
+	// delay the first write so that the second one would finish first without ordering
+	fdb.delay = func(val []byte) {
+		if len(val) == len(oldVal) {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = fdb
+	batch := dynamo.NewBatch()
+
+	key := []byte("key")
+	assert.NoError(t, batch.Put(key, oldVal))
+	assert.NoError(t, batch.Put(key, newVal))
+
+	go func() {
+		input := <-writeCh
+		assert.Len(t, input.items, 1)
+		assert.Equal(t, overSizedDataPrefix, input.items[0].PutRequest.Item["Val"].B)
+		input.wg.Done()
+	}()
+	assert.NoError(t, batch.Write())
+
+	// the fileDB writes of the same key are not reordered
+	assert.Equal(t, [][]byte{oldVal, newVal}, fdb.written)
+	val, err := fdb.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, newVal, val)
+}
+
+func TestDynamoBatch_MixedPutDelete(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	batch := dynamo.NewBatch()
+
+	// puts and deletes are dispatched in the same batch write request
+	for i := 0; i < dynamoBatchSize+5; i++ {
+		key := []byte(strconv.Itoa(i))
+		if i%2 == 0 {
+			assert.NoError(t, batch.Put(key, []byte("val")))
+		} else {
+			assert.NoError(t, batch.Delete(key))
+		}
+	}
+	input := <-writeCh
+	input.wg.Done()
+	if assert.Len(t, input.items, dynamoBatchSize) {
+		for i, item := range input.items {
+			key := []byte(strconv.Itoa(i))
+			if i%2 == 0 {
+				assert.Nil(t, item.DeleteRequest)
+				assert.Equal(t, key, item.PutRequest.Item["Key"].B)
+			} else {
+				assert.Nil(t, item.PutRequest)
+				assert.Equal(t, key, item.DeleteRequest.Key["Key"].B)
+			}
+		}
+	}
+
+	// a delete replaces the put of the same key and vice versa
+	assert.NoError(t, batch.Put([]byte("deleted"), []byte("val")))
+	assert.NoError(t, batch.Delete([]byte("deleted")))
+	assert.NoError(t, batch.Delete([]byte("put")))
+	assert.NoError(t, batch.Put([]byte("put"), []byte("val")))
+
+	go func() { assert.NoError(t, batch.Write()) }()
+	input = <-writeCh
+	input.wg.Done()
+	if assert.Len(t, input.items, 7) {
+		assert.Equal(t, []byte("deleted"), input.items[5].DeleteRequest.Key["Key"].B)
+		assert.Nil(t, input.items[5].PutRequest)
+		assert.Equal(t, []byte("put"), input.items[6].PutRequest.Item["Key"].B)
+		assert.Nil(t, input.items[6].DeleteRequest)
+	}
+}
+
+func TestDynamoBatch_DeleteOversizedKey(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	val := make([]byte, dynamoWriteSizeLimit+1)
+
+	fdb := newStubFileDB()
+	// delay the write so that the delete would finish first without ordering
+	fdb.delay = func([]byte) { time.Sleep(100 * time.Millisecond) }
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = fdb
+	batch := dynamo.NewBatch()
+
+	key := []byte("key")
+	assert.NoError(t, batch.Put(key, val))
+	assert.NoError(t, batch.Delete(key))
+	assert.NoError(t, batch.Put([]byte("small"), []byte("val")))
+	assert.NoError(t, batch.Delete([]byte("small")))
+	assert.Equal(t, 2*dynamoItemOverhead+len("Key"+"key")+len("Key"+"small"), batch.ValueSize())
+
+	go func() {
+		input := <-writeCh
+		if assert.Len(t, input.items, 2) {
+			assert.Equal(t, key, input.items[0].DeleteRequest.Key["Key"].B)
+			assert.Equal(t, []byte("small"), input.items[1].DeleteRequest.Key["Key"].B)
+		}
+		input.wg.Done()
+	}()
+	assert.NoError(t, batch.Write())
+
+	// the fileDB item is deleted after it is written
+	assert.Equal(t, [][]byte{val}, fdb.written)
+	_, err := fdb.read(key)
+	assert.Equal(t, dataNotFoundErr, err)
+}
+
+func TestDynamoBatch_DeleteExistingOversizedKey(t *testing.T) {
+	items := map[string]map[string]*dynamodb.AttributeValue{}
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	fdb := newStubFileDB()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = fdb
+
+	// the oversized item is written by another batch
+	oversized, small := []byte("oversized"), []byte("small")
+	batch := dynamo.NewBatch()
+	assert.NoError(t, batch.Put(oversized, make([]byte, dynamoWriteSizeLimit+1)))
+	assert.NoError(t, batch.Put(small, []byte("val")))
+	assert.NoError(t, batch.Write())
+	assert.Len(t, fdb.items, 1)
+
+	batch = dynamo.NewBatch()
+	assert.NoError(t, batch.Delete(oversized))
+	assert.NoError(t, batch.Delete(small))
+	assert.NoError(t, batch.Delete([]byte("missing")))
+	assert.NoError(t, batch.Write())
+
+	// the fileDB item is deleted along with the item
+	assert.Empty(t, items)
+	_, err := fdb.read(oversized)
+	assert.Equal(t, dataNotFoundErr, err)
+}
+
+func TestDynamoBatch_NewBatchWithSize(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	batch := dynamo.NewBatchWithSize(10).(*dynamoBatch)
+	assert.Equal(t, 10, cap(batch.batchItems))
+
+	// the capacity is limited by dynamoBatchSize
+	batch = dynamo.NewBatchWithSize(10 * dynamoBatchSize).(*dynamoBatch)
+	assert.Equal(t, dynamoBatchSize, cap(batch.batchItems))
+
+	// the capacity is kept after items are dispatched
+	for i := 0; i < dynamoBatchSize; i++ {
+		assert.NoError(t, batch.Put([]byte(strconv.Itoa(i)), []byte("val")))
+	}
+	input := <-writeCh
+	assert.Len(t, input.items, dynamoBatchSize)
+	input.wg.Done()
+	assert.Equal(t, dynamoBatchSize, cap(batch.batchItems))
+	assert.Len(t, batch.batchItems, 0)
+}
+
+func BenchmarkDynamoBatch_NewBatchWithSize(b *testing.B) {
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	const numItems = 10000
+	keys := make([][]byte, numItems)
+	for i := range keys {
+		keys[i] = common.MakeRandomBytes(32)
+	}
+	val := make([]byte, 100)
+
+	benchmark := func(newBatch func() Batch) func(b *testing.B) {
+		return func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				batch := newBatch()
+				for _, key := range keys {
+					batch.Put(key, val)
+				}
+				batch.Write()
+				batch.Release()
+			}
+		}
+	}
+	b.Run("NewBatch", benchmark(dynamo.NewBatch))
+	b.Run("NewBatchWithSize", benchmark(func() Batch { return dynamo.NewBatchWithSize(numItems) }))
+}
+
+func TestDynamoBatch_WriteAsync(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+
+	batch := newStubDynamoDB(GetTestDynamoConfig()).NewBatch().(*dynamoBatch)
+
+	var (
+		mu      sync.Mutex
+		results []string
+		called  sync.WaitGroup
+	)
+	callback := func(name string) func(error) {
+		called.Add(1)
+		return func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, fmt.Sprintf("%s: %v", name, err))
+			called.Done()
+		}
+	}
+
+	assert.NoError(t, batch.Put([]byte("k1"), []byte("val")))
+	batch.WriteAsync(callback("first"))
+	batch.Reset()
+	assert.NoError(t, batch.Put([]byte("k2"), []byte("val")))
+	batch.WriteAsync(callback("second"))
+	batch.Reset()
+
+	first, second := <-writeCh, <-writeCh
+	assert.Equal(t, []byte("k1"), writeRequestKey(first.items[0]))
+	assert.Equal(t, []byte("k2"), writeRequestKey(second.items[0]))
+
+	// the second batch lands first, but its callback waits for the first one
+	second.wg.Done()
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	assert.Empty(t, results)
+	mu.Unlock()
+
+	first.result.fail(errors.New("invalid input"))
+	first.wg.Done()
+	called.Wait()
+	assert.Equal(t, []string{"first: invalid input", "second: <nil>"}, results)
+
+	// the synchronous write is not affected by the error of the previous writes
+	assert.NoError(t, batch.Put([]byte("k3"), []byte("val")))
+	go func() {
+		input := <-writeCh
+		input.wg.Done()
+	}()
+	assert.NoError(t, batch.Write())
+}
+
+func TestDynamoBatch_WriteValidationError(t *testing.T) {
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			return &dynamodb.BatchWriteItemOutput{}, errors.New("ValidationException: Provided list of item keys contains duplicates")
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	batch := newStubDynamoDB(GetTestDynamoConfig()).NewBatch()
+	assert.NoError(t, batch.Put([]byte("key"), []byte("val")))
+
+	// the invalid batch is not retried, and its error is returned
+	err := batch.Write()
+	assert.ErrorContains(t, err, "ValidationException")
+
+	var asyncErr error
+	done := make(chan struct{})
+	WriteBatchAsync(batch, func(err error) {
+		asyncErr = err
+		close(done)
+	})
+	<-done
+	assert.ErrorContains(t, asyncErr, "ValidationException")
+}
+
+func TestCreateBatchWriteWorkerPool_WorkerNum(t *testing.T) {
+	defer func(ch chan *batchWriteWorkerInput, budget *retryBudget) {
+		dynamoWriteCh, dynamoRetryBudget = ch, budget
+	}(dynamoWriteCh, dynamoRetryBudget)
+
+	const workerNum = 3
+	entered, release := make(chan struct{}, workerNum+1), make(chan struct{})
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			entered <- struct{}{}
+			<-release
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+
+	createBatchWriteWorkerPool(workerNum, defaultDynamoBatchWriteRetryRate, 0)
+	assert.Equal(t, 2*workerNum, cap(dynamoWriteCh))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(workerNum + 1)
+	for i := 0; i <= workerNum; i++ {
+		items := []*dynamodb.WriteRequest{newTestWriteRequest(fmt.Sprintf("key%d", i))}
+		dynamoWriteCh <- &batchWriteWorkerInput{"table", items, wg, nil, nil, nil, nil, nil, 0, nil}
+	}
+
+	// only workerNum batches are written at once
+	for i := 0; i < workerNum; i++ {
+		<-entered
+	}
+	select {
+	case <-entered:
+		t.Fatal("more batches are written than the workers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	wg.Wait()
+	close(dynamoWriteCh)
+}
+
+func TestDynamoBatch_WriteMeters(t *testing.T) {
+	defer func(meters []metrics.Meter) {
+		dynamoBatchWriteTimeMeter, dynamoBatchWriteCountMeter, dynamoBatchWriteBytesMeter = meters[0], meters[1], meters[2]
+		dynamoBatchWriteTimePerItemMeter, dynamoBatchWriteTimePerByteMeter = meters[3], meters[4]
+	}([]metrics.Meter{dynamoBatchWriteTimeMeter, dynamoBatchWriteCountMeter, dynamoBatchWriteBytesMeter,
+		dynamoBatchWriteTimePerItemMeter, dynamoBatchWriteTimePerByteMeter})
+	dynamoBatchWriteTimeMeter, dynamoBatchWriteCountMeter, dynamoBatchWriteBytesMeter = metrics.NewMeter(), metrics.NewMeter(), metrics.NewMeter()
+	dynamoBatchWriteTimePerItemMeter, dynamoBatchWriteTimePerByteMeter = metrics.NewMeter(), metrics.NewMeter()
+
+	// the first request leaves an item unprocessed, which is retried
+	var written []*dynamodb.WriteRequest
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			for tableName, items := range input.RequestItems {
+				if len(written) == 0 && len(items) > 1 {
+					written = append(written, items[1:]...)
+					return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{tableName: items[:1]}}, nil
+				}
+				written = append(written, items...)
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	batch := newStubDynamoDB(GetTestDynamoConfig()).NewBatch()
+	const numItems = 5
+	for i := 0; i < numItems; i++ {
+		assert.NoError(t, batch.Put([]byte(fmt.Sprintf("key%d", i)), []byte("val")))
+	}
+	assert.NoError(t, batch.Write())
+
+	assert.Len(t, written, numItems)
+	size := 0
+	for _, req := range written {
+		size += requestSize(req, defaultDynamoItemSize)
+	}
+	// the retried item is counted once
+	assert.Equal(t, int64(numItems), dynamoBatchWriteCountMeter.Count())
+	assert.Equal(t, int64(size), dynamoBatchWriteBytesMeter.Count())
+	assert.Positive(t, dynamoBatchWriteTimeMeter.Count())
+	assert.Equal(t, dynamoBatchWriteTimeMeter.Count()/numItems, dynamoBatchWriteTimePerItemMeter.Count())
+	assert.Equal(t, dynamoBatchWriteTimeMeter.Count()/int64(size), dynamoBatchWriteTimePerByteMeter.Count())
+}
+
+// blockingCodec blocks Encode until unblock is closed, signaling entered.
+type blockingCodec struct {
+	dynamoDataCodec
+	entered, unblock chan struct{}
+}
+
+func (c blockingCodec) Encode(key, val []byte) (map[string]*dynamodb.AttributeValue, error) {
+	close(c.entered)
+	<-c.unblock
+	return c.dynamoDataCodec.Encode(key, val)
+}
+
+func TestDynamoBatch_Misuse(t *testing.T) {
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	go func() {
+		for input := range writeCh {
+			input.wg.Done()
+		}
+	}()
+	defer close(writeCh)
+
+	// the batch is modified after Write without Reset
+	batch := newStubDynamoDB(GetTestDynamoConfig()).NewBatch()
+	assert.NoError(t, batch.Put([]byte("key1"), []byte("val")))
+	assert.NoError(t, batch.Write())
+	assert.ErrorIs(t, batch.Put([]byte("key2"), []byte("val")), errBatchNotReset)
+	assert.ErrorIs(t, batch.Delete([]byte("key1")), errBatchNotReset)
+
+	// the batch can be reused after Reset
+	batch.Reset()
+	assert.NoError(t, batch.Put([]byte("key2"), []byte("val")))
+	WriteBatchAsync(batch, func(error) {})
+	assert.ErrorIs(t, batch.Put([]byte("key3"), []byte("val")), errBatchNotReset)
+	batch.Reset()
+
+	// the batch is used by another goroutine during Put
+	config := GetTestDynamoConfig()
+	codec := blockingCodec{entered: make(chan struct{}), unblock: make(chan struct{})}
+	config.ItemCodec = codec
+	batch = newStubDynamoDB(config).NewBatch()
+
+	putErr := make(chan error)
+	go func() { putErr <- batch.Put([]byte("key1"), []byte("val")) }()
+	<-codec.entered
+	assert.ErrorIs(t, batch.Put([]byte("key2"), []byte("val")), errConcurrentBatchUse)
+	assert.ErrorIs(t, batch.Write(), errConcurrentBatchUse)
+	assert.PanicsWithError(t, errConcurrentBatchUse.Error(), batch.Reset)
+	close(codec.unblock)
+	assert.NoError(t, <-putErr)
+	assert.NoError(t, batch.Write())
+}
+
+func TestDynamoDB_CloseReturnsWriteError(t *testing.T) {
+	var failing bool
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			if failing {
+				return &dynamodb.BatchWriteItemOutput{}, errors.New("ValidationException: invalid input")
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	// keep the shared write channel open on Close
+	defer func(n uint) { dynamoOpenedDBNum = n }(dynamoOpenedDBNum)
+	dynamoOpenedDBNum = 3
+
+	// the pending writes are flushed on Close
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	batch := dynamo.NewBatch()
+	assert.NoError(t, batch.Put([]byte("key"), []byte("val")))
+	WriteBatchAsync(batch, func(error) {})
+	assert.NoError(t, dynamo.Close())
+
+	// the error of a pending write is returned by Close even if nobody waits for it
+	failing = true
+	dynamo = newStubDynamoDB(GetTestDynamoConfig())
+	batch = dynamo.NewBatch()
+	assert.NoError(t, batch.Put([]byte("key"), []byte("val")))
+	WriteBatchAsync(batch, func(error) {})
+	assert.ErrorContains(t, dynamo.Close(), "ValidationException")
+}
+
+func TestDynamoDB_CloseFlushesPendingWrites(t *testing.T) {
+	client := newMemoryDynamoDBClient(map[string]map[string]*dynamodb.AttributeValue{})
+	batchWriteItem := client.batchWriteItem
+	client.batchWriteItem = func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		time.Sleep(20 * time.Millisecond)
+		return batchWriteItem(input)
+	}
+	defer setTestDynamoDBClient(client)()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	// keep the shared write channel open on Close
+	defer func(n uint) { dynamoOpenedDBNum = n }(dynamoOpenedDBNum)
+	dynamoOpenedDBNum = 3
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	var keys [][]byte
+	for i := 0; i < 3; i++ {
+		batch := dynamo.NewBatch()
+		for j := 0; j < 2*dynamoBatchSize; j++ {
+			key := []byte(fmt.Sprintf("key-%d-%d", i, j))
+			keys = append(keys, key)
+			assert.NoError(t, batch.Put(key, key))
+		}
+		WriteBatchAsync(batch, func(error) {})
+	}
+	pending := dynamo.NewBatch()
+	assert.NoError(t, pending.Put([]byte("pending"), []byte("val")))
+
+	assert.NoError(t, dynamo.Close())
+	for _, key := range keys {
+		val, err := dynamo.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, key, val)
+	}
+
+	// the batches are not written after Close
+	assert.ErrorIs(t, pending.Write(), errDynamoClosed)
+	assert.ErrorIs(t, dynamo.NewBatch().Put([]byte("key"), []byte("val")), errDynamoClosed)
+	_, err := dynamo.Get([]byte("pending"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestDynamoDB_Flush(t *testing.T) {
+	client := newMemoryDynamoDBClient(map[string]map[string]*dynamodb.AttributeValue{})
+	batchWriteItem := client.batchWriteItem
+	client.batchWriteItem = func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		time.Sleep(20 * time.Millisecond)
+		return batchWriteItem(input)
+	}
+	defer setTestDynamoDBClient(client)()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	assert.NoError(t, dynamo.Flush())
+
+	// the pending writes are written on Flush, and the writes go on after it
+	for i := 0; i < 2; i++ {
+		batch := dynamo.NewBatch()
+		key := []byte(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, batch.Put(key, key))
+		WriteBatchAsync(batch, func(error) {})
+		assert.NoError(t, Flush(dynamo))
+
+		val, err := dynamo.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, key, val)
+	}
+}
+
+func TestDynamoDB_GetWithConsistency(t *testing.T) {
+	var consistentRead *bool
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			consistentRead = input.ConsistentRead
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": {B: input.Key["Key"].B},
+				"Val": {B: []byte("val")},
+			}}, nil
+		},
+	})()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	key := []byte("key")
+
+	for _, strong := range []bool{true, false} {
+		val, err := dynamo.GetWithConsistency(key, strong)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("val"), val)
+		assert.Equal(t, aws.Bool(strong), consistentRead)
+
+		val, err = GetWithConsistency(dynamo, key, strong)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("val"), val)
+		assert.Equal(t, aws.Bool(strong), consistentRead)
+	}
+
+	// Get uses a strongly consistent read
+	_, err := dynamo.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, aws.Bool(true), consistentRead)
+}
+
+func TestDynamoDB_GetWithMeta(t *testing.T) {
+	var consistentRead *bool
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			consistentRead = input.ConsistentRead
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": {B: input.Key["Key"].B},
+				"Val": {B: []byte("val")},
+			}}, nil
+		},
+	})()
+	key := []byte("key")
+
+	for _, eventual := range []bool{false, true} {
+		config := GetTestDynamoConfig()
+		config.EventuallyConsistentReads = eventual
+		dynamo := newStubDynamoDB(config)
+
+		// the wrappers report the meta of the underlying database
+		for _, db := range []Database{dynamo, NewCoalescingDatabase(dynamo)} {
+			val, meta, err := GetWithMeta(db, key)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("val"), val)
+			assert.Equal(t, !eventual, meta.Consistent, "eventual %v", eventual)
+			assert.Equal(t, aws.Bool(!eventual), consistentRead)
+		}
+
+		// Get reads with the configured consistency
+		_, err := dynamo.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, aws.Bool(!eventual), consistentRead)
+	}
+}
+
+func TestDynamoDB_KeyTooLong(t *testing.T) {
+	requests := 0
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			requests++
+			return &dynamodb.GetItemOutput{}, nil
+		},
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			requests++
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		deleteItem: func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+			requests++
+			return &dynamodb.DeleteItemOutput{}, nil
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	longKey := bytes.Repeat([]byte{1}, dynamoMaxKeyLength+1)
+
+	assertKeyTooLong := func(err error) {
+		assert.ErrorIs(t, err, errKeyTooLong)
+		assert.Contains(t, err.Error(), strconv.Itoa(len(longKey)))
+	}
+	assertKeyTooLong(dynamo.Put(longKey, []byte("val")))
+	_, err := dynamo.Get(longKey)
+	assertKeyTooLong(err)
+	_, err = dynamo.Has(longKey)
+	assertKeyTooLong(err)
+	assertKeyTooLong(dynamo.Delete(longKey))
+
+	batch := dynamo.NewBatch()
+	assertKeyTooLong(batch.Put(longKey, []byte("val")))
+	assertKeyTooLong(batch.Delete(longKey))
+	assert.Equal(t, 0, batch.ValueSize())
+
+	// the requests are not sent to DynamoDB
+	assert.Equal(t, 0, requests)
+
+	// a key of the maximum length is sent
+	maxKey := longKey[:dynamoMaxKeyLength]
+	assert.NoError(t, dynamo.Put(maxKey, []byte("val")))
+	_, err = dynamo.Get(maxKey)
+	assert.Equal(t, dataNotFoundErr, err)
+	assert.NoError(t, dynamo.Delete(maxKey))
+	assert.Equal(t, 3, requests)
+}
+
+func TestDynamoDB_Has(t *testing.T) {
+	failing := awserr.New(request.ErrCodeRequestError, "connection reset", nil)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			switch string(input.Key["Key"].B) {
+			case "present":
+				return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+					"Key": input.Key["Key"],
+					"Val": {B: []byte("val")},
+				}}, nil
+			case "failing":
+				return nil, failing
+			}
+			return &dynamodb.GetItemOutput{}, nil
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	has, err := dynamo.Has([]byte("present"))
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// a missing key is not an error
+	has, err = dynamo.Has([]byte("absent"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// the errors of DynamoDB are returned
+	has, err = dynamo.Has([]byte("failing"))
+	assert.ErrorIs(t, err, failing)
+	assert.False(t, has)
+}
+
+func TestDynamoDB_EmptyKey(t *testing.T) {
+	requests := 0
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			requests++
+			return &dynamodb.GetItemOutput{}, nil
+		},
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			requests++
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		deleteItem: func(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+			requests++
+			return &dynamodb.DeleteItemOutput{}, nil
+		},
+	})()
+
+	for _, key := range [][]byte{nil, {}} {
+		// lenient by default: the empty key is ignored
+		dynamo := newStubDynamoDB(GetTestDynamoConfig())
+		assert.NoError(t, dynamo.Put(key, []byte("val")))
+		_, err := dynamo.Get(key)
+		assert.Equal(t, dataNotFoundErr, err)
+		has, err := dynamo.Has(key)
+		assert.NoError(t, err)
+		assert.False(t, has)
+		assert.NoError(t, dynamo.Delete(key))
+
+		// strict: the empty key is rejected
+		config := GetTestDynamoConfig()
+		config.StrictEmptyKeys = true
+		dynamo = newStubDynamoDB(config)
+		assert.ErrorIs(t, dynamo.Put(key, []byte("val")), errEmptyKey)
+		_, err = dynamo.Get(key)
+		assert.ErrorIs(t, err, errEmptyKey)
+		has, err = dynamo.Has(key)
+		assert.ErrorIs(t, err, errEmptyKey)
+		assert.False(t, has)
+		assert.ErrorIs(t, dynamo.Delete(key), errEmptyKey)
+	}
+
+	// the requests are not sent to DynamoDB in either mode
+	assert.Equal(t, 0, requests)
+}
+
+// unmarshalItemValue is the generic path of reading the value of an item.
+func unmarshalItemValue(item map[string]*dynamodb.AttributeValue) ([]byte, error) {
+	var data DynamoData
+	err := dynamodbattribute.UnmarshalMap(item, &data)
+	return data.Val, err
+}
+
+func TestItemValue(t *testing.T) {
+	items := []map[string]*dynamodb.AttributeValue{
+		{"Key": {B: []byte("key")}, "Val": {B: []byte("val")}},
+		{"Key": {B: []byte("key")}, "Val": {B: []byte{}}},
+		{"Key": {B: []byte("key")}, "Val": {B: overSizedDataPrefix}},
+		{"Key": {B: []byte("key")}},                                                // missing
+		{"Key": {B: []byte("key")}, "Val": nil},                                    // missing
+		{"Key": {B: []byte("key")}, "Val": {NULL: aws.Bool(true)}},                 // null
+		{"Key": {B: []byte("key")}, "Val": {NULL: aws.Bool(true), B: []byte("x")}}, // null takes precedence
+		{"Key": {B: []byte("key")}, "Val": {BS: [][]byte{[]byte("val")}}},          // binary set
+		{"Key": {B: []byte("key")}, "Val": {S: aws.String("val")}},                 // malformed
+	}
+	// random values of various sizes
+	for i := 0; i < 1000; i++ {
+		items = append(items, map[string]*dynamodb.AttributeValue{
+			"Key": {B: common.MakeRandomBytes(32)},
+			"Val": {B: common.MakeRandomBytes(i)},
+		})
+	}
+
+	for i, item := range items {
+		expected, expectedErr := unmarshalItemValue(item)
+		val, err := itemValue(item)
+		assert.Equal(t, expectedErr, err, "item %d", i)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, expected == nil, val == nil, "item %d", i)
+		assert.Equal(t, expected, val, "item %d", i)
+	}
+}
+
+func BenchmarkItemValue(b *testing.B) {
+	item := map[string]*dynamodb.AttributeValue{
+		"Key": {B: common.MakeRandomBytes(32)},
+		"Val": {B: common.MakeRandomBytes(100)},
+	}
+	b.Run("UnmarshalMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			unmarshalItemValue(item)
+		}
+	})
+	b.Run("FastPath", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			itemValue(item)
+		}
+	})
+}
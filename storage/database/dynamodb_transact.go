@@ -0,0 +1,126 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// dynamoTransactMaxItems and dynamoTransactMaxSize are the limits of a
+// TransactWriteItems request: up to 25 items of up to 4MB in total.
+const (
+	dynamoTransactMaxItems = 25
+	dynamoTransactMaxSize  = 4 * 1024 * 1024
+)
+
+var (
+	errTransactTooManyItems = fmt.Errorf("a transaction can write up to %d items", dynamoTransactMaxItems)
+	errTransactTooLarge     = fmt.Errorf("a transaction can write up to %d bytes", dynamoTransactMaxSize)
+	errTransactOversized    = errors.New("an oversized value stored in S3 cannot be written by a transaction")
+)
+
+// TransactionCanceledError is returned by TransactWrite if DynamoDB cancels the
+// transaction, in which case none of the items are written. Reasons holds the
+// cancellation reason of each item in the same order as the items, such as
+// "ConditionalCheckFailed" for an item whose condition is not met, or "None"
+// for an item which did not cause the cancellation.
+type TransactionCanceledError struct {
+	Reasons []string
+	err     error
+}
+
+func (e *TransactionCanceledError) Error() string {
+	return fmt.Sprintf("dynamoDB transaction is cancelled: [%s]", strings.Join(e.Reasons, ", "))
+}
+
+func (e *TransactionCanceledError) Unwrap() error {
+	return e.err
+}
+
+// TransactWrite writes the items atomically by a TransactWriteItems request,
+// which can hold up to 25 items of up to 4MB in total. An item with IfAbsent is
+// written only if its key does not exist, otherwise the transaction is
+// cancelled with a TransactionCanceledError. The values exceeding the inline
+// limit of an item cannot be written by a transaction, since they are stored in
+// S3 separately.
+func (dynamo *dynamoDB) TransactWrite(items []KV) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if len(items) > dynamoTransactMaxItems {
+		return errTransactTooManyItems
+	}
+
+	tableName := aws.String(dynamo.config.TableName)
+	transactItems := make([]*dynamodb.TransactWriteItem, 0, len(items))
+	size := 0
+	for _, item := range items {
+		if err := checkKeyLength(item.Key, dynamoMaxKeyLength); err != nil {
+			return err
+		}
+		if len(item.Value) > dynamoWriteSizeLimit {
+			return fmt.Errorf("%w: key=%x, size=%d", errTransactOversized, item.Key, len(item.Value))
+		}
+		marshaledData, err := dynamo.encodeItem(item.Key, item.Value)
+		if err != nil {
+			return err
+		}
+		size += defaultDynamoItemSize(marshaledData)
+
+		put := &dynamodb.Put{TableName: tableName, Item: marshaledData}
+		if item.IfAbsent {
+			put.ConditionExpression = aws.String("attribute_not_exists(#k)")
+			put.ExpressionAttributeNames = map[string]*string{"#k": aws.String("Key")}
+		}
+		transactItems = append(transactItems, &dynamodb.TransactWriteItem{Put: put})
+	}
+	if size > dynamoTransactMaxSize {
+		return errTransactTooLarge
+	}
+
+	if err := dynamo.table.allow(); err != nil {
+		return err
+	}
+	if err := dynamo.breaker.allow(); err != nil {
+		return err
+	}
+	_, err := dynamoDBClient.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: transactItems})
+
+	// a cancelled transaction is neither a success nor a failure of DynamoDB
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		dynamo.breaker.release()
+		reasons := make([]string, len(canceled.CancellationReasons))
+		for i, reason := range canceled.CancellationReasons {
+			reasons[i] = aws.StringValue(reason.Code)
+		}
+		return &TransactionCanceledError{Reasons: reasons, err: err}
+	}
+	dynamo.breaker.done(err)
+	if err != nil {
+		if !dynamo.table.observe(err) {
+			dynamo.logFailure("failed to write items by a transaction", "err", err, "numItems", len(items))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamoDB_TransactWrite(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	// a successful transaction
+	assert.NoError(t, dynamo.TransactWrite([]KV{
+		{Key: []byte("checkpoint"), Value: []byte("1")},
+		{Key: []byte("governance"), Value: []byte("params"), IfAbsent: true},
+	}))
+	assert.Equal(t, map[string][]byte{"checkpoint": []byte("1"), "governance": []byte("params")}, memoryValues(items))
+
+	// a transaction cancelled by a conditional check writes nothing
+	err := dynamo.TransactWrite([]KV{
+		{Key: []byte("checkpoint"), Value: []byte("2")},
+		{Key: []byte("governance"), Value: []byte("other"), IfAbsent: true},
+	})
+	var canceled *TransactionCanceledError
+	if assert.ErrorAs(t, err, &canceled) {
+		assert.Equal(t, []string{"None", "ConditionalCheckFailed"}, canceled.Reasons)
+	}
+	assert.Equal(t, map[string][]byte{"checkpoint": []byte("1"), "governance": []byte("params")}, memoryValues(items))
+
+	// the cancellation is not a failure of DynamoDB
+	assert.NoError(t, dynamo.breaker.allow())
+}
+
+func TestDynamoDB_TransactWrite_Limits(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	// too many items
+	kvs := make([]KV, dynamoTransactMaxItems+1)
+	for i := range kvs {
+		kvs[i] = KV{Key: []byte{byte(i)}, Value: []byte("value")}
+	}
+	assert.Equal(t, errTransactTooManyItems, dynamo.TransactWrite(kvs))
+
+	// an oversized value
+	err := dynamo.TransactWrite([]KV{
+		{Key: []byte("key"), Value: []byte("value")},
+		{Key: []byte("oversized"), Value: bytes.Repeat([]byte{1}, dynamoWriteSizeLimit+1)},
+	})
+	assert.ErrorIs(t, err, errTransactOversized)
+
+	// too large in total
+	kvs = kvs[:11]
+	for i := range kvs {
+		kvs[i].Value = bytes.Repeat([]byte{1}, dynamoWriteSizeLimit)
+	}
+	assert.Equal(t, errTransactTooLarge, dynamo.TransactWrite(kvs))
+
+	// nothing is written by the refused transactions
+	assert.Empty(t, items)
+}
+
+func TestTransactWrite_Wrappers(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+
+	// the keys are namespaced and the values are encrypted
+	encrypted, err := NewEncryptedDatabase(NewCoalescingDatabase(dynamo), testEncryptionKey, [][]byte{[]byte("ns-")})
+	assert.NoError(t, err)
+	db := NewNamespacedDatabase(encrypted, []byte("ns-"))
+	assert.NoError(t, TransactWrite(db, []KV{{Key: []byte("key"), Value: []byte("value")}}))
+	if assert.Contains(t, items, "ns-key") {
+		assert.NotEqual(t, []byte("value"), items["ns-key"]["Val"].B)
+	}
+
+	// the databases without transactions
+	assert.Equal(t, errTransactionNotSupported, TransactWrite(NewMemDB(), []KV{{Key: []byte("key")}}))
+	assert.Equal(t, errTransactionNotSupported, TransactWrite(NewNamespacedDatabase(NewMemDB(), []byte("ns-")), []KV{{Key: []byte("key")}}))
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// encodeItem encodes the key-value pair by the item codec of the database. If
+// the TTL is enabled, the item expires TTLDuration after the write.
+func (dynamo *dynamoDB) encodeItem(key, val []byte) (map[string]*dynamodb.AttributeValue, error) {
+	item, err := dynamo.codec().Encode(key, val)
+	if err != nil || dynamo.config.TTLAttributeName == "" {
+		return item, err
+	}
+	expiry := time.Now().Add(dynamo.config.TTLDuration).Unix()
+	item[dynamo.config.TTLAttributeName] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expiry, 10))}
+	return item, nil
+}
+
+// enableTTL registers the TTL attribute of the table. It waits for the table to
+// be created first, since the TTL of a table being created cannot be updated.
+func (dynamo *dynamoDB) enableTTL() error {
+	tableName := aws.String(dynamo.config.TableName)
+	if err := dynamoDBClient.WaitUntilTableExists(&dynamodb.DescribeTableInput{TableName: tableName}); err != nil {
+		return fmt.Errorf("failed to wait for the table to be created: %w", err)
+	}
+	_, err := dynamoDBClient.UpdateTimeToLive(&dynamodb.UpdateTimeToLiveInput{
+		TableName: tableName,
+		TimeToLiveSpecification: &dynamodb.TimeToLiveSpecification{
+			AttributeName: aws.String(dynamo.config.TTLAttributeName),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable the TTL of the table: %w", err)
+	}
+	dynamo.logger.Info("enabled the TTL of the DynamoDB table", "attribute", dynamo.config.TTLAttributeName,
+		"duration", dynamo.config.TTLDuration)
+	return nil
+}
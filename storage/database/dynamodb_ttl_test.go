@@ -0,0 +1,146 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/klaytn/klaytn/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// assertExpiry checks that the item expires ttl after a write between start and now.
+func assertExpiry(t *testing.T, item map[string]*dynamodb.AttributeValue, attr string, ttl time.Duration, start time.Time) {
+	if !assert.NotNil(t, item[attr]) || !assert.NotNil(t, item[attr].N) {
+		return
+	}
+	expiry, err := strconv.ParseInt(*item[attr].N, 10, 64)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, expiry, start.Add(ttl).Unix())
+	assert.LessOrEqual(t, expiry, time.Now().Add(ttl).Unix())
+}
+
+func TestDynamoDB_CreateTable_TTL(t *testing.T) {
+	var calls []string
+	var spec *dynamodb.TimeToLiveSpecification
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		createTable: func(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+			calls = append(calls, "create")
+			return &dynamodb.CreateTableOutput{}, nil
+		},
+		waitUntil: func(exists bool, input *dynamodb.DescribeTableInput) error {
+			calls = append(calls, "wait")
+			return nil
+		},
+		updateTTL: func(input *dynamodb.UpdateTimeToLiveInput) (*dynamodb.UpdateTimeToLiveOutput, error) {
+			calls = append(calls, "ttl")
+			spec = input.TimeToLiveSpecification
+			return &dynamodb.UpdateTimeToLiveOutput{}, nil
+		},
+	})()
+
+	// the TTL is not registered by default
+	assert.NoError(t, newStubDynamoDB(GetTestDynamoConfig()).createTable())
+	assert.Equal(t, []string{"create"}, calls)
+
+	// the TTL is registered after the table is created
+	calls = nil
+	config := GetTestDynamoConfig()
+	config.TTLAttributeName, config.TTLDuration = "ExpiresAt", time.Hour
+	assert.NoError(t, newStubDynamoDB(config).createTable())
+	assert.Equal(t, []string{"create", "wait", "ttl"}, calls)
+	assert.Equal(t, "ExpiresAt", aws.StringValue(spec.AttributeName))
+	assert.True(t, aws.BoolValue(spec.Enabled))
+}
+
+func TestDynamoDB_TTL(t *testing.T) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	defer setTestDynamoDBClient(newMemoryDynamoDBClient(items))()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	config := GetTestDynamoConfig()
+	config.TTLAttributeName, config.TTLDuration = "ExpiresAt", 24*time.Hour
+	dynamo := newStubDynamoDB(config)
+
+	start := time.Now()
+	assert.NoError(t, dynamo.Put([]byte("put"), []byte("val")))
+	batch := dynamo.NewBatch()
+	assert.NoError(t, batch.Put([]byte("batch"), []byte("val")))
+	assert.NoError(t, batch.Write())
+
+	assertExpiry(t, items["put"], "ExpiresAt", 24*time.Hour, start)
+	assertExpiry(t, items["batch"], "ExpiresAt", 24*time.Hour, start)
+
+	// the expiry attribute doesn't change the value read
+	val, err := dynamo.Get([]byte("put"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("val"), val)
+
+	// the items have no expiry by default
+	dynamo = newStubDynamoDB(GetTestDynamoConfig())
+	assert.NoError(t, dynamo.Put([]byte("put"), []byte("val")))
+	assert.Len(t, items["put"], 2)
+	assert.NotContains(t, items["put"], "ExpiresAt")
+}
+
+func TestDynamoDB_TTL_Local(t *testing.T) {
+	storage.SkipLocalTest(t)
+
+	// to start test with DynamoDB singletons
+	oldClient, oldOnceWorker, oldWriteCh := dynamoDBClient, dynamoOnceWorker, dynamoWriteCh
+	dynamoDBClient, dynamoOnceWorker, dynamoWriteCh = nil, &sync.Once{}, nil
+	defer func() {
+		dynamoDBClient, dynamoOnceWorker, dynamoWriteCh = oldClient, oldOnceWorker, oldWriteCh
+	}()
+
+	config := GetTestDynamoConfig()
+	config.TTLAttributeName, config.TTLDuration = "ExpiresAt", time.Hour
+	start := time.Now()
+	dynamo, err := newDynamoDB(config)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		dynamo.Close()
+		dynamo.deleteTable()
+		dynamo.fdb.deleteBucket()
+	}()
+
+	output, err := dynamoDBClient.DescribeTimeToLive(&dynamodb.DescribeTimeToLiveInput{TableName: aws.String(config.TableName)})
+	assert.NoError(t, err)
+	assert.Equal(t, "ExpiresAt", aws.StringValue(output.TimeToLiveDescription.AttributeName))
+	assert.Contains(t, []string{dynamodb.TimeToLiveStatusEnabling, dynamodb.TimeToLiveStatusEnabled},
+		aws.StringValue(output.TimeToLiveDescription.TimeToLiveStatus))
+
+	key := []byte("ttl-key")
+	assert.NoError(t, dynamo.Put(key, []byte("val")))
+	result, err := dynamoDBClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(config.TableName),
+		Key:            map[string]*dynamodb.AttributeValue{"Key": {B: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	assert.NoError(t, err)
+	assertExpiry(t, result.Item, "ExpiresAt", time.Hour, start)
+}
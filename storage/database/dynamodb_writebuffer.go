@@ -0,0 +1,98 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"sync"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+var (
+	// dynamoWriteBuffer bounds the bytes of the items dispatched by the batches
+	// of all the databases but not written yet, which are held by the shared
+	// write channel and the batch write workers. The un-dispatched items of a
+	// batch are not counted, since they are bounded by dynamoBatchSize. It is
+	// created with the workers by the first database, and it is nil if
+	// MaxBufferedBytes is 0.
+	dynamoWriteBuffer *writeBuffer
+
+	dynamoBufferedBytesGauge metrics.Gauge = &metrics.NilGauge{}
+)
+
+// writeBuffer counts the buffered bytes, and makes the writers wait while the
+// buffered bytes exceed the limit.
+//
+// A nil *writeBuffer never waits.
+type writeBuffer struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int
+	size  int
+}
+
+func newWriteBuffer(limit int) *writeBuffer {
+	b := &writeBuffer{limit: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire adds n bytes to the buffer, waiting for the buffered bytes to be
+// released if the buffer is full. The bytes are added to an empty buffer even
+// if they exceed the limit, so that the items larger than the limit can be
+// written.
+func (b *writeBuffer) acquire(n int) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for !b.fits(n) {
+		b.cond.Wait()
+	}
+	b.add(n)
+}
+
+// release removes n bytes from the buffer, and wakes up the waiting writers.
+func (b *writeBuffer) release(n int) {
+	if b == nil || n == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.add(-n)
+	b.cond.Broadcast()
+}
+
+// buffered returns the buffered bytes.
+func (b *writeBuffer) buffered() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+func (b *writeBuffer) fits(n int) bool {
+	return b.size == 0 || b.size+n <= b.limit
+}
+
+func (b *writeBuffer) add(n int) {
+	b.size += n
+	dynamoBufferedBytesGauge.Update(int64(b.size))
+}
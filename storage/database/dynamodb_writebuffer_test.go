@@ -0,0 +1,108 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBuffer(t *testing.T) {
+	b := newWriteBuffer(100)
+	b.acquire(60)
+	b.acquire(40)
+	assert.Equal(t, 100, b.buffered())
+
+	acquired := make(chan struct{})
+	go func() {
+		b.acquire(50)
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	b.release(60)
+	<-acquired
+	assert.Equal(t, 90, b.buffered())
+
+	// an item larger than the limit is acquired by an empty buffer
+	b.release(90)
+	b.acquire(200)
+	b.release(200)
+	assert.Zero(t, b.buffered())
+
+	// a nil buffer never waits
+	var nilBuffer *writeBuffer
+	nilBuffer.acquire(1)
+	nilBuffer.release(1)
+}
+
+func TestDynamoBatch_MaxBufferedBytes(t *testing.T) {
+	unblock := make(chan struct{})
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		batchWriteItem: func(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+			<-unblock
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		},
+	})()
+	writeCh, restore := setTestDynamoWriteCh()
+	defer restore()
+	defer close(writeCh)
+	go createBatchWriteWorker(writeCh)
+
+	// each item is counted as 10 bytes, and 30 items can be buffered
+	defer func(b *writeBuffer) { dynamoWriteBuffer = b }(dynamoWriteBuffer)
+	dynamoWriteBuffer = newWriteBuffer(30 * 10)
+	config := GetTestDynamoConfig()
+	config.ItemSizer = func(map[string]*dynamodb.AttributeValue) int { return 10 }
+	batch := newStubDynamoDB(config).NewBatch()
+
+	// the first 25 items are dispatched to the worker, which is blocked, and
+	// the un-dispatched items are not buffered
+	for i := 0; i < 49; i++ {
+		assert.NoError(t, batch.Put([]byte(fmt.Sprintf("key%02d", i)), []byte("val")))
+	}
+	assert.Equal(t, 250, dynamoWriteBuffer.buffered())
+
+	// the buffer is full, so Put dispatching the next 25 items waits until the
+	// worker writes the items
+	put := make(chan error)
+	go func() { put <- batch.Put([]byte("key49"), []byte("val")) }()
+	select {
+	case <-put:
+		t.Fatal("Put is not blocked by the full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	assert.NoError(t, <-put)
+	assert.NoError(t, batch.Write())
+	assert.Zero(t, dynamoWriteBuffer.buffered())
+	batch.Reset()
+
+	// an abandoned batch holds no buffered bytes
+	assert.NoError(t, batch.Put([]byte("key"), []byte("val")))
+	assert.Zero(t, dynamoWriteBuffer.buffered())
+	batch.Reset()
+	assert.Zero(t, dynamoWriteBuffer.buffered())
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// writeCheckKey is the reserved key of the probe items written by checkWritable.
+// It is never used by the data of klaytn.
+var writeCheckKey = []byte("klaytn-dynamodb-write-check")
+
+// checkWritable checks if DynamoDB and S3 are writable by writing, reading back
+// and deleting a probe item in each of them. It is called on startup not to
+// discover the lack of write permissions only when the first block is persisted.
+func (dynamo *dynamoDB) checkWritable() error {
+	val := []byte("probe")
+	if err := dynamo.probeItemPut(val); err != nil {
+		return fmt.Errorf("failed to write a probe item to DynamoDB table %s: %w", dynamo.config.TableName, err)
+	}
+	ret, err := dynamo.probeItemGet()
+	if err != nil {
+		return fmt.Errorf("failed to read a probe item from DynamoDB table %s: %w", dynamo.config.TableName, err)
+	}
+	if !bytes.Equal(val, ret) {
+		return fmt.Errorf("a probe item read from DynamoDB table %s is different from the written one", dynamo.config.TableName)
+	}
+	if err := dynamo.probeItemDelete(); err != nil {
+		return fmt.Errorf("failed to delete a probe item from DynamoDB table %s: %w", dynamo.config.TableName, err)
+	}
+
+	// an oversized item is stored in S3
+	val = bytes.Repeat([]byte{0xff}, dynamoWriteSizeLimit+1)
+	if _, err := dynamo.fdb.write(item{key: writeCheckKey, val: val}); err != nil {
+		return fmt.Errorf("failed to write a probe item to S3: %w", err)
+	}
+	ret, err = dynamo.fdb.read(writeCheckKey)
+	if err != nil {
+		return fmt.Errorf("failed to read a probe item from S3: %w", err)
+	}
+	if !bytes.Equal(val, ret) {
+		return fmt.Errorf("a probe item read from S3 is different from the written one")
+	}
+	if err := dynamo.fdb.delete(writeCheckKey); err != nil {
+		return fmt.Errorf("failed to delete a probe item from S3: %w", err)
+	}
+
+	dynamo.logger.Info("checked DynamoDB and S3 are writable")
+	return nil
+}
+
+// probeItemPut writes the probe item to DynamoDB. The probe item is accessed
+// without put, get and delete of dynamoDB, which exit the process on failures
+// if the circuit breaker is disabled.
+func (dynamo *dynamoDB) probeItemPut(val []byte) error {
+	marshaledData, err := dynamo.encodeItem(writeCheckKey, val)
+	if err != nil {
+		return err
+	}
+	_, err = dynamoDBClient.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(dynamo.config.TableName),
+		Item:      marshaledData,
+	})
+	return err
+}
+
+func (dynamo *dynamoDB) probeItemGet() ([]byte, error) {
+	result, err := dynamoDBClient.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(dynamo.config.TableName),
+		Key:            map[string]*dynamodb.AttributeValue{"Key": {B: writeCheckKey}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, dataNotFoundErr
+	}
+	_, val, err := dynamo.codec().Decode(result.Item)
+	return val, err
+}
+
+func (dynamo *dynamoDB) probeItemDelete() error {
+	_, err := dynamoDBClient.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(dynamo.config.TableName),
+		Key:       map[string]*dynamodb.AttributeValue{"Key": {B: writeCheckKey}},
+	})
+	return err
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMapDynamoDBClient returns a memory DynamoDB client which stores items in
+// the returned map. Writes fail with putErr if it is set.
+func newMapDynamoDBClient(putErr error) (*stubDynamoDBClient, map[string]map[string]*dynamodb.AttributeValue) {
+	items := make(map[string]map[string]*dynamodb.AttributeValue)
+	client := newMemoryDynamoDBClient(items)
+	if putErr != nil {
+		client.putItem = func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			return nil, putErr
+		}
+	}
+	return client, items
+}
+
+func TestDynamoDB_CheckWritable(t *testing.T) {
+	client, items := newMapDynamoDBClient(nil)
+	defer setTestDynamoDBClient(client)()
+
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	fdb := newStubFileDB()
+	dynamo.fdb = fdb
+
+	assert.NoError(t, dynamo.checkWritable())
+
+	// the probe items are deleted
+	assert.Empty(t, items)
+	assert.Empty(t, fdb.items)
+	if assert.Len(t, fdb.written, 1) {
+		assert.Greater(t, len(fdb.written[0]), dynamoWriteSizeLimit)
+	}
+}
+
+func TestDynamoDB_CheckWritable_Denied(t *testing.T) {
+	denied := awserr.New("AccessDeniedException", "not authorized to perform dynamodb:PutItem", nil)
+
+	// DynamoDB is not writable
+	client, _ := newMapDynamoDBClient(denied)
+	restore := setTestDynamoDBClient(client)
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+	err := dynamo.checkWritable()
+	assert.ErrorIs(t, err, denied)
+	assert.Contains(t, err.Error(), "DynamoDB")
+	restore()
+
+	// S3 is not writable
+	client, _ = newMapDynamoDBClient(nil)
+	defer setTestDynamoDBClient(client)()
+	dynamo = newStubDynamoDB(GetTestDynamoConfig())
+	fdb := newStubFileDB()
+	fdb.err = errors.New("AccessDenied: Access Denied")
+	dynamo.fdb = fdb
+	err = dynamo.checkWritable()
+	assert.ErrorIs(t, err, fdb.err)
+	assert.Contains(t, err.Error(), "S3")
+
+	// failures are not critical
+	for _, msg := range dynamo.logger.(*testLogger).messages() {
+		assert.NotContains(t, msg, "CRIT")
+	}
+}
+
+func TestNewDynamoDB_WriteCheck(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	config := GetTestDynamoConfig()
+	server, _ := newFakeS3Server(config.TableName)
+	defer server.Close()
+	config.S3Endpoint = server.URL
+
+	// startup fails if DynamoDB is not writable
+	numPuts := 0
+	client, _ := newMapDynamoDBClient(awserr.New("AccessDeniedException", "not authorized", nil))
+	putItem := client.putItem
+	client.putItem = func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		numPuts++
+		return putItem(input)
+	}
+	defer setTestDynamoDBClient(client)()
+
+	_, err := newDynamoDB(config)
+	assert.Error(t, err)
+	assert.Equal(t, 1, numPuts)
+
+	// read-only DynamoDB is not checked
+	readOnlyConfig := *config
+	_, err = newDynamoDBReadOnly(&readOnlyConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numPuts)
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// encryptedValueMarker is prepended to the encrypted values with the version of
+// the format, which is followed by the nonce and the ciphertext.
+var encryptedValueMarker = []byte("\x00klay-enc")
+
+const encryptedValueVersion byte = 1
+
+var errDecryptValue = errors.New("failed to decrypt value")
+
+// encryptedDB encrypts the values of the keys with the given prefixes with
+// AES-GCM before they are written to the underlying database, which protects
+// sensitive values independently of the encryption of the storage. The values
+// of the other keys are stored and read as they are. A value of the prefixes
+// without the marker of the encrypted values is not read, so a plaintext value
+// written to the underlying database is not returned as a decrypted one. The
+// values already written are not read anymore if the prefixes are changed.
+type encryptedDB struct {
+	Database
+	aead     cipher.AEAD
+	prefixes [][]byte
+}
+
+// NewEncryptedDatabase returns a database which encrypts the values of the keys
+// with the given prefixes. The key should be 16, 24 or 32 bytes to select
+// AES-128, AES-192 or AES-256.
+func NewEncryptedDatabase(db Database, key []byte, prefixes [][]byte) (Database, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid encryption key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	encDB := &encryptedDB{Database: db, aead: aead}
+	for _, prefix := range prefixes {
+		encDB.prefixes = append(encDB.prefixes, append([]byte{}, prefix...))
+	}
+	return encDB, nil
+}
+
+// encrypted returns true if the value of the key should be encrypted.
+func (db *encryptedDB) encrypted(key []byte) bool {
+	for _, prefix := range db.prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// encrypt returns the value to be stored for the key. The key is authenticated
+// with the value, so an encrypted value cannot be moved to another key.
+func (db *encryptedDB) encrypt(key, value []byte) ([]byte, error) {
+	if !db.encrypted(key) {
+		return value, nil
+	}
+	header := len(encryptedValueMarker) + 1
+	enc := make([]byte, header+db.aead.NonceSize(), header+db.aead.NonceSize()+len(value)+db.aead.Overhead())
+	copy(enc, encryptedValueMarker)
+	enc[len(encryptedValueMarker)] = encryptedValueVersion
+	nonce := enc[header:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return db.aead.Seal(enc, nonce, value, key), nil
+}
+
+// decrypt returns the original value of a stored value. The values of the
+// keys with the prefixes must be encrypted.
+func (db *encryptedDB) decrypt(key, value []byte) ([]byte, error) {
+	if !db.encrypted(key) {
+		return value, nil
+	}
+	if !bytes.HasPrefix(value, encryptedValueMarker) {
+		return nil, fmt.Errorf("%w: value is not encrypted", errDecryptValue)
+	}
+	enc := value[len(encryptedValueMarker):]
+	if len(enc) < 1+db.aead.NonceSize() {
+		return nil, errDecryptValue
+	}
+	if enc[0] != encryptedValueVersion {
+		return nil, fmt.Errorf("%w: unknown version %d", errDecryptValue, enc[0])
+	}
+	nonce, ciphertext := enc[1:1+db.aead.NonceSize()], enc[1+db.aead.NonceSize():]
+	dec, err := db.aead.Open(nil, nonce, ciphertext, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errDecryptValue, err)
+	}
+	return dec, nil
+}
+
+func (db *encryptedDB) Put(key []byte, value []byte) error {
+	enc, err := db.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return db.Database.Put(key, enc)
+}
+
+func (db *encryptedDB) PutContext(ctx context.Context, key []byte, value []byte) error {
+	enc, err := db.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return PutContext(ctx, db.Database, key, enc)
+}
+
+func (db *encryptedDB) DeleteContext(ctx context.Context, key []byte) error {
+	return DeleteContext(ctx, db.Database, key)
+}
+
+func (db *encryptedDB) Get(key []byte) ([]byte, error) {
+	val, err := db.Database.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return db.decrypt(key, val)
+}
+
+// GetWithConsistency keeps the consistency of reads selectable if the
+// underlying database supports it.
+func (db *encryptedDB) GetWithConsistency(key []byte, strong bool) ([]byte, error) {
+	val, err := GetWithConsistency(db.Database, key, strong)
+	if err != nil {
+		return nil, err
+	}
+	return db.decrypt(key, val)
+}
+
+func (db *encryptedDB) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	val, err := GetContext(ctx, db.Database, key)
+	if err != nil {
+		return nil, err
+	}
+	return db.decrypt(key, val)
+}
+
+func (db *encryptedDB) GetWithMeta(key []byte) ([]byte, ReadMeta, error) {
+	val, meta, err := GetWithMeta(db.Database, key)
+	if err != nil {
+		return nil, meta, err
+	}
+	val, err = db.decrypt(key, val)
+	return val, meta, err
+}
+
+func (db *encryptedDB) TransactWrite(items []KV) error {
+	encrypted := make([]KV, len(items))
+	for i, item := range items {
+		enc, err := db.encrypt(item.Key, item.Value)
+		if err != nil {
+			return err
+		}
+		encrypted[i] = KV{Key: item.Key, Value: enc, IfAbsent: item.IfAbsent}
+	}
+	return TransactWrite(db.Database, encrypted)
+}
+
+func (db *encryptedDB) PutReturningOld(key []byte, value []byte) ([]byte, error) {
+	enc, err := db.encrypt(key, value)
+	if err != nil {
+		return nil, err
+	}
+	old, err := PutReturningOld(db.Database, key, enc)
+	if err != nil || old == nil {
+		return nil, err
+	}
+	return db.decrypt(key, old)
+}
+
+func (db *encryptedDB) Flush() error {
+	return Flush(db.Database)
+}
+
+func (db *encryptedDB) NewBatch() Batch {
+	return &encryptedBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *encryptedDB) NewBatchWithSize(n int) Batch {
+	return &encryptedBatch{Batch: db.Database.NewBatchWithSize(n), db: db}
+}
+
+func (db *encryptedDB) NewIterator(prefix []byte, start []byte) Iterator {
+	it := db.Database.NewIterator(prefix, start)
+	if it == nil {
+		return nil
+	}
+	return &encryptedIterator{Iterator: it, db: db}
+}
+
+func (db *encryptedDB) NewIteratorWithRange(start, end []byte) Iterator {
+	it := db.Database.NewIteratorWithRange(start, end)
+	if it == nil {
+		return nil
+	}
+	return &encryptedIterator{Iterator: it, db: db}
+}
+
+// encryptedBatch encrypts the values written like its database.
+type encryptedBatch struct {
+	Batch
+	db *encryptedDB
+}
+
+func (b *encryptedBatch) Put(key, value []byte) error {
+	enc, err := b.db.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return b.Batch.Put(key, enc)
+}
+
+// Replay replays the batch contents with the decrypted values.
+func (b *encryptedBatch) Replay(w KeyValueWriter) error {
+	return b.Batch.Replay(&valueDecrypter{w: w, db: b.db})
+}
+
+// valueDecrypter decrypts the values written to w.
+type valueDecrypter struct {
+	w  KeyValueWriter
+	db *encryptedDB
+}
+
+func (d *valueDecrypter) Put(key []byte, value []byte) error {
+	dec, err := d.db.decrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return d.w.Put(key, dec)
+}
+
+func (d *valueDecrypter) Delete(key []byte) error {
+	return d.w.Delete(key)
+}
+
+// encryptedIterator presents the decrypted values of the underlying iterator.
+// A value failed to be decrypted is presented as nil, and the failure is
+// returned by Error.
+type encryptedIterator struct {
+	Iterator
+	db  *encryptedDB
+	err error
+}
+
+func (it *encryptedIterator) Value() []byte {
+	val, err := it.db.decrypt(it.Iterator.Key(), it.Iterator.Value())
+	if err != nil {
+		if it.err == nil {
+			it.err = err
+		}
+		return nil
+	}
+	return val
+}
+
+func (it *encryptedIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newTestEncryptedDB(t *testing.T) (Database, *MemDB) {
+	memDB := NewMemDB()
+	db, err := NewEncryptedDatabase(memDB, testEncryptionKey, [][]byte{[]byte("secret-")})
+	assert.NoError(t, err)
+	return db, memDB
+}
+
+func TestEncryptedDB_RoundTrip(t *testing.T) {
+	db, memDB := newTestEncryptedDB(t)
+	secretKey, plainKey := []byte("secret-key"), []byte("plain-key")
+	val := []byte("sensitive value")
+
+	assert.NoError(t, db.Put(secretKey, val))
+	assert.NoError(t, db.Put(plainKey, val))
+
+	for _, key := range [][]byte{secretKey, plainKey} {
+		got, err := db.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, val, got)
+
+		got, err = GetWithConsistency(db, key, true)
+		assert.NoError(t, err)
+		assert.Equal(t, val, got)
+
+		got, _, err = GetWithMeta(db, key)
+		assert.NoError(t, err)
+		assert.Equal(t, val, got)
+	}
+
+	// only the value of the secret key is encrypted in the underlying database
+	stored, err := memDB.Get(secretKey)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(stored, encryptedValueMarker))
+	assert.False(t, bytes.Contains(stored, val))
+	stored, err = memDB.Get(plainKey)
+	assert.NoError(t, err)
+	assert.Equal(t, val, stored)
+
+	// the same value is encrypted differently with another nonce
+	assert.NoError(t, db.Put([]byte("secret-key2"), val))
+	stored2, err := memDB.Get([]byte("secret-key2"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, stored[len(encryptedValueMarker):], stored2[len(encryptedValueMarker):])
+
+	// an empty value is kept empty
+	assert.NoError(t, db.Put([]byte("secret-empty"), []byte{}))
+	got, err := db.Get([]byte("secret-empty"))
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestEncryptedDB_BatchAndIterator(t *testing.T) {
+	db, memDB := newTestEncryptedDB(t)
+	batch := db.NewBatch()
+	assert.NoError(t, batch.Put([]byte("secret-a"), []byte("a")))
+	assert.NoError(t, batch.Put([]byte("plain-b"), []byte("b")))
+
+	// the batch is replayed with the original values
+	replayed := NewMemDB()
+	assert.NoError(t, batch.Replay(replayed))
+	got, err := replayed.Get([]byte("secret-a"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("a"), got)
+
+	assert.NoError(t, batch.Write())
+	stored, err := memDB.Get([]byte("secret-a"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, []byte("a"), stored)
+
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	values := map[string]string{}
+	for it.Next() {
+		values[string(it.Key())] = string(it.Value())
+	}
+	assert.NoError(t, it.Error())
+	assert.Equal(t, map[string]string{"plain-b": "b", "secret-a": "a"}, values)
+}
+
+func TestEncryptedDB_Errors(t *testing.T) {
+	_, err := NewEncryptedDatabase(NewMemDB(), []byte("short"), nil)
+	assert.Error(t, err)
+
+	db, memDB := newTestEncryptedDB(t)
+	assert.NoError(t, db.Put([]byte("secret-key"), []byte("value")))
+
+	// a value encrypted for another key is not decrypted
+	stored, _ := memDB.Get([]byte("secret-key"))
+	assert.NoError(t, memDB.Put([]byte("secret-moved"), stored))
+	_, err = db.Get([]byte("secret-moved"))
+	assert.ErrorIs(t, err, errDecryptValue)
+
+	// a tampered value is not decrypted
+	tampered := append([]byte{}, stored...)
+	tampered[len(tampered)-1] ^= 0xff
+	assert.NoError(t, memDB.Put([]byte("secret-key"), tampered))
+	_, err = db.Get([]byte("secret-key"))
+	assert.ErrorIs(t, err, errDecryptValue)
+
+	// a plaintext value of the prefixes is not read
+	assert.NoError(t, memDB.Put([]byte("secret-plain"), []byte("value")))
+	_, err = db.Get([]byte("secret-plain"))
+	assert.ErrorIs(t, err, errDecryptValue)
+
+	// a value of another key is read as it is even if it looks encrypted
+	marked := append(append([]byte{}, encryptedValueMarker...), "value"...)
+	assert.NoError(t, db.Put([]byte("plain-marked"), marked))
+	got, err := db.Get([]byte("plain-marked"))
+	assert.NoError(t, err)
+	assert.Equal(t, marked, got)
+
+	// a value encrypted with another key is not decrypted
+	otherDB, err := NewEncryptedDatabase(memDB, []byte("fedcba9876543210"), [][]byte{[]byte("secret-")})
+	assert.NoError(t, err)
+	assert.NoError(t, otherDB.Put([]byte("secret-other"), []byte("value")))
+	_, err = db.Get([]byte("secret-other"))
+	assert.ErrorIs(t, err, errDecryptValue)
+}
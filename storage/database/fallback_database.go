@@ -0,0 +1,116 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+)
+
+// FallbackRoute is the ordered list of the databases read after the primary
+// database for the keys with Prefix. An empty prefix matches every key.
+type FallbackRoute struct {
+	Prefix    []byte
+	Databases []Database
+}
+
+// fallbackDB writes the keys to the primary database, and reads the keys
+// missing in the primary database from the fallback databases of the longest
+// route matching the key, in the order of the route. It lets the nodes read
+// the keys from the old databases while they are migrated to the primary one,
+// without stopping the node.
+//
+// A key deleted from the primary database is still read from the fallback
+// databases, and the iterators iterate only the primary database.
+type fallbackDB struct {
+	Database
+	routes []FallbackRoute // sorted by the length of the prefixes in descending order
+}
+
+// NewFallbackDatabase returns a database writing to primary and reading the
+// missing keys from the fallback databases of routes.
+func NewFallbackDatabase(primary Database, routes []FallbackRoute) Database {
+	sorted := make([]FallbackRoute, len(routes))
+	copy(sorted, routes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return &fallbackDB{Database: primary, routes: sorted}
+}
+
+// fallbacks returns the fallback databases of the key.
+func (db *fallbackDB) fallbacks(key []byte) []Database {
+	for _, route := range db.routes {
+		if bytes.HasPrefix(key, route.Prefix) {
+			return route.Databases
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the primary database, or the value of the first
+// fallback database having the key if the primary database does not have it.
+// The error of the primary database is returned if no database has the key,
+// and the error of a database failing for a reason other than a missing key
+// is returned as it is.
+func (db *fallbackDB) Get(key []byte) ([]byte, error) {
+	val, err := db.Database.Get(key)
+	// a value written to the primary database is never shadowed by a fallback
+	// database because of a failed read
+	if !errors.Is(err, ErrKeyNotFound) {
+		return val, err
+	}
+	for _, fallback := range db.fallbacks(key) {
+		val, fallbackErr := fallback.Get(key)
+		if !errors.Is(fallbackErr, ErrKeyNotFound) {
+			return val, fallbackErr
+		}
+	}
+	return nil, err
+}
+
+func (db *fallbackDB) Has(key []byte) (bool, error) {
+	has, err := db.Database.Has(key)
+	if has || err != nil {
+		return has, err
+	}
+	for _, fallback := range db.fallbacks(key) {
+		if has, err := fallback.Has(key); has || err != nil {
+			return has, err
+		}
+	}
+	return false, nil
+}
+
+// Close closes the primary database and the fallback databases.
+func (db *fallbackDB) Close() error {
+	closed := map[Database]bool{db.Database: true}
+	err := db.Database.Close()
+	for _, route := range db.routes {
+		for _, fallback := range route.Databases {
+			if closed[fallback] {
+				continue
+			}
+			closed[fallback] = true
+			if closeErr := fallback.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
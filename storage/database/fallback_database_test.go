@@ -0,0 +1,142 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingGetDB fails Get with err for the keys it has.
+type failingGetDB struct {
+	*MemDB
+	err error
+}
+
+func (db *failingGetDB) Get(key []byte) ([]byte, error) {
+	return nil, db.err
+}
+
+func TestFallbackDatabase(t *testing.T) {
+	primary, oldState, oldMisc := NewMemDB(), NewMemDB(), NewMemDB()
+	assert.NoError(t, primary.Put([]byte("s-both"), []byte("new")))
+	assert.NoError(t, oldState.Put([]byte("s-both"), []byte("old")))
+	assert.NoError(t, oldState.Put([]byte("s-old"), []byte("state")))
+	assert.NoError(t, oldMisc.Put([]byte("s-misc"), []byte("misc")))
+	assert.NoError(t, oldMisc.Put([]byte("m-misc"), []byte("misc")))
+
+	db := NewFallbackDatabase(primary, []FallbackRoute{
+		{Prefix: nil, Databases: []Database{oldMisc}},
+		{Prefix: []byte("s-"), Databases: []Database{oldState, oldMisc}},
+	})
+
+	for key, expected := range map[string]string{
+		"s-both": "new",   // the primary database is read first
+		"s-old":  "state", // only in the fallback database
+		"s-misc": "misc",  // in the second fallback database of the route
+		"m-misc": "misc",  // in the fallback database of the empty prefix
+	} {
+		val, err := db.Get([]byte(key))
+		assert.NoError(t, err, key)
+		assert.Equal(t, []byte(expected), val, key)
+		has, err := db.Has([]byte(key))
+		assert.NoError(t, err, key)
+		assert.True(t, has, key)
+	}
+	_, err := db.Get([]byte("missing"))
+	assert.ErrorIs(t, err, dataNotFoundErr)
+	has, err := db.Has([]byte("missing"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	// the writes land only in the primary database
+	assert.NoError(t, db.Put([]byte("s-new"), []byte("val")))
+	batch := db.NewBatch()
+	assert.NoError(t, batch.Put([]byte("s-batch"), []byte("val")))
+	assert.NoError(t, batch.Write())
+	for _, key := range []string{"s-new", "s-batch"} {
+		has, _ := primary.Has([]byte(key))
+		assert.True(t, has, key)
+		for _, fallback := range []*MemDB{oldState, oldMisc} {
+			has, _ := fallback.Has([]byte(key))
+			assert.False(t, has, key)
+		}
+	}
+
+	// all the databases are closed once
+	assert.NoError(t, db.Close())
+	for _, closed := range []*MemDB{primary, oldState, oldMisc} {
+		_, err := closed.Get([]byte("s-both"))
+		assert.ErrorIs(t, err, errMemorydbClosed)
+	}
+}
+
+func TestFallbackDatabase_PrimaryError(t *testing.T) {
+	errRead := errors.New("read failed")
+	primary := &failingGetDB{MemDB: NewMemDB(), err: errRead}
+	old := NewMemDB()
+	assert.NoError(t, primary.Put([]byte("key"), []byte("new")))
+	assert.NoError(t, old.Put([]byte("key"), []byte("old")))
+
+	// the stale value of the fallback database is not returned for the key of
+	// the primary database which failed to read
+	db := NewFallbackDatabase(primary, []FallbackRoute{{Databases: []Database{old}}})
+	_, err := db.Get([]byte("key"))
+	assert.ErrorIs(t, err, errRead)
+}
+
+// hasCountingDB counts the Has calls of the database besides its Get calls.
+type hasCountingDB struct {
+	*countingDB
+	hases int
+}
+
+func (db *hasCountingDB) Has(key []byte) (bool, error) {
+	db.hases++
+	return db.countingDB.Has(key)
+}
+
+func newHasCountingDB() *hasCountingDB {
+	release := make(chan struct{})
+	close(release)
+	return &hasCountingDB{countingDB: &countingDB{Database: NewMemDB(), release: release}}
+}
+
+func TestFallbackDatabase_Reads(t *testing.T) {
+	primary, first, second := newHasCountingDB(), newHasCountingDB(), newHasCountingDB()
+	assert.NoError(t, second.Put([]byte("key"), []byte("old")))
+
+	// a miss of the primary database is read by a Get of each fallback database
+	db := NewFallbackDatabase(primary, []FallbackRoute{{Databases: []Database{first, second}}})
+	val, err := db.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("old"), val)
+	for _, counted := range []*hasCountingDB{primary, first, second} {
+		assert.Equal(t, int32(1), counted.gets)
+		assert.Zero(t, counted.hases)
+	}
+
+	// a fallback database failing to read stops the fallback
+	errRead := errors.New("read failed")
+	db = NewFallbackDatabase(NewMemDB(), []FallbackRoute{{Databases: []Database{
+		&failingGetDB{MemDB: NewMemDB(), err: errRead}, second,
+	}}})
+	_, err = db.Get([]byte("key"))
+	assert.ErrorIs(t, err, errRead)
+}
@@ -16,6 +16,15 @@
 
 package database
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errInvalidFileRange is returned by readRange if the range is not within the data.
+var errInvalidFileRange = errors.New("invalid range of the file")
+
 type item struct {
 	key []byte
 	val []byte
@@ -27,6 +36,43 @@ type item struct {
 type fileDB interface {
 	write(items item) (string, error)
 	read(key []byte) ([]byte, error)
+	// readContext reads the data as read does, and gives up when ctx is done.
+	readContext(ctx context.Context, key []byte) ([]byte, error)
+	// readRange returns length bytes of the data from offset, which are truncated
+	// at the end of the data. It fails with errInvalidFileRange if offset is not
+	// within the data.
+	readRange(key []byte, offset, length int64) ([]byte, error)
+	// exists returns true if the data with the given key exists, without reading it.
+	exists(key []byte) (bool, error)
 	delete(key []byte) error
+	// deleteExisting deletes the data as delete does, and fails with
+	// dataNotFoundErr if the data does not exist.
+	deleteExisting(key []byte) error
 	deleteBucket()
+	resetBucket() error
+}
+
+// checkFileRange returns an error wrapping errInvalidFileRange if the range
+// can't be read regardless of the size of the data.
+func checkFileRange(offset, length int64) error {
+	if offset < 0 || length <= 0 {
+		return fmt.Errorf("%w: offset %d, length %d", errInvalidFileRange, offset, length)
+	}
+	return nil
+}
+
+// sliceFileRange returns the range of the data read entirely, in the same way
+// as fileDB.readRange.
+func sliceFileRange(val []byte, offset, length int64) ([]byte, error) {
+	if err := checkFileRange(offset, length); err != nil {
+		return nil, err
+	}
+	size := int64(len(val))
+	if offset >= size {
+		return nil, fmt.Errorf("%w: offset %d is beyond the size %d", errInvalidFileRange, offset, size)
+	}
+	if length > size-offset {
+		length = size - offset
+	}
+	return val[offset : offset+length], nil
 }
@@ -0,0 +1,142 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
+	"github.com/rcrowley/go-metrics"
+)
+
+// cachedFileDB keeps the recently read values of a fileDB in an LRU cache, so
+// that the hot oversized items are not read from S3 on every Get. The values
+// written or deleted through it are evicted from the cache.
+type cachedFileDB struct {
+	fileDB
+	cache *lru.Cache // the values keyed by the hex-encoded keys
+
+	// changes counts the writes and deletes, and a value read while it changes
+	// is not cached, since it may be overwritten by the concurrent write.
+	mu      sync.Mutex
+	changes uint64
+
+	hitMeter  metrics.Meter
+	missMeter metrics.Meter
+}
+
+// newCachedFileDB returns fdb caching up to size values. It returns fdb itself
+// if size is 0.
+func newCachedFileDB(fdb fileDB, size int) fileDB {
+	if size <= 0 {
+		return fdb
+	}
+	cache, _ := lru.New(size)
+	return &cachedFileDB{
+		fileDB:    fdb,
+		cache:     cache,
+		hitMeter:  &metrics.NilMeter{},
+		missMeter: &metrics.NilMeter{},
+	}
+}
+
+func (c *cachedFileDB) read(key []byte) ([]byte, error) {
+	return c.readContext(context.Background(), key)
+}
+
+func (c *cachedFileDB) readContext(ctx context.Context, key []byte) ([]byte, error) {
+	if val, ok := c.get(key); ok {
+		return common.CopyBytes(val), nil
+	}
+	c.mu.Lock()
+	changes := c.changes
+	c.mu.Unlock()
+
+	val, err := c.fileDB.readContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.changes == changes {
+		c.cache.Add(hexutil.Encode(key), common.CopyBytes(val))
+	}
+	return val, nil
+}
+
+func (c *cachedFileDB) readRange(key []byte, offset, length int64) ([]byte, error) {
+	if val, ok := c.get(key); ok {
+		ret, err := sliceFileRange(val, offset, length)
+		return common.CopyBytes(ret), err
+	}
+	return c.fileDB.readRange(key, offset, length)
+}
+
+func (c *cachedFileDB) write(item item) (string, error) {
+	defer c.evict(item.key)
+	return c.fileDB.write(item)
+}
+
+func (c *cachedFileDB) delete(key []byte) error {
+	defer c.evict(key)
+	return c.fileDB.delete(key)
+}
+
+func (c *cachedFileDB) deleteExisting(key []byte) error {
+	defer c.evict(key)
+	return c.fileDB.deleteExisting(key)
+}
+
+func (c *cachedFileDB) deleteBucket() {
+	defer c.purge()
+	c.fileDB.deleteBucket()
+}
+
+func (c *cachedFileDB) resetBucket() error {
+	defer c.purge()
+	return c.fileDB.resetBucket()
+}
+
+// get returns the cached value of the key, and counts the hits and the misses.
+func (c *cachedFileDB) get(key []byte) ([]byte, bool) {
+	if val, ok := c.cache.Get(hexutil.Encode(key)); ok {
+		c.hitMeter.Mark(1)
+		return val.([]byte), true
+	}
+	c.missMeter.Mark(1)
+	return nil, false
+}
+
+// evict removes the value of the key after it is changed, and counts the
+// change not to cache the values read before it.
+func (c *cachedFileDB) evict(key []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes++
+	c.cache.Remove(hexutil.Encode(key))
+}
+
+func (c *cachedFileDB) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.changes++
+	c.cache.Purge()
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFileDB counts the reads of the underlying fileDB, and blocks them
+// until unblock is closed if it is set.
+type countingFileDB struct {
+	*stubFileDB
+	reads   int32
+	entered chan struct{}
+	unblock chan struct{}
+}
+
+func (f *countingFileDB) readContext(ctx context.Context, key []byte) ([]byte, error) {
+	atomic.AddInt32(&f.reads, 1)
+	if f.unblock != nil {
+		close(f.entered)
+		<-f.unblock
+	}
+	return f.stubFileDB.readContext(ctx, key)
+}
+
+func newTestCachedFileDB(size int) (*cachedFileDB, *countingFileDB) {
+	fdb := &countingFileDB{stubFileDB: newStubFileDB()}
+	cache := newCachedFileDB(fdb, size).(*cachedFileDB)
+	cache.hitMeter, cache.missMeter = metrics.NewMeter(), metrics.NewMeter()
+	return cache, fdb
+}
+
+func TestCachedFileDB(t *testing.T) {
+	cache, fdb := newTestCachedFileDB(2)
+	key := []byte("key")
+	fdb.items[string(key)] = []byte("val")
+
+	// the second read is served by the cache
+	for i := 0; i < 2; i++ {
+		val, err := cache.read(key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("val"), val)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fdb.reads))
+	assert.Equal(t, int64(1), cache.hitMeter.Count())
+	assert.Equal(t, int64(1), cache.missMeter.Count())
+
+	// the cached value is not modified by the callers
+	val, _ := cache.read(key)
+	val[0] = 'x'
+	ret, err := cache.readRange(key, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("al"), ret)
+	val, _ = cache.read(key)
+	assert.Equal(t, []byte("val"), val)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fdb.reads))
+
+	// a write evicts the value
+	_, err = cache.write(item{key: key, val: []byte("new")})
+	assert.NoError(t, err)
+	val, err = cache.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("new"), val)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fdb.reads))
+
+	// a delete evicts the value
+	assert.NoError(t, cache.delete(key))
+	_, err = cache.read(key)
+	assert.ErrorIs(t, err, dataNotFoundErr)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&fdb.reads))
+
+	// the missing value is not cached
+	_, err = cache.read(key)
+	assert.ErrorIs(t, err, dataNotFoundErr)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&fdb.reads))
+}
+
+func TestCachedFileDB_ConcurrentWrite(t *testing.T) {
+	cache, fdb := newTestCachedFileDB(2)
+	key := []byte("key")
+	fdb.items[string(key)] = []byte("old")
+	fdb.entered, fdb.unblock = make(chan struct{}), make(chan struct{})
+
+	// the value read before a write of the same key is not cached
+	done := make(chan []byte)
+	go func() {
+		val, _ := cache.read(key)
+		done <- val
+	}()
+	<-fdb.entered
+	_, err := cache.write(item{key: key, val: []byte("new")})
+	assert.NoError(t, err)
+	close(fdb.unblock)
+	<-done
+
+	fdb.unblock = nil
+	val, err := cache.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("new"), val)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fdb.reads))
+}
+
+func TestNewCachedFileDB_Disabled(t *testing.T) {
+	fdb := newStubFileDB()
+	assert.Equal(t, fileDB(fdb), newCachedFileDB(fdb, 0))
+}
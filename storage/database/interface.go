@@ -20,7 +20,12 @@
 
 package database
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Code using batches should try to add this much data to the batch.
 // The value was determined empirically.
@@ -36,18 +41,46 @@ const (
 	ShardedDB        = "ShardedDB"
 )
 
-// ToValid converts DBType to a valid one.
-// If it is unable to convert, "" is returned.
-func (db DBType) ToValid() DBType {
-	validDBType := []DBType{LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB}
+// supportedDBTypes are the database types which can be selected by users.
+// ShardedDB is not included because it is built on top of the others.
+var supportedDBTypes = []DBType{LevelDB, RocksDB, BadgerDB, MemoryDB, DynamoDB}
+
+var errUnknownDBType = errors.New("unknown database type")
+
+// errTransactionNotSupported is returned by TransactWrite if the database cannot
+// write the items atomically.
+var errTransactionNotSupported = errors.New("transaction is not supported by the database")
+
+// errPutReturningOldNotSupported is returned by PutReturningOld if the database
+// cannot return the overwritten value in the same write.
+var errPutReturningOldNotSupported = errors.New("returning the old value on put is not supported by the database")
+
+// errBulkLoadNotSupported is returned by BulkLoad if the database cannot load
+// the items in bulk.
+var errBulkLoadNotSupported = errors.New("bulk load is not supported by the database")
+
+// SupportedDBTypes returns the database types which can be selected by users.
+func SupportedDBTypes() []DBType {
+	return append([]DBType{}, supportedDBTypes...)
+}
 
-	for _, vdb := range validDBType {
-		if strings.ToLower(string(vdb)) == strings.ToLower(string(db)) {
-			return vdb
+// ParseDBType returns the database type of the given name, which is
+// case-insensitive. An error listing the supported types is returned if the
+// name is unknown.
+func ParseDBType(s string) (DBType, error) {
+	for _, dbtype := range supportedDBTypes {
+		if strings.EqualFold(string(dbtype), s) {
+			return dbtype, nil
 		}
 	}
+	return "", fmt.Errorf("%w %q, supported types are %v", errUnknownDBType, s, supportedDBTypes)
+}
 
-	return ""
+// ToValid converts DBType to a valid one.
+// If it is unable to convert, "" is returned.
+func (db DBType) ToValid() DBType {
+	dbtype, _ := ParseDBType(string(db))
+	return dbtype
 }
 
 // selfShardable returns if the db is able to shard by itself or not
@@ -73,8 +106,11 @@ type Database interface {
 	KeyValueWriter
 	Get(key []byte) ([]byte, error)
 	Has(key []byte) (bool, error)
-	Close()
+	// Close releases the resources of the database. It returns an error if the
+	// pending writes are not flushed or the underlying database fails to close.
+	Close() error
 	NewBatch() Batch
+	NewBatchWithSize(n int) Batch
 	Type() DBType
 	Meter(prefix string)
 	Iteratee
@@ -83,6 +119,203 @@ type Database interface {
 	TryCatchUpWithPrimary() error
 }
 
+// ConsistencyReader wraps the GetWithConsistency method of a database which can
+// choose the consistency of each read.
+type ConsistencyReader interface {
+	// GetWithConsistency retrieves the given key with a strongly consistent read
+	// if strong is true, or with an eventually consistent read otherwise.
+	GetWithConsistency(key []byte, strong bool) ([]byte, error)
+}
+
+// GetWithConsistency retrieves the given key from db with the requested consistency.
+// If db does not implement ConsistencyReader, strong is ignored and it is the same as Get.
+func GetWithConsistency(db Database, key []byte, strong bool) ([]byte, error) {
+	if cr, ok := db.(ConsistencyReader); ok {
+		return cr.GetWithConsistency(key, strong)
+	}
+	return db.Get(key)
+}
+
+// ContextReader wraps the GetContext method of a database whose reads can be
+// bounded by the deadline of a context.
+type ContextReader interface {
+	// GetContext retrieves the given key as Get does within the deadline of ctx.
+	GetContext(ctx context.Context, key []byte) ([]byte, error)
+}
+
+// GetContext retrieves the given key from db within the deadline of ctx. If db
+// does not implement ContextReader, it is the same as Get unless ctx is
+// already done, since the reads of a local database are fast enough.
+func GetContext(ctx context.Context, db Database, key []byte) ([]byte, error) {
+	if cr, ok := db.(ContextReader); ok {
+		return cr.GetContext(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return db.Get(key)
+}
+
+// ContextWriter wraps the PutContext and DeleteContext methods of a database
+// whose writes can be canceled by a context.
+type ContextWriter interface {
+	// PutContext inserts the given value into the database as Put does until ctx is done.
+	PutContext(ctx context.Context, key []byte, value []byte) error
+
+	// DeleteContext removes the key from the database as Delete does until ctx is done.
+	DeleteContext(ctx context.Context, key []byte) error
+}
+
+// PutContext inserts the given value into db until ctx is done. If db does not
+// implement ContextWriter, it is the same as Put unless ctx is already done.
+func PutContext(ctx context.Context, db Database, key []byte, value []byte) error {
+	if cw, ok := db.(ContextWriter); ok {
+		return cw.PutContext(ctx, key, value)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.Put(key, value)
+}
+
+// DeleteContext removes the key from db until ctx is done. If db does not
+// implement ContextWriter, it is the same as Delete unless ctx is already done.
+func DeleteContext(ctx context.Context, db Database, key []byte) error {
+	if cw, ok := db.(ContextWriter); ok {
+		return cw.DeleteContext(ctx, key)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return db.Delete(key)
+}
+
+// ReadMeta describes how a value was read, which lets the callers decide if the
+// value should be read again to be verified.
+type ReadMeta struct {
+	// Consistent is true if the read was strongly consistent, which always
+	// returns the latest value. An eventually consistent read may return a
+	// stale value.
+	Consistent bool
+}
+
+// MetaReader wraps the GetWithMeta method of a database whose reads may be stale.
+type MetaReader interface {
+	// GetWithMeta retrieves the given key as Get does, and returns how it is read.
+	GetWithMeta(key []byte) ([]byte, ReadMeta, error)
+}
+
+// GetWithMeta retrieves the given key from db with the metadata of the read.
+// If db does not implement MetaReader, it is the same as Get and the read is
+// consistent, like the reads of LevelDB and MemoryDB.
+func GetWithMeta(db Database, key []byte) ([]byte, ReadMeta, error) {
+	if mr, ok := db.(MetaReader); ok {
+		return mr.GetWithMeta(key)
+	}
+	val, err := db.Get(key)
+	return val, ReadMeta{Consistent: true}, err
+}
+
+// ScanStats is the number of items returned and skipped by a ScanIterator.
+type ScanStats struct {
+	Items   int
+	Skipped int
+}
+
+// ScanIterator is an iterator over the items of a database in no particular order.
+type ScanIterator interface {
+	Iterator
+
+	// Stats returns the number of items returned and skipped so far.
+	Stats() ScanStats
+}
+
+// Scanner wraps the NewScanIterator method of a database which cannot iterate
+// its items in order, like DynamoDB, but can scan them for maintenance.
+type Scanner interface {
+	// NewScanIterator creates an iterator over all items in no particular order.
+	// If skipCorrupt is true, the items which cannot be decoded or resolved are
+	// logged and skipped instead of stopping the iterator with an error.
+	NewScanIterator(skipCorrupt bool) ScanIterator
+}
+
+// KV is a key-value pair written by a transaction. If IfAbsent is set, the pair
+// is written only if the key does not exist, otherwise the transaction is
+// cancelled.
+type KV struct {
+	Key      []byte
+	Value    []byte
+	IfAbsent bool
+}
+
+// TransactWriter wraps the TransactWrite method of a database which can write
+// several keys atomically.
+type TransactWriter interface {
+	// TransactWrite writes all the items or none of them.
+	TransactWrite(items []KV) error
+}
+
+// TransactWrite writes the items to db atomically. It returns
+// errTransactionNotSupported if db does not implement TransactWriter.
+func TransactWrite(db Database, items []KV) error {
+	if tw, ok := db.(TransactWriter); ok {
+		return tw.TransactWrite(items)
+	}
+	return errTransactionNotSupported
+}
+
+// OldValueWriter wraps the PutReturningOld method of a database which can
+// return the overwritten value of a key in the same write.
+type OldValueWriter interface {
+	// PutReturningOld inserts the given value, and returns the previous value
+	// of the key, or nil if the key did not exist.
+	PutReturningOld(key []byte, value []byte) ([]byte, error)
+}
+
+// PutReturningOld inserts the given value to db, and returns the previous value
+// of the key. It returns errPutReturningOldNotSupported if db does not
+// implement OldValueWriter, since a Get before the Put may miss a concurrent
+// write.
+func PutReturningOld(db Database, key []byte, value []byte) ([]byte, error) {
+	if w, ok := db.(OldValueWriter); ok {
+		return w.PutReturningOld(key, value)
+	}
+	return nil, errPutReturningOldNotSupported
+}
+
+// BulkLoader wraps the BulkLoad method of a database which can load a large
+// number of items faster than the batches.
+type BulkLoader interface {
+	// BulkLoad writes the items of the iterator and returns the number of the
+	// written items. It stops reading the iterator when quit is closed.
+	BulkLoad(it Iterator, quit <-chan struct{}) (int, error)
+}
+
+// BulkLoad writes the items of the iterator to db by its bulk load. It returns
+// errBulkLoadNotSupported if db does not implement BulkLoader.
+func BulkLoad(db Database, it Iterator, quit <-chan struct{}) (int, error) {
+	if bl, ok := db.(BulkLoader); ok {
+		return bl.BulkLoad(it, quit)
+	}
+	return 0, errBulkLoadNotSupported
+}
+
+// Flusher wraps the Flush method of a database which writes the data in the
+// background, like the batch writes of DynamoDB.
+type Flusher interface {
+	// Flush waits until the pending writes are written, and returns their error.
+	Flush() error
+}
+
+// Flush waits for the pending writes of db if it is a Flusher. Otherwise, it
+// returns nil, since the data is written when the writes return.
+func Flush(db Database) error {
+	if f, ok := db.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
 func WriteBatches(batches ...Batch) (int, error) {
 	bytes := 0
 	for _, batch := range batches {
@@ -18,6 +18,8 @@
 package database
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -50,3 +52,77 @@ func TestDBType_ToValid(t *testing.T) {
 		assert.Equal(t, DBType(""), newType, "dbtype should not acceptable:"+dbtype)
 	}
 }
+
+func TestParseDBType(t *testing.T) {
+	for _, name := range []string{"LevelDB", "leveldb", "ROCKSDB", "badgerDB", "memorydb", "DynamoDBS3", "dynamodbS3"} {
+		dbtype, err := ParseDBType(name)
+		assert.NoError(t, err)
+		assert.Contains(t, SupportedDBTypes(), dbtype)
+		assert.True(t, strings.EqualFold(name, string(dbtype)))
+	}
+
+	for _, name := range []string{"", "level", "dynamo", "ShardedDB", "LevelDB "} {
+		dbtype, err := ParseDBType(name)
+		assert.ErrorIs(t, err, errUnknownDBType)
+		assert.Contains(t, err.Error(), "DynamoDBS3", "the supported types should be listed")
+		assert.Equal(t, DBType(""), dbtype)
+	}
+
+	// the supported types can't be modified by the caller
+	SupportedDBTypes()[0] = "modified"
+	assert.Equal(t, LevelDB, SupportedDBTypes()[0])
+}
+
+func TestNewDatabase(t *testing.T) {
+	dir, err := os.MkdirTemp("", "klaytn-test-new-database")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewDatabase("memorydb", &DBConfig{})
+	assert.NoError(t, err)
+	assert.IsType(t, &MemDB{}, db)
+	db.Close()
+
+	db, err = NewDatabase(LevelDB, &DBConfig{Dir: dir})
+	assert.NoError(t, err)
+	assert.Equal(t, DBType(LevelDB), db.Type())
+	db.Close()
+
+	// the constructor of DynamoDB is chosen, which validates the config
+	_, err = NewDatabase(DynamoDB, &DBConfig{})
+	assert.Equal(t, nilDynamoConfigErr, err)
+
+	_, err = NewDatabase("unknown", &DBConfig{Dir: dir})
+	assert.ErrorIs(t, err, errUnknownDBType)
+}
+
+func TestGetWithConsistency_NotSupported(t *testing.T) {
+	db := NewMemDB()
+	key, val := []byte("key"), []byte("val")
+	assert.NoError(t, db.Put(key, val))
+
+	// the consistency is ignored if the database does not support it
+	for _, strong := range []bool{true, false} {
+		ret, err := GetWithConsistency(db, key, strong)
+		assert.NoError(t, err)
+		assert.Equal(t, val, ret)
+	}
+	_, err := GetWithConsistency(db, []byte("missing"), true)
+	assert.Equal(t, dataNotFoundErr, err)
+}
+
+func TestGetWithMeta_NotSupported(t *testing.T) {
+	db := NewMemDB()
+	key, val := []byte("key"), []byte("val")
+	assert.NoError(t, db.Put(key, val))
+
+	// the reads of the databases which do not report the meta are consistent
+	for _, db := range []Database{db, NewNamespacedDatabase(db, []byte{}), NewCoalescingDatabase(db)} {
+		ret, meta, err := GetWithMeta(db, key)
+		assert.NoError(t, err)
+		assert.Equal(t, val, ret)
+		assert.True(t, meta.Consistent)
+	}
+	_, _, err := GetWithMeta(db, []byte("missing"))
+	assert.Equal(t, dataNotFoundErr, err)
+}
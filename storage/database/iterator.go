@@ -19,6 +19,8 @@
 
 package database
 
+import "bytes"
+
 // Iterator iterates over a database's key/value pairs in ascending key order.
 //
 // When it encounters an error any seek will return false and will yield no key/
@@ -61,4 +63,67 @@ type Iteratee interface {
 	// Note: This method assumes that the prefix is NOT part of the start, so there's
 	// no need for the caller to prepend the prefix to the start
 	NewIterator(prefix []byte, start []byte) Iterator
+
+	// NewIteratorWithRange creates a binary-alphabetical iterator over the keys
+	// in [start, end). A nil start or end leaves the range unbounded on that side.
+	NewIteratorWithRange(start, end []byte) Iterator
 }
+
+// rangeIterator stops the underlying iterator at the end of a range, for the
+// databases which cannot bound their iterators by themselves.
+type rangeIterator struct {
+	Iterator
+	end  []byte
+	done bool
+}
+
+// newRangeIterator returns an iterator which stops it before end. A nil end
+// leaves it unbounded.
+func newRangeIterator(it Iterator, end []byte) Iterator {
+	if it == nil || end == nil {
+		return it
+	}
+	return &rangeIterator{Iterator: it, end: end}
+}
+
+func (it *rangeIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if !it.Iterator.Next() || bytes.Compare(it.Iterator.Key(), it.end) >= 0 {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+func (it *rangeIterator) Key() []byte {
+	if it.done {
+		return nil
+	}
+	return it.Iterator.Key()
+}
+
+func (it *rangeIterator) Value() []byte {
+	if it.done {
+		return nil
+	}
+	return it.Iterator.Value()
+}
+
+// errIterator is an exhausted iterator which reports an error, returned by the
+// databases which cannot create the requested iterator.
+type errIterator struct {
+	err error
+}
+
+// newErrIterator returns an iterator which yields nothing and fails with err.
+func newErrIterator(err error) Iterator {
+	return &errIterator{err: err}
+}
+
+func (it *errIterator) Next() bool    { return false }
+func (it *errIterator) Error() error  { return it.err }
+func (it *errIterator) Key() []byte   { return nil }
+func (it *errIterator) Value() []byte { return nil }
+func (it *errIterator) Release()      {}
@@ -0,0 +1,147 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestShardedDB() (Database, func(), string) {
+	dirName, err := os.MkdirTemp(os.TempDir(), "klay_shardeddb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	config := &DBConfig{Dir: dirName, DBType: LevelDB, NumStateTrieShards: 2, ParallelDBWrite: true}
+	db, err := newShardedDB(config, 0, config.NumStateTrieShards)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirName)
+	}, "sharded"
+}
+
+func newTestNamespacedDB() (Database, func(), string) {
+	memDB := NewMemDB()
+	// the keys of another namespace are out of the range
+	for i := 0; i < 20; i++ {
+		memDB.Put([]byte(fmt.Sprintf("other-key%02d", i)), []byte("other"))
+	}
+	return NewNamespacedDatabase(memDB, []byte("ns-")), func() {}, "namespaced"
+}
+
+func newTestEncryptedMemDB() (Database, func(), string) {
+	db, err := NewEncryptedDatabase(NewMemDB(), testEncryptionKey, [][]byte{[]byte("key1")})
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+	return db, func() {}, "encrypted"
+}
+
+func newTestStubDynamoDB() (Database, func(), string) {
+	restore := setTestDynamoDBClient(newMemoryDynamoDBClient(make(map[string]map[string]*dynamodb.AttributeValue)))
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = newStubFileDB()
+	return dynamo, restore, "dynamo"
+}
+
+func TestNewIteratorWithRange(t *testing.T) {
+	testcases := []struct {
+		start, end string
+		first      int // the first index of the keys in the range
+		last       int // the last index + 1
+	}{
+		{"key05", "key10", 5, 10},
+		{"", "key03", 0, 3},
+		{"key17", "", 17, 20},
+		{"", "", 0, 20},
+		{"key04a", "key06a", 5, 7}, // the bounds not in the database
+		{"key10", "key10", 10, 10},
+		{"key12", "key08", 12, 12},
+	}
+	bytesOrNil := func(s string) []byte {
+		if s == "" {
+			return nil
+		}
+		return []byte(s)
+	}
+
+	for _, newFn := range []func() (Database, func(), string){newTestLDB, newTestMemDB, newTestShardedDB, newTestNamespacedDB, newTestEncryptedMemDB, newTestStubDynamoDB} {
+		db, remove, name := newFn()
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, db.Put([]byte(fmt.Sprintf("key%02d", i)), []byte(fmt.Sprintf("val%02d", i))))
+		}
+
+		for _, tc := range testcases {
+			var keys, vals []string
+			it := db.NewIteratorWithRange(bytesOrNil(tc.start), bytesOrNil(tc.end))
+			for it.Next() {
+				keys = append(keys, string(it.Key()))
+				vals = append(vals, string(it.Value()))
+			}
+			assert.NoError(t, it.Error())
+			it.Release()
+
+			var wantKeys, wantVals []string
+			for i := tc.first; i < tc.last; i++ {
+				wantKeys = append(wantKeys, fmt.Sprintf("key%02d", i))
+				wantVals = append(wantVals, fmt.Sprintf("val%02d", i))
+			}
+			assert.Equal(t, wantKeys, keys, "%s [%s, %s)", name, tc.start, tc.end)
+			assert.Equal(t, wantVals, vals, "%s [%s, %s)", name, tc.start, tc.end)
+		}
+		remove()
+	}
+}
+
+func TestRangeIterator(t *testing.T) {
+	memDB := NewMemDB()
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, memDB.Put([]byte(fmt.Sprintf("key%d", i)), []byte("val")))
+	}
+
+	it := newRangeIterator(memDB.NewIterator(nil, []byte("key3")), []byte("key5"))
+	assert.True(t, it.Next())
+	assert.Equal(t, []byte("key3"), it.Key())
+	assert.True(t, it.Next())
+	assert.Equal(t, []byte("key4"), it.Key())
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+	assert.Nil(t, it.Value())
+	// it stays exhausted
+	assert.False(t, it.Next())
+	it.Release()
+
+	assert.Nil(t, newRangeIterator(nil, []byte("key5")))
+}
+
+func TestErrIterator(t *testing.T) {
+	errTest := errors.New("test")
+	it := &namespacedIterator{Iterator: newErrIterator(errTest), namespace: []byte("ns")}
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+	assert.Nil(t, it.Value())
+	assert.Equal(t, errTest, it.Error())
+	it.Release()
+}
@@ -142,6 +142,7 @@ func getLevelDBOptions(dbc *DBConfig) *opt.Options {
 		CompactionTableSize:           2 * opt.MiB,
 		CompactionTableSizeMultiplier: 1.0,
 		DisableSeeksCompaction:        true,
+		ReadOnly:                      dbc.ReadOnly,
 	}
 
 	return newOption
@@ -319,7 +320,13 @@ func (db *levelDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return db.db.NewIterator(bytesPrefixRange(prefix, start), nil)
 }
 
-func (db *levelDB) Close() {
+// NewIteratorWithRange creates a binary-alphabetical iterator over the keys in
+// [start, end).
+func (db *levelDB) NewIteratorWithRange(start, end []byte) Iterator {
+	return db.db.NewIterator(&util.Range{Start: start, Limit: end}, nil)
+}
+
+func (db *levelDB) Close() error {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
 	defer db.quitLock.Unlock()
@@ -338,6 +345,7 @@ func (db *levelDB) Close() {
 	} else {
 		db.logger.Error("Failed to close database", "err", err)
 	}
+	return err
 }
 
 func (db *levelDB) LDB() *leveldb.DB {
@@ -513,7 +521,17 @@ func (db *levelDB) TryCatchUpWithPrimary() error {
 }
 
 func (db *levelDB) NewBatch() Batch {
-	return &ldbBatch{b: new(leveldb.Batch), ldb: db}
+	return db.NewBatchWithSize(0)
+}
+
+// NewBatchWithSize creates a write-only database batch whose buffer is
+// pre-allocated for n items, up to IdealBatchSize bytes.
+func (db *levelDB) NewBatchWithSize(n int) Batch {
+	size := n * batchItemSizeHint
+	if size > IdealBatchSize {
+		size = IdealBatchSize
+	}
+	return &ldbBatch{b: leveldb.MakeBatch(size), ldb: db}
 }
 
 // ldbBatch is a write-only leveldb batch that commits changes to its host database
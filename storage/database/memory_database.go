@@ -21,7 +21,11 @@
 package database
 
 import (
+	"bufio"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"sync"
@@ -33,12 +37,24 @@ import (
 // invocation of a data access operation.
 var errMemorydbClosed = errors.New("database closed")
 
+// errMemoryDBFull is returned if a write to a memory database would exceed its
+// maximum size.
+var errMemoryDBFull = errors.New("memory database is full")
+
+// memDBRestoreChunkSize is the size of the chunks in which Restore reads a key
+// or a value, so that a corrupted length doesn't allocate more memory than the
+// snapshot holds.
+const memDBRestoreChunkSize = 64 * 1024
+
 /*
  * This is a test memory database. Do not use for any production it does not get persisted
  */
 type MemDB struct {
 	db   map[string][]byte
 	lock sync.RWMutex
+
+	size    int // total bytes of the stored keys and values
+	maxSize int // maximum bytes of the stored keys and values, 0 means unlimited
 }
 
 func NewMemDB() *MemDB {
@@ -53,13 +69,25 @@ func NewMemDBWithCap(size int) *MemDB {
 	}
 }
 
+// NewMemDBWithMaxSize creates a MemDB which refuses writes exceeding maxSize
+// bytes of keys and values. A non-positive maxSize means unlimited.
+func NewMemDBWithMaxSize(maxSize int) *MemDB {
+	db := NewMemDB()
+	if maxSize > 0 {
+		db.maxSize = maxSize
+	}
+	return db
+}
+
 // Close deallocates the internal map and ensures any consecutive data access op
 // fails with an error.
-func (db *MemDB) Close() {
+func (db *MemDB) Close() error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
 	db.db = nil
+	db.size = 0
+	return nil
 }
 
 func (db *MemDB) Type() DBType {
@@ -103,7 +131,12 @@ func (db *MemDB) Put(key []byte, value []byte) error {
 	if db.db == nil {
 		return errMemorydbClosed
 	}
+	size := db.size + db.sizeDiff(string(key), value)
+	if db.maxSize > 0 && size > db.maxSize {
+		return errMemoryDBFull
+	}
 	db.db[string(key)] = common.CopyBytes(value)
+	db.size = size
 	return nil
 }
 
@@ -115,10 +148,22 @@ func (db *MemDB) Delete(key []byte) error {
 	if db.db == nil {
 		return errMemorydbClosed
 	}
-	delete(db.db, string(key))
+	if val, ok := db.db[string(key)]; ok {
+		db.size -= len(key) + len(val)
+		delete(db.db, string(key))
+	}
 	return nil
 }
 
+// sizeDiff returns how much the size of the database changes when the value of
+// the given key is replaced. It should be called with the lock held.
+func (db *MemDB) sizeDiff(key string, value []byte) int {
+	if old, ok := db.db[key]; ok {
+		return len(value) - len(old)
+	}
+	return len(key) + len(value)
+}
+
 func (db *MemDB) Keys() [][]byte {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -130,8 +175,119 @@ func (db *MemDB) Keys() [][]byte {
 	return keys
 }
 
+// Snapshot writes all the key-value pairs to w in the order of the keys. Each
+// key and value is written with its length as a uvarint prefix, so that the
+// snapshot can be read back by Restore.
+func (db *MemDB) Snapshot(w io.Writer) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return errMemorydbClosed
+	}
+	keys := make([]string, 0, len(db.db))
+	for key := range db.db {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, key := range keys {
+		for _, field := range [][]byte{[]byte(key), db.db[key]} {
+			n := binary.PutUvarint(buf, uint64(len(field)))
+			if _, err := bw.Write(buf[:n]); err != nil {
+				return err
+			}
+			if _, err := bw.Write(field); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Restore replaces the contents of the database with the key-value pairs read
+// from a snapshot written by Snapshot. The database is left unchanged if the
+// snapshot is malformed or exceeds the maximum size of the database.
+func (db *MemDB) Restore(r io.Reader) error {
+	var (
+		br   = bufio.NewReader(r)
+		kvs  = make(map[string][]byte)
+		size int
+	)
+	// readField returns io.EOF only if the snapshot ends before the field
+	readField := func() ([]byte, error) {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		// a field larger than the database is refused before it is read
+		if db.maxSize > 0 && length > uint64(db.maxSize) {
+			return nil, errMemoryDBFull
+		}
+		chunk := uint64(memDBRestoreChunkSize)
+		if length < chunk {
+			chunk = length
+		}
+		field := make([]byte, 0, chunk)
+		for uint64(len(field)) < length {
+			if remaining := length - uint64(len(field)); remaining < chunk {
+				chunk = remaining
+			}
+			start := len(field)
+			field = append(field, make([]byte, chunk)...)
+			if _, err := io.ReadFull(br, field[start:]); err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		return field, nil
+	}
+	for {
+		key, err := readField()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read a key of the snapshot: %w", err)
+		}
+		value, err := readField()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the value of the snapshot: key=%x, err=%w", key, err)
+		}
+		if old, ok := kvs[string(key)]; ok {
+			size -= len(key) + len(old)
+		}
+		kvs[string(key)] = value
+		size += len(key) + len(value)
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return errMemorydbClosed
+	}
+	if db.maxSize > 0 && size > db.maxSize {
+		return errMemoryDBFull
+	}
+	db.db = kvs
+	db.size = size
+	return nil
+}
+
 func (db *MemDB) NewBatch() Batch {
-	return &memBatch{db: db}
+	return db.NewBatchWithSize(0)
+}
+
+// NewBatchWithSize creates a write-only key-value store that buffers changes to
+// its host database until a final write is called, pre-allocating space for n items.
+func (db *MemDB) NewBatchWithSize(n int) Batch {
+	return &memBatch{db: db, writes: make([]keyvalue, 0, n)}
 }
 
 // NewIterator creates a binary-alphabetical iterator over a subset
@@ -168,6 +324,32 @@ func (db *MemDB) NewIterator(prefix []byte, start []byte) Iterator {
 	}
 }
 
+// NewIteratorWithRange creates a binary-alphabetical iterator over the keys in
+// [start, end).
+func (db *MemDB) NewIteratorWithRange(start, end []byte) Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		st     = string(start)
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	for key := range db.db {
+		if key >= st && (end == nil || key < string(end)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, db.db[key])
+	}
+	return &iterator{
+		keys:   keys,
+		values: values,
+	}
+}
+
 // Stat returns a particular internal stat of the database.
 func (db *MemDB) Stat(property string) (string, error) {
 	return "", errors.New("unknown property")
@@ -242,16 +424,61 @@ func (b *memBatch) Write() error {
 	b.db.lock.Lock()
 	defer b.db.lock.Unlock()
 
+	if b.db.db == nil {
+		return errMemorydbClosed
+	}
+	if b.db.maxSize > 0 {
+		// check the size first so that the batch is written entirely or not at all.
+		if size := b.db.size + b.sizeDiff(); size > b.db.maxSize {
+			return errMemoryDBFull
+		}
+	}
 	for _, keyvalue := range b.writes {
+		if val, ok := b.db.db[string(keyvalue.key)]; ok {
+			b.db.size -= len(keyvalue.key) + len(val)
+		}
 		if keyvalue.delete {
 			delete(b.db.db, string(keyvalue.key))
 			continue
 		}
 		b.db.db[string(keyvalue.key)] = keyvalue.value
+		b.db.size += len(keyvalue.key) + len(keyvalue.value)
 	}
 	return nil
 }
 
+// sizeDiff returns how much the size of the host database changes when the
+// batch is written. It should be called with the lock of the host database held.
+func (b *memBatch) sizeDiff() int {
+	var (
+		diff    int
+		pending = make(map[string][]byte) // the latest value of each key in the batch, nil if deleted
+	)
+	for _, keyvalue := range b.writes {
+		key := string(keyvalue.key)
+		old, ok := pending[key]
+		if !ok {
+			old, ok = b.db.db[key]
+		} else {
+			ok = old != nil
+		}
+		if ok {
+			diff -= len(key) + len(old)
+		}
+		if keyvalue.delete {
+			pending[key] = nil
+			continue
+		}
+		value := keyvalue.value
+		if value == nil {
+			value = []byte{}
+		}
+		pending[key] = value
+		diff += len(key) + len(value)
+	}
+	return diff
+}
+
 // Reset resets the batch for reuse.
 func (b *memBatch) Reset() {
 	b.writes = b.writes[:0]
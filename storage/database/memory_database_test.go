@@ -0,0 +1,155 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemDB_MaxSize(t *testing.T) {
+	// 10 entries of a 4-byte key and a 6-byte value
+	db := NewMemDBWithMaxSize(100)
+	val := bytes.Repeat([]byte{1}, 6)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, db.Put([]byte(fmt.Sprintf("k%03d", i)), val))
+	}
+
+	// the database is full
+	assert.Equal(t, errMemoryDBFull, db.Put([]byte("k010"), val))
+	assert.Equal(t, errMemoryDBFull, db.Put([]byte("k000"), append(val, 1)))
+	_, err := db.Get([]byte("k010"))
+	assert.Equal(t, dataNotFoundErr, err)
+
+	// overwriting with a value of the same size is allowed
+	assert.NoError(t, db.Put([]byte("k000"), bytes.Repeat([]byte{2}, 6)))
+
+	// a batch exceeding the limit is not written at all
+	batch := db.NewBatch()
+	assert.NoError(t, batch.Delete([]byte("k000")))
+	assert.NoError(t, batch.Put([]byte("k010"), val))
+	assert.NoError(t, batch.Put([]byte("k011"), val))
+	assert.Equal(t, errMemoryDBFull, batch.Write())
+	has, _ := db.Has([]byte("k000"))
+	assert.True(t, has)
+	assert.Equal(t, 10, db.Len())
+
+	// deleting entries makes room again
+	assert.NoError(t, db.Delete([]byte("k000")))
+	assert.NoError(t, db.Delete([]byte("k001")))
+	assert.NoError(t, db.Delete([]byte("k001")))
+	assert.NoError(t, db.Put([]byte("k010"), val))
+
+	batch.Reset()
+	assert.NoError(t, batch.Put([]byte("k011"), val))
+	assert.NoError(t, batch.Put([]byte("k011"), val[:1]))
+	assert.NoError(t, batch.Write())
+	assert.Equal(t, 100-5, db.size)
+
+	// a batch which frees space is written
+	batch.Reset()
+	assert.NoError(t, batch.Put([]byte("k012"), val))
+	assert.NoError(t, batch.Delete([]byte("k002")))
+	assert.NoError(t, batch.Write())
+	assert.Equal(t, 100-5, db.size)
+}
+
+func TestMemDB_Unlimited(t *testing.T) {
+	db := NewMemDBWithMaxSize(0)
+	val := bytes.Repeat([]byte{1}, 1024)
+	for i := 0; i < 1024; i++ {
+		assert.NoError(t, db.Put([]byte(fmt.Sprintf("k%04d", i)), val))
+	}
+	assert.Equal(t, 1024*(5+1024), db.size)
+}
+
+func TestMemDB_SnapshotRestore(t *testing.T) {
+	db := NewMemDB()
+	assert.NoError(t, db.Put([]byte("empty"), nil))
+	assert.NoError(t, db.Put([]byte{}, []byte("empty key")))
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, db.Put([]byte(fmt.Sprintf("key%03d", i)), bytes.Repeat([]byte{byte(i)}, i*10)))
+	}
+
+	var snapshot bytes.Buffer
+	assert.NoError(t, db.Snapshot(&snapshot))
+
+	// the existing contents are replaced by the snapshot
+	restored := NewMemDB()
+	assert.NoError(t, restored.Put([]byte("stale"), []byte("value")))
+	assert.NoError(t, restored.Restore(bytes.NewReader(snapshot.Bytes())))
+	assert.Equal(t, db.Len(), restored.Len())
+	assert.Equal(t, db.size, restored.size)
+	for _, key := range db.Keys() {
+		expected, _ := db.Get(key)
+		val, err := restored.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, val, "key %q", key)
+	}
+
+	val, err := restored.Get([]byte("empty"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{}, val)
+	_, err = restored.Get([]byte("stale"))
+	assert.Equal(t, dataNotFoundErr, err)
+
+	// the snapshots of the same contents are the same
+	var again bytes.Buffer
+	assert.NoError(t, restored.Snapshot(&again))
+	assert.Equal(t, snapshot.Bytes(), again.Bytes())
+}
+
+func TestMemDB_RestoreInvalid(t *testing.T) {
+	src := NewMemDB()
+	assert.NoError(t, src.Put([]byte("key"), []byte("value")))
+	var snapshot bytes.Buffer
+	assert.NoError(t, src.Snapshot(&snapshot))
+
+	db := NewMemDB()
+	assert.NoError(t, db.Put([]byte("old"), []byte("value")))
+
+	// a truncated snapshot leaves the database unchanged
+	for i := 1; i < snapshot.Len(); i++ {
+		err := db.Restore(bytes.NewReader(snapshot.Bytes()[:i]))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "length %d", i)
+	}
+	has, _ := db.Has([]byte("old"))
+	assert.True(t, has)
+
+	// a snapshot exceeding the maximum size is refused
+	small := NewMemDBWithMaxSize(5)
+	assert.Equal(t, errMemoryDBFull, small.Restore(bytes.NewReader(snapshot.Bytes())))
+
+	// a corrupted length is refused without allocating it
+	huge := make([]byte, binary.MaxVarintLen64)
+	huge = huge[:binary.PutUvarint(huge, math.MaxUint64)]
+	assert.ErrorIs(t, small.Restore(bytes.NewReader(huge)), errMemoryDBFull)
+	assert.ErrorIs(t, db.Restore(bytes.NewReader(huge)), io.ErrUnexpectedEOF)
+	has, _ = db.Has([]byte("old"))
+	assert.True(t, has)
+
+	// a closed database
+	db.Close()
+	assert.Equal(t, errMemorydbClosed, db.Snapshot(io.Discard))
+	assert.Equal(t, errMemorydbClosed, db.Restore(bytes.NewReader(snapshot.Bytes())))
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"context"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// namespacedDB prepends a namespace to every key of the underlying database,
+// which lets several logical databases, such as the databases of different
+// chains, share one physical database like a DynamoDB table.
+// The keys returned by its iterators are within the namespace, and they are
+// presented without the namespace.
+type namespacedDB struct {
+	Database
+	namespace []byte
+}
+
+// NewNamespacedDatabase returns a database which stores its keys in db with
+// the given namespace prepended. The namespaces sharing one database should not
+// be a prefix of each other, otherwise their keys can collide.
+func NewNamespacedDatabase(db Database, namespace []byte) Database {
+	return &namespacedDB{
+		Database:  db,
+		namespace: append([]byte{}, namespace...),
+	}
+}
+
+// key returns the key in the underlying database.
+func (db *namespacedDB) key(key []byte) []byte {
+	nsKey := make([]byte, len(db.namespace)+len(key))
+	copy(nsKey, db.namespace)
+	copy(nsKey[len(db.namespace):], key)
+	return nsKey
+}
+
+func (db *namespacedDB) Put(key []byte, value []byte) error {
+	return db.Database.Put(db.key(key), value)
+}
+
+func (db *namespacedDB) Get(key []byte) ([]byte, error) {
+	return db.Database.Get(db.key(key))
+}
+
+// GetWithConsistency keeps the consistency of reads selectable if the
+// underlying database supports it.
+func (db *namespacedDB) GetWithConsistency(key []byte, strong bool) ([]byte, error) {
+	return GetWithConsistency(db.Database, db.key(key), strong)
+}
+
+func (db *namespacedDB) GetContext(ctx context.Context, key []byte) ([]byte, error) {
+	return GetContext(ctx, db.Database, db.key(key))
+}
+
+func (db *namespacedDB) GetWithMeta(key []byte) ([]byte, ReadMeta, error) {
+	return GetWithMeta(db.Database, db.key(key))
+}
+
+func (db *namespacedDB) TransactWrite(items []KV) error {
+	namespaced := make([]KV, len(items))
+	for i, item := range items {
+		namespaced[i] = KV{Key: db.key(item.Key), Value: item.Value, IfAbsent: item.IfAbsent}
+	}
+	return TransactWrite(db.Database, namespaced)
+}
+
+func (db *namespacedDB) PutReturningOld(key []byte, value []byte) ([]byte, error) {
+	return PutReturningOld(db.Database, db.key(key), value)
+}
+
+func (db *namespacedDB) Has(key []byte) (bool, error) {
+	return db.Database.Has(db.key(key))
+}
+
+func (db *namespacedDB) Delete(key []byte) error {
+	return db.Database.Delete(db.key(key))
+}
+
+func (db *namespacedDB) PutContext(ctx context.Context, key []byte, value []byte) error {
+	return PutContext(ctx, db.Database, db.key(key), value)
+}
+
+func (db *namespacedDB) DeleteContext(ctx context.Context, key []byte) error {
+	return DeleteContext(ctx, db.Database, db.key(key))
+}
+
+func (db *namespacedDB) Flush() error {
+	return Flush(db.Database)
+}
+
+func (db *namespacedDB) NewBatch() Batch {
+	return &namespacedBatch{Batch: db.Database.NewBatch(), db: db}
+}
+
+func (db *namespacedDB) NewBatchWithSize(n int) Batch {
+	return &namespacedBatch{Batch: db.Database.NewBatchWithSize(n), db: db}
+}
+
+func (db *namespacedDB) NewIterator(prefix []byte, start []byte) Iterator {
+	return &namespacedIterator{
+		Iterator:  db.Database.NewIterator(db.key(prefix), start),
+		namespace: db.namespace,
+	}
+}
+
+func (db *namespacedDB) NewIteratorWithRange(start, end []byte) Iterator {
+	// the end of the namespace bounds the range if end is not given
+	nsEnd := util.BytesPrefix(db.namespace).Limit
+	if end != nil {
+		nsEnd = db.key(end)
+	}
+	return &namespacedIterator{
+		Iterator:  db.Database.NewIteratorWithRange(db.key(start), nsEnd),
+		namespace: db.namespace,
+	}
+}
+
+// namespacedBatch prepends the namespace of its database to the keys written.
+type namespacedBatch struct {
+	Batch
+	db *namespacedDB
+}
+
+func (b *namespacedBatch) Put(key, value []byte) error {
+	return b.Batch.Put(b.db.key(key), value)
+}
+
+func (b *namespacedBatch) Delete(key []byte) error {
+	return b.Batch.Delete(b.db.key(key))
+}
+
+// Replay replays the batch contents with the keys without the namespace.
+func (b *namespacedBatch) Replay(w KeyValueWriter) error {
+	return b.Batch.Replay(&namespaceStripper{w: w, namespace: b.db.namespace})
+}
+
+// namespaceStripper removes the namespace from the keys written to w.
+type namespaceStripper struct {
+	w         KeyValueWriter
+	namespace []byte
+}
+
+func (s *namespaceStripper) Put(key []byte, value []byte) error {
+	return s.w.Put(key[len(s.namespace):], value)
+}
+
+func (s *namespaceStripper) Delete(key []byte) error {
+	return s.w.Delete(key[len(s.namespace):])
+}
+
+// namespacedIterator presents the keys of the underlying iterator without the
+// namespace. The underlying iterator only returns the keys in the namespace,
+// because the namespace is a part of its prefix.
+type namespacedIterator struct {
+	Iterator
+	namespace []byte
+}
+
+func (it *namespacedIterator) Key() []byte {
+	key := it.Iterator.Key()
+	if len(key) < len(it.namespace) {
+		return nil
+	}
+	return key[len(it.namespace):]
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectIterator(it Iterator) map[string]string {
+	defer it.Release()
+	entries := make(map[string]string)
+	for it.Next() {
+		entries[string(it.Key())] = string(it.Value())
+	}
+	return entries
+}
+
+func TestNamespacedDB_Isolation(t *testing.T) {
+	mem := NewMemDB()
+	mainnet := NewNamespacedDatabase(mem, []byte("mainnet/"))
+	testnet := NewNamespacedDatabase(mem, []byte("testnet/"))
+
+	assert.NoError(t, mainnet.Put([]byte("key"), []byte("mainnet-val")))
+	assert.NoError(t, testnet.Put([]byte("key"), []byte("testnet-val")))
+	assert.NoError(t, mainnet.Put([]byte("only-mainnet"), []byte("val")))
+
+	// the same key is stored separately in the physical database
+	assert.Equal(t, 3, mem.Len())
+	val, err := mem.Get([]byte("mainnet/key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("mainnet-val"), val)
+
+	val, err = mainnet.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("mainnet-val"), val)
+	val, err = testnet.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("testnet-val"), val)
+
+	has, err := testnet.Has([]byte("only-mainnet"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+	_, err = testnet.Get([]byte("only-mainnet"))
+	assert.Equal(t, dataNotFoundErr, err)
+
+	// deleting a key doesn't affect the other namespace
+	assert.NoError(t, testnet.Delete([]byte("key")))
+	has, err = testnet.Has([]byte("key"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+	has, err = mainnet.Has([]byte("key"))
+	assert.NoError(t, err)
+	assert.True(t, has)
+}
+
+func TestNamespacedDB_Iterator(t *testing.T) {
+	mem := NewMemDB()
+	a := NewNamespacedDatabase(mem, []byte("a/"))
+	b := NewNamespacedDatabase(mem, []byte("b/"))
+
+	assert.NoError(t, mem.Put([]byte("no-namespace"), []byte("x")))
+	for _, key := range []string{"p1", "p2", "q1"} {
+		assert.NoError(t, a.Put([]byte(key), []byte("a-"+key)))
+		assert.NoError(t, b.Put([]byte(key), []byte("b-"+key)))
+	}
+
+	assert.Equal(t, map[string]string{"p1": "a-p1", "p2": "a-p2", "q1": "a-q1"}, collectIterator(a.NewIterator(nil, nil)))
+	assert.Equal(t, map[string]string{"p1": "b-p1", "p2": "b-p2"}, collectIterator(b.NewIterator([]byte("p"), nil)))
+	assert.Equal(t, map[string]string{"p2": "b-p2", "q1": "b-q1"}, collectIterator(b.NewIterator(nil, []byte("p2"))))
+	assert.Empty(t, collectIterator(a.NewIterator([]byte("r"), nil)))
+}
+
+func TestNamespacedDB_Batch(t *testing.T) {
+	mem := NewMemDB()
+	a := NewNamespacedDatabase(mem, []byte("a/"))
+	b := NewNamespacedDatabase(mem, []byte("b/"))
+	assert.NoError(t, b.Put([]byte("k1"), []byte("b-v1")))
+
+	batch := a.NewBatch()
+	assert.NoError(t, batch.Put([]byte("k1"), []byte("v1")))
+	assert.NoError(t, batch.Put([]byte("k2"), []byte("v2")))
+	assert.NoError(t, batch.Delete([]byte("k2")))
+	assert.NoError(t, batch.Write())
+
+	assert.Equal(t, map[string]string{"k1": "v1"}, collectIterator(a.NewIterator(nil, nil)))
+	assert.Equal(t, map[string]string{"k1": "b-v1"}, collectIterator(b.NewIterator(nil, nil)))
+
+	// replaying the batch writes the keys without the namespace
+	replayed := NewMemDB()
+	assert.NoError(t, batch.Replay(replayed))
+	assert.Equal(t, map[string]string{"k1": "v1"}, collectIterator(replayed.NewIterator(nil, nil)))
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/klaytn/klaytn/rlp"
+)
+
+// An RLP dump is a stream of the key-value pairs of a database, followed by
+// the checksum of the whole stream.
+//
+//	dump     = entry* checksum
+//	entry    = RLP([key, value])
+//	checksum = RLP(SHA-256 of all the preceding bytes)
+//
+// Each entry is a list and the checksum is a string, so that the end of the
+// entries is known without any additional marker.
+
+var (
+	errRLPDumpTruncated        = errors.New("rlp dump is truncated")
+	errRLPDumpChecksumMismatch = errors.New("rlp dump checksum mismatch")
+	errRLPDumpTrailingData     = errors.New("rlp dump has trailing data after the checksum")
+)
+
+type rlpDumpEntry struct {
+	Key []byte
+	Val []byte
+}
+
+// hashingReader hashes the bytes read from the underlying reader. It is a
+// io.ByteReader, so that rlp.Stream does not read ahead of the decoded values.
+type hashingReader struct {
+	r *bufio.Reader
+	h hash.Hash
+}
+
+func (hr *hashingReader) Read(b []byte) (int, error) {
+	n, err := hr.r.Read(b)
+	hr.h.Write(b[:n])
+	return n, err
+}
+
+func (hr *hashingReader) ReadByte() (byte, error) {
+	c, err := hr.r.ReadByte()
+	if err == nil {
+		hr.h.Write([]byte{c})
+	}
+	return c, err
+}
+
+// verifyRLPDump reads the whole dump and checks its structure and checksum.
+// It returns the number of entries in the dump.
+func verifyRLPDump(r io.Reader) (int, error) {
+	hr := &hashingReader{r: bufio.NewReader(r), h: sha256.New()}
+	s := rlp.NewStream(hr, 0)
+
+	for entries := 0; ; entries++ {
+		// the checksum covers the bytes before its own encoding
+		sum := hr.h.Sum(nil)
+
+		kind, _, err := s.Kind()
+		if err == io.EOF {
+			return 0, fmt.Errorf("%w: missing checksum after %d entries", errRLPDumpTruncated, entries)
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to read rlp dump entry %d: %w", entries, err)
+		}
+
+		if kind == rlp.List {
+			var entry rlpDumpEntry
+			if err := s.Decode(&entry); err != nil {
+				if err == io.ErrUnexpectedEOF {
+					err = errRLPDumpTruncated
+				}
+				return 0, fmt.Errorf("failed to read rlp dump entry %d: %w", entries, err)
+			}
+			continue
+		}
+
+		checksum, err := s.Bytes()
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = errRLPDumpTruncated
+			}
+			return 0, fmt.Errorf("failed to read rlp dump checksum: %w", err)
+		}
+		if !bytes.Equal(checksum, sum) {
+			return 0, fmt.Errorf("%w: expected %x, computed %x", errRLPDumpChecksumMismatch, checksum, sum)
+		}
+		if _, err := hr.r.ReadByte(); err != io.EOF {
+			return 0, errRLPDumpTrailingData
+		}
+		return entries, nil
+	}
+}
+
+// ImportRLPDump writes the key-value pairs of an RLP dump into the database.
+// The whole dump is verified before anything is written, so that a truncated
+// or corrupted dump is not partially imported. It returns the number of
+// imported entries.
+func ImportRLPDump(r io.ReadSeeker, db Database) (int, error) {
+	start := time.Now()
+	entries, err := verifyRLPDump(r)
+	if err != nil {
+		return 0, err
+	}
+	logger.Info("Verified rlp dump", "entries", entries, "elapsed", time.Since(start))
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	s := rlp.NewStream(bufio.NewReader(r), 0)
+	batch := db.NewBatch()
+	defer batch.Release()
+
+	for imported := 0; imported < entries; imported++ {
+		var entry rlpDumpEntry
+		if err := s.Decode(&entry); err != nil {
+			return imported, fmt.Errorf("failed to read rlp dump entry %d: %w", imported, err)
+		}
+		// oversized values of DynamoDB are stored in S3 by the batch
+		if err := batch.Put(entry.Key, entry.Val); err != nil {
+			return imported, fmt.Errorf("failed to put rlp dump entry %d: %w", imported, err)
+		}
+		if batch.ValueSize() > IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return imported, err
+			}
+			batch.Reset()
+		}
+		if imported%reportCycle == 0 && imported > 0 {
+			logger.Info("Importing rlp dump", "imported", imported, "entries", entries, "elapsed", time.Since(start))
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return entries, err
+	}
+	logger.Info("Imported rlp dump", "entries", entries, "elapsed", time.Since(start))
+	return entries, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The klaytn Authors
+// This file is part of the klaytn library.
+//
+// The klaytn library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The klaytn library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the klaytn library. If not, see <http://www.gnu.org/licenses/>.
+
+package database
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+// encodeRLPDump encodes the entries into an RLP dump with a valid checksum.
+func encodeRLPDump(t *testing.T, entries []rlpDumpEntry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		assert.NoError(t, rlp.Encode(&buf, &entry))
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	assert.NoError(t, rlp.Encode(&buf, sum[:]))
+	return buf.Bytes()
+}
+
+func testRLPDumpEntries() []rlpDumpEntry {
+	entries := make([]rlpDumpEntry, 0, 100)
+	for i := 0; i < 99; i++ {
+		entries = append(entries, rlpDumpEntry{Key: common.MakeRandomBytes(32), Val: common.MakeRandomBytes(100)})
+	}
+	// an oversized value is written by a batch of its own
+	return append(entries, rlpDumpEntry{Key: common.MakeRandomBytes(32), Val: common.MakeRandomBytes(IdealBatchSize + 1)})
+}
+
+func TestImportRLPDump(t *testing.T) {
+	entries := testRLPDumpEntries()
+	db := NewMemDB()
+
+	imported, err := ImportRLPDump(bytes.NewReader(encodeRLPDump(t, entries)), db)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), imported)
+	assert.Equal(t, len(entries), db.Len())
+	for _, entry := range entries {
+		val, err := db.Get(entry.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, entry.Val, val)
+	}
+
+	// an empty dump only has the checksum
+	imported, err = ImportRLPDump(bytes.NewReader(encodeRLPDump(t, nil)), NewMemDB())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, imported)
+}
+
+func TestImportRLPDump_Truncated(t *testing.T) {
+	dump := encodeRLPDump(t, testRLPDumpEntries())
+
+	for _, size := range []int{
+		0,                     // empty
+		len(dump) - 33,        // without the checksum
+		len(dump) - 1,         // in the middle of the checksum
+		(len(dump) - 33) / 2,  // in the middle of the entries
+		len(dump) - 33 - 1000, // in the middle of the oversized value
+	} {
+		db := NewMemDB()
+		_, err := ImportRLPDump(bytes.NewReader(dump[:size]), db)
+		assert.ErrorIs(t, err, errRLPDumpTruncated, "size %d", size)
+		assert.Equal(t, 0, db.Len(), "size %d", size)
+	}
+}
+
+func TestImportRLPDump_Corrupted(t *testing.T) {
+	entries := testRLPDumpEntries()
+
+	// a value of an entry is modified after the checksum is computed
+	dump := encodeRLPDump(t, entries)
+	idx := bytes.Index(dump, entries[50].Val)
+	dump[idx] ^= 0xff
+
+	db := NewMemDB()
+	_, err := ImportRLPDump(bytes.NewReader(dump), db)
+	assert.ErrorIs(t, err, errRLPDumpChecksumMismatch)
+	assert.Equal(t, 0, db.Len())
+
+	// the checksum itself is modified
+	dump = encodeRLPDump(t, entries)
+	dump[len(dump)-1] ^= 0xff
+	_, err = ImportRLPDump(bytes.NewReader(dump), db)
+	assert.ErrorIs(t, err, errRLPDumpChecksumMismatch)
+	assert.Equal(t, 0, db.Len())
+
+	// data follows the checksum
+	dump = append(encodeRLPDump(t, entries), 0x80)
+	_, err = ImportRLPDump(bytes.NewReader(dump), db)
+	assert.ErrorIs(t, err, errRLPDumpTrailingData)
+	assert.Equal(t, 0, db.Len())
+}
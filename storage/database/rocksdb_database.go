@@ -291,13 +291,20 @@ func (db *rocksDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return &rdbIter{first: true, iter: iter, prefix: prefix, db: db}
 }
 
-func (db *rocksDB) Close() {
+// NewIteratorWithRange creates a binary-alphabetical iterator over the keys in
+// [start, end).
+func (db *rocksDB) NewIteratorWithRange(start, end []byte) Iterator {
+	return newRangeIterator(db.NewIterator(nil, start), end)
+}
+
+func (db *rocksDB) Close() error {
 	close(db.quitCh)
 	db.db.CancelAllBackgroundWork(true)
 	db.db.Close()
 	db.wo.Destroy()
 	db.ro.Destroy()
 	db.logger.Info("RocksDB is closed")
+	return nil
 }
 
 func (db *rocksDB) updateMeter(name string, meter metrics.Meter) {
@@ -349,6 +356,11 @@ func (db *rocksDB) NewBatch() Batch {
 	return &rdbBatch{b: grocksdb.NewWriteBatch(), db: db}
 }
 
+// NewBatchWithSize is the same as NewBatch since the size hint is not supported.
+func (db *rocksDB) NewBatchWithSize(n int) Batch {
+	return db.NewBatch()
+}
+
 // rdbBatch is a write-only rocksdb batch that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type rdbBatch struct {
@@ -26,16 +26,25 @@ package database
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/klaytn/klaytn/common/hexutil"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/klaytn/klaytn/log"
 )
 
@@ -45,22 +54,201 @@ type s3FileDB struct {
 	region   string
 	endpoint string
 	bucket   string
+	session  *session.Session
 	s3       *s3.S3
 	logger   log.Logger
+
+	deriveKey      S3KeyDeriver       // derives the key of an S3 object from the key of an item
+	keyPrefix      string             // prepended to the keys of all objects, which can be empty
+	regionRedirect bool               // retries the bucket operations with the region expected by the server
+	logAllRequests bool               // logs the request IDs of all calls, not only failed ones
+	awsLogLevel    aws.LogLevelType   // the log level of the AWS SDK itself
+	resolver       endpoints.Resolver // resolves the endpoint if it is not given, which can be nil
+
+	compressionThreshold int // values larger than it are gzip-compressed. 0 disables the compression
+	multipartThreshold   int // values larger than it are written by multipart upload. 0 disables the multipart upload
+
+	readMaxRetries  int // the maximum number of retries of reading an object
+	writeMaxRetries int // the maximum number of retries of writing or deleting an object
+
+	sse         string // the server-side encryption of the objects, the default of the bucket if empty
+	sseKMSKeyID string // the KMS key of the "aws:kms" encryption, the AWS managed key if empty
+}
+
+// Reads are retried more than writes by default, since they are always safe to
+// retry and a failed read of an oversized item fails the block processing.
+const (
+	defaultS3ReadMaxRetries  = 40
+	defaultS3WriteMaxRetries = dynamoMaxRetry
+)
+
+// s3MultipartPartSize is the size of the parts of a multipart upload, which is
+// the minimum allowed by S3. The parts are uploaded in parallel.
+const s3MultipartPartSize = int(s3manager.MinUploadPartSize)
+
+// defaultS3MultipartThreshold is the default size above which the values are
+// written by multipart upload, so that a failed part of a large value is
+// retried alone instead of the whole value.
+const defaultS3MultipartThreshold = s3MultipartPartSize
+
+// s3ContentEncodingGzip is the content encoding of the gzip-compressed objects.
+const s3ContentEncodingGzip = "gzip"
+
+// s3ErrCodeInvalidRange is the error code of S3 for a range not within the object.
+const s3ErrCodeInvalidRange = "InvalidRange"
+
+// s3BucketRegionHeader is the header of the region of a bucket, which S3 returns
+// even if a HeadBucket request fails due to a region mismatch.
+const s3BucketRegionHeader = "X-Amz-Bucket-Region"
+
+// errS3BucketAccessDenied is returned if the credentials have no permission on
+// the bucket, which requires s3:ListBucket to check its existence.
+var errS3BucketAccessDenied = errors.New("access to the S3 bucket is denied")
+
+// s3RegionMismatchError is returned by a bucket operation sent to a region other
+// than the one of the bucket, which is given by the server in a header.
+type s3RegionMismatchError struct {
+	region string
+	err    error
+}
+
+func (e *s3RegionMismatchError) Error() string {
+	return fmt.Sprintf("the S3 bucket is in the region %s: %v", e.region, e.err)
+}
+
+func (e *s3RegionMismatchError) Unwrap() error {
+	return e.err
+}
+
+// S3KeyDeriver derives the key of an S3 object from the key of an item. The same
+// deriver must be used for reading, writing and deleting the items.
+type S3KeyDeriver func(key []byte) string
+
+// defaultS3KeyDeriver encodes the key of an item in hex, since the key is
+// already a content hash in most cases.
+func defaultS3KeyDeriver(key []byte) string {
+	return hexutil.Encode(key)
+}
+
+// objectKey returns the key of the S3 object storing the item with the given key.
+func (s3DB *s3FileDB) objectKey(key []byte) string {
+	return s3DB.keyPrefix + s3DB.deriveKey(key)
+}
+
+// s3FileDBOption is an optional configuration of s3FileDB.
+type s3FileDBOption func(*s3FileDB)
+
+// withS3KeyDeriver sets the key deriver of s3FileDB. If it is nil, the default
+// deriver is used.
+func withS3KeyDeriver(deriver S3KeyDeriver) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		if deriver != nil {
+			s3DB.deriveKey = deriver
+		}
+	}
+}
+
+// withS3KeyPrefix sets the prefix of the keys of all objects written, read and
+// deleted by s3FileDB.
+func withS3KeyPrefix(prefix string) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.keyPrefix = prefix
+	}
+}
+
+// withS3RegionRedirect allows s3FileDB to switch to the region expected by the
+// server if the bucket operations fail due to a region mismatch.
+func withS3RegionRedirect(allow bool) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.regionRedirect = allow
+	}
+}
+
+// withS3RequestLogging makes s3FileDB log the request IDs of all calls at debug
+// level. The request IDs of failed calls are always logged.
+func withS3RequestLogging(logAll bool) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.logAllRequests = logAll
+	}
+}
+
+// withS3AWSLogLevel enables the logs of the AWS SDK for the S3 calls.
+func withS3AWSLogLevel(level aws.LogLevelType) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.awsLogLevel = level
+	}
+}
+
+// withS3EndpointResolver makes s3FileDB resolve the endpoint of S3 by the given
+// resolver if the endpoint is not given. If it is nil, the default resolver is used.
+func withS3EndpointResolver(resolver endpoints.Resolver) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.resolver = resolver
+	}
+}
+
+// withS3CompressionThreshold makes s3FileDB gzip-compress the values larger than
+// the given threshold. The compression is disabled if it is 0.
+func withS3CompressionThreshold(threshold int) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.compressionThreshold = threshold
+	}
+}
+
+// withS3MultipartThreshold makes s3FileDB write the values larger than the
+// given threshold by multipart upload. The multipart upload is disabled if it is 0.
+func withS3MultipartThreshold(threshold int) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.multipartThreshold = threshold
+	}
+}
+
+// withS3MaxRetries sets the maximum number of retries of reading and writing
+// an object. The default value is used for 0.
+func withS3MaxRetries(read, write int) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		if read > 0 {
+			s3DB.readMaxRetries = read
+		}
+		if write > 0 {
+			s3DB.writeMaxRetries = write
+		}
+	}
+}
+
+// withS3ServerSideEncryption makes s3FileDB write the objects with the given
+// server-side encryption and KMS key. The objects are encrypted by the default
+// of the bucket if sse is empty.
+func withS3ServerSideEncryption(sse, kmsKeyID string) s3FileDBOption {
+	return func(s3DB *s3FileDB) {
+		s3DB.sse, s3DB.sseKMSKeyID = sse, kmsKeyID
+	}
+}
+
+// newS3Retryer returns the retryer of S3 requests with the given maximum number of retries.
+func newS3Retryer(maxRetries int) CustomRetryer {
+	return CustomRetryer{
+		DefaultRetryer: client.DefaultRetryer{
+			NumMaxRetries:    maxRetries,
+			MaxRetryDelay:    time.Second,
+			MaxThrottleDelay: time.Second,
+		},
+	}
+}
+
+// withMaxRetries returns a request option overriding the retryer of the session.
+func withMaxRetries(maxRetries int) request.Option {
+	return func(r *request.Request) {
+		r.Retryer = newS3Retryer(maxRetries)
+	}
 }
 
 // newS3FileDB returns a new s3FileDB with the given region, endpoint and bucketName.
 // If the given bucket does not exist, it creates one.
-func newS3FileDB(region, endpoint, bucketName string) (*s3FileDB, error) {
+func newS3FileDB(region, endpoint, bucketName string, opts ...s3FileDBOption) (*s3FileDB, error) {
 	localLogger := logger.NewWith("endpoint", endpoint, "bucketName", bucketName)
 	sessionConf, err := session.NewSession(&aws.Config{
-		Retryer: CustomRetryer{
-			DefaultRetryer: client.DefaultRetryer{
-				NumMaxRetries:    dynamoMaxRetry,
-				MaxRetryDelay:    time.Second,
-				MaxThrottleDelay: time.Second,
-			},
-		},
+		Retryer:          newS3Retryer(dynamoMaxRetry),
 		Region:           aws.String(region),
 		Endpoint:         aws.String(endpoint),
 		S3ForcePathStyle: aws.Bool(true),
@@ -71,14 +259,31 @@ func newS3FileDB(region, endpoint, bucketName string) (*s3FileDB, error) {
 	}
 
 	s3DB := &s3FileDB{
-		region:   region,
-		endpoint: endpoint,
-		bucket:   bucketName,
-		s3:       s3.New(sessionConf),
-		logger:   localLogger,
+		region:    region,
+		endpoint:  endpoint,
+		bucket:    bucketName,
+		session:   sessionConf,
+		logger:    localLogger,
+		deriveKey: defaultS3KeyDeriver,
+
+		readMaxRetries:  defaultS3ReadMaxRetries,
+		writeMaxRetries: defaultS3WriteMaxRetries,
 	}
+	for _, opt := range opts {
+		opt(s3DB)
+	}
+	setAWSLogLevel(sessionConf.Config, s3DB.awsLogLevel, localLogger)
+	if s3DB.resolver != nil {
+		sessionConf.Config.EndpointResolver = s3DB.resolver
+	}
+	sessionConf.Handlers.Complete.PushBackNamed(awsRequestLogger(localLogger, s3DB.logAllRequests))
+	s3DB.s3 = s3.New(sessionConf)
 
-	exist, err := s3DB.hasBucket(bucketName)
+	var exist bool
+	err = s3DB.redirectRegion(func() error {
+		exist, err = s3DB.hasBucket(bucketName)
+		return err
+	})
 	if err != nil {
 		localLogger.Error("failed to retrieve a bucket list", "err", err)
 		return nil, err
@@ -86,8 +291,11 @@ func newS3FileDB(region, endpoint, bucketName string) (*s3FileDB, error) {
 
 	if !exist {
 		localLogger.Warn("creating a S3 bucket. You will be CHARGED until the bucket is deleted")
-		_, err = s3DB.s3.CreateBucket(&s3.CreateBucketInput{
-			Bucket: aws.String(bucketName),
+		err = s3DB.redirectRegion(func() error {
+			_, err := s3DB.s3.CreateBucket(&s3.CreateBucketInput{
+				Bucket: aws.String(bucketName),
+			})
+			return err
 		})
 		if err != nil {
 			localLogger.Error("failed to create a bucket", "err", err)
@@ -98,68 +306,339 @@ func newS3FileDB(region, endpoint, bucketName string) (*s3FileDB, error) {
 	return s3DB, nil
 }
 
-// hasBucket returns if the bucket exists in the endpoint of s3FileDB.
-func (s3DB *s3FileDB) hasBucket(bucketName string) (bool, error) {
-	output, err := s3DB.s3.ListBuckets(&s3.ListBucketsInput{})
-	if err != nil {
-		return false, err
+// expectedRegionPattern matches the region expected by the server in the
+// message of AuthorizationHeaderMalformed error, which is like "the region
+// 'us-east-1' is wrong; expecting 'ap-northeast-2'".
+var expectedRegionPattern = regexp.MustCompile(`expecting '([a-zA-Z0-9-]+)'`)
+
+// expectedRegion returns the region expected by the server if the error is
+// caused by a region mismatch. Otherwise, it returns an empty string.
+func expectedRegion(err error) string {
+	var mismatch *s3RegionMismatchError
+	if errors.As(err, &mismatch) {
+		return mismatch.region
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != "AuthorizationHeaderMalformed" {
+		return ""
+	}
+	if match := expectedRegionPattern.FindStringSubmatch(aerr.Message()); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// redirectRegion runs the given bucket operation. If the operation fails due to
+// a region mismatch and the region redirect is allowed, it switches to the
+// region expected by the server and runs the operation once more.
+func (s3DB *s3FileDB) redirectRegion(op func() error) error {
+	err := op()
+	if err == nil || !s3DB.regionRedirect {
+		return err
+	}
+	region := expectedRegion(err)
+	if region == "" || region == s3DB.region {
+		return err
 	}
+	s3DB.logger.Warn("switching S3 region to the one expected by the server", "configured", s3DB.region, "expected", region)
+	s3DB.region = region
+	s3DB.s3 = s3.New(s3DB.session, aws.NewConfig().WithRegion(region))
+	return op()
+}
 
-	bucketExist := false
-	for _, bucket := range output.Buckets {
-		if bucketName == *bucket.Name {
-			bucketExist = true
-			break
+// hasBucket returns if the bucket exists in the endpoint of s3FileDB. It sends
+// a HeadBucket request on the bucket, which requires no permission on the other
+// buckets of the account unlike ListBuckets.
+func (s3DB *s3FileDB) hasBucket(bucketName string) (bool, error) {
+	req, _ := s3DB.s3.HeadBucketRequest(&s3.HeadBucketInput{Bucket: aws.String(bucketName)})
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		// neither a missing bucket, a denied access nor a region mismatch is
+		// resolved by retries
+		if r.HTTPResponse == nil {
+			return
+		}
+		status := r.HTTPResponse.StatusCode
+		if status == http.StatusNotFound || status == http.StatusForbidden || s3DB.mismatchedRegion(r.HTTPResponse) != "" {
+			r.Retryable = aws.Bool(false)
+		}
+	})
+	err := req.Send()
+	if err == nil {
+		return true, nil
+	}
+	if req.HTTPResponse != nil {
+		if region := s3DB.mismatchedRegion(req.HTTPResponse); region != "" {
+			return false, &s3RegionMismatchError{region: region, err: err}
+		}
+	}
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		switch reqErr.StatusCode() {
+		case http.StatusNotFound:
+			return false, nil
+		case http.StatusForbidden:
+			return false, fmt.Errorf("%w: %s (%v)", errS3BucketAccessDenied, bucketName, err)
 		}
 	}
-	return bucketExist, nil
+	return false, err
+}
+
+// mismatchedRegion returns the region of the bucket given by the response if it
+// differs from the region of s3FileDB. The response of HeadBucket has no body,
+// so the region is given only by the header. Otherwise, it returns an empty string.
+func (s3DB *s3FileDB) mismatchedRegion(resp *http.Response) string {
+	if region := resp.Header.Get(s3BucketRegionHeader); region != s3DB.region {
+		return region
+	}
+	return ""
 }
 
 // write puts list of items to its bucket and returns the list of URIs.
 func (s3DB *s3FileDB) write(item item) (string, error) {
+	objectKey := s3DB.objectKey(item.key)
+	compress := s3DB.compressionThreshold > 0 && len(item.val) > s3DB.compressionThreshold
+
+	if s3DB.multipartThreshold > 0 && len(item.val) > s3DB.multipartThreshold {
+		if err := s3DB.upload(objectKey, item.val, compress); err != nil {
+			return "", fmt.Errorf("failed to upload item to S3. key: %v, err: %w", string(item.key), err)
+		}
+		return objectKey, nil
+	}
+
 	o := &s3.PutObjectInput{
 		Bucket:      aws.String(s3DB.bucket),
-		Key:         aws.String(hexutil.Encode(item.key)),
+		Key:         aws.String(objectKey),
 		Body:        bytes.NewReader(item.val),
 		ContentType: aws.String("application/octet-stream"),
 	}
+	o.ServerSideEncryption, o.SSEKMSKeyId = s3DB.encryption()
+
+	// only the largest values are compressed, not to pay the CPU on every spill
+	if compress {
+		compressed, err := gzipCompress(item.val)
+		if err != nil {
+			return "", fmt.Errorf("failed to compress item. key: %v, err: %w", string(item.key), err)
+		}
+		o.Body = bytes.NewReader(compressed)
+		o.ContentEncoding = aws.String(s3ContentEncodingGzip)
+	}
 
-	if _, err := s3DB.s3.PutObject(o); err != nil {
+	if _, err := s3DB.s3.PutObjectWithContext(aws.BackgroundContext(), o, withMaxRetries(s3DB.writeMaxRetries)); err != nil {
 		return "", fmt.Errorf("failed to write item to S3. key: %v, err: %w", string(item.key), err)
 	}
 
-	return hexutil.Encode(item.key), nil
+	return objectKey, nil
+}
+
+// upload writes the value by multipart upload, which sends the parts in
+// parallel and isn't limited to 5GB like a single PutObject. The compressed
+// value is streamed to the uploader part by part, not to hold the whole
+// compressed copy of the value in memory.
+func (s3DB *s3FileDB) upload(objectKey string, val []byte, compress bool) error {
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(s3DB.bucket),
+		Key:         aws.String(objectKey),
+		Body:        bytes.NewReader(val),
+		ContentType: aws.String("application/octet-stream"),
+	}
+	input.ServerSideEncryption, input.SSEKMSKeyId = s3DB.encryption()
+	if compress {
+		pr, pw := io.Pipe()
+		// closing the reader stops the compression if the upload fails
+		defer pr.Close()
+		go func() {
+			gw := gzip.NewWriter(pw)
+			_, err := gw.Write(val)
+			if err == nil {
+				err = gw.Close()
+			}
+			pw.CloseWithError(err)
+		}()
+		input.Body = pr
+		input.ContentEncoding = aws.String(s3ContentEncodingGzip)
+	}
+
+	// the uploader is created on every upload, since the client may be replaced by the region redirect
+	uploader := s3manager.NewUploaderWithClient(s3DB.s3, func(u *s3manager.Uploader) {
+		u.PartSize = int64(s3MultipartPartSize)
+		u.RequestOptions = append(u.RequestOptions, withMaxRetries(s3DB.writeMaxRetries))
+	})
+	_, err := uploader.UploadWithContext(aws.BackgroundContext(), input)
+	return err
+}
+
+// encryption returns the server-side encryption and the KMS key of the written
+// objects, which are nil if they are not set.
+func (s3DB *s3FileDB) encryption() (*string, *string) {
+	var sse, kmsKeyID *string
+	if s3DB.sse != "" {
+		sse = aws.String(s3DB.sse)
+	}
+	if s3DB.sseKMSKeyID != "" {
+		kmsKeyID = aws.String(s3DB.sseKMSKeyID)
+	}
+	return sse, kmsKeyID
 }
 
 // read gets the data from the bucket with the given key.
 func (s3DB *s3FileDB) read(key []byte) ([]byte, error) {
-	output, err := s3DB.s3.GetObject(&s3.GetObjectInput{
+	return s3DB.readContext(aws.BackgroundContext(), key)
+}
+
+// readContext gets the data from the bucket with the given key within the
+// deadline of ctx.
+func (s3DB *s3FileDB) readContext(ctx context.Context, key []byte) ([]byte, error) {
+	output, err := s3DB.s3.GetObjectWithContext(ctx, &s3.GetObjectInput{
 		Bucket:              aws.String(s3DB.bucket),
-		Key:                 aws.String(hexutil.Encode(key)),
+		Key:                 aws.String(s3DB.objectKey(key)),
 		ResponseContentType: aws.String("application/octet-stream"),
-	})
+	}, withMaxRetries(s3DB.readMaxRetries))
 	if err != nil {
 		return nil, err
 	}
 
-	returnVal, err := io.ReadAll(output.Body)
+	defer output.Body.Close()
+
+	// the HTTP client may have already decompressed the body, removing the content encoding
+	body, size := io.Reader(output.Body), aws.Int64Value(output.ContentLength)
+	if aws.StringValue(output.ContentEncoding) == s3ContentEncodingGzip {
+		gr, err := gzip.NewReader(output.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		body, size = gr, 0
+	}
+	return readBody(body, size)
+}
+
+// readBody reads r to the end. If the size of r is known, the buffer is
+// allocated once for it instead of being grown while reading. The size is
+// only a hint, and the bytes beyond it are also read.
+func readBody(r io.Reader, size int64) ([]byte, error) {
+	if size <= 0 {
+		return io.ReadAll(r)
+	}
+	// bytes.Buffer grows unless MinRead bytes are left to read the EOF
+	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readRange gets length bytes of the data from offset with the Range header.
+// A compressed object is read entirely and sliced, since the range is applied
+// to the compressed bytes, which may also be shorter than the range.
+func (s3DB *s3FileDB) readRange(key []byte, offset, length int64) ([]byte, error) {
+	if err := checkFileRange(offset, length); err != nil {
+		return nil, err
+	}
+	output, err := s3DB.s3.GetObjectWithContext(aws.BackgroundContext(), &s3.GetObjectInput{
+		Bucket:              aws.String(s3DB.bucket),
+		Key:                 aws.String(s3DB.objectKey(key)),
+		Range:               aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+		ResponseContentType: aws.String("application/octet-stream"),
+	}, withMaxRetries(s3DB.readMaxRetries))
+	if err != nil {
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != s3ErrCodeInvalidRange {
+			return nil, err
+		}
+		// the offset is beyond the stored bytes, which are compressed or shorter than the offset
+		return s3DB.readSlice(key, offset, length)
+	}
+	defer output.Body.Close()
+
+	if aws.StringValue(output.ContentEncoding) == s3ContentEncodingGzip {
+		return s3DB.readSlice(key, offset, length)
+	}
+	return readBody(output.Body, aws.Int64Value(output.ContentLength))
+}
+
+// readSlice reads the entire data and returns the range of it.
+func (s3DB *s3FileDB) readSlice(key []byte, offset, length int64) ([]byte, error) {
+	val, err := s3DB.read(key)
 	if err != nil {
 		return nil, err
 	}
+	return sliceFileRange(val, offset, length)
+}
 
-	return returnVal, nil
+func gzipCompress(val []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(val); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // delete removes the data with the given key from the bucket.
 // No error is returned if the data with the given key does not exist.
 func (s3DB *s3FileDB) delete(key []byte) error {
-	_, err := s3DB.s3.DeleteObject(&s3.DeleteObjectInput{
+	_, err := s3DB.s3.DeleteObjectWithContext(aws.BackgroundContext(), &s3.DeleteObjectInput{
 		Bucket: aws.String(s3DB.bucket),
-		Key:    aws.String(hexutil.Encode(key)),
-	})
+		Key:    aws.String(s3DB.objectKey(key)),
+	}, withMaxRetries(s3DB.writeMaxRetries))
 	return err
 }
 
+// exists checks the data with the given key by a HeadObject request.
+func (s3DB *s3FileDB) exists(key []byte) (bool, error) {
+	_, err := s3DB.s3.HeadObjectWithContext(aws.BackgroundContext(), &s3.HeadObjectInput{
+		Bucket: aws.String(s3DB.bucket),
+		Key:    aws.String(s3DB.objectKey(key)),
+	}, withMaxRetries(s3DB.readMaxRetries))
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s3DB *s3FileDB) deleteExisting(key []byte) error {
+	exists, err := s3DB.exists(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return dataNotFoundErr
+	}
+	return s3DB.delete(key)
+}
+
+// resetBucket removes all objects and the bucket, and creates an empty bucket
+// with the same name. If the keys are prefixed, it removes only the objects
+// under the prefix, since the bucket can be shared by other tables.
+func (s3DB *s3FileDB) resetBucket() error {
+	bucket := aws.String(s3DB.bucket)
+	input := &s3.ListObjectsInput{Bucket: bucket}
+	if s3DB.keyPrefix != "" {
+		input.Prefix = aws.String(s3DB.keyPrefix)
+	}
+	iter := s3manager.NewDeleteListIterator(s3DB.s3, input)
+	if err := s3manager.NewBatchDeleteWithClient(s3DB.s3).Delete(aws.BackgroundContext(), iter); err != nil {
+		return err
+	}
+	if s3DB.keyPrefix != "" {
+		s3DB.logger.Info("successfully removed the S3 objects under the prefix", "prefix", s3DB.keyPrefix)
+		return nil
+	}
+	if _, err := s3DB.s3.DeleteBucket(&s3.DeleteBucketInput{Bucket: bucket}); err != nil {
+		return err
+	}
+	if _, err := s3DB.s3.CreateBucket(&s3.CreateBucketInput{Bucket: bucket}); err != nil {
+		return err
+	}
+	s3DB.logger.Info("successfully reset the S3 bucket")
+	return nil
+}
+
 // deleteBucket removes the bucket
 func (s3DB *s3FileDB) deleteBucket() {
 	if _, err := s3DB.s3.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(s3DB.bucket)}); err != nil {
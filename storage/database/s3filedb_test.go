@@ -21,13 +21,25 @@ package database
 
 import (
 	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/klaytn/klaytn/common"
+	"github.com/klaytn/klaytn/common/hexutil"
 	"github.com/klaytn/klaytn/storage"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -134,8 +146,744 @@ func (s *SuiteS3FileDB) TestS3FileDB_Overwrite() {
 	s.Equal(len(testVals), len(uris))
 }
 
+func (s *SuiteS3FileDB) TestS3FileDB_Multipart() {
+	testKey := common.MakeRandomBytes(32)
+	testVal := common.MakeRandomBytes(3*s3MultipartPartSize + 1)
+
+	s.s3DB.multipartThreshold = s3MultipartPartSize
+	defer func() { s.s3DB.multipartThreshold = 0 }()
+
+	_, err := s.s3DB.write(item{key: testKey, val: testVal})
+	s.NoError(err)
+	defer s.s3DB.delete(testKey)
+
+	val, err := s.s3DB.read(testKey)
+	s.NoError(err)
+	s.True(bytes.Equal(testVal, val))
+}
+
 func (s *SuiteS3FileDB) TestS3FileDB_EmptyDelete() {
 	testKey := common.MakeRandomBytes(256)
 	s.NoError(s.s3DB.delete(testKey))
 	s.NoError(s.s3DB.delete(testKey))
 }
+
+// newFakeS3Server returns a server which serves the objects of a bucket like S3,
+// including the multipart uploads, the listing and the batch deletion.
+func newFakeS3Server(bucket string) (*httptest.Server, map[string][]byte) {
+	var (
+		mu        sync.Mutex
+		objects   = make(map[string][]byte)
+		encodings = make(map[string]string)
+		uploads   = make(map[string]map[int][]byte) // the parts of the multipart uploads in progress
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodHead && strings.Count(strings.TrimSuffix(r.URL.Path, "/"), "/") == 1 {
+			if strings.TrimSuffix(r.URL.Path, "/") != "/"+bucket {
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/"+bucket+"/")
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet && strings.TrimSuffix(r.URL.Path, "/") == "/"+bucket:
+			fmt.Fprintf(w, `<ListBucketResult><Name>%s</Name><IsTruncated>false</IsTruncated>`, bucket)
+			for k := range objects {
+				if strings.HasPrefix(k, query.Get("prefix")) {
+					fmt.Fprintf(w, `<Contents><Key>%s</Key></Contents>`, k)
+				}
+			}
+			fmt.Fprint(w, `</ListBucketResult>`)
+			return
+		case r.Method == http.MethodPost && query.Has("delete"):
+			var input struct {
+				Objects []struct{ Key string } `xml:"Object"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			xml.Unmarshal(body, &input)
+			fmt.Fprint(w, `<DeleteResult>`)
+			for _, object := range input.Objects {
+				delete(objects, object.Key)
+				delete(encodings, object.Key)
+				fmt.Fprintf(w, `<Deleted><Key>%s</Key></Deleted>`, object.Key)
+			}
+			fmt.Fprint(w, `</DeleteResult>`)
+			return
+		case r.Method == http.MethodPost && query.Has("uploads"):
+			uploadID := fmt.Sprintf("upload-%d", len(uploads))
+			uploads[uploadID] = make(map[int][]byte)
+			encodings[key] = r.Header.Get("Content-Encoding")
+			fmt.Fprintf(w, `<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, bucket, key, uploadID)
+			return
+		case r.Method == http.MethodPut && query.Has("uploadId"):
+			partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+			uploads[query.Get("uploadId")][partNumber], _ = io.ReadAll(r.Body)
+			w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, partNumber))
+			return
+		case r.Method == http.MethodPost && query.Has("uploadId"):
+			parts := uploads[query.Get("uploadId")]
+			var object []byte
+			for i := 1; i <= len(parts); i++ {
+				object = append(object, parts[i]...)
+			}
+			objects[key] = object
+			delete(uploads, query.Get("uploadId"))
+			fmt.Fprintf(w, `<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key></CompleteMultipartUploadResult>`, bucket, key)
+			return
+		case r.Method == http.MethodDelete && query.Has("uploadId"):
+			delete(uploads, query.Get("uploadId"))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[key], _ = io.ReadAll(r.Body)
+			encodings[key] = r.Header.Get("Content-Encoding")
+		case http.MethodGet:
+			val, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+				return
+			}
+			if encodings[key] != "" {
+				w.Header().Set("Content-Encoding", encodings[key])
+			}
+			var first, last int
+			if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &first, &last); err == nil {
+				if first >= len(val) {
+					w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+					fmt.Fprint(w, `<Error><Code>InvalidRange</Code></Error>`)
+					return
+				}
+				if last >= len(val) {
+					last = len(val) - 1
+				}
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", first, last, len(val)))
+				w.WriteHeader(http.StatusPartialContent)
+				val = val[first : last+1]
+			}
+			w.Write(val)
+		case http.MethodDelete:
+			delete(objects, key)
+			delete(encodings, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	return server, objects
+}
+
+func TestS3FileDB_KeyDeriver(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	server, objects := newFakeS3Server("test-bucket")
+	defer server.Close()
+
+	deriver := func(key []byte) string {
+		return "custom-" + hexutil.Encode(key[:4])
+	}
+	s3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket", withS3KeyDeriver(deriver))
+	assert.NoError(t, err)
+
+	// round-trip an oversized value through dynamoDB
+	dynamoItems := make(map[string][]byte)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			dynamoItems[string(input.Item["Key"].B)] = input.Item["Val"].B
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			key := input.Key["Key"].B
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": {B: key},
+				"Val": {B: dynamoItems[string(key)]},
+			}}, nil
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = s3DB
+
+	key := common.MakeRandomBytes(32)
+	val := common.MakeRandomBytes(dynamoWriteSizeLimit + 1)
+	assert.NoError(t, dynamo.Put(key, val))
+
+	// the object is stored with the derived key
+	assert.Equal(t, val, objects[deriver(key)])
+	assert.NotContains(t, objects, hexutil.Encode(key))
+
+	ret, err := dynamo.Get(key)
+	assert.NoError(t, err)
+	assert.Equal(t, val, ret)
+
+	assert.NoError(t, s3DB.delete(key))
+	assert.Empty(t, objects)
+
+	// the default deriver encodes the key in hex
+	s3DB, err = newS3FileDB("us-east-1", server.URL, "test-bucket", withS3KeyDeriver(nil))
+	assert.NoError(t, err)
+	uri, err := s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Encode(key), uri)
+	assert.Equal(t, val, objects[hexutil.Encode(key)])
+}
+
+func TestS3FileDB_KeyPrefix(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	server, objects := newFakeS3Server("shared-bucket")
+	defer server.Close()
+
+	newS3DB := func(table, prefix string) *s3FileDB {
+		config := GetDefaultDynamoDBConfig()
+		config.TableName, config.Region, config.S3Endpoint = table, "us-east-1", server.URL
+		config.S3Bucket, config.S3KeyPrefix = "shared-bucket", prefix
+		s3DB, err := newS3FileDBWithConfig(config)
+		assert.NoError(t, err)
+		assert.Equal(t, "shared-bucket", s3DB.bucket)
+		return s3DB
+	}
+	s3DBA, s3DBB := newS3DB("table-a", "a/"), newS3DB("table-b", "b/")
+
+	// the same key is written by both tables without overwriting each other
+	key := common.MakeRandomBytes(32)
+	valA, valB := common.MakeRandomBytes(100), common.MakeRandomBytes(100)
+	uri, err := s3DBA.write(item{key: key, val: valA})
+	assert.NoError(t, err)
+	assert.Equal(t, "a/"+hexutil.Encode(key), uri)
+	_, err = s3DBB.write(item{key: key, val: valB})
+	assert.NoError(t, err)
+	assert.Equal(t, valA, objects["a/"+hexutil.Encode(key)])
+	assert.Equal(t, valB, objects["b/"+hexutil.Encode(key)])
+
+	ret, err := s3DBA.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, valA, ret)
+	ret, err = s3DBB.readRange(key, 10, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, valB[10:30], ret)
+
+	exists, err := s3DBB.exists(key)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// deleting or resetting one table leaves the objects of the other
+	assert.NoError(t, s3DBA.delete(key))
+	assert.NotContains(t, objects, "a/"+hexutil.Encode(key))
+	assert.Contains(t, objects, "b/"+hexutil.Encode(key))
+
+	_, err = s3DBA.write(item{key: key, val: valA})
+	assert.NoError(t, err)
+	assert.NoError(t, s3DBB.resetBucket())
+	assert.Contains(t, objects, "a/"+hexutil.Encode(key))
+	assert.NotContains(t, objects, "b/"+hexutil.Encode(key))
+
+	// the bucket is named after the table without a prefix by default
+	config := GetDefaultDynamoDBConfig()
+	config.TableName, config.Region, config.S3Endpoint = "shared-bucket", "us-east-1", server.URL
+	s3DB, err := newS3FileDBWithConfig(config)
+	assert.NoError(t, err)
+	uri, err = s3DB.write(item{key: key, val: valA})
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Encode(key), uri)
+}
+
+func TestS3FileDB_HasBucket(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	fake, _ := newFakeS3Server("test-bucket")
+	defer fake.Close()
+
+	var (
+		mu      sync.Mutex
+		denied  bool
+		methods []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		if denied {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	s3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket")
+	assert.NoError(t, err)
+
+	// the bucket is checked by HeadBucket without listing the buckets
+	assert.Equal(t, []string{"HEAD /test-bucket"}, methods)
+
+	exist, err := s3DB.hasBucket("test-bucket")
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	exist, err = s3DB.hasBucket("other-bucket")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// the denied access fails instead of creating the bucket
+	mu.Lock()
+	denied = true
+	mu.Unlock()
+	exist, err = s3DB.hasBucket("test-bucket")
+	assert.ErrorIs(t, err, errS3BucketAccessDenied)
+	assert.False(t, exist)
+
+	_, err = newS3FileDB("us-east-1", server.URL, "test-bucket")
+	assert.ErrorIs(t, err, errS3BucketAccessDenied)
+}
+
+func TestS3FileDB_RegionRedirect(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	fakeS3, objects := newFakeS3Server("test-bucket")
+	defer fakeS3.Close()
+
+	// the server rejects the requests signed for a region other than the expected one
+	var mu sync.Mutex
+	signedRegions := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authorization: AWS4-HMAC-SHA256 Credential=test/20240101/<region>/s3/aws4_request, ...
+		region := strings.Split(r.Header.Get("Authorization"), "/")[2]
+		mu.Lock()
+		signedRegions = append(signedRegions, region)
+		mu.Unlock()
+		if region != "ap-northeast-2" {
+			w.Header().Set(s3BucketRegionHeader, "ap-northeast-2")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `<Error><Code>AuthorizationHeaderMalformed</Code><Message>The authorization header is malformed; the region '%s' is wrong; expecting 'ap-northeast-2'</Message></Error>`, region)
+			return
+		}
+		fakeS3.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	// the region mismatch fails without retries if the redirect is not allowed
+	_, err := newS3FileDB("us-east-1", server.URL, "test-bucket")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"us-east-1"}, signedRegions)
+
+	signedRegions = signedRegions[:0]
+	s3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket", withS3RegionRedirect(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1", "ap-northeast-2"}, signedRegions)
+	assert.Equal(t, "ap-northeast-2", s3DB.region)
+
+	// the object operations use the corrected region
+	key, val := common.MakeRandomBytes(32), common.MakeRandomBytes(100)
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+	assert.Equal(t, val, objects[hexutil.Encode(key)])
+}
+
+func TestS3FileDB_Compression(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	server, objects := newFakeS3Server("test-bucket")
+	defer server.Close()
+
+	threshold := 2 * dynamoWriteSizeLimit
+	s3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket", withS3CompressionThreshold(threshold))
+	assert.NoError(t, err)
+
+	dynamoItems := make(map[string][]byte)
+	defer setTestDynamoDBClient(&stubDynamoDBClient{
+		putItem: func(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+			dynamoItems[string(input.Item["Key"].B)] = input.Item["Val"].B
+			return &dynamodb.PutItemOutput{}, nil
+		},
+		getItem: func(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+			key := input.Key["Key"].B
+			return &dynamodb.GetItemOutput{Item: map[string]*dynamodb.AttributeValue{
+				"Key": {B: key},
+				"Val": {B: dynamoItems[string(key)]},
+			}}, nil
+		},
+	})()
+	dynamo := newStubDynamoDB(GetTestDynamoConfig())
+	dynamo.fdb = s3DB
+
+	// the client which doesn't decompress the responses by itself
+	rawClient := s3.New(s3DB.session, aws.NewConfig().WithHTTPClient(&http.Client{
+		Transport: &http.Transport{DisableCompression: true},
+	}))
+
+	tests := []struct {
+		size       int
+		inS3       bool
+		compressed bool
+	}{
+		{dynamoWriteSizeLimit, false, false},
+		{dynamoWriteSizeLimit + 1, true, false},
+		{threshold, true, false},
+		{threshold + 1, true, true},
+	}
+	for _, tt := range tests {
+		key := common.MakeRandomBytes(32)
+		val := bytes.Repeat([]byte{byte(tt.size)}, tt.size)
+		assert.NoError(t, dynamo.Put(key, val))
+
+		object, inS3 := objects[hexutil.Encode(key)]
+		assert.Equal(t, tt.inS3, inS3, "size %d", tt.size)
+		if inS3 {
+			// a gzip stream starts with the magic number 0x1f8b
+			assert.Equal(t, tt.compressed, bytes.HasPrefix(object, []byte{0x1f, 0x8b}), "size %d", tt.size)
+			assert.Equal(t, tt.compressed, len(object) < tt.size, "size %d", tt.size)
+		}
+
+		ret, err := dynamo.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, val, ret, "size %d", tt.size)
+
+		// the value is decompressed according to the content encoding
+		if inS3 {
+			defaultClient := s3DB.s3
+			s3DB.s3 = rawClient
+			ret, err = s3DB.read(key)
+			s3DB.s3 = defaultClient
+			assert.NoError(t, err)
+			assert.Equal(t, val, ret, "size %d", tt.size)
+		}
+	}
+}
+
+func TestS3FileDB_MaxRetries(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const bucket = "test-bucket"
+	fake, _ := newFakeS3Server(bucket)
+	defer fake.Close()
+
+	// the object requests of each method fail as many times as configured
+	var (
+		mu       sync.Mutex
+		failures = make(map[string]int)
+		attempts = make(map[string]int)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			mu.Lock()
+			attempts[r.Method]++
+			fail := failures[r.Method] > 0
+			if fail {
+				failures[r.Method]--
+			}
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `<Error><Code>InternalError</Code></Error>`)
+				return
+			}
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	setFailures := func(method string, n int) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures[method], attempts[method] = n, 0
+	}
+	getAttempts := func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return attempts[method]
+	}
+
+	s3DB, err := newS3FileDB("us-east-1", server.URL, bucket, withS3MaxRetries(3, 1))
+	assert.NoError(t, err)
+	key, val := []byte("key"), []byte("value")
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+
+	// reads are retried up to the read max retries
+	setFailures(http.MethodGet, 3)
+	ret, err := s3DB.read(key)
+	assert.NoError(t, err)
+	assert.Equal(t, val, ret)
+	assert.Equal(t, 4, getAttempts(http.MethodGet))
+
+	setFailures(http.MethodGet, 4)
+	_, err = s3DB.read(key)
+	assert.Error(t, err)
+	assert.Equal(t, 4, getAttempts(http.MethodGet))
+
+	// writes are retried up to the write max retries
+	setFailures(http.MethodPut, 1)
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, getAttempts(http.MethodPut))
+
+	setFailures(http.MethodPut, 2)
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.Error(t, err)
+	assert.Equal(t, 2, getAttempts(http.MethodPut))
+
+	// the defaults are used if not configured
+	s3DB, err = newS3FileDB("us-east-1", server.URL, bucket, withS3MaxRetries(0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, defaultS3ReadMaxRetries, s3DB.readMaxRetries)
+	assert.Equal(t, defaultS3WriteMaxRetries, s3DB.writeMaxRetries)
+	assert.Greater(t, s3DB.readMaxRetries, s3DB.writeMaxRetries)
+}
+
+func TestS3FileDB_Multipart(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const bucket = "test-bucket"
+	fake, objects := newFakeS3Server(bucket)
+	defer fake.Close()
+
+	// counts the uploaded parts, and the objects written by a single PutObject
+	var (
+		mu         sync.Mutex
+		parts      int
+		putObjects int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			mu.Lock()
+			if r.URL.Query().Has("uploadId") {
+				parts++
+			} else {
+				putObjects++
+			}
+			mu.Unlock()
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	threshold := s3MultipartPartSize
+	s3DB, err := newS3FileDB("us-east-1", server.URL, bucket,
+		withS3MultipartThreshold(threshold), withS3CompressionThreshold(threshold))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		size       int
+		random     bool // random values are not compressed much
+		parts      int
+		putObjects int
+	}{
+		{threshold, true, 0, 1},
+		{3*s3MultipartPartSize + 1, true, 4, 0},
+		// the compressed value fits in a part, so it is written by a single PutObject
+		{3*s3MultipartPartSize + 1, false, 0, 1},
+	}
+	for _, tt := range tests {
+		mu.Lock()
+		parts, putObjects = 0, 0
+		mu.Unlock()
+
+		key := common.MakeRandomBytes(32)
+		val := bytes.Repeat([]byte{1}, tt.size)
+		if tt.random {
+			val = common.MakeRandomBytes(tt.size)
+		}
+		_, err := s3DB.write(item{key: key, val: val})
+		assert.NoError(t, err)
+
+		mu.Lock()
+		assert.Equal(t, tt.parts, parts, "size %d", tt.size)
+		assert.Equal(t, tt.putObjects, putObjects, "size %d", tt.size)
+		mu.Unlock()
+		assert.Contains(t, objects, hexutil.Encode(key))
+
+		ret, err := s3DB.read(key)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(val, ret), "size %d", tt.size)
+	}
+}
+
+func TestS3FileDB_DefaultMultipartThreshold(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const bucket = "test-bucket"
+	fake, _ := newFakeS3Server(bucket)
+	defer fake.Close()
+
+	var (
+		mu    sync.Mutex
+		parts int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Query().Has("uploadId") {
+			mu.Lock()
+			parts++
+			mu.Unlock()
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	config := GetDefaultDynamoDBConfig()
+	config.TableName, config.Region, config.S3Endpoint = bucket, "us-east-1", server.URL
+	s3DB, err := newS3FileDBWithConfig(config)
+	assert.NoError(t, err)
+
+	// the value larger than a part is uploaded in parts by default
+	key, val := common.MakeRandomBytes(32), common.MakeRandomBytes(2*s3MultipartPartSize+1)
+	_, err = s3DB.write(item{key: key, val: val})
+	assert.NoError(t, err)
+	mu.Lock()
+	assert.Equal(t, 3, parts)
+	mu.Unlock()
+
+	ret, err := s3DB.read(key)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(val, ret))
+}
+
+func TestS3FileDB_ServerSideEncryption(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const bucket = "test-bucket"
+	fake, _ := newFakeS3Server(bucket)
+	defer fake.Close()
+
+	// records the encryption headers of the PutObject and CreateMultipartUpload requests
+	var (
+		mu      sync.Mutex
+		headers [][2]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if (r.Method == http.MethodPut && !query.Has("uploadId")) || (r.Method == http.MethodPost && query.Has("uploads")) {
+			mu.Lock()
+			headers = append(headers, [2]string{
+				r.Header.Get("X-Amz-Server-Side-Encryption"),
+				r.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"),
+			})
+			mu.Unlock()
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		sse, kmsKeyID string
+	}{
+		{"", ""},
+		{s3.ServerSideEncryptionAes256, ""},
+		{s3.ServerSideEncryptionAwsKms, "test-key"},
+	}
+	for _, tt := range tests {
+		s3DB, err := newS3FileDB("us-east-1", server.URL, bucket,
+			withS3MultipartThreshold(s3MultipartPartSize), withS3ServerSideEncryption(tt.sse, tt.kmsKeyID))
+		assert.NoError(t, err)
+
+		mu.Lock()
+		headers = nil
+		mu.Unlock()
+
+		// a single PutObject and a multipart upload
+		for _, size := range []int{1024, s3MultipartPartSize + 1} {
+			_, err := s3DB.write(item{key: common.MakeRandomBytes(32), val: common.MakeRandomBytes(size)})
+			assert.NoError(t, err)
+		}
+
+		mu.Lock()
+		assert.Equal(t, [][2]string{{tt.sse, tt.kmsKeyID}, {tt.sse, tt.kmsKeyID}}, headers, "sse %q", tt.sse)
+		mu.Unlock()
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	val := common.MakeRandomBytes(3*bytes.MinRead + 7)
+
+	tests := []struct {
+		name   string
+		reader func() io.Reader
+		size   int64
+	}{
+		{"unknown size", func() io.Reader { return bytes.NewReader(val) }, 0},
+		{"known size", func() io.Reader { return bytes.NewReader(val) }, int64(len(val))},
+		// the last bytes are returned with io.EOF
+		{"data with EOF", func() io.Reader { return iotest.DataErrReader(bytes.NewReader(val)) }, int64(len(val))},
+		{"data with EOF of unknown size", func() io.Reader { return iotest.DataErrReader(bytes.NewReader(val)) }, 0},
+		{"one byte at a time", func() io.Reader { return iotest.OneByteReader(bytes.NewReader(val)) }, int64(len(val))},
+		// the size is a hint
+		{"smaller size", func() io.Reader { return iotest.DataErrReader(bytes.NewReader(val)) }, 10},
+		{"larger size", func() io.Reader { return bytes.NewReader(val) }, int64(2 * len(val))},
+	}
+	for _, tt := range tests {
+		ret, err := readBody(tt.reader(), tt.size)
+		assert.NoError(t, err, tt.name)
+		assert.True(t, bytes.Equal(val, ret), tt.name)
+	}
+
+	errRead := errors.New("connection reset")
+	_, err := readBody(iotest.ErrReader(errRead), int64(len(val)))
+	assert.ErrorIs(t, err, errRead)
+}
+
+func TestFileDB_ReadRange(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	server, _ := newFakeS3Server("test-bucket")
+	defer server.Close()
+
+	s3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket")
+	assert.NoError(t, err)
+	compressedS3DB, err := newS3FileDB("us-east-1", server.URL, "test-bucket", withS3CompressionThreshold(1))
+	assert.NoError(t, err)
+
+	fdbs := map[string]fileDB{
+		"memory":        newStubFileDB(),
+		"s3":            s3DB,
+		"s3-compressed": compressedS3DB,
+	}
+	for name, fdb := range fdbs {
+		key := common.MakeRandomBytes(32)
+		val := bytes.Repeat([]byte("0123456789"), 100)
+		_, err := fdb.write(item{key: key, val: val})
+		assert.NoError(t, err, name)
+
+		tests := []struct {
+			offset, length int64
+			expected       []byte
+		}{
+			{0, 10, val[:10]},
+			{95, 10, val[95:105]},
+			{int64(len(val)) - 1, 1, val[len(val)-1:]},
+			// the range is truncated at the end of the data
+			{990, 100, val[990:]},
+			{0, int64(len(val)) + 1, val},
+		}
+		for _, tt := range tests {
+			ret, err := fdb.readRange(key, tt.offset, tt.length)
+			assert.NoError(t, err, "%s: offset %d, length %d", name, tt.offset, tt.length)
+			assert.Equal(t, tt.expected, ret, "%s: offset %d, length %d", name, tt.offset, tt.length)
+		}
+
+		invalidTests := []struct {
+			offset, length int64
+		}{
+			{int64(len(val)), 1},
+			{int64(len(val)) + 100, 10},
+			{-1, 10},
+			{0, 0},
+		}
+		for _, tt := range invalidTests {
+			_, err := fdb.readRange(key, tt.offset, tt.length)
+			assert.ErrorIs(t, err, errInvalidFileRange, "%s: offset %d, length %d", name, tt.offset, tt.length)
+		}
+	}
+}
@@ -93,6 +93,8 @@ var (
 	preimagePrefix = []byte("secure-key-")  // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("klay-config-") // config prefix for the db
 
+	migrationCursorPrefix = []byte("klay-migration-cursor-") // migrationCursorPrefix + db name -> the last migrated key
+
 	pruningEnabledKey        = []byte("PruningEnabled")
 	pruningMarkPrefix        = []byte("Pruning-")                                // KIP-111 pruning markings
 	pruningMarkValue         = []byte{0x01}                                      // A nonempty value to store a pruning mark
@@ -164,12 +164,17 @@ func (db *shardedDB) Delete(key []byte) error {
 	}
 }
 
-func (db *shardedDB) Close() {
+// Close closes all shards, and returns the first error of them.
+func (db *shardedDB) Close() error {
 	close(db.sdbBatchTaskCh)
 
+	var firstErr error
 	for _, shard := range db.shards {
-		shard.Close()
+		if err := shard.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
+	return firstErr
 }
 
 // Not enough size of channel slows down the iterator
@@ -200,6 +205,12 @@ func (db *shardedDB) NewIterator(prefix []byte, start []byte) Iterator {
 	return it
 }
 
+// NewIteratorWithRange creates a binary-alphabetical iterator over the keys in
+// [start, end).
+func (db *shardedDB) NewIteratorWithRange(start, end []byte) Iterator {
+	return newRangeIterator(db.NewIterator(nil, start), end)
+}
+
 // NewIteratorUnsorted creates a iterator over the entire keyspace contained within
 // the key-value database. This is useful when you want to get items fast in serial.
 // If you want to get ordered items in serial, checkout shardedDB.NewIterator()
@@ -417,9 +428,16 @@ func (it *shardedDBParallelIterator) Release() {
 }
 
 func (db *shardedDB) NewBatch() Batch {
+	return db.NewBatchWithSize(0)
+}
+
+// NewBatchWithSize creates a batch whose shard batches are pre-allocated for
+// the evenly distributed number of items.
+func (db *shardedDB) NewBatchWithSize(n int) Batch {
+	shardSize := (n + int(db.numShards) - 1) / int(db.numShards)
 	batches := make([]Batch, 0, db.numShards)
 	for i := 0; i < int(db.numShards); i++ {
-		batches = append(batches, db.shards[i].NewBatch())
+		batches = append(batches, db.shards[i].NewBatchWithSize(shardSize))
 	}
 
 	return &shardedDBBatch{
@@ -32,6 +32,7 @@ import (
 	"github.com/klaytn/klaytn/blockchain/vm"
 	"github.com/klaytn/klaytn/common"
 	"github.com/klaytn/klaytn/consensus"
+	"github.com/klaytn/klaytn/consensus/istanbul"
 	"github.com/klaytn/klaytn/consensus/misc"
 	"github.com/klaytn/klaytn/event"
 	klaytnmetrics "github.com/klaytn/klaytn/metrics"
@@ -265,8 +266,10 @@ func (self *worker) stop() {
 	}
 
 	// istanbul BFT
-	if istanbul, ok := self.engine.(consensus.Istanbul); ok {
-		istanbul.Stop()
+	if ist, ok := self.engine.(consensus.Istanbul); ok {
+		if err := ist.Stop(); err != nil && err != istanbul.ErrStoppedEngine {
+			logger.Error("Failed to stop istanbul engine", "err", err)
+		}
 	}
 
 	atomic.StoreInt32(&self.mining, 0)